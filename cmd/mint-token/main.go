@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/auth"
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/joho/godotenv"
+)
+
+// mint-token issues a token of the local auth provider for development and tests
+// signing key and issuer are read from LOCAL_AUTH_SIGNING_KEY and LOCAL_AUTH_ISSUER
+func main() {
+	email := flag.String("email", "", "email of the user")
+	role := flag.String("role", string(custom.ORGADMIN), "role of the user")
+	org := flag.String("org", "", "organization id of the user")
+	sub := flag.String("sub", "", "subject of the token, defaults to email")
+	ttl := flag.Duration("ttl", 24*time.Hour, "validity of the token")
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	if *email == "" {
+		log.Fatalln("email is required")
+	}
+	if !custom.UserRole(*role).IsValid() {
+		log.Fatalf("invalid role: %s\n", *role)
+	}
+
+	provider, err := auth.NewLocalProvider(os.Getenv("LOCAL_AUTH_SIGNING_KEY"), os.Getenv("LOCAL_AUTH_ISSUER"))
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	token, err := provider.Mint(auth.Identity{
+		Role:  custom.UserRole(*role),
+		OrgId: *org,
+		Email: *email,
+		Sub:   *sub,
+	}, *ttl)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Println(token)
+}
@@ -0,0 +1,50 @@
+package init
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"circledigital.in/real-state-erp/utils/auth"
+)
+
+// envOrDefault returns the environment variable or fallback when not set
+func envOrDefault(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// createAuthProvider creates the authentication provider selected by AUTH_PROVIDER
+// cognito is used when not set, local issuer is meant only for development and tests
+func (a *app) createAuthProvider() auth.Provider {
+	var provider auth.Provider
+	var err error
+
+	switch name := envOrDefault("AUTH_PROVIDER", "cognito"); name {
+	case "cognito":
+		provider, err = auth.NewCognitoProvider(os.Getenv("AWS_REGION"), os.Getenv("USER_POOL_ID"))
+	case "oidc":
+		provider, err = auth.NewOIDCProvider(auth.OIDCConfig{
+			Issuer:   os.Getenv("OIDC_ISSUER"),
+			JWKSURL:  os.Getenv("OIDC_JWKS_URL"),
+			Audience: os.Getenv("OIDC_AUDIENCE"),
+			Claims: auth.ClaimMapping{
+				Role:  envOrDefault("OIDC_ROLE_CLAIM", "role"),
+				OrgId: envOrDefault("OIDC_ORG_CLAIM", "org_id"),
+				Email: envOrDefault("OIDC_EMAIL_CLAIM", "email"),
+			},
+		})
+	case "local":
+		log.Println("Using local auth provider, not meant for production.")
+		provider, err = auth.NewLocalProvider(os.Getenv("LOCAL_AUTH_SIGNING_KEY"), os.Getenv("LOCAL_AUTH_ISSUER"))
+	default:
+		log.Fatalf("Unsupported auth provider: %s\n", name)
+	}
+
+	if err != nil {
+		log.Fatalf("Failed to create auth provider.\nError: %s", err)
+	}
+	return provider
+}
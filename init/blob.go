@@ -0,0 +1,42 @@
+package init
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"circledigital.in/real-state-erp/utils/blob"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// createBlobStore creates the store of generated files selected by BLOB_STORE (local | s3), local is used when not set
+// local store keeps files under BLOB_LOCAL_DIR, s3 store uses S3_BUCKET, S3_REGION and S3_ENDPOINT for s3 compatible services
+func (a *app) createBlobStore() blob.Store {
+	switch name := envOrDefault("BLOB_STORE", "local"); name {
+	case "local":
+		store, err := blob.NewLocalStore(envOrDefault("BLOB_LOCAL_DIR", "data/blobs"))
+		if err != nil {
+			log.Fatalf("Error creating local blob store: %v\n", err)
+		}
+		return store
+	case "s3":
+		cfg, err := config.LoadDefaultConfig(context.TODO())
+		if err != nil {
+			log.Fatalf("unable to load SDK config, %v", err)
+		}
+		region := envOrDefault("S3_REGION", cfg.Region)
+		store, err := blob.NewS3Store(blob.S3Config{
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    region,
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			PathStyle: os.Getenv("S3_FORCE_PATH_STYLE") == "true",
+		}, cfg.Credentials)
+		if err != nil {
+			log.Fatalf("Error creating s3 blob store: %v\n", err)
+		}
+		return store
+	default:
+		log.Fatalf("Unsupported blob store: %s\n", name)
+		return nil
+	}
+}
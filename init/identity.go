@@ -0,0 +1,31 @@
+package init
+
+import (
+	"log"
+	"os"
+
+	"circledigital.in/real-state-erp/utils/auth"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/identity"
+)
+
+// createIdentityProvider creates the identity provider selected by IDENTITY_PROVIDER, cognito is used when not set
+// database provider issues tokens of the local auth provider so it requires AUTH_PROVIDER to be local,
+// it mails reset tokens through the email transport with a link to PASSWORD_RESET_URL when set
+func (a *app) createIdentityProvider() common.IdentityProvider {
+	switch name := envOrDefault("IDENTITY_PROVIDER", "cognito"); name {
+	case "cognito":
+		a.aws = a.createAWSConfig()
+		return identity.NewCognitoProvider(a.aws.GetCognitoClient(), os.Getenv("USER_POOL_ID"))
+	case "database":
+		issuer, ok := a.auth.(*auth.LocalProvider)
+		if !ok {
+			log.Fatalln("Database identity provider requires local auth provider.")
+		}
+		return identity.NewDatabaseProvider(a.dbClient, issuer, a.transports[custom.CHANNEL_EMAIL], os.Getenv("PASSWORD_RESET_URL"))
+	default:
+		log.Fatalf("Unsupported identity provider: %s\n", name)
+		return nil
+	}
+}
@@ -1,9 +1,12 @@
 package init
 
 import (
+	"circledigital.in/real-state-erp/utils/auth"
+	"circledigital.in/real-state-erp/utils/blob"
 	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
 	"circledigital.in/real-state-erp/utils/payload"
-	"github.com/MicahParks/keyfunc/v3"
 	"github.com/go-chi/chi/v5"
 	"gorm.io/gorm"
 	"log"
@@ -13,10 +16,13 @@ import (
 
 // app is implementation of App interface
 type app struct {
-	mux      *chi.Mux
-	dbClient *gorm.DB
-	aws      *awsConfig
-	jwtKey   keyfunc.Keyfunc
+	mux        *chi.Mux
+	dbClient   *gorm.DB
+	aws        *awsConfig
+	auth       auth.Provider
+	identity   common.IdentityProvider
+	blob       blob.Store
+	transports map[custom.NotificationChannel]notification.Transport
 }
 
 func (a *app) GetRouter() *chi.Mux {
@@ -31,6 +37,14 @@ func (a *app) GetAWSConfig() common.IAWSConfig {
 	return a.aws
 }
 
+func (a *app) GetIdentityProvider() common.IdentityProvider {
+	return a.identity
+}
+
+func (a *app) GetBlobStore() blob.Store {
+	return a.blob
+}
+
 // initApplication configures all the objects required for startup of application
 func (a *app) initApplication() {
 	// register validators
@@ -40,11 +54,16 @@ func (a *app) initApplication() {
 	}
 
 	a.dbClient = a.createDBClient()
-	a.aws = a.createAWSConfig()
-	a.jwtKey = a.createJWTKeyFunc()
+	a.auth = a.createAuthProvider()
+	a.transports = a.createNotificationTransports()
+	a.identity = a.createIdentityProvider()
+	a.configureNotifications()
+	a.blob = a.createBlobStore()
 
 	// route multiplexer at end inorder to get all the fields required by the services
 	a.mux = a.createRouter()
+
+	a.startWorkers()
 }
 
 func GetApplication() common.IApp {
@@ -0,0 +1,78 @@
+package init
+
+import (
+	"log"
+	"os"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
+)
+
+// configureNotifications sets the unsubscribe links of rendered notifications
+func (a *app) configureNotifications() {
+	notification.Configure(notification.Config{
+		UnsubscribeUrl: os.Getenv("NOTIFICATION_UNSUBSCRIBE_URL"),
+		Secret:         os.Getenv("NOTIFICATION_SECRET"),
+	})
+}
+
+// createNotificationTransports creates the transports selected by NOTIFICATION_EMAIL_TRANSPORT (smtp | log)
+// and NOTIFICATION_SMS_TRANSPORT (http | log), log transport writes messages to NOTIFICATION_LOG_FILE or stdout
+func (a *app) createNotificationTransports() map[custom.NotificationChannel]notification.Transport {
+	var logTransport notification.Transport
+	logSink := func() notification.Transport {
+		if logTransport != nil {
+			return logTransport
+		}
+		writer := os.Stdout
+		if path := os.Getenv("NOTIFICATION_LOG_FILE"); path != "" {
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+			if err != nil {
+				log.Fatalf("Failed to open notification log file.\nError: %s", err)
+			}
+			writer = file
+		}
+		logTransport = notification.NewLogTransport(writer)
+		return logTransport
+	}
+
+	transports := make(map[custom.NotificationChannel]notification.Transport)
+
+	switch name := envOrDefault("NOTIFICATION_EMAIL_TRANSPORT", "log"); name {
+	case "smtp":
+		transport, err := notification.NewSMTPTransport(notification.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		})
+		if err != nil {
+			log.Fatalf("Failed to create email transport.\nError: %s", err)
+		}
+		transports[custom.CHANNEL_EMAIL] = transport
+	case "log":
+		transports[custom.CHANNEL_EMAIL] = logSink()
+	default:
+		log.Fatalf("Unsupported email transport: %s\n", name)
+	}
+
+	switch name := envOrDefault("NOTIFICATION_SMS_TRANSPORT", "log"); name {
+	case "http":
+		transport, err := notification.NewSMSGatewayTransport(notification.SMSGatewayConfig{
+			Url:      os.Getenv("SMS_GATEWAY_URL"),
+			Token:    os.Getenv("SMS_GATEWAY_TOKEN"),
+			SenderId: os.Getenv("SMS_SENDER_ID"),
+		})
+		if err != nil {
+			log.Fatalf("Failed to create sms transport.\nError: %s", err)
+		}
+		transports[custom.CHANNEL_SMS] = transport
+	case "log":
+		transports[custom.CHANNEL_SMS] = logSink()
+	default:
+		log.Fatalf("Unsupported sms transport: %s\n", name)
+	}
+
+	return transports
+}
@@ -3,17 +3,27 @@ package init
 import (
 	"net/http"
 
+	"circledigital.in/real-state-erp/services/account"
+	"circledigital.in/real-state-erp/services/analytics"
+	"circledigital.in/real-state-erp/services/approval"
+	"circledigital.in/real-state-erp/services/audit"
 	"circledigital.in/real-state-erp/services/bank"
 	"circledigital.in/real-state-erp/services/broker"
+	"circledigital.in/real-state-erp/services/construction"
 	"circledigital.in/real-state-erp/services/flat"
+	"circledigital.in/real-state-erp/services/job"
+	"circledigital.in/real-state-erp/services/notification"
 	"circledigital.in/real-state-erp/services/organization"
 	"circledigital.in/real-state-erp/services/receipt"
+	"circledigital.in/real-state-erp/services/reminder"
 	"circledigital.in/real-state-erp/services/reports"
 	"circledigital.in/real-state-erp/services/sale"
 	"circledigital.in/real-state-erp/services/society"
 	"circledigital.in/real-state-erp/services/tower"
+	"circledigital.in/real-state-erp/services/webhook"
 
 	paymentPlanGroup "circledigital.in/real-state-erp/services/payment-plan-group"
+	recycleBin "circledigital.in/real-state-erp/services/recycle-bin"
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	appMiddleware "circledigital.in/real-state-erp/utils/middleware"
@@ -37,8 +47,24 @@ var services = []serviceFactory{
 	paymentPlanGroup.CreatePaymentPlanService,
 	broker.CreateBrokerService,
 	bank.CreateBankService,
+	construction.CreateConstructionService,
 	receipt.CreateReceiptService,
 	reports.NewReportService,
+	reports.CreateReportTemplateService,
+	analytics.CreateAnalyticsService,
+	audit.CreateAuditService,
+	recycleBin.CreateRecycleBinService,
+	approval.CreateApprovalService,
+	webhook.CreateWebhookService,
+	notification.CreateNotificationService,
+	reminder.CreateReminderService,
+	job.CreateJobService,
+}
+
+// publicServices are mounted without authentication, their handlers authenticate the request themselves
+var publicServices = []serviceFactory{
+	account.CreateAccountService,
+	notification.CreateUnsubscribeService,
 }
 
 // handle400 returns custom responses for not found routes and not allowed methods
@@ -73,24 +99,48 @@ func (a *app) createRouter() *chi.Mux {
 	mux.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 	a.handle400(mux)
 
-	// add authentication middleware
-	authenticationMiddleware := appMiddleware.AuthenticationMiddleware{
-		JWKS: a.jwtKey,
-	}
-	mux.Use(authenticationMiddleware.AuthenticateRequest)
-
-	// add services routes
-	for _, factory := range services {
-		service := factory(a)
-		mux.Mount(service.GetBasePath(), service.GetRoutes())
-	}
+	// add public services routes
+	mux.Group(func(router chi.Router) {
+		for _, factory := range publicServices {
+			service := factory(a)
+			router.Mount(service.GetBasePath(), service.GetRoutes())
+		}
+	})
+
+	mux.Group(func(router chi.Router) {
+		// add authentication middleware
+		authenticationMiddleware := appMiddleware.AuthenticationMiddleware{
+			Provider: a.auth,
+			DB:       a.dbClient,
+		}
+		router.Use(authenticationMiddleware.AuthenticateRequest)
+
+		// resolve permissions and society membership of organization users
+		accessMiddleware := appMiddleware.AccessMiddleware{
+			DB: a.dbClient,
+		}
+		router.Use(accessMiddleware.LoadUserAccess)
+
+		// record mutating requests, router is used to resolve the route before the handler runs
+		auditMiddleware := appMiddleware.AuditMiddleware{
+			DB:     a.dbClient,
+			Router: mux,
+		}
+		router.Use(auditMiddleware.RecordRequest)
+
+		// add services routes
+		for _, factory := range services {
+			service := factory(a)
+			router.Mount(service.GetBasePath(), service.GetRoutes())
+		}
+	})
 
 	return mux
 }
@@ -0,0 +1,42 @@
+package init
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/jobs"
+	"circledigital.in/real-state-erp/utils/notification"
+	"circledigital.in/real-state-erp/utils/reminder"
+	"circledigital.in/real-state-erp/utils/webhook"
+)
+
+// startWorkers starts the background workers of the application, workers run for the lifetime of the process
+func (a *app) startWorkers() {
+	dispatcher := webhook.NewDispatcher(a.dbClient)
+	go dispatcher.Run(context.Background())
+
+	sender := notification.NewSender(a.dbClient, a.transports)
+	go sender.Run(context.Background())
+
+	// payment reminders run daily after REMINDER_RUN_HOUR (server local time), 9 when not set
+	runHour, err := strconv.Atoi(envOrDefault("REMINDER_RUN_HOUR", "9"))
+	if err != nil || runHour < 0 || runHour > 23 {
+		log.Fatalf("Invalid REMINDER_RUN_HOUR: %s\n", envOrDefault("REMINDER_RUN_HOUR", "9"))
+	}
+	scheduler := reminder.NewScheduler(reminder.NewEngine(a.dbClient), runHour)
+	go scheduler.Run(context.Background())
+
+	// background jobs run on JOB_WORKERS goroutines, 2 when not set, results are kept for JOB_RESULT_DAYS days
+	workers, err := strconv.Atoi(envOrDefault("JOB_WORKERS", "2"))
+	if err != nil || workers < 1 {
+		log.Fatalf("Invalid JOB_WORKERS: %s\n", envOrDefault("JOB_WORKERS", "2"))
+	}
+	resultDays, err := strconv.Atoi(envOrDefault("JOB_RESULT_DAYS", "7"))
+	if err != nil || resultDays < 1 {
+		log.Fatalf("Invalid JOB_RESULT_DAYS: %s\n", envOrDefault("JOB_RESULT_DAYS", "7"))
+	}
+	runner := jobs.NewRunner(a.dbClient, a.blob, workers, time.Duration(resultDays)*24*time.Hour)
+	go runner.Run(context.Background())
+}
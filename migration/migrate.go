@@ -10,8 +10,12 @@ import (
 func Migrate(db *gorm.DB) {
 	err := db.AutoMigrate(
 		&models.Organization{},
+		&models.Role{},
 		&models.User{},
+		&models.UserCredential{},
+		&models.ApiKey{},
 		&models.Society{},
+		&models.UserSociety{},
 		//&models.FlatType{},
 		&models.Tower{},
 		&models.Flat{},
@@ -31,6 +35,27 @@ func Migrate(db *gorm.DB) {
 		&models.Bank{},
 		&models.Receipt{},
 		&models.ReceiptClear{},
+		&models.BankTransfer{},
+		&models.SaleAllotment{},
+		&models.ConstructionMilestone{},
+		&models.ConstructionMilestonePhoto{},
+		&models.BrokerCommissionSchedule{},
+		&models.BrokerPayout{},
+		&models.BrokerCommission{},
+		&models.AuditLog{},
+		&models.ApprovalPolicy{},
+		&models.ApprovalRequest{},
+		&models.WebhookEndpoint{},
+		&models.OutboxEvent{},
+		&models.WebhookDelivery{},
+		&models.NotificationTemplate{},
+		&models.NotificationOptOut{},
+		&models.NotificationLog{},
+		&models.PaymentReminderPolicy{},
+		&models.PaymentReminder{},
+		&models.CollectionTask{},
+		&models.Job{},
+		&models.ReportTemplate{},
 	)
 
 	// err := db.Migrator().DropTable(
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/shopspring/decimal"
+)
+
+// AgeingBucket is a range of days past the due date, To is 0 for the last bucket which has no upper bound
+type AgeingBucket struct {
+	Label string `json:"label"`
+	From  int    `json:"from"`
+	To    int    `json:"to,omitempty"`
+}
+
+// AgeingBuckets are the buckets receivables are aged in, in order, by the ageing report and the dashboard alike
+// items not due yet are not aged in any bucket
+var AgeingBuckets = []AgeingBucket{
+	{Label: "0-30", From: 0, To: 30},
+	{Label: "31-60", From: 31, To: 60},
+	{Label: "61-90", From: 61, To: 90},
+	{Label: "90+", From: 91},
+}
+
+// AgeingBucketOf returns the index of the bucket of the days past due, -1 when not due yet
+func AgeingBucketOf(daysOverdue int) int {
+	for i, bucket := range AgeingBuckets {
+		if daysOverdue >= bucket.From && (bucket.To == 0 || daysOverdue <= bucket.To) {
+			return i
+		}
+	}
+	return -1
+}
+
+// OverdueItem is the unpaid balance of an active payment plan item whose due date has passed
+type OverdueItem struct {
+	Item        PaymentPlanRatioItem
+	DueDate     custom.DateOnly
+	DaysOverdue int
+	Amount      decimal.Decimal
+}
+
+// OverdueItems returns the items of the sale due on or before activationCtx.AsOf with a remaining balance
+// paid amount is distributed over the items in plan order, PaymentPlanRatio with its items must be loaded
+func (u Sale) OverdueItems(activationCtx PaymentActivationContext) []OverdueItem {
+	breakDown := u.GetPaymentPlanBreakDown(activationCtx)
+
+	items := make([]OverdueItem, 0)
+	for _, detail := range breakDown.Details {
+		if detail.Activation == nil || detail.Finance == nil || !detail.Finance.Remaining.IsPositive() {
+			continue
+		}
+
+		days := DaysBetween(detail.Activation.DueDate.Time, activationCtx.AsOf)
+		if days < 0 {
+			continue
+		}
+		items = append(items, OverdueItem{
+			Item:        detail.Item,
+			DueDate:     detail.Activation.DueDate,
+			DaysOverdue: days,
+			Amount:      detail.Finance.Remaining,
+		})
+	}
+	return items
+}
+
+// LastReceiptDate returns the issue date of the latest receipt of the sale, failed and adjustment receipts are skipped
+func (u Sale) LastReceiptDate() *time.Time {
+	var last *time.Time
+	for _, receipt := range u.Receipts {
+		if receipt.Failed || receipt.Mode == custom.ADJUSTMENT {
+			continue
+		}
+
+		issued := receipt.CreatedAt
+		if receipt.DateIssued.Valid {
+			issued = receipt.DateIssued.Time
+		}
+		if last == nil || issued.After(*last) {
+			last = &issued
+		}
+	}
+	return last
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+func TestAgeingBucketOf(t *testing.T) {
+	tests := map[int]int{-1: -1, 0: 0, 30: 0, 31: 1, 60: 1, 61: 2, 90: 2, 91: 3, 1000: 3}
+	for days, want := range tests {
+		if got := AgeingBucketOf(days); got != want {
+			t.Errorf("AgeingBucketOf(%d) = %d, want %d", days, got, want)
+		}
+	}
+}
+
+func TestSaleOverdueItems(t *testing.T) {
+	cleared := &ReceiptClear{CreatedAt: date(2025, time.January, 12)}
+	sale := Sale{
+		CreatedAt:  date(2025, time.January, 10),
+		TotalPrice: decimal.NewFromInt(1000),
+		PaymentPlanRatio: &PaymentPlanRatio{Ratios: []PaymentPlanRatioItem{
+			{Description: "Booking", Ratio: "10", Scope: custom.SCOPE_SALE, ConditionType: custom.ONBOOKING},
+			{Description: "Within 30 days", Ratio: "20", Scope: custom.SCOPE_SALE, ConditionType: custom.WITHINDAYS, ConditionValue: 30, CreatedAt: date(2025, time.January, 10)},
+			{Description: "Within 90 days", Ratio: "30", Scope: custom.SCOPE_SALE, ConditionType: custom.WITHINDAYS, ConditionValue: 90, CreatedAt: date(2025, time.January, 10)},
+		}},
+		Receipts: []Receipt{
+			{TotalAmount: decimal.NewFromInt(150), Mode: custom.CHEQUE, DateIssued: pgtype.Date{Time: date(2025, time.January, 11), Valid: true}, Cleared: cleared},
+			{TotalAmount: decimal.NewFromInt(500), Mode: custom.CHEQUE, DateIssued: pgtype.Date{Time: date(2025, time.February, 20), Valid: true}, Failed: true},
+		},
+	}
+
+	asOf := date(2025, time.March, 20)
+	items := sale.OverdueItems(NewPaymentActivationContext(asOf, &sale, nil, nil))
+	if len(items) != 1 {
+		t.Fatalf("want only the within 30 days item overdue, got %+v", items)
+	}
+	item := items[0]
+	if item.Item.Description != "Within 30 days" || !item.Amount.Equal(decimal.NewFromInt(150)) {
+		t.Errorf("want 150 of within 30 days overdue, got %s of %s", item.Amount, item.Item.Description)
+	}
+	if item.DaysOverdue != 39 || AgeingBucketOf(item.DaysOverdue) != 1 {
+		t.Errorf("want 39 days overdue, got %d", item.DaysOverdue)
+	}
+
+	if last := sale.LastReceiptDate(); last == nil || !last.Equal(date(2025, time.January, 11)) {
+		t.Errorf("want last receipt on 2025-01-11 skipping failed receipt, got %v", last)
+	}
+	if last := (Sale{}).LastReceiptDate(); last != nil {
+		t.Errorf("want no last receipt, got %v", last)
+	}
+}
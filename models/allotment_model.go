@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+// SaleAllotment records the allotment of a flat to the buyers of a sale
+// a sale can only be allotted once, payment plan items with on-allotment condition are due from AllotmentDate
+type SaleAllotment struct {
+	Id            uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SaleId        uuid.UUID       `gorm:"not null;uniqueIndex" json:"saleId"`
+	Sale          *Sale           `gorm:"foreignKey:SaleId;constraint:OnDelete:CASCADE" json:"sale,omitempty"`
+	SocietyId     string          `gorm:"not null;index;uniqueIndex:idx_society_allotment_letter" json:"societyId"`
+	OrgId         uuid.UUID       `gorm:"not null;index;uniqueIndex:idx_society_allotment_letter" json:"orgId"`
+	LetterNumber  string          `gorm:"not null;uniqueIndex:idx_society_allotment_letter" json:"letterNumber"`
+	AllotmentDate custom.DateOnly `gorm:"type:date;not null" json:"allotmentDate"`
+	IssuedBy      string          `gorm:"not null" json:"issuedBy"`
+	CreatedAt     time.Time       `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time       `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (a SaleAllotment) GetCreatedAt() time.Time {
+	return a.CreatedAt
+}
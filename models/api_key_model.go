@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+// StringList is a list of strings stored as jsonb
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal StringList: %v", value)
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// ApiKey is an organization scoped key used by machine integrations, only the hash of the key is stored
+// requests made with the key get the permissions in scopes on the allowed societies, every society when empty
+type ApiKey struct {
+	Id           uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId        uuid.UUID      `gorm:"not null;index" json:"orgId"`
+	Organization *Organization  `gorm:"foreignKey:OrgId;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"organization,omitempty"`
+	Name         string         `gorm:"not null" json:"name"`
+	Prefix       string         `gorm:"not null" json:"prefix"`
+	KeyHash      string         `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes       PermissionList `gorm:"type:jsonb;not null" json:"scopes"`
+	Societies    StringList     `gorm:"type:jsonb;not null" json:"societies"`
+	ExpiresAt    *time.Time     `json:"expiresAt,omitempty"`
+	LastUsedAt   *time.Time     `json:"lastUsedAt,omitempty"`
+	RevokedAt    *time.Time     `json:"revokedAt,omitempty"`
+	CreatedBy    string         `json:"createdBy"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (k ApiKey) GetCreatedAt() time.Time {
+	return k.CreatedAt
+}
+
+// Usable reports whether the key is neither revoked nor expired
+func (k *ApiKey) Usable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// Access returns the access of requests made with the key
+func (k *ApiKey) Access() *custom.UserAccess {
+	access := &custom.UserAccess{
+		Permissions: make(map[custom.Permission]bool, len(k.Scopes)),
+	}
+	for _, permission := range k.Scopes {
+		access.Permissions[permission] = true
+	}
+
+	if len(k.Societies) > 0 {
+		access.Societies = make(map[string]bool, len(k.Societies))
+		for _, society := range k.Societies {
+			access.Societies[society] = true
+		}
+	}
+	return access
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+func TestApiKeyUsable(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	if !(&ApiKey{}).Usable(now) {
+		t.Error("want key without expiry usable")
+	}
+	if !(&ApiKey{ExpiresAt: &future}).Usable(now) {
+		t.Error("want unexpired key usable")
+	}
+	if (&ApiKey{ExpiresAt: &past}).Usable(now) {
+		t.Error("want expired key rejected")
+	}
+	if (&ApiKey{RevokedAt: &past}).Usable(now) {
+		t.Error("want revoked key rejected")
+	}
+}
+
+func TestApiKeyAccess(t *testing.T) {
+	key := ApiKey{Scopes: PermissionList{custom.PERMISSION_SALE_VIEW}}
+	access := key.Access()
+	if !access.Can(custom.PERMISSION_SALE_VIEW) || access.Can(custom.PERMISSION_SALE_CREATE) {
+		t.Error("want access limited to key scopes")
+	}
+	if !access.CanAccessSociety("any") {
+		t.Error("want key without societies to access every society")
+	}
+
+	key.Societies = StringList{"rera-1"}
+	access = key.Access()
+	if !access.CanAccessSociety("rera-1") || access.CanAccessSociety("rera-2") {
+		t.Error("want access limited to allowed societies")
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ApprovalPolicy enables maker-checker approval of an action for the organization
+// actions without a policy are executed right away
+type ApprovalPolicy struct {
+	OrgId     uuid.UUID             `gorm:"type:uuid;primaryKey" json:"orgId"`
+	Action    custom.ApprovalAction `gorm:"primaryKey" json:"action"`
+	Enabled   bool                  `gorm:"not null;default:false" json:"enabled"`
+	Threshold *decimal.Decimal      `gorm:"type:numeric" json:"threshold,omitempty"`
+	UpdatedBy string                `json:"updatedBy"`
+	CreatedAt time.Time             `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time             `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// Requires reports whether an operation of amount needs approval
+// threshold applies only to actions with an amount, approval is required above the threshold
+func (p ApprovalPolicy) Requires(amount *decimal.Decimal) bool {
+	if !p.Enabled {
+		return false
+	}
+	if p.Threshold == nil || amount == nil {
+		return true
+	}
+	return amount.Abs().GreaterThan(*p.Threshold)
+}
+
+// ApprovalRequest is a pending sensitive operation, payload is the request executed once approved
+type ApprovalRequest struct {
+	Id             uuid.UUID             `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId          uuid.UUID             `gorm:"not null;index" json:"orgId"`
+	SocietyId      string                `gorm:"not null;index" json:"societyId"`
+	Society        *Society              `gorm:"foreignKey:SocietyId,OrgId;references:ReraNumber,OrgId;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"society,omitempty"`
+	Action         custom.ApprovalAction `gorm:"not null;index" json:"action"`
+	EntityId       string                `gorm:"not null;index" json:"entityId"`
+	Amount         *decimal.Decimal      `gorm:"type:numeric" json:"amount,omitempty"`
+	Payload        RawJSON               `gorm:"type:jsonb" json:"payload,omitempty"`
+	Status         custom.ApprovalStatus `gorm:"not null;index;default:'pending'" json:"status"`
+	RequestedBy    string                `gorm:"not null" json:"requestedBy"`
+	ApprovedBy     string                `json:"approvedBy,omitempty"`
+	DecidedAt      *time.Time            `json:"decidedAt,omitempty"`
+	Remarks        string                `json:"remarks,omitempty"`
+	ExecutionError string                `json:"executionError,omitempty"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time             `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (r ApprovalRequest) GetCreatedAt() time.Time {
+	return r.CreatedAt
+}
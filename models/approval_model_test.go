@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestApprovalPolicyRequires(t *testing.T) {
+	threshold := decimal.NewFromInt(100000)
+	small := decimal.NewFromInt(-50000)
+	large := decimal.NewFromInt(-150000)
+
+	disabled := ApprovalPolicy{Threshold: &threshold}
+	if disabled.Requires(&large) {
+		t.Error("want disabled policy to never require approval")
+	}
+
+	policy := ApprovalPolicy{Enabled: true, Threshold: &threshold}
+	if policy.Requires(&small) {
+		t.Error("want amount below threshold executed right away")
+	}
+	if !policy.Requires(&large) {
+		t.Error("want negative amount above threshold to require approval")
+	}
+	if !policy.Requires(nil) {
+		t.Error("want action without amount to require approval")
+	}
+
+	always := ApprovalPolicy{Enabled: true}
+	if !always.Requires(&small) {
+		t.Error("want policy without threshold to require approval")
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RawJSON holds raw json stored as jsonb, empty value is stored as null
+type RawJSON json.RawMessage
+
+func (j RawJSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *RawJSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = RawJSON(v)
+	default:
+		return fmt.Errorf("failed to unmarshal RawJSON: %v", value)
+	}
+	return nil
+}
+
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *RawJSON) UnmarshalJSON(b []byte) error {
+	*j = append((*j)[:0], b...)
+	return nil
+}
+
+// AuditChange is the before and after value of a changed field
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditLog records a mutating api call with the state of the affected entity
+type AuditLog struct {
+	Id         uuid.UUID  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId      *uuid.UUID `gorm:"index" json:"orgId,omitempty"`
+	SocietyId  string     `gorm:"index" json:"societyId,omitempty"`
+	ActorEmail string     `gorm:"index" json:"actorEmail"`
+	ActorSub   string     `json:"actorSub"`
+	ActorRole  string     `json:"actorRole"`
+	ApiKeyId   *uuid.UUID `gorm:"type:uuid;index" json:"apiKeyId,omitempty"`
+	Method     string     `gorm:"not null" json:"method"`
+	Route      string     `gorm:"not null" json:"route"`
+	Path       string     `gorm:"not null" json:"path"`
+	EntityType string     `gorm:"index:idx_audit_entity" json:"entityType"`
+	EntityId   string     `gorm:"index:idx_audit_entity" json:"entityId,omitempty"`
+	StatusCode int        `json:"statusCode"`
+	Request    RawJSON    `gorm:"type:jsonb" json:"request,omitempty"`
+	Before     RawJSON    `gorm:"type:jsonb" json:"before,omitempty"`
+	After      RawJSON    `gorm:"type:jsonb" json:"after,omitempty"`
+	Diff       RawJSON    `gorm:"type:jsonb" json:"diff,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+func (a AuditLog) GetCreatedAt() time.Time {
+	return a.CreatedAt
+}
+
+// DiffAuditState returns the fields whose value differs between before and after
+// a missing state (create or delete) reports every field of the other state
+func DiffAuditState(before, after map[string]any) map[string]AuditChange {
+	diff := make(map[string]AuditChange)
+
+	for key, beforeValue := range before {
+		afterValue, ok := after[key]
+		if !ok || !sameAuditValue(beforeValue, afterValue) {
+			diff[key] = AuditChange{Before: beforeValue, After: afterValue}
+		}
+	}
+
+	for key, afterValue := range after {
+		if _, ok := before[key]; !ok {
+			diff[key] = AuditChange{After: afterValue}
+		}
+	}
+
+	// bookkeeping columns change on every update
+	delete(diff, "updated_at")
+	return diff
+}
+
+func sameAuditValue(a, b any) bool {
+	aJson, aErr := json.Marshal(a)
+	bJson, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+	return string(aJson) == string(bJson)
+}
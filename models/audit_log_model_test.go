@@ -0,0 +1,20 @@
+package models
+
+import "testing"
+
+func TestDiffAuditState(t *testing.T) {
+	before := map[string]any{"name": "A", "price": "100", "updated_at": "t1"}
+	after := map[string]any{"name": "B", "price": "100", "updated_at": "t2"}
+
+	diff := DiffAuditState(before, after)
+	if len(diff) != 1 {
+		t.Fatalf("want only name changed, got %v", diff)
+	}
+	if change := diff["name"]; change.Before != "A" || change.After != "B" {
+		t.Errorf("want A -> B, got %v", change)
+	}
+
+	if diff := DiffAuditState(before, nil); len(diff) != 2 {
+		t.Errorf("want every field on delete except updated_at, got %v", diff)
+	}
+}
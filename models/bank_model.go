@@ -1,27 +1,60 @@
 package models
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"time"
 )
 
 type Bank struct {
-	Id              uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
-	SocietyId       string         `gorm:"not null;index;uniqueIndex:idx_society_bank_account_number" json:"societyId"`
-	OrgId           uuid.UUID      `gorm:"not null;index;uniqueIndex:idx_society_bank_account_number" json:"orgId"`
-	Society         *Society       `gorm:"foreignKey:SocietyId,OrgId;references:ReraNumber,OrgId;not null;constraint:OnUpdate:CASCADE" json:"society,omitempty"`
-	Name            string         `gorm:"not null" json:"name"`
-	AccountNumber   string         `gorm:"not null;uniqueIndex:idx_society_bank_account_number" json:"accountNumber"`
-	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
-	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
-	ClearedReceipts []ReceiptClear `gorm:"foreignKey:BankId" json:"clearedReceipts,omitempty"`
+	Id              uuid.UUID              `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SocietyId       string                 `gorm:"not null;index;uniqueIndex:idx_society_bank_account_number" json:"societyId"`
+	OrgId           uuid.UUID              `gorm:"not null;index;uniqueIndex:idx_society_bank_account_number" json:"orgId"`
+	Society         *Society               `gorm:"foreignKey:SocietyId,OrgId;references:ReraNumber,OrgId;not null;constraint:OnUpdate:CASCADE" json:"society,omitempty"`
+	Name            string                 `gorm:"not null" json:"name"`
+	AccountNumber   string                 `gorm:"not null;uniqueIndex:idx_society_bank_account_number" json:"accountNumber"`
+	AccountType     custom.BankAccountType `gorm:"not null;default:collection" json:"accountType"`
+	CreatedAt       time.Time              `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time              `gorm:"autoUpdateTime" json:"updatedAt"`
+	ClearedReceipts []ReceiptClear         `gorm:"foreignKey:BankId" json:"clearedReceipts,omitempty"`
 }
 
 func (u Bank) GetCreatedAt() time.Time {
 	return u.CreatedAt
 }
 
+// RequiredTransfer returns the amount to transfer to the designated account out of an amount realised in the account,
+// nothing is due when it is realised in the designated account itself
+func (u Bank) RequiredTransfer(realised decimal.Decimal) decimal.Decimal {
+	if u.AccountType == custom.BANK_ACCOUNT_DESIGNATED {
+		return decimal.Zero
+	}
+	return RequiredDesignatedDeposit(realised)
+}
+
+// BankTransfer is money moved between two bank accounts of a society,
+// transfers into the designated account are deposits and out of it are withdrawals
+type BankTransfer struct {
+	Id           uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SocietyId    string          `gorm:"not null;index" json:"societyId"`
+	OrgId        uuid.UUID       `gorm:"not null;index" json:"orgId"`
+	FromBankId   uuid.UUID       `gorm:"not null;index" json:"fromBankId"`
+	FromBank     *Bank           `gorm:"foreignKey:FromBankId" json:"fromBank,omitempty"`
+	ToBankId     uuid.UUID       `gorm:"not null;index" json:"toBankId"`
+	ToBank       *Bank           `gorm:"foreignKey:ToBankId" json:"toBank,omitempty"`
+	Amount       decimal.Decimal `gorm:"type:numeric;not null" json:"amount"`
+	TransferDate custom.DateOnly `gorm:"type:date;not null" json:"transferDate"`
+	Reference    string          `json:"reference"`
+	Remarks      string          `json:"remarks"`
+	CreatedBy    string          `json:"createdBy"`
+	CreatedAt    time.Time       `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (t BankTransfer) GetCreatedAt() time.Time {
+	return t.CreatedAt
+}
+
 type BankReport struct {
 	TotalAmount decimal.Decimal `json:"totalAmount"`
 	Details     Bank
@@ -0,0 +1,190 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var decimalHundred = decimal.NewFromInt(100)
+
+// CommissionSlab applies Rate (percent of basic cost) once the broker has at least MinBookings bookings in the society
+type CommissionSlab struct {
+	MinBookings int             `json:"minBookings" validate:"gte=1"`
+	Rate        decimal.Decimal `json:"rate"`
+}
+
+type CommissionSlabs []CommissionSlab
+
+func (s CommissionSlabs) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *CommissionSlabs) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal CommissionSlabs: %v", value)
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// GetRate returns the rate of the highest slab reached by the booking count
+func (s CommissionSlabs) GetRate(bookings int) decimal.Decimal {
+	rate := decimal.Zero
+	reached := 0
+	for _, slab := range s {
+		if bookings >= slab.MinBookings && slab.MinBookings > reached {
+			rate = slab.Rate
+			reached = slab.MinBookings
+		}
+	}
+	return rate
+}
+
+// BrokerCommissionSchedule defines how commission is computed for sales of a broker
+// schedules without a broker are the society default
+type BrokerCommissionSchedule struct {
+	Id                 uuid.UUID             `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SocietyId          string                `gorm:"not null;index" json:"societyId"`
+	OrgId              uuid.UUID             `gorm:"not null;index" json:"orgId"`
+	Society            *Society              `gorm:"foreignKey:SocietyId,OrgId;references:ReraNumber,OrgId;not null;constraint:OnUpdate:CASCADE" json:"society,omitempty"`
+	BrokerId           *uuid.UUID            `gorm:"index" json:"brokerId,omitempty"`
+	Broker             *Broker               `gorm:"foreignKey:BrokerId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"broker,omitempty"`
+	Name               string                `gorm:"not null" json:"name"`
+	Type               custom.CommissionType `gorm:"not null" json:"type"`
+	Rate               decimal.Decimal       `gorm:"type:numeric;not null;default:0" json:"rate"`
+	Slabs              CommissionSlabs       `gorm:"type:jsonb" json:"slabs,omitempty"`
+	EligibilityPercent decimal.Decimal       `gorm:"type:numeric;not null;default:0" json:"eligibilityPercent"`
+	EffectiveFrom      custom.DateOnly       `gorm:"type:date;not null" json:"effectiveFrom"`
+	CreatedAt          time.Time             `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time             `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (s BrokerCommissionSchedule) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+// CalcCommission returns the commission on a sale, bookings is the broker's booking count including the sale
+func (s BrokerCommissionSchedule) CalcCommission(basicCost decimal.Decimal, bookings int) decimal.Decimal {
+	switch s.Type {
+	case custom.COMMISSION_PERCENT:
+		return basicCost.Mul(s.Rate).Div(decimalHundred).Round(2)
+	case custom.COMMISSION_FLAT:
+		return s.Rate.Round(2)
+	case custom.COMMISSION_SLAB:
+		return basicCost.Mul(s.Slabs.GetRate(bookings)).Div(decimalHundred).Round(2)
+	}
+	return decimal.Zero
+}
+
+// GetEligibleDate returns the date the collection on the sale reached the eligibility threshold, nil if not reached yet
+func (s BrokerCommissionSchedule) GetEligibleDate(sale Sale) *time.Time {
+	threshold := sale.TotalPrice.Mul(s.EligibilityPercent).Div(decimalHundred)
+	if threshold.LessThanOrEqual(decimal.Zero) {
+		bookedAt := sale.CreatedAt
+		return &bookedAt
+	}
+
+	var cleared []Receipt
+	for _, receipt := range sale.Receipts {
+		if receipt.Mode != custom.ADJUSTMENT && receipt.Cleared != nil {
+			cleared = append(cleared, receipt)
+		}
+	}
+	slices.SortFunc(cleared, func(a, b Receipt) int {
+		return a.Cleared.CreatedAt.Compare(b.Cleared.CreatedAt)
+	})
+
+	collected := decimal.Zero
+	for _, receipt := range cleared {
+		collected = collected.Add(receipt.TotalAmount)
+		if collected.GreaterThanOrEqual(threshold) {
+			clearedAt := receipt.Cleared.CreatedAt
+			return &clearedAt
+		}
+	}
+	return nil
+}
+
+// BrokerCommission is the commission accrued to the broker of a sale
+type BrokerCommission struct {
+	Id         uuid.UUID                 `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	SaleId     uuid.UUID                 `gorm:"not null;uniqueIndex" json:"saleId"`
+	Sale       *Sale                     `gorm:"foreignKey:SaleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"sale,omitempty"`
+	BrokerId   uuid.UUID                 `gorm:"not null;index" json:"brokerId"`
+	Broker     *Broker                   `gorm:"foreignKey:BrokerId;constraint:OnUpdate:CASCADE" json:"broker,omitempty"`
+	SocietyId  string                    `gorm:"not null;index" json:"societyId"`
+	OrgId      uuid.UUID                 `gorm:"not null;index" json:"orgId"`
+	ScheduleId uuid.UUID                 `gorm:"not null" json:"scheduleId"`
+	Schedule   *BrokerCommissionSchedule `gorm:"foreignKey:ScheduleId;constraint:OnUpdate:CASCADE" json:"schedule,omitempty"`
+	BasicCost  decimal.Decimal           `gorm:"type:numeric;not null" json:"basicCost"`
+	Amount     decimal.Decimal           `gorm:"type:numeric;not null" json:"amount"`
+	Status     custom.CommissionStatus   `gorm:"not null" json:"status"`
+	EligibleOn *time.Time                `json:"eligibleOn,omitempty"`
+	PayoutId   *uuid.UUID                `gorm:"index" json:"payoutId,omitempty"`
+	CreatedAt  time.Time                 `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt  time.Time                 `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (c BrokerCommission) GetCreatedAt() time.Time {
+	return c.CreatedAt
+}
+
+// BrokerPayout is a batch of eligible commissions paid to a broker, tds is deducted under section 194H
+type BrokerPayout struct {
+	Id          uuid.UUID          `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	BrokerId    uuid.UUID          `gorm:"not null;index" json:"brokerId"`
+	Broker      *Broker            `gorm:"foreignKey:BrokerId;constraint:OnUpdate:CASCADE" json:"broker,omitempty"`
+	SocietyId   string             `gorm:"not null;index" json:"societyId"`
+	OrgId       uuid.UUID          `gorm:"not null;index" json:"orgId"`
+	PayoutDate  custom.DateOnly    `gorm:"type:date;not null" json:"payoutDate"`
+	Reference   string             `json:"reference"`
+	GrossAmount decimal.Decimal    `gorm:"type:numeric;not null" json:"grossAmount"`
+	TdsRate     decimal.Decimal    `gorm:"type:numeric;not null" json:"tdsRate"`
+	TdsAmount   decimal.Decimal    `gorm:"type:numeric;not null" json:"tdsAmount"`
+	NetAmount   decimal.Decimal    `gorm:"type:numeric;not null" json:"netAmount"`
+	CreatedBy   string             `json:"createdBy"`
+	Commissions []BrokerCommission `gorm:"foreignKey:PayoutId" json:"commissions,omitempty"`
+	CreatedAt   time.Time          `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (p BrokerPayout) GetCreatedAt() time.Time {
+	return p.CreatedAt
+}
+
+// TDS under section 194H on commission or brokerage
+var (
+	TDS194HRate      = decimal.NewFromInt(2)
+	TDS194HThreshold = decimal.NewFromInt(20000)
+)
+
+// CalcTDS194H returns the tds on a payout
+// previousGross and previousTds are the broker's payouts earlier in the same financial year,
+// once the yearly aggregate crosses the threshold tds is deducted on the whole aggregate not yet taxed
+func CalcTDS194H(gross, previousGross, previousTds, rate decimal.Decimal) decimal.Decimal {
+	aggregate := gross.Add(previousGross)
+	if aggregate.LessThanOrEqual(TDS194HThreshold) {
+		return decimal.Zero
+	}
+
+	tds := aggregate.Mul(rate).Div(decimalHundred).Sub(previousTds).Round(0)
+	if tds.LessThan(decimal.Zero) {
+		return decimal.Zero
+	}
+	return tds
+}
+
+// GetFinancialYearStart returns the start of the indian financial year (1st April) containing t
+func GetFinancialYearStart(t time.Time) time.Time {
+	year := t.Year()
+	if t.Month() < time.April {
+		year--
+	}
+	return time.Date(year, time.April, 1, 0, 0, 0, 0, t.Location())
+}
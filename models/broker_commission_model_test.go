@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/shopspring/decimal"
+)
+
+func TestCalcCommission(t *testing.T) {
+	basicCost := decimal.NewFromInt(5000000)
+
+	slab := BrokerCommissionSchedule{
+		Type: custom.COMMISSION_SLAB,
+		Slabs: CommissionSlabs{
+			{MinBookings: 5, Rate: decimal.NewFromInt(2)},
+			{MinBookings: 1, Rate: decimal.NewFromInt(1)},
+		},
+	}
+	if got := slab.CalcCommission(basicCost, 3); !got.Equal(decimal.NewFromInt(50000)) {
+		t.Errorf("want 1%% slab below 5 bookings, got %s", got)
+	}
+	if got := slab.CalcCommission(basicCost, 5); !got.Equal(decimal.NewFromInt(100000)) {
+		t.Errorf("want 2%% slab from 5 bookings, got %s", got)
+	}
+
+	percent := BrokerCommissionSchedule{Type: custom.COMMISSION_PERCENT, Rate: decimal.NewFromFloat(1.5)}
+	if got := percent.CalcCommission(basicCost, 1); !got.Equal(decimal.NewFromInt(75000)) {
+		t.Errorf("want 75000, got %s", got)
+	}
+}
+
+func TestCalcTDS194H(t *testing.T) {
+	rate := TDS194HRate
+
+	if got := CalcTDS194H(decimal.NewFromInt(15000), decimal.Zero, decimal.Zero, rate); !got.IsZero() {
+		t.Errorf("want no tds below threshold, got %s", got)
+	}
+
+	// crossing the threshold deducts tds on the earlier untaxed payout as well
+	if got := CalcTDS194H(decimal.NewFromInt(10000), decimal.NewFromInt(15000), decimal.Zero, rate); !got.Equal(decimal.NewFromInt(500)) {
+		t.Errorf("want 500 on aggregate 25000, got %s", got)
+	}
+
+	if got := CalcTDS194H(decimal.NewFromInt(10000), decimal.NewFromInt(25000), decimal.NewFromInt(500), rate); !got.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("want 200 once threshold is crossed, got %s", got)
+	}
+
+	if got := GetFinancialYearStart(date(2025, time.February, 10)); !got.Equal(date(2024, time.April, 1)) {
+		t.Errorf("want 2024-04-01, got %s", got)
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"time"
@@ -27,3 +28,10 @@ type BrokerReport struct {
 	TotalAmount decimal.Decimal `json:"totalAmount"`
 	Details     Broker          `json:"details"`
 }
+
+// SaleChannelReport holds sales booked through a channel
+type SaleChannelReport struct {
+	Channel     custom.SaleChannel `json:"channel"`
+	TotalAmount decimal.Decimal    `json:"totalAmount"`
+	Sales       []Sale             `json:"sales"`
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ExpectedCollection is the unpaid balance of a payment plan item and the date it is expected to be paid
+type ExpectedCollection struct {
+	Item PaymentPlanRatioItem
+	// Due is nil when the item has no planned date, like on-allotment items or stages without a planned date
+	Due    *time.Time
+	Amount decimal.Decimal
+}
+
+// StageSchedule returns the planned date of the tower or flat stage of the item, nil when it is not planned
+type StageSchedule func(item PaymentPlanRatioItem) *time.Time
+
+// ExpectedCollections returns the unpaid balance of every payment plan item of the sale with its expected date
+// paid amount goes to the items active as of activationCtx.AsOf first, like GetPaymentPlanBreakDown, then to the rest in plan order
+// active items are expected on their due date, within-days items on their offset from the creation of the item and stage items on the planned date of the stage
+// nothing is expected before the booking date, PaymentPlanRatio with its items must be loaded
+func (u Sale) ExpectedCollections(activationCtx PaymentActivationContext, schedule StageSchedule) []ExpectedCollection {
+	if u.PaymentPlanRatio == nil {
+		return nil
+	}
+
+	items := u.PaymentPlanRatio.Ratios
+	totalPayableAmount := u.GetTotalPayableAmount()
+	paidRemaining := u.PaidAmount()
+	remaining := make([]decimal.Decimal, len(items))
+	active := make([]*PaymentActivation, len(items))
+	for i, item := range items {
+		active[i] = item.GetActivation(activationCtx)
+	}
+	for _, activeFirst := range []bool{true, false} {
+		for i, item := range items {
+			if (active[i] != nil) != activeFirst {
+				continue
+			}
+			finance := item.GetAmountDetails(totalPayableAmount, paidRemaining)
+			if finance == nil {
+				continue
+			}
+			paidRemaining = paidRemaining.Sub(finance.Paid)
+			remaining[i] = finance.Remaining
+		}
+	}
+
+	// sale items of the future are found without the as of date, stages only activate on completion
+	unbounded := activationCtx
+	unbounded.AsOf = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	bookedOn := time.Date(u.CreatedAt.Year(), u.CreatedAt.Month(), u.CreatedAt.Day(), 0, 0, 0, 0, time.UTC)
+
+	collections := make([]ExpectedCollection, 0, len(items))
+	for i, item := range items {
+		if !remaining[i].IsPositive() {
+			continue
+		}
+
+		var due *time.Time
+		if activation := item.GetActivation(unbounded); activation != nil {
+			due = &activation.DueDate.Time
+		} else if schedule != nil && (item.Scope == custom.SCOPE_TOWER || item.Scope == custom.SCOPE_FLAT) {
+			due = schedule(item)
+		}
+		if due != nil && due.Before(bookedOn) {
+			due = &bookedOn
+		}
+
+		collections = append(collections, ExpectedCollection{Item: item, Due: due, Amount: remaining[i]})
+	}
+	return collections
+}
+
+// ConstructionSchedule holds the planned dates of the pending construction milestones by the payment plan items they activate
+type ConstructionSchedule struct {
+	milestones map[uuid.UUID][]ConstructionMilestone
+}
+
+// NewConstructionSchedule creates the schedule of the milestones, PaymentPlanItems of the milestones must be loaded
+// completed milestones and milestones without a planned date are left out
+func NewConstructionSchedule(milestones []ConstructionMilestone) ConstructionSchedule {
+	schedule := ConstructionSchedule{milestones: make(map[uuid.UUID][]ConstructionMilestone)}
+	for _, milestone := range milestones {
+		if milestone.IsCompleted() || milestone.PlannedDate == nil || milestone.PlannedDate.IsZero() {
+			continue
+		}
+		for _, item := range milestone.PaymentPlanItems {
+			schedule.milestones[item.Id] = append(schedule.milestones[item.Id], milestone)
+		}
+	}
+	return schedule
+}
+
+// For returns the stage schedule of the flat, the earliest planned milestone of the flat's tower and floor activating the item
+// milestones without a floor apply to every floor of the tower
+func (s ConstructionSchedule) For(flat Flat) StageSchedule {
+	return func(item PaymentPlanRatioItem) *time.Time {
+		var planned *time.Time
+		for _, milestone := range s.milestones[item.Id] {
+			if milestone.TowerId != flat.TowerId {
+				continue
+			}
+			if item.Scope == custom.SCOPE_FLAT && milestone.FloorNumber != nil && *milestone.FloorNumber != flat.FloorNumber {
+				continue
+			}
+			if planned == nil || milestone.PlannedDate.Before(*planned) {
+				date := milestone.PlannedDate.Time
+				planned = &date
+			}
+		}
+		return planned
+	}
+}
+
+// PriceList prices unsold flats at a basic rate per sq ft of saleable area with the charges of the society
+type PriceList struct {
+	BasicRate       decimal.Decimal
+	LocationCharges []PreferenceLocationCharge
+	OtherCharges    []OtherCharge
+}
+
+// Price returns the total price of the flat, location charges and charges that are not fixed are per sq ft
+// disabled, optional and recurring charges are left out
+func (p PriceList) Price(flat Flat) decimal.Decimal {
+	price := p.BasicRate.Mul(flat.SaleableArea)
+
+	for _, charge := range p.LocationCharges {
+		if charge.Disable {
+			continue
+		}
+		applies := (charge.Type == custom.FLOOR && charge.Floor == flat.FloorNumber) ||
+			(charge.Type == custom.FACING && flat.Facing == custom.SPECIAL)
+		if applies {
+			price = price.Add(charge.Price.Mul(flat.SaleableArea))
+		}
+	}
+
+	for _, charge := range p.OtherCharges {
+		if charge.Disable || charge.Optional || charge.Recurring {
+			continue
+		}
+		if charge.Fixed {
+			price = price.Add(charge.Price)
+		} else {
+			price = price.Add(charge.Price.Mul(flat.SaleableArea))
+		}
+	}
+	return price
+}
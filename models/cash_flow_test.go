@@ -0,0 +1,106 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+func TestSaleExpectedCollections(t *testing.T) {
+	towerId := uuid.New()
+	floor := 3
+	onBooking := PaymentPlanRatioItem{Id: uuid.New(), Description: "Booking", Ratio: "10", Scope: custom.SCOPE_SALE, ConditionType: custom.ONBOOKING}
+	withinDays := PaymentPlanRatioItem{Id: uuid.New(), Description: "Within 60 days", Ratio: "20", Scope: custom.SCOPE_SALE, ConditionType: custom.WITHINDAYS, ConditionValue: 60, CreatedAt: date(2025, time.January, 10)}
+	slab := PaymentPlanRatioItem{Id: uuid.New(), Description: "Slab", Ratio: "30", Scope: custom.SCOPE_FLAT, ConditionType: custom.ONFlatSTAGE}
+	roof := PaymentPlanRatioItem{Id: uuid.New(), Description: "Roof", Ratio: "30", Scope: custom.SCOPE_TOWER, ConditionType: custom.ONTOWERSTAGE}
+	allotment := PaymentPlanRatioItem{Id: uuid.New(), Description: "Allotment", Ratio: "10", Scope: custom.SCOPE_SALE, ConditionType: custom.ONALLOTMENT}
+
+	sale := Sale{
+		CreatedAt:        date(2025, time.January, 10),
+		TotalPrice:       decimal.NewFromInt(1000),
+		PaymentPlanRatio: &PaymentPlanRatio{Ratios: []PaymentPlanRatioItem{onBooking, withinDays, slab, roof, allotment}},
+		Receipts: []Receipt{{
+			TotalAmount: decimal.NewFromInt(150),
+			Mode:        custom.CHEQUE,
+			DateIssued:  pgtype.Date{Time: date(2025, time.January, 10), Valid: true},
+			Cleared:     &ReceiptClear{},
+		}},
+	}
+	flat := Flat{TowerId: towerId, FloorNumber: floor}
+
+	schedule := NewConstructionSchedule([]ConstructionMilestone{
+		{TowerId: towerId, FloorNumber: &floor, PlannedDate: &custom.DateOnly{Time: date(2025, time.June, 1)}, PaymentPlanItems: []PaymentPlanRatioItem{slab}},
+		{TowerId: towerId, PlannedDate: &custom.DateOnly{Time: date(2025, time.May, 1)}, PaymentPlanItems: []PaymentPlanRatioItem{slab}},
+		{TowerId: uuid.New(), PlannedDate: &custom.DateOnly{Time: date(2025, time.February, 1)}, PaymentPlanItems: []PaymentPlanRatioItem{roof}},
+	})
+
+	ctx := NewPaymentActivationContext(date(2025, time.February, 1), &sale, nil, nil)
+	collections := sale.ExpectedCollections(ctx, schedule.For(flat))
+
+	want := []struct {
+		description string
+		due         *time.Time
+		amount      int64
+	}{
+		{"Within 60 days", ptr(date(2025, time.March, 11)), 150},
+		{"Slab", ptr(date(2025, time.May, 1)), 300},
+		{"Roof", nil, 300},
+		{"Allotment", nil, 100},
+	}
+	if len(collections) != len(want) {
+		t.Fatalf("want %d collections, got %+v", len(want), collections)
+	}
+	for i, w := range want {
+		got := collections[i]
+		if got.Item.Description != w.description || !got.Amount.Equal(decimal.NewFromInt(w.amount)) {
+			t.Errorf("%d: want %s %d, got %s %s", i, w.description, w.amount, got.Item.Description, got.Amount)
+		}
+		if (w.due == nil) != (got.Due == nil) || (w.due != nil && !got.Due.Equal(*w.due)) {
+			t.Errorf("%s: want due %v, got %v", w.description, w.due, got.Due)
+		}
+	}
+
+	// stages completed before booking are expected on booking
+	flat.ActivePaymentPlanRatioItems = []FlatPaymentStatus{{PaymentId: slab.Id, PaymentActivation: NewPaymentActivation(date(2024, time.December, 1), 0)}}
+	ctx = NewPaymentActivationContext(date(2025, time.February, 1), &sale, flat.ActivePaymentPlanRatioItems, nil)
+	for _, collection := range sale.ExpectedCollections(ctx, schedule.For(flat)) {
+		if collection.Item.Id == slab.Id && !collection.Due.Equal(sale.CreatedAt) {
+			t.Errorf("want slab expected on booking, got %v", collection.Due)
+		}
+	}
+}
+
+func TestPriceListPrice(t *testing.T) {
+	list := PriceList{
+		BasicRate: decimal.NewFromInt(5000),
+		LocationCharges: []PreferenceLocationCharge{
+			{Type: custom.FLOOR, Floor: 2, Price: decimal.NewFromInt(100)},
+			{Type: custom.FACING, Price: decimal.NewFromInt(200)},
+			{Type: custom.FLOOR, Floor: 2, Price: decimal.NewFromInt(1000), Disable: true},
+		},
+		OtherCharges: []OtherCharge{
+			{Price: decimal.NewFromInt(50)},
+			{Price: decimal.NewFromInt(300000), Fixed: true},
+			{Price: decimal.NewFromInt(10), Recurring: true},
+			{Price: decimal.NewFromInt(500000), Fixed: true, Optional: true},
+		},
+	}
+
+	flat := Flat{FloorNumber: 2, Facing: custom.SPECIAL, SaleableArea: decimal.NewFromInt(1000)}
+	if price := list.Price(flat); !price.Equal(decimal.NewFromInt(5000000 + 100000 + 200000 + 50000 + 300000)) {
+		t.Errorf("unexpected price %s", price)
+	}
+
+	flat = Flat{FloorNumber: 5, Facing: custom.DEFAULT, SaleableArea: decimal.NewFromInt(1000)}
+	if price := list.Price(flat); !price.Equal(decimal.NewFromInt(5000000 + 50000 + 300000)) {
+		t.Errorf("unexpected price %s", price)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+// ConstructionMilestone is a construction stage of a tower like slab casting of a floor or brickwork
+// completing a milestone activates the mapped on-tower-stage and on-flat-stage payment plan items
+type ConstructionMilestone struct {
+	Id                   uuid.UUID                    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	TowerId              uuid.UUID                    `gorm:"not null;index" json:"towerId"`
+	Tower                *Tower                       `gorm:"foreignKey:TowerId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"tower,omitempty"`
+	SocietyId            string                       `gorm:"not null;index" json:"societyId"`
+	OrgId                uuid.UUID                    `gorm:"not null;index" json:"orgId"`
+	Society              *Society                     `gorm:"foreignKey:SocietyId,OrgId;references:ReraNumber,OrgId;not null;constraint:OnUpdate:CASCADE" json:"society,omitempty"`
+	Stage                custom.ConstructionStage     `gorm:"not null" json:"stage"`
+	FloorNumber          *int                         `json:"floorNumber,omitempty"`
+	Description          string                       `json:"description"`
+	PlannedDate          *custom.DateOnly             `gorm:"type:date" json:"plannedDate,omitempty"`
+	CompletedOn          *custom.DateOnly             `gorm:"type:date" json:"completedOn,omitempty"`
+	CertificateReference string                       `json:"certificateReference,omitempty"`
+	CertifiedBy          string                       `json:"certifiedBy,omitempty"`
+	CompletedBy          string                       `json:"completedBy,omitempty"`
+	PaymentPlanItems     []PaymentPlanRatioItem       `gorm:"many2many:construction_milestone_payment_items;constraint:OnDelete:CASCADE" json:"paymentPlanItems,omitempty"`
+	Photos               []ConstructionMilestonePhoto `gorm:"foreignKey:MilestoneId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"photos,omitempty"`
+	CreatedAt            time.Time                    `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt            time.Time                    `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (m ConstructionMilestone) GetCreatedAt() time.Time {
+	return m.CreatedAt
+}
+
+// IsCompleted reports whether the milestone has been certified
+func (m ConstructionMilestone) IsCompleted() bool {
+	return m.CompletedOn != nil && !m.CompletedOn.IsZero()
+}
+
+// ConstructionMilestonePhoto is a site photo attached to a milestone as proof of progress
+type ConstructionMilestonePhoto struct {
+	Id          uuid.UUID `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	MilestoneId uuid.UUID `gorm:"not null;index" json:"milestoneId"`
+	FileName    string    `gorm:"not null" json:"fileName"`
+	ContentType string    `gorm:"not null" json:"contentType"`
+	Caption     string    `json:"caption,omitempty"`
+	Content     []byte    `gorm:"type:bytea;not null" json:"-"`
+	UploadedBy  string    `json:"uploadedBy"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (p ConstructionMilestonePhoto) GetCreatedAt() time.Time {
+	return p.CreatedAt
+}
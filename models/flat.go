@@ -1,7 +1,6 @@
 package models
 
 import (
-	"fmt"
 	"log"
 	"strconv"
 	"strings"
@@ -10,6 +9,7 @@ import (
 	"circledigital.in/real-state-erp/utils/custom"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 const (
@@ -40,14 +40,19 @@ type Flat struct {
 	UpdatedAt                   time.Time           `gorm:"autoUpdateTime" json:"updatedAt"`
 	SaleDetail                  *Sale               `gorm:"foreignKey:FlatId" json:"saleDetail,omitempty"`
 	ActivePaymentPlanRatioItems []FlatPaymentStatus `gorm:"foreignKey:FlatId" json:"-"`
-	//DeletedAt   gorm.DeletedAt `gorm:"index"`
+	DeletedAt                   gorm.DeletedAt      `gorm:"index" json:"deletedAt,omitempty"`
+	DeletedBy                   string              `json:"deletedBy,omitempty"`
 }
 
+// Header is a heading of the master report, Key is the id of the column or group in MasterReportColumns
+// Param is the price breakdown summary or installment number of the column
 type Header struct {
-	ID      *uuid.UUID // used for payment plan only
-	Heading string
-	Items   []Header
-	Color   string
+	ID         *uuid.UUID // used for payment plan only
+	Key        string
+	Param      string
+	Heading    string
+	Items      []Header
+	Color      string
 	IsMonetary bool
 }
 
@@ -65,244 +70,75 @@ func (p SafePrint) Print(s any) {
 	}
 }
 
-func (f Flat) GetRowData(headers []Header, towerName string, print SafePrint, activeTowerPaymentPlans []TowerPaymentStatus) []string {
+// GetRowData returns the master report row of the flat, payment plan items are evaluated as of asOf
+// values are looked up by the keys of headers, headings are only display text
+// activeTowerPaymentPlans must only contain activations of the flat's tower
+func (f Flat) GetRowData(headers []Header, towerName string, print SafePrint, activeTowerPaymentPlans []TowerPaymentStatus, asOf time.Time) []string {
 	var row []string
 
-	totalPaidRemaining := decimal.Zero
-	totalPayableAmount := decimal.Zero
-	if f.SaleDetail != nil {
-		row = append(row, f.SaleDetail.SaleNumber)
-		totalPaidRemaining = f.SaleDetail.PaidAmount()
-		totalPayableAmount = f.SaleDetail.GetTotalPayableAmount()
-	} else {
-		row = append(row, "")
+	base := reportRow{flat: &f, towerName: towerName}
+	if f.SaleDetail != nil && f.SaleDetail.PaymentPlanRatio != nil {
+		base.sale = f.SaleDetail
 	}
 
-	// Recursive helper to flatten headers and fetch values
-	var fill func(hs []Header, parent string, level int, parentID *uuid.UUID)
-	fill = func(hs []Header, parent string, level int, parentID *uuid.UUID) {
+	// appendLeaves appends the values of the leaf headers, a header without column is blank
+	var appendLeaves func(hs []Header, r *reportRow)
+	appendLeaves = func(hs []Header, r *reportRow) {
 		for _, h := range hs {
-			// if leaf or level is 1
-			if level == 1 || len(h.Items) == 0 {
-				// Flat
-				if strings.HasPrefix(parent, HeadingFlat) {
-					switch h.Heading {
-					case "Flat":
-						row = append(row, f.Name)
-					case "Floor":
-						row = append(row, fmt.Sprintf("%d", f.FloorNumber))
-					case "Facing":
-						row = append(row, string(f.Facing))
-					case "Saleable Area":
-						row = append(row, f.SaleableArea.String())
-					case "Unit Type":
-						row = append(row, f.UnitType)
-					case "Tower":
-						row = append(row, towerName)
-					default:
-						row = append(row, "")
-					}
-				} else if f.SaleDetail == nil || f.SaleDetail.PaymentPlanRatio == nil {
-					row = append(row, "")
-					continue
-				} else if strings.HasPrefix(parent, HeadingSale) {
-					// sale
-					switch h.Heading {
-					case "ID":
-						row = append(row, f.SaleDetail.SaleNumber)
-					case "Total Price":
-						row = append(row, f.SaleDetail.TotalPrice.String())
-					case "Total Payable Amount":
-						row = append(row, f.SaleDetail.GetTotalPayableAmount().String())
-					case "Total Paid Amount":
-						row = append(row, f.SaleDetail.PaidAmount().String())
-					case "Paid Amount":
-						paid := f.SaleDetail.PaidAmount()
-
-						// Subtract all tax-related values if present
-						for _, r := range f.SaleDetail.Receipts {
-							if r.CGST != nil {
-								paid = paid.Sub(*r.CGST)
-							}
-							if r.SGST != nil {
-								paid = paid.Sub(*r.SGST)
-							}
-							if r.ServiceTax != nil {
-								paid = paid.Sub(*r.ServiceTax)
-							}
-							if r.SwathchBharatCess != nil {
-								paid = paid.Sub(*r.SwathchBharatCess)
-							}
-							if r.KrishiKalyanCess != nil {
-								paid = paid.Sub(*r.KrishiKalyanCess)
-							}
-						}
-
-						row = append(row, paid.String())
-					case "Pending Amount":
-						row = append(row, f.SaleDetail.Pending().String())
-					case "CGST":
-						row = append(row, f.SaleDetail.GetTotalCGST())
-
-					case "SGST":
-						row = append(row, f.SaleDetail.GetTotalSGST())
-
-					case "Service Tax":
-						row = append(row, f.SaleDetail.GetTotalServiceTax())
-
-					case "Swathch Bharat Cess":
-						row = append(row, f.SaleDetail.GetTotalSwachhBharatCess())
-
-					case "Krishi Kalyan Cess":
-						row = append(row, f.SaleDetail.GetTotalKrishiKalyanCess())
-					default:
-						row = append(row, "")
-					}
-				} else if strings.HasPrefix(parent, HeadingBroker) {
-					// broker
-					switch h.Heading {
-					case "Name":
-						row = append(row, f.SaleDetail.Broker.Name)
-					case "Aadhar":
-						row = append(row, f.SaleDetail.Broker.AadharNumber)
-					case "PAN":
-						row = append(row, f.SaleDetail.Broker.PanNumber)
-					default:
-						row = append(row, "")
-					}
-				} else if strings.HasPrefix(parent, HeadingPaymentPlan) {
-					// payment plan info
-					switch h.Heading {
-					case "Name":
-						row = append(row, f.SaleDetail.PaymentPlanRatio.PaymentPlanGroup.Name)
-					case "Ratio":
-						row = append(row, f.SaleDetail.PaymentPlanRatio.Ratio)
-					default:
-						row = append(row, "")
-					}
-				} else if strings.HasPrefix(parent, HeadingCustomer) {
-					// customer
-					if len(f.SaleDetail.Customers) == 0 {
-						row = append(row, "")
-						continue
-					}
-					customer := f.SaleDetail.Customers[0]
-					switch h.Heading {
-					case "Name":
-						row = append(row, customer.FirstName+" "+customer.LastName)
-					case "Gender":
-						row = append(row, string(customer.Gender))
-					case "Email":
-						row = append(row, customer.Email)
-					case "Phone Number":
-						row = append(row, customer.PhoneNumber)
-					case "Nationality":
-						row = append(row, string(customer.Nationality))
-					case "Aadhar":
-						row = append(row, customer.AadharNumber)
-					case "PAN":
-						row = append(row, customer.PanNumber)
-					case "Passport Number":
-						row = append(row, customer.PassportNumber)
-					case "Profession":
-						row = append(row, customer.Profession)
-					case "Company Name":
-						row = append(row, customer.CompanyName)
-					default:
-						row = append(row, "")
-					}
-				} else if strings.HasPrefix(parent, HeadingCompanyCustomer) {
-					// Company Customer
-					if f.SaleDetail.CompanyCustomer == nil {
-						row = append(row, "")
-						continue
-					}
-
-					companyCustomer := f.SaleDetail.CompanyCustomer
-
-					switch h.Heading {
-					case "Name":
-						row = append(row, companyCustomer.Name)
-					case "Company PAN":
-						row = append(row, companyCustomer.CompanyPan)
-					case "GST":
-						row = append(row, companyCustomer.CompanyGst)
-					case "Aadhar":
-						row = append(row, companyCustomer.AadharNumber)
-					case "PAN":
-						row = append(row, companyCustomer.PanNumber)
-					default:
-						row = append(row, "")
-					}
-				} else if strings.HasPrefix(parent, HeadingPricebreakdown) {
-					// price breakdown
-					row = append(row, f.SaleDetail.PriceBreakdown.GetPriceFromSummary(h.Heading).String())
-				} else if strings.HasPrefix(parent, HeadingInstallment) {
-					// installment handling
-					receipts := f.SaleDetail.Receipts
-
-					// curent heading will be installment number, installment has 4 sub heading
-					ind, convErr := strconv.Atoi(h.Heading)
-					if convErr != nil ||
-						// check if receipt is present
-						len(receipts) < ind {
-						row = append(row, make([]string, 11)...)
-						continue
-					}
-
-					requiredReceipt := receipts[ind-1]
-					row = append(row, requiredReceipt.ReceiptNumber)
-					row = append(row, formatDateTime(requiredReceipt.CreatedAt))
-					row = append(row, requiredReceipt.TotalAmount.String())
-					row = append(row, string(requiredReceipt.Mode))
-
-					// receipt tax
-					row = append(row, requiredReceipt.GetCGST())
-					row = append(row, requiredReceipt.GetSGST())
-					row = append(row, requiredReceipt.GetServiceTax())
-					row = append(row, requiredReceipt.GetSwathchBharatCess())
-					row = append(row, requiredReceipt.GetKrishiKalyanCess())
+			if len(h.Items) > 0 {
+				appendLeaves(h.Items, r)
+				continue
+			}
 
-					row = append(row, requiredReceipt.GetReceiptStatus())
-					if requiredReceipt.Cleared != nil {
-						row = append(row, formatDateTime(requiredReceipt.Cleared.CreatedAt))
-					} else {
-						row = append(row, "")
-					}
-				} else {
-					// this is payment plan row
-					if parentID != nil && f.SaleDetail.PaymentPlanRatioId == *parentID {
-						// handle payment plan here
-						financeDetail, collectionDate := f.SaleDetail.PaymentPlanRatio.GetRatioAmountDetail(*h.ID, totalPayableAmount, totalPaidRemaining, f.ActivePaymentPlanRatioItems, activeTowerPaymentPlans)
+			column, ok := reportColumnsById[h.Key]
+			if !ok {
+				row = append(row, "")
+				continue
+			}
+			r.summary = h.Param
+			row = append(row, column.value(r))
+		}
+	}
 
-						if financeDetail != nil {
-							row = append(row, formatDateTime(*collectionDate))
-							row = append(row, financeDetail.Total.String())
-							row = append(row, financeDetail.Paid.String())
-							row = append(row, financeDetail.Remaining.String())
+	totalPaidRemaining := decimal.Zero
+	totalPayableAmount := decimal.Zero
+	if base.sale != nil {
+		totalPaidRemaining = base.sale.PaidAmount()
+		totalPayableAmount = base.sale.GetTotalPayableAmount()
+	}
 
-							totalPaidRemaining = totalPaidRemaining.Sub(financeDetail.Paid)
-							continue
-						}
+	for _, h := range headers {
+		switch h.Key {
+		case ColumnGroupPaymentPlanItems:
+			// a header per payment plan with a header per item, only the plan of the sale has values
+			// paid amount is allocated to the items in order
+			for _, item := range h.Items {
+				r := base
+				if base.sale != nil && h.ID != nil && item.ID != nil && base.sale.PaymentPlanRatioId == *h.ID {
+					activationCtx := NewPaymentActivationContext(asOf, base.sale, f.ActivePaymentPlanRatioItems, activeTowerPaymentPlans)
+					financeDetail, activation := base.sale.PaymentPlanRatio.GetRatioAmountDetail(*item.ID, totalPayableAmount, totalPaidRemaining, activationCtx)
+					if financeDetail != nil {
+						r.finance, r.activation = financeDetail, activation
+						totalPaidRemaining = totalPaidRemaining.Sub(financeDetail.Paid)
 					}
-					count := len(h.Items)
-					row = append(row, make([]string, max(1, count))...)
-
 				}
-			} else if len(h.Items) > 0 {
-				// Recursive for nested headers (Payment Plans, Installments, etc.)
-				nextParent := parent
-				if parent == "" {
-					nextParent = h.Heading
-				} else {
-					nextParent = fmt.Sprintf("%s-%s", parent, h.Heading)
+				appendLeaves(item.Items, &r)
+			}
+		case ColumnGroupInstallments:
+			// a header per installment number, installment n is the nth receipt of the sale
+			for _, installment := range h.Items {
+				r := base
+				if ind, err := strconv.Atoi(installment.Param); err == nil && base.sale != nil && ind >= 1 && ind <= len(base.sale.Receipts) {
+					r.receipt = &base.sale.Receipts[ind-1]
 				}
-				fill(h.Items, nextParent, level+1, h.ID)
+				appendLeaves(installment.Items, &r)
 			}
+		default:
+			r := base
+			appendLeaves([]Header{h}, &r)
 		}
 	}
 
-	fill(headers, "", 0, nil)
 	for i, v := range row {
 		if strings.TrimSpace(v) == "" {
 			row[i] = "-"
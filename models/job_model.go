@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+// Job is a unit of background work claimed by the workers of the job queue
+// permission is required to see the job and download its result, result is kept in the blob store till it expires
+type Job struct {
+	Id                uuid.UUID         `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId             uuid.UUID         `gorm:"not null;index" json:"orgId"`
+	Organization      *Organization     `gorm:"foreignKey:OrgId;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"organization,omitempty"`
+	SocietyId         string            `gorm:"index" json:"societyId,omitempty"`
+	Type              custom.JobType    `gorm:"not null" json:"type"`
+	Params            RawJSON           `gorm:"type:jsonb" json:"params"`
+	Permission        custom.Permission `gorm:"not null" json:"permission"`
+	Status            custom.JobStatus  `gorm:"not null;index:idx_job_queue,priority:1" json:"status"`
+	Progress          int               `gorm:"not null;default:0" json:"progress"`
+	Message           string            `json:"message,omitempty"`
+	Attempts          int               `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts       int               `gorm:"not null;default:3" json:"maxAttempts"`
+	RunAfter          time.Time         `gorm:"not null;index:idx_job_queue,priority:2" json:"runAfter"`
+	LockedUntil       *time.Time        `json:"-"`
+	CancelRequested   bool              `gorm:"not null;default:false" json:"cancelRequested"`
+	ResultKey         string            `json:"-"`
+	ResultName        string            `json:"resultName,omitempty"`
+	ResultContentType string            `json:"resultContentType,omitempty"`
+	ResultSize        int64             `json:"resultSize,omitempty"`
+	Error             string            `json:"error,omitempty"`
+	CreatedBy         string            `json:"createdBy"`
+	StartedAt         *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt        *time.Time        `json:"finishedAt,omitempty"`
+	ExpiresAt         *time.Time        `json:"expiresAt,omitempty"`
+	CreatedAt         time.Time         `gorm:"autoCreateTime;index" json:"createdAt"`
+	UpdatedAt         time.Time         `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (j Job) GetCreatedAt() time.Time {
+	return j.CreatedAt
+}
+
+// Downloadable reports whether the result of the job can be downloaded at now
+func (j *Job) Downloadable(now time.Time) bool {
+	return j.Status == custom.JOB_SUCCEEDED && j.ResultKey != "" && (j.ExpiresAt == nil || now.Before(*j.ExpiresAt))
+}
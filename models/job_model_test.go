@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+func TestJobDownloadable(t *testing.T) {
+	now := time.Date(2025, 4, 1, 10, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		job  Job
+		want bool
+	}{
+		{"succeeded with result", Job{Status: custom.JOB_SUCCEEDED, ResultKey: "jobs/1/a.xlsx", ExpiresAt: &later}, true},
+		{"succeeded without expiry", Job{Status: custom.JOB_SUCCEEDED, ResultKey: "jobs/1/a.xlsx"}, true},
+		{"expired", Job{Status: custom.JOB_SUCCEEDED, ResultKey: "jobs/1/a.xlsx", ExpiresAt: &earlier}, false},
+		{"purged", Job{Status: custom.JOB_SUCCEEDED, ExpiresAt: &later}, false},
+		{"running", Job{Status: custom.JOB_RUNNING, ResultKey: "jobs/1/a.xlsx"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.job.Downloadable(now); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+// NotificationTemplate overrides the default template of a notification for the organization
+// subject is used only by email, body is a text/template rendered with the notification data
+type NotificationTemplate struct {
+	Id           uuid.UUID                  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId        uuid.UUID                  `gorm:"not null;uniqueIndex:idx_notification_template" json:"orgId"`
+	Organization *Organization              `gorm:"foreignKey:OrgId;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"organization,omitempty"`
+	Kind         custom.NotificationKind    `gorm:"not null;uniqueIndex:idx_notification_template" json:"kind"`
+	Channel      custom.NotificationChannel `gorm:"not null;uniqueIndex:idx_notification_template" json:"channel"`
+	Subject      string                     `json:"subject"`
+	Body         string                     `gorm:"not null" json:"body"`
+	Enabled      bool                       `gorm:"not null;default:true" json:"enabled"`
+	UpdatedBy    string                     `json:"updatedBy"`
+	CreatedAt    time.Time                  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time                  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// NotificationOptOut is an email address or phone number that receives no notification of the organization
+type NotificationOptOut struct {
+	OrgId     uuid.UUID                  `gorm:"primaryKey" json:"orgId"`
+	Channel   custom.NotificationChannel `gorm:"primaryKey" json:"channel"`
+	Address   string                     `gorm:"primaryKey" json:"address"`
+	Reason    string                     `json:"reason"`
+	CreatedBy string                     `json:"createdBy"`
+	CreatedAt time.Time                  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (o NotificationOptOut) GetCreatedAt() time.Time {
+	return o.CreatedAt
+}
+
+// NotificationLog is a rendered notification with the result of its delivery
+// reference identifies what the notification is about, like the receipt or payment plan item
+type NotificationLog struct {
+	Id            uuid.UUID                  `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId         uuid.UUID                  `gorm:"not null;index" json:"orgId"`
+	SocietyId     string                     `gorm:"index" json:"societyId"`
+	SaleId        *uuid.UUID                 `gorm:"type:uuid;index" json:"saleId,omitempty"`
+	CustomerId    *uuid.UUID                 `gorm:"type:uuid" json:"customerId,omitempty"`
+	Kind          custom.NotificationKind    `gorm:"not null;index" json:"kind"`
+	Channel       custom.NotificationChannel `gorm:"not null" json:"channel"`
+	Reference     string                     `gorm:"index" json:"reference,omitempty"`
+	Recipient     string                     `gorm:"not null" json:"recipient"`
+	Subject       string                     `json:"subject,omitempty"`
+	Body          string                     `gorm:"not null" json:"body"`
+	Status        custom.NotificationStatus  `gorm:"not null;index" json:"status"`
+	Transport     string                     `json:"transport,omitempty"`
+	Attempts      int                        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt *time.Time                 `gorm:"index" json:"nextAttemptAt,omitempty"`
+	SentAt        *time.Time                 `json:"sentAt,omitempty"`
+	Error         string                     `json:"error,omitempty"`
+	CreatedAt     time.Time                  `gorm:"autoCreateTime;index" json:"createdAt"`
+	UpdatedAt     time.Time                  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (n NotificationLog) GetCreatedAt() time.Time {
+	return n.CreatedAt
+}
@@ -38,4 +38,13 @@ type PaymentPlanSaleBreakDown struct {
 	PaidAmount  decimal.Decimal `json:"paidAmount"`
 	Remaining   decimal.Decimal `json:"remaining"`
 	// Details     []PaymentPlan   `json:"details"`
+	Details []PaymentPlanItemBreakDown `json:"details"`
+}
+
+// PaymentPlanItemBreakDown is the position of a single payment plan item of a sale
+// Activation and Finance are nil when the item is not active yet
+type PaymentPlanItemBreakDown struct {
+	Item       PaymentPlanRatioItem `json:"item"`
+	Activation *PaymentActivation   `json:"activation,omitempty"`
+	Finance    *Finance             `json:"finance,omitempty"`
 }
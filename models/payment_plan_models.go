@@ -34,12 +34,18 @@ type PaymentPlanRatio struct {
 	UpdatedAt          time.Time              `gorm:"autoUpdateTime" json:"updatedAt"`
 }
 
-func (p PaymentPlanRatio) GetRatioAmountDetail(ratioID uuid.UUID, totalPayableAmount, remaining decimal.Decimal, activeFlatPaymentPlans []FlatPaymentStatus, activeTowerPaymentPlans []TowerPaymentStatus) (*Finance, *time.Time) {
+// GetRatioAmountDetail returns the finance detail and activation of the ratio item if it is active
+func (p PaymentPlanRatio) GetRatioAmountDetail(ratioID uuid.UUID, totalPayableAmount, remaining decimal.Decimal, activationCtx PaymentActivationContext) (*Finance, *PaymentActivation) {
 	for _, item := range p.Ratios {
-		if item.Id == ratioID && item.IsActive(activeFlatPaymentPlans, activeTowerPaymentPlans) {
-			// Found the matching item, calculate finance
-			return item.GetAmountDetails(totalPayableAmount, remaining), &item.CreatedAt
+		if item.Id != ratioID {
+			continue
 		}
+
+		activation := item.GetActivation(activationCtx)
+		if activation == nil {
+			return nil, nil
+		}
+		return item.GetAmountDetails(totalPayableAmount, remaining), activation
 	}
 
 	// If not found, return zeroed Finance
@@ -56,6 +62,7 @@ type PaymentPlanRatioItem struct {
 	ConditionType      custom.PaymentPlanCondition `gorm:"not null" json:"conditionType"`
 	ConditionValue     int                         `json:"conditionValue,omitempty"`
 	Active             *bool                       `gorm:"-" json:"active,omitempty"`
+	Activation         *PaymentActivation          `gorm:"-" json:"activation,omitempty"`
 	CreatedAt          time.Time                   `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt          time.Time                   `gorm:"autoUpdateTime" json:"updatedAt"`
 }
@@ -82,34 +89,72 @@ func (p PaymentPlanRatioItem) GetAmountDetails(totalPayableAmount, remaining dec
 	}
 }
 
-func (p PaymentPlanRatioItem) IsActive(
-	activeFlatPaymentPlans []FlatPaymentStatus,
-	activeTowerPaymentPlans []TowerPaymentStatus,
-) bool {
-	now := time.Now()
+// PaymentActivationContext holds everything that decides when payment plan items become payable for a sale
+type PaymentActivationContext struct {
+	AsOf                    time.Time
+	BookedAt                time.Time
+	Allotment               *SaleAllotment
+	ActiveFlatPaymentPlans  []FlatPaymentStatus
+	ActiveTowerPaymentPlans []TowerPaymentStatus
+}
+
+// NewPaymentActivationContext creates the activation context of a sale as of the given date
+// activeTowerPaymentPlans must only contain activations of the sale's tower
+func NewPaymentActivationContext(asOf time.Time, sale *Sale, activeFlatPaymentPlans []FlatPaymentStatus, activeTowerPaymentPlans []TowerPaymentStatus) PaymentActivationContext {
+	return PaymentActivationContext{
+		AsOf:                    asOf,
+		BookedAt:                sale.CreatedAt,
+		Allotment:               sale.Allotment,
+		ActiveFlatPaymentPlans:  activeFlatPaymentPlans,
+		ActiveTowerPaymentPlans: activeTowerPaymentPlans,
+	}
+}
+
+// GetActivation returns when the item became payable for a sale, nil if it is not active as of ctx.AsOf
+// within-days items are counted from the creation of the item, not from the booking of the sale
+func (p PaymentPlanRatioItem) GetActivation(ctx PaymentActivationContext) *PaymentActivation {
+	var activation *PaymentActivation
 
 	switch p.Scope {
 	case custom.SCOPE_SALE:
 		switch p.ConditionType {
 		case custom.ONBOOKING:
-			return true
+			a := NewPaymentActivation(ctx.BookedAt, 0)
+			activation = &a
 		case custom.WITHINDAYS:
-			target := p.CreatedAt.AddDate(0, 0, p.ConditionValue)
-			return !now.Before(target) // active if current date >= created + days
+			a := NewPaymentActivation(p.CreatedAt.AddDate(0, 0, p.ConditionValue), 0)
+			activation = &a
+		case custom.ONALLOTMENT:
+			if ctx.Allotment != nil {
+				a := NewPaymentActivation(ctx.Allotment.AllotmentDate.Time, 0)
+				activation = &a
+			}
 		}
 	case custom.SCOPE_FLAT:
-		for _, plan := range activeFlatPaymentPlans {
+		for _, plan := range ctx.ActiveFlatPaymentPlans {
 			if plan.PaymentId == p.Id {
-				return true
+				a := plan.GetActivation()
+				activation = &a
+				break
 			}
 		}
 	case custom.SCOPE_TOWER:
-		for _, plan := range activeTowerPaymentPlans {
+		for _, plan := range ctx.ActiveTowerPaymentPlans {
 			if plan.PaymentId == p.Id {
-				return true
+				a := plan.GetActivation()
+				activation = &a
+				break
 			}
 		}
 	}
 
-	return false
+	if activation == nil || !activation.IsEffective(ctx.AsOf) {
+		return nil
+	}
+	return activation
+}
+
+// IsActive reports whether the item is payable for a sale as of ctx.AsOf
+func (p PaymentPlanRatioItem) IsActive(ctx PaymentActivationContext) bool {
+	return p.GetActivation(ctx) != nil
 }
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestGetActivationSaleScope(t *testing.T) {
+	bookedAt := date(2025, time.January, 10)
+
+	onBooking := PaymentPlanRatioItem{Scope: custom.SCOPE_SALE, ConditionType: custom.ONBOOKING}
+	// within-days items count from their creation, not from the booking
+	withinDays := PaymentPlanRatioItem{Scope: custom.SCOPE_SALE, ConditionType: custom.WITHINDAYS, ConditionValue: 30, CreatedAt: date(2024, time.December, 31)}
+	onAllotment := PaymentPlanRatioItem{Scope: custom.SCOPE_SALE, ConditionType: custom.ONALLOTMENT}
+
+	ctx := PaymentActivationContext{AsOf: date(2025, time.January, 20), BookedAt: bookedAt}
+
+	activation := onBooking.GetActivation(ctx)
+	if activation == nil || !activation.EffectiveDate.Equal(bookedAt) {
+		t.Errorf("on-booking: want effective %s, got %v", bookedAt, activation)
+	}
+
+	if withinDays.IsActive(ctx) {
+		t.Errorf("within-days: want inactive before %d days of the creation of the item", withinDays.ConditionValue)
+	}
+
+	ctx.AsOf = date(2025, time.January, 30)
+	activation = withinDays.GetActivation(ctx)
+	if activation == nil || !activation.DueDate.Equal(date(2025, time.January, 30)) {
+		t.Errorf("within-days: want due 2025-01-30, got %v", activation)
+	}
+
+	ctx.AsOf = date(2025, time.February, 9)
+
+	if onAllotment.IsActive(ctx) {
+		t.Error("on-allotment: want inactive without allotment")
+	}
+
+	ctx.Allotment = &SaleAllotment{AllotmentDate: custom.DateOnly{Time: date(2025, time.March, 1)}}
+	if onAllotment.IsActive(ctx) {
+		t.Error("on-allotment: want inactive before allotment date")
+	}
+
+	ctx.AsOf = date(2025, time.March, 1)
+	if !onAllotment.IsActive(ctx) {
+		t.Error("on-allotment: want active on allotment date")
+	}
+}
+
+func TestGetActivationTowerScope(t *testing.T) {
+	item := PaymentPlanRatioItem{Id: uuid.New(), Scope: custom.SCOPE_TOWER, ConditionType: custom.ONTOWERSTAGE}
+
+	// backdated activation recorded later than its effective date
+	status := TowerPaymentStatus{
+		PaymentId:         item.Id,
+		PaymentActivation: NewPaymentActivation(date(2025, time.April, 15), 15),
+		CreatedAt:         date(2025, time.June, 1),
+	}
+
+	ctx := PaymentActivationContext{
+		AsOf:                    date(2025, time.April, 14),
+		ActiveTowerPaymentPlans: []TowerPaymentStatus{status},
+	}
+	if item.IsActive(ctx) {
+		t.Error("want inactive before effective date")
+	}
+
+	ctx.AsOf = date(2025, time.April, 15)
+	activation := item.GetActivation(ctx)
+	if activation == nil {
+		t.Fatal("want active on effective date")
+	}
+	if !activation.DueDate.Equal(date(2025, time.April, 30)) {
+		t.Errorf("want due 2025-04-30, got %s", activation.DueDate)
+	}
+
+	// records without effective date fall back to creation time
+	legacy := TowerPaymentStatus{PaymentId: item.Id, CreatedAt: date(2025, time.May, 5)}
+	ctx.ActiveTowerPaymentPlans = []TowerPaymentStatus{legacy}
+	activation = item.GetActivation(ctx)
+	if activation != nil {
+		t.Errorf("legacy: want inactive before creation, got %v", activation)
+	}
+
+	ctx.AsOf = date(2025, time.May, 5)
+	activation = item.GetActivation(ctx)
+	if activation == nil || !activation.DueDate.Equal(legacy.CreatedAt) {
+		t.Errorf("legacy: want due on creation, got %v", activation)
+	}
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ReminderStage is a day relative to the due date of a payment plan item, negative before the due date
+// buyers are notified and a collection task is opened on the stages that ask for it
+type ReminderStage struct {
+	Days     int  `json:"days"`
+	Notify   bool `json:"notify"`
+	Escalate bool `json:"escalate"`
+}
+
+// ReminderStages are the stages of a reminder policy ordered by days
+type ReminderStages []ReminderStage
+
+// DefaultReminderStages are used by societies without a reminder policy
+var DefaultReminderStages = ReminderStages{
+	{Days: -7, Notify: true},
+	{Days: 0, Notify: true},
+	{Days: 15, Notify: true},
+	{Days: 30, Notify: true, Escalate: true},
+}
+
+func (s ReminderStages) Value() (driver.Value, error) {
+	if s == nil {
+		s = ReminderStages{}
+	}
+	return json.Marshal(s)
+}
+
+func (s *ReminderStages) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal ReminderStages: %v", value)
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// Normalize returns the stages ordered by days, error if a day repeats or a stage does nothing
+func (s ReminderStages) Normalize() (ReminderStages, error) {
+	stages := make(ReminderStages, len(s))
+	copy(stages, s)
+	sort.Slice(stages, func(i, j int) bool { return stages[i].Days < stages[j].Days })
+
+	for i, stage := range stages {
+		if stage.Days < -90 || stage.Days > 365 {
+			return nil, fmt.Errorf("stage days must be between -90 and 365")
+		}
+		if !stage.Notify && !stage.Escalate {
+			return nil, fmt.Errorf("stage %d must notify or escalate", stage.Days)
+		}
+		if i > 0 && stages[i-1].Days == stage.Days {
+			return nil, fmt.Errorf("stage %d is repeated", stage.Days)
+		}
+	}
+	return stages, nil
+}
+
+// Current returns the latest stage reached on date for an item due on dueDate, nil before the first stage
+// earlier stages missed while the item was not yet due are not sent late
+func (s ReminderStages) Current(dueDate, date time.Time) *ReminderStage {
+	days := DaysBetween(dueDate, date)
+
+	var current *ReminderStage
+	for i := range s {
+		if s[i].Days <= days && (current == nil || s[i].Days > current.Days) {
+			current = &s[i]
+		}
+	}
+	return current
+}
+
+// DaysBetween returns the calendar days from one date to the other, negative when to is before from
+func DaysBetween(from, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
+
+// PaymentReminderPolicy is the reminder schedule of a society
+type PaymentReminderPolicy struct {
+	OrgId     uuid.UUID      `gorm:"primaryKey" json:"orgId"`
+	SocietyId string         `gorm:"primaryKey" json:"societyId"`
+	Enabled   bool           `gorm:"not null;default:true" json:"enabled"`
+	Stages    ReminderStages `gorm:"type:jsonb;not null" json:"stages"`
+	UpdatedBy string         `json:"updatedBy"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// PaymentReminder records a stage reached by an unpaid payment plan item of a sale
+// the unique index makes every stage of an item run once, an item activated again with a new due date starts over
+type PaymentReminder struct {
+	Id            uuid.UUID       `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId         uuid.UUID       `gorm:"not null;index" json:"orgId"`
+	SocietyId     string          `gorm:"not null;index" json:"societyId"`
+	SaleId        uuid.UUID       `gorm:"not null;uniqueIndex:idx_payment_reminder_stage" json:"saleId"`
+	Sale          *Sale           `gorm:"foreignKey:SaleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"sale,omitempty"`
+	PaymentItemId uuid.UUID       `gorm:"not null;uniqueIndex:idx_payment_reminder_stage" json:"paymentItemId"`
+	DueDate       custom.DateOnly `gorm:"type:date;not null;uniqueIndex:idx_payment_reminder_stage" json:"dueDate"`
+	Days          int             `gorm:"not null;uniqueIndex:idx_payment_reminder_stage" json:"days"`
+	Amount        decimal.Decimal `gorm:"not null;type:numeric" json:"amount"`
+	Notified      bool            `gorm:"not null" json:"notified"`
+	Escalated     bool            `gorm:"not null" json:"escalated"`
+	CreatedAt     time.Time       `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+func (r PaymentReminder) GetCreatedAt() time.Time {
+	return r.CreatedAt
+}
+
+// CollectionTask is an overdue payment escalated to the collection team
+type CollectionTask struct {
+	Id            uuid.UUID                   `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId         uuid.UUID                   `gorm:"not null;index" json:"orgId"`
+	SocietyId     string                      `gorm:"not null;index" json:"societyId"`
+	SaleId        uuid.UUID                   `gorm:"not null;index" json:"saleId"`
+	Sale          *Sale                       `gorm:"foreignKey:SaleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"sale,omitempty"`
+	ReminderId    uuid.UUID                   `gorm:"not null;uniqueIndex" json:"reminderId"`
+	PaymentItemId uuid.UUID                   `gorm:"not null" json:"paymentItemId"`
+	Description   string                      `json:"description"`
+	DueDate       custom.DateOnly             `gorm:"type:date;not null" json:"dueDate"`
+	DaysOverdue   int                         `json:"daysOverdue"`
+	Amount        decimal.Decimal             `gorm:"not null;type:numeric" json:"amount"`
+	Status        custom.CollectionTaskStatus `gorm:"not null;index" json:"status"`
+	Note          string                      `json:"note"`
+	ClosedBy      string                      `json:"closedBy,omitempty"`
+	ClosedAt      *time.Time                  `json:"closedAt,omitempty"`
+	CreatedAt     time.Time                   `gorm:"autoCreateTime;index" json:"createdAt"`
+	UpdatedAt     time.Time                   `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (t CollectionTask) GetCreatedAt() time.Time {
+	return t.CreatedAt
+}
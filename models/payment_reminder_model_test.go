@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderStagesCurrent(t *testing.T) {
+	due := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		date time.Time
+		want *int
+	}{
+		{due.AddDate(0, 0, -8), nil},
+		{due.AddDate(0, 0, -7), intPtr(-7)},
+		{due.AddDate(0, 0, -1), intPtr(-7)},
+		{due.Add(15 * time.Hour), intPtr(0)},
+		{due.AddDate(0, 0, 16), intPtr(15)},
+		{due.AddDate(0, 0, 90), intPtr(30)},
+	}
+	for _, test := range tests {
+		got := DefaultReminderStages.Current(due, test.date)
+		switch {
+		case test.want == nil && got != nil:
+			t.Errorf("Current(%s) = %d, want none", test.date.Format(time.DateOnly), got.Days)
+		case test.want != nil && (got == nil || got.Days != *test.want):
+			t.Errorf("Current(%s) = %v, want %d", test.date.Format(time.DateOnly), got, *test.want)
+		}
+	}
+}
+
+func TestReminderStagesNormalize(t *testing.T) {
+	stages, err := ReminderStages{{Days: 30, Escalate: true}, {Days: -3, Notify: true}}.Normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stages[0].Days != -3 || stages[1].Days != 30 {
+		t.Errorf("stages not ordered: %+v", stages)
+	}
+
+	if _, err := (ReminderStages{{Days: 1, Notify: true}, {Days: 1, Escalate: true}}).Normalize(); err == nil {
+		t.Error("want error for repeated day")
+	}
+	if _, err := (ReminderStages{{Days: 1}}).Normalize(); err == nil {
+		t.Error("want error for stage without action")
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
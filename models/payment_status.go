@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"circledigital.in/real-state-erp/utils/custom"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -18,7 +19,13 @@ type TowerPaymentStatus struct {
 	TowerId              uuid.UUID             `gorm:"primaryKey" json:"towerId"`
 	PaymentPlanRatioItem *PaymentPlanRatioItem `gorm:"foreignKey:PaymentId;not null" json:"paymentPlan,omitempty"`
 	Tower                *Tower                `gorm:"foreignKey:TowerId;not null" json:"tower,omitempty"`
-	CreatedAt            time.Time             `gorm:"autoCreateTime" json:"createdAt"`
+	PaymentActivation
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// GetActivation returns the activation dates, records created before effective dating fall back to creation time
+func (s TowerPaymentStatus) GetActivation() PaymentActivation {
+	return s.PaymentActivation.withFallback(s.CreatedAt)
 }
 
 type FlatPaymentStatus struct {
@@ -26,7 +33,43 @@ type FlatPaymentStatus struct {
 	FlatId               uuid.UUID             `gorm:"primaryKey" json:"flatId"`
 	PaymentPlanRatioItem *PaymentPlanRatioItem `gorm:"foreignKey:PaymentId;not null" json:"paymentPlan,omitempty"`
 	Flat                 *Flat                 `gorm:"foreignKey:FlatId;not null" json:"flat,omitempty"`
-	CreatedAt            time.Time             `gorm:"autoCreateTime" json:"createdAt"`
+	PaymentActivation
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// GetActivation returns the activation dates, records created before effective dating fall back to creation time
+func (s FlatPaymentStatus) GetActivation() PaymentActivation {
+	return s.PaymentActivation.withFallback(s.CreatedAt)
+}
+
+// PaymentActivation records when an activated payment plan item became payable and the date it is due by
+type PaymentActivation struct {
+	EffectiveDate custom.DateOnly `gorm:"type:date" json:"effectiveDate"`
+	DueDate       custom.DateOnly `gorm:"type:date" json:"dueDate"`
+}
+
+// NewPaymentActivation creates an activation effective from the given date, due after the credit period in days
+func NewPaymentActivation(effectiveDate time.Time, creditPeriod int) PaymentActivation {
+	effective := time.Date(effectiveDate.Year(), effectiveDate.Month(), effectiveDate.Day(), 0, 0, 0, 0, time.UTC)
+	return PaymentActivation{
+		EffectiveDate: custom.DateOnly{Time: effective},
+		DueDate:       custom.DateOnly{Time: effective.AddDate(0, 0, creditPeriod)},
+	}
+}
+
+// IsEffective reports whether the activation has taken effect on the given date
+func (a PaymentActivation) IsEffective(asOf time.Time) bool {
+	return !asOf.Before(a.EffectiveDate.Time)
+}
+
+func (a PaymentActivation) withFallback(createdAt time.Time) PaymentActivation {
+	if a.EffectiveDate.IsZero() {
+		a.EffectiveDate = custom.DateOnly{Time: createdAt}
+	}
+	if a.DueDate.IsZero() {
+		a.DueDate = a.EffectiveDate
+	}
+	return a
 }
 
 //type SalePaymentStatus struct {
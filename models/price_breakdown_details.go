@@ -38,3 +38,13 @@ func (p *PriceBreakdownDetails) Scan(value interface{}) error {
 	}
 	return json.Unmarshal(bytes, p)
 }
+
+// GetBasicCost returns the basic flat cost of the sale
+func (p PriceBreakdownDetails) GetBasicCost() decimal.Decimal {
+	for _, detail := range p {
+		if detail.Type == "basic-cost" {
+			return detail.Total
+		}
+	}
+	return decimal.Zero
+}
@@ -124,10 +124,13 @@ func (r Receipt) CalcGST(rate int) *AmountWithGSTInclusive {
 	// }
 }
 
+// ReceiptClear is the clearance of a receipt in a bank account of the society,
+// RequiredTransfer is the deposit due to the designated account out of the amount of the receipt when it cleared
 type ReceiptClear struct {
-	ReceiptId uuid.UUID `gorm:"not null;uniqueIndex" json:"receiptId"`
-	BankId    uuid.UUID `gorm:"not null" json:"bankId"`
-	Bank      *Bank     `gorm:"foreignKey:BankId" json:"bank,omitempty"`
-	Receipt   *Receipt  `gorm:"foreignKey:ReceiptId" json:"receipt,omitempty"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	ReceiptId        uuid.UUID       `gorm:"not null;uniqueIndex" json:"receiptId"`
+	BankId           uuid.UUID       `gorm:"not null" json:"bankId"`
+	Bank             *Bank           `gorm:"foreignKey:BankId" json:"bank,omitempty"`
+	Receipt          *Receipt        `gorm:"foreignKey:ReceiptId" json:"receipt,omitempty"`
+	RequiredTransfer decimal.Decimal `gorm:"type:numeric;not null;default:0" json:"requiredTransfer"`
+	CreatedAt        time.Time       `gorm:"autoCreateTime" json:"createdAt"`
 }
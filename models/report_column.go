@@ -0,0 +1,422 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// master report columns are identified by stable ids, headings are only display text
+// report templates select, order and rename columns by id and GetRowData fills the values by id
+
+// column groups of the master report
+const (
+	ColumnGroupMember           = "member"
+	ColumnGroupFlat             = "flat"
+	ColumnGroupPaymentPlan      = "payment-plan"
+	ColumnGroupCustomer         = "customer"
+	ColumnGroupCompanyCustomer  = "company-customer"
+	ColumnGroupPriceBreakdown   = "price-breakdown"
+	ColumnGroupSale             = "sale"
+	ColumnGroupBroker           = "broker"
+	ColumnGroupPaymentPlanItems = "payment-plan-items"
+	ColumnGroupInstallments     = "installments"
+)
+
+// reportRow is what the values of a master report row are computed from
+// sale is nil for unsold flats, the item fields are set for the columns repeated per item
+type reportRow struct {
+	flat      *Flat
+	sale      *Sale
+	towerName string
+
+	// price breakdown summary of the column
+	summary string
+	// payment plan item of the column, nil when the item is not active
+	finance    *Finance
+	activation *PaymentActivation
+	// receipt of the installment column, nil when the sale has fewer receipts
+	receipt *Receipt
+}
+
+// ReportColumn is a column of the master report registry
+type ReportColumn struct {
+	Id       string `json:"id"`
+	Heading  string `json:"heading"`
+	Monetary bool   `json:"monetary"`
+	Color    string `json:"color,omitempty"`
+	// Default columns are in the report when no template is selected
+	Default bool `json:"default"`
+
+	value func(r *reportRow) string
+}
+
+// ReportColumnGroup is a group of master report columns
+// columns of repeated groups are repeated for each price breakdown item, payment plan item or installment of the sales
+type ReportColumnGroup struct {
+	Id       string         `json:"id"`
+	Heading  string         `json:"heading"`
+	Repeated bool           `json:"repeated"`
+	Columns  []ReportColumn `json:"columns"`
+}
+
+// saleValue returns a column value computed from the sale, blank for unsold flats
+func saleValue(value func(s *Sale) string) func(r *reportRow) string {
+	return func(r *reportRow) string {
+		if r.sale == nil {
+			return ""
+		}
+		return value(r.sale)
+	}
+}
+
+// customerValue returns a column value computed from the first customer of the sale
+func customerValue(value func(c *Customer) string) func(r *reportRow) string {
+	return saleValue(func(s *Sale) string {
+		if len(s.Customers) == 0 {
+			return ""
+		}
+		return value(&s.Customers[0])
+	})
+}
+
+func companyCustomerValue(value func(c *CompanyCustomer) string) func(r *reportRow) string {
+	return saleValue(func(s *Sale) string {
+		if s.CompanyCustomer == nil {
+			return ""
+		}
+		return value(s.CompanyCustomer)
+	})
+}
+
+func paymentPlanItemValue(value func(f *Finance, a *PaymentActivation) string) func(r *reportRow) string {
+	return func(r *reportRow) string {
+		if r.finance == nil || r.activation == nil {
+			return ""
+		}
+		return value(r.finance, r.activation)
+	}
+}
+
+func receiptValue(value func(receipt *Receipt) string) func(r *reportRow) string {
+	return func(r *reportRow) string {
+		if r.receipt == nil {
+			return ""
+		}
+		return value(r.receipt)
+	}
+}
+
+// paidExcludingTax is the amount paid against the price of the sale, taxes collected with receipts are excluded
+func paidExcludingTax(s *Sale) decimal.Decimal {
+	paid := s.PaidAmount()
+	for _, r := range s.Receipts {
+		for _, tax := range []*decimal.Decimal{r.CGST, r.SGST, r.ServiceTax, r.SwathchBharatCess, r.KrishiKalyanCess} {
+			if tax != nil {
+				paid = paid.Sub(*tax)
+			}
+		}
+	}
+	return paid
+}
+
+// MasterReportColumns is the registry of master report columns, in the default order of the report
+// column ids are stored in report templates and must not change
+var MasterReportColumns = []ReportColumnGroup{
+	{
+		Id:      ColumnGroupMember,
+		Heading: "Member ID",
+		Columns: []ReportColumn{
+			{Id: "member.id", Heading: "Member ID", Default: true, value: func(r *reportRow) string {
+				if r.flat.SaleDetail == nil {
+					return ""
+				}
+				return r.flat.SaleDetail.SaleNumber
+			}},
+		},
+	},
+	{
+		Id:      ColumnGroupFlat,
+		Heading: HeadingFlat,
+		Columns: []ReportColumn{
+			{Id: "flat.tower", Heading: "Tower", Default: true, value: func(r *reportRow) string { return r.towerName }},
+			{Id: "flat.floor", Heading: "Floor", Default: true, value: func(r *reportRow) string { return fmt.Sprintf("%d", r.flat.FloorNumber) }},
+			{Id: "flat.name", Heading: "Flat", Default: true, value: func(r *reportRow) string { return r.flat.Name }},
+			{Id: "flat.facing", Heading: "Facing", Default: true, value: func(r *reportRow) string { return string(r.flat.Facing) }},
+			{Id: "flat.unit-type", Heading: "Unit Type", Default: true, value: func(r *reportRow) string { return r.flat.UnitType }},
+			{Id: "flat.saleable-area", Heading: "Saleable Area", Default: true, value: func(r *reportRow) string { return r.flat.SaleableArea.String() }},
+		},
+	},
+	{
+		Id:      ColumnGroupPaymentPlan,
+		Heading: HeadingPaymentPlan,
+		Columns: []ReportColumn{
+			{Id: "payment-plan.name", Heading: "Name", Default: true, value: saleValue(func(s *Sale) string {
+				if s.PaymentPlanRatio.PaymentPlanGroup == nil {
+					return ""
+				}
+				return s.PaymentPlanRatio.PaymentPlanGroup.Name
+			})},
+			{Id: "payment-plan.ratio", Heading: "Ratio", value: saleValue(func(s *Sale) string { return s.PaymentPlanRatio.Ratio })},
+		},
+	},
+	{
+		Id:      ColumnGroupCustomer,
+		Heading: HeadingCustomer,
+		Columns: []ReportColumn{
+			{Id: "customer.name", Heading: "Name", Default: true, value: customerValue(func(c *Customer) string { return c.FirstName + " " + c.LastName })},
+			{Id: "customer.gender", Heading: "Gender", value: customerValue(func(c *Customer) string { return string(c.Gender) })},
+			{Id: "customer.email", Heading: "Email", Default: true, value: customerValue(func(c *Customer) string { return c.Email })},
+			{Id: "customer.phone", Heading: "Phone Number", Default: true, value: customerValue(func(c *Customer) string { return c.PhoneNumber })},
+			{Id: "customer.nationality", Heading: "Nationality", Default: true, value: customerValue(func(c *Customer) string { return string(c.Nationality) })},
+			{Id: "customer.aadhar", Heading: "Aadhar", Default: true, value: customerValue(func(c *Customer) string { return c.AadharNumber })},
+			{Id: "customer.pan", Heading: "PAN", Default: true, value: customerValue(func(c *Customer) string { return c.PanNumber })},
+			{Id: "customer.passport", Heading: "Passport Number", Default: true, value: customerValue(func(c *Customer) string { return c.PassportNumber })},
+			{Id: "customer.profession", Heading: "Profession", Default: true, value: customerValue(func(c *Customer) string { return c.Profession })},
+			{Id: "customer.company-name", Heading: "Company Name", Default: true, value: customerValue(func(c *Customer) string { return c.CompanyName })},
+		},
+	},
+	{
+		Id:      ColumnGroupCompanyCustomer,
+		Heading: HeadingCompanyCustomer,
+		Columns: []ReportColumn{
+			{Id: "company-customer.name", Heading: "Name", Default: true, value: companyCustomerValue(func(c *CompanyCustomer) string { return c.Name })},
+			{Id: "company-customer.company-pan", Heading: "Company PAN", Default: true, value: companyCustomerValue(func(c *CompanyCustomer) string { return c.CompanyPan })},
+			{Id: "company-customer.gst", Heading: "GST", Default: true, value: companyCustomerValue(func(c *CompanyCustomer) string { return c.CompanyGst })},
+			{Id: "company-customer.aadhar", Heading: "Aadhar", Default: true, value: companyCustomerValue(func(c *CompanyCustomer) string { return c.AadharNumber })},
+			{Id: "company-customer.pan", Heading: "PAN", Default: true, value: companyCustomerValue(func(c *CompanyCustomer) string { return c.PanNumber })},
+		},
+	},
+	{
+		Id:       ColumnGroupPriceBreakdown,
+		Heading:  HeadingPricebreakdown,
+		Repeated: true,
+		Columns: []ReportColumn{
+			// heading is the summary of the price breakdown item
+			{Id: "price-breakdown.total", Heading: "Total", Monetary: true, Default: true, value: func(r *reportRow) string {
+				if r.sale == nil {
+					return ""
+				}
+				return r.sale.PriceBreakdown.GetPriceFromSummary(r.summary).String()
+			}},
+		},
+	},
+	{
+		Id:      ColumnGroupSale,
+		Heading: HeadingSale,
+		Columns: []ReportColumn{
+			{Id: "sale.number", Heading: "ID", value: saleValue(func(s *Sale) string { return s.SaleNumber })},
+			{Id: "sale.total-price", Heading: "Total Price", Monetary: true, Default: true, value: saleValue(func(s *Sale) string { return s.TotalPrice.String() })},
+			{Id: "sale.total-payable", Heading: "Total Payable Amount", Monetary: true, Default: true, value: saleValue(func(s *Sale) string { return s.GetTotalPayableAmount().String() })},
+			{Id: "sale.total-paid", Heading: "Total Paid Amount", Monetary: true, Default: true, value: saleValue(func(s *Sale) string { return s.PaidAmount().String() })},
+			{Id: "sale.paid-excluding-tax", Heading: "Paid Amount", Monetary: true, Color: "yellow", Default: true, value: saleValue(func(s *Sale) string { return paidExcludingTax(s).String() })},
+			{Id: "sale.cgst", Heading: "CGST", Monetary: true, Color: "yellow", Default: true, value: saleValue((*Sale).GetTotalCGST)},
+			{Id: "sale.sgst", Heading: "SGST", Monetary: true, Color: "yellow", Default: true, value: saleValue((*Sale).GetTotalSGST)},
+			{Id: "sale.service-tax", Heading: "Service Tax", Monetary: true, Color: "yellow", Default: true, value: saleValue((*Sale).GetTotalServiceTax)},
+			{Id: "sale.swachh-bharat-cess", Heading: "Swathch Bharat Cess", Monetary: true, Color: "yellow", Default: true, value: saleValue((*Sale).GetTotalSwachhBharatCess)},
+			{Id: "sale.krishi-kalyan-cess", Heading: "Krishi Kalyan Cess", Monetary: true, Color: "yellow", Default: true, value: saleValue((*Sale).GetTotalKrishiKalyanCess)},
+			{Id: "sale.pending", Heading: "Pending Amount", Monetary: true, Default: true, value: saleValue(func(s *Sale) string { return s.Pending().String() })},
+		},
+	},
+	{
+		Id:      ColumnGroupBroker,
+		Heading: HeadingBroker,
+		Columns: []ReportColumn{
+			{Id: "broker.channel", Heading: "Channel", Default: true, value: saleValue(func(s *Sale) string { return string(s.Channel) })},
+			{Id: "broker.name", Heading: "Name", Default: true, value: saleValue(func(s *Sale) string {
+				name, _, _ := s.GetChannelPartner()
+				return name
+			})},
+			{Id: "broker.aadhar", Heading: "Aadhar", Default: true, value: saleValue(func(s *Sale) string {
+				_, aadhar, _ := s.GetChannelPartner()
+				return aadhar
+			})},
+			{Id: "broker.pan", Heading: "PAN", Default: true, value: saleValue(func(s *Sale) string {
+				_, _, pan := s.GetChannelPartner()
+				return pan
+			})},
+		},
+	},
+	{
+		// a heading for each payment plan of the sales with the columns under each item of the plan
+		Id:       ColumnGroupPaymentPlanItems,
+		Heading:  "Payment Plan Items",
+		Repeated: true,
+		Columns: []ReportColumn{
+			{Id: "payment-plan-item.collection-date", Heading: "Collection Date", Default: true, value: paymentPlanItemValue(func(_ *Finance, a *PaymentActivation) string { return formatDateTime(a.EffectiveDate.Time) })},
+			{Id: "payment-plan-item.due-date", Heading: "Due Date", Default: true, value: paymentPlanItemValue(func(_ *Finance, a *PaymentActivation) string { return formatDateTime(a.DueDate.Time) })},
+			{Id: "payment-plan-item.total", Heading: "Total Amount", Monetary: true, Default: true, value: paymentPlanItemValue(func(f *Finance, _ *PaymentActivation) string { return f.Total.String() })},
+			{Id: "payment-plan-item.paid", Heading: "Paid", Monetary: true, Default: true, value: paymentPlanItemValue(func(f *Finance, _ *PaymentActivation) string { return f.Paid.String() })},
+			{Id: "payment-plan-item.pending", Heading: "Pending", Monetary: true, Default: true, value: paymentPlanItemValue(func(f *Finance, _ *PaymentActivation) string { return f.Remaining.String() })},
+		},
+	},
+	{
+		Id:       ColumnGroupInstallments,
+		Heading:  HeadingInstallment,
+		Repeated: true,
+		Columns: []ReportColumn{
+			{Id: "installment.number", Heading: "Number", Default: true, value: receiptValue(func(r *Receipt) string { return r.ReceiptNumber })},
+			{Id: "installment.date", Heading: "Date", Default: true, value: receiptValue(func(r *Receipt) string { return formatDateTime(r.CreatedAt) })},
+			{Id: "installment.amount", Heading: "Amount", Monetary: true, Default: true, value: receiptValue(func(r *Receipt) string { return r.TotalAmount.String() })},
+			{Id: "installment.mode", Heading: "Type", Default: true, value: receiptValue(func(r *Receipt) string { return string(r.Mode) })},
+			{Id: "installment.cgst", Heading: "CGST", Monetary: true, Default: true, value: receiptValue((*Receipt).GetCGST)},
+			{Id: "installment.sgst", Heading: "SGST", Monetary: true, Default: true, value: receiptValue((*Receipt).GetSGST)},
+			{Id: "installment.service-tax", Heading: "Service Tax", Monetary: true, Default: true, value: receiptValue((*Receipt).GetServiceTax)},
+			{Id: "installment.swachh-bharat-cess", Heading: "Swathch Bharat Cess", Monetary: true, Default: true, value: receiptValue((*Receipt).GetSwathchBharatCess)},
+			{Id: "installment.krishi-kalyan-cess", Heading: "Krishi Kalyan Cess", Monetary: true, Default: true, value: receiptValue((*Receipt).GetKrishiKalyanCess)},
+			{Id: "installment.status", Heading: "Status", Default: true, value: receiptValue((*Receipt).GetReceiptStatus)},
+			{Id: "installment.cleared-at", Heading: "Cleared At", Default: true, value: receiptValue(func(r *Receipt) string {
+				if r.Cleared == nil {
+					return ""
+				}
+				return formatDateTime(r.Cleared.CreatedAt)
+			})},
+		},
+	},
+}
+
+var (
+	reportColumnsById = make(map[string]*ReportColumn)
+	reportGroupsById  = make(map[string]*ReportColumnGroup)
+	reportColumnGroup = make(map[string]string)
+)
+
+func init() {
+	for i := range MasterReportColumns {
+		group := &MasterReportColumns[i]
+		reportGroupsById[group.Id] = group
+		for j := range group.Columns {
+			reportColumnsById[group.Columns[j].Id] = &group.Columns[j]
+			reportColumnGroup[group.Columns[j].Id] = group.Id
+		}
+	}
+}
+
+// ReportLayoutColumn selects a column of the registry, heading renames it
+type ReportLayoutColumn struct {
+	Id      string `json:"id"`
+	Heading string `json:"heading,omitempty"`
+}
+
+// ReportLayoutGroup selects a group of the registry with its columns in order, heading renames it
+// all default columns of the group are selected when columns is empty
+type ReportLayoutGroup struct {
+	Id      string               `json:"id"`
+	Heading string               `json:"heading,omitempty"`
+	Columns []ReportLayoutColumn `json:"columns,omitempty"`
+}
+
+// ReportLayout is the groups of a master report in order
+type ReportLayout []ReportLayoutGroup
+
+// DefaultReportLayout is the layout of the master report when no template is selected
+func DefaultReportLayout() ReportLayout {
+	layout := make(ReportLayout, 0, len(MasterReportColumns))
+	for _, group := range MasterReportColumns {
+		layout = append(layout, ReportLayoutGroup{Id: group.Id})
+	}
+	return layout
+}
+
+func (l ReportLayout) Value() (driver.Value, error) {
+	if l == nil {
+		l = ReportLayout{}
+	}
+	return json.Marshal(l)
+}
+
+func (l *ReportLayout) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal ReportLayout: %v", value)
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Validate reports the first unknown, misplaced or repeated group or column of the layout
+func (l ReportLayout) Validate() error {
+	if len(l) == 0 {
+		return fmt.Errorf("layout must select at least one group")
+	}
+
+	groups := make(map[string]bool)
+	columns := make(map[string]bool)
+	for _, group := range l {
+		if _, ok := reportGroupsById[group.Id]; !ok {
+			return fmt.Errorf("unknown column group %q", group.Id)
+		}
+		if groups[group.Id] {
+			return fmt.Errorf("column group %q is repeated", group.Id)
+		}
+		groups[group.Id] = true
+		if len(group.Heading) > 100 {
+			return fmt.Errorf("heading of column group %q is too long", group.Id)
+		}
+
+		for _, column := range group.Columns {
+			if reportColumnGroup[column.Id] != group.Id {
+				return fmt.Errorf("column %q is not in group %q", column.Id, group.Id)
+			}
+			if columns[column.Id] {
+				return fmt.Errorf("column %q is repeated", column.Id)
+			}
+			columns[column.Id] = true
+			if len(column.Heading) > 100 {
+				return fmt.Errorf("heading of column %q is too long", column.Id)
+			}
+		}
+	}
+	return nil
+}
+
+// Resolve returns the groups of the layout with their selected columns, headings renamed by the layout
+// the layout must be valid
+func (l ReportLayout) Resolve() []ReportColumnGroup {
+	resolved := make([]ReportColumnGroup, 0, len(l))
+	for _, selected := range l {
+		registered := reportGroupsById[selected.Id]
+		if registered == nil {
+			continue
+		}
+
+		group := *registered
+		if heading := strings.TrimSpace(selected.Heading); heading != "" {
+			group.Heading = heading
+		}
+
+		group.Columns = nil
+		if len(selected.Columns) == 0 {
+			for _, column := range registered.Columns {
+				if column.Default {
+					group.Columns = append(group.Columns, column)
+				}
+			}
+		}
+		for _, selectedColumn := range selected.Columns {
+			column := *reportColumnsById[selectedColumn.Id]
+			if heading := strings.TrimSpace(selectedColumn.Heading); heading != "" {
+				column.Heading = heading
+			}
+			group.Columns = append(group.Columns, column)
+		}
+		resolved = append(resolved, group)
+	}
+	return resolved
+}
+
+// ReportColumnHeaders returns the leaf headers of the columns
+func ReportColumnHeaders(columns []ReportColumn) []Header {
+	headers := make([]Header, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, Header{
+			Key:        column.Id,
+			Heading:    column.Heading,
+			Color:      column.Color,
+			IsMonetary: column.Monetary,
+		})
+	}
+	return headers
+}
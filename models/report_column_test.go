@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReportLayoutValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout ReportLayout
+		valid  bool
+	}{
+		{"default", DefaultReportLayout(), true},
+		{"selected columns", ReportLayout{{Id: ColumnGroupCustomer, Columns: []ReportLayoutColumn{{Id: "customer.name"}, {Id: "customer.gender"}}}}, true},
+		{"empty", ReportLayout{}, false},
+		{"unknown group", ReportLayout{{Id: "unknown"}}, false},
+		{"repeated group", ReportLayout{{Id: ColumnGroupFlat}, {Id: ColumnGroupFlat}}, false},
+		{"column of other group", ReportLayout{{Id: ColumnGroupFlat, Columns: []ReportLayoutColumn{{Id: "customer.name"}}}}, false},
+		{"repeated column", ReportLayout{{Id: ColumnGroupFlat, Columns: []ReportLayoutColumn{{Id: "flat.name"}, {Id: "flat.name"}}}}, false},
+		{"long heading", ReportLayout{{Id: ColumnGroupFlat, Heading: strings.Repeat("a", 101)}}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.layout.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: got error %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestReportLayoutResolve(t *testing.T) {
+	layout := ReportLayout{
+		{Id: ColumnGroupCustomer, Heading: "Buyer", Columns: []ReportLayoutColumn{{Id: "customer.pan", Heading: "PAN No."}, {Id: "customer.name"}}},
+		{Id: ColumnGroupFlat},
+	}
+	groups := layout.Resolve()
+	if len(groups) != 2 {
+		t.Fatalf("want 2 groups, got %d", len(groups))
+	}
+
+	buyer := groups[0]
+	if buyer.Heading != "Buyer" || len(buyer.Columns) != 2 {
+		t.Fatalf("unexpected customer group %+v", buyer)
+	}
+	if buyer.Columns[0].Id != "customer.pan" || buyer.Columns[0].Heading != "PAN No." || buyer.Columns[1].Heading != "Name" {
+		t.Errorf("unexpected customer columns %+v", buyer.Columns)
+	}
+
+	// an empty selection is the default columns of the group
+	for _, column := range groups[1].Columns {
+		if !column.Default {
+			t.Errorf("non default column %s selected", column.Id)
+		}
+	}
+
+	// renaming does not change the registry
+	if reportColumnsById["customer.pan"].Heading == "PAN No." {
+		t.Errorf("registry heading changed")
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportTemplate is a saved selection of master report columns of the organization
+// the master report is generated with the layout of the template when its id is given
+type ReportTemplate struct {
+	Id           uuid.UUID     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId        uuid.UUID     `gorm:"not null;uniqueIndex:idx_report_template_name" json:"orgId"`
+	Organization *Organization `gorm:"foreignKey:OrgId;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"organization,omitempty"`
+	Name         string        `gorm:"not null;uniqueIndex:idx_report_template_name" json:"name"`
+	Description  string        `json:"description,omitempty"`
+	Layout       ReportLayout  `gorm:"type:jsonb;not null" json:"layout"`
+	CreatedBy    string        `json:"createdBy"`
+	UpdatedBy    string        `json:"updatedBy"`
+	CreatedAt    time.Time     `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time     `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (t ReportTemplate) GetCreatedAt() time.Time {
+	return t.CreatedAt
+}
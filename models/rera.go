@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/shopspring/decimal"
+)
+
+// ReraDesignatedShare is the share of the amount realised from allottees that is deposited in the designated account of the project
+var ReraDesignatedShare = decimal.RequireFromString("0.70")
+
+// RequiredDesignatedDeposit returns the amount to deposit in the designated account out of the amount realised
+func RequiredDesignatedDeposit(realised decimal.Decimal) decimal.Decimal {
+	return realised.Mul(ReraDesignatedShare).Round(2)
+}
+
+// Quarter is a calendar quarter progress of a project is filed for with the authority
+type Quarter struct {
+	Year   int
+	Number int
+}
+
+// ParseQuarter parses a quarter written as YYYY-QN, like 2025-Q1 for january to march 2025
+func ParseQuarter(value string) (Quarter, error) {
+	var quarter Quarter
+	_, err := fmt.Sscanf(value, "%4d-Q%1d", &quarter.Year, &quarter.Number)
+	if err != nil || quarter.Number < 1 || quarter.Number > 4 || quarter.String() != value {
+		return Quarter{}, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid quarter (expected YYYY-QN).",
+		}
+	}
+	return quarter, nil
+}
+
+// QuarterOf returns the quarter of the date
+func QuarterOf(t time.Time) Quarter {
+	return Quarter{Year: t.Year(), Number: (int(t.Month())-1)/3 + 1}
+}
+
+// Start returns the first day of the quarter
+func (q Quarter) Start() time.Time {
+	return time.Date(q.Year, time.Month((q.Number-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// End returns the first day of the next quarter, the quarter ends before it
+func (q Quarter) End() time.Time {
+	return q.Start().AddDate(0, 3, 0)
+}
+
+// Previous returns the quarter before
+func (q Quarter) Previous() Quarter {
+	return QuarterOf(q.Start().AddDate(0, -1, 0))
+}
+
+func (q Quarter) String() string {
+	return fmt.Sprintf("%04d-Q%d", q.Year, q.Number)
+}
+
+// DesignatedAccountCompliance is the deposit in the designated account against the deposit required out of the amount realised,
+// deposits are collections realised directly in the designated account and transfers into it from the other accounts
+type DesignatedAccountCompliance struct {
+	Realised  decimal.Decimal `json:"realised"`
+	Required  decimal.Decimal `json:"required"`
+	Deposited decimal.Decimal `json:"deposited"`
+	Shortfall decimal.Decimal `json:"shortfall"`
+}
+
+// NewDesignatedAccountCompliance returns the compliance of the deposit, there is no shortfall when more than required is deposited
+func NewDesignatedAccountCompliance(realised, deposited decimal.Decimal) DesignatedAccountCompliance {
+	required := RequiredDesignatedDeposit(realised)
+	return DesignatedAccountCompliance{
+		Realised:  realised,
+		Required:  required,
+		Deposited: deposited,
+		Shortfall: decimal.Max(required.Sub(deposited), decimal.Zero),
+	}
+}
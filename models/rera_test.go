@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/shopspring/decimal"
+)
+
+func TestParseQuarter(t *testing.T) {
+	quarter, err := ParseQuarter("2025-Q2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !quarter.Start().Equal(date(2025, time.April, 1)) || !quarter.End().Equal(date(2025, time.July, 1)) {
+		t.Errorf("want april to june, got %s to %s", quarter.Start(), quarter.End())
+	}
+	if previous := quarter.Previous(); previous.String() != "2025-Q1" {
+		t.Errorf("want previous 2025-Q1, got %s", previous)
+	}
+	if previous := (Quarter{Year: 2025, Number: 1}).Previous(); previous.String() != "2024-Q4" {
+		t.Errorf("want previous 2024-Q4, got %s", previous)
+	}
+	if quarter := QuarterOf(date(2024, time.December, 31)); quarter.String() != "2024-Q4" {
+		t.Errorf("want 2024-Q4, got %s", quarter)
+	}
+
+	for _, value := range []string{"", "2025-Q0", "2025-Q5", "2025Q1", "2025-Q1x", "25-Q1"} {
+		if _, err := ParseQuarter(value); err == nil {
+			t.Errorf("want error for %q", value)
+		}
+	}
+}
+
+func TestRequiredDesignatedDeposit(t *testing.T) {
+	if deposit := RequiredDesignatedDeposit(decimal.RequireFromString("1000000.55")); !deposit.Equal(decimal.RequireFromString("700000.39")) {
+		t.Errorf("want 7,00,000.39, got %s", deposit)
+	}
+}
+
+func TestDesignatedAccountCompliance(t *testing.T) {
+	compliance := NewDesignatedAccountCompliance(decimal.NewFromInt(1000000), decimal.NewFromInt(500000))
+	if !compliance.Required.Equal(decimal.NewFromInt(700000)) || !compliance.Shortfall.Equal(decimal.NewFromInt(200000)) {
+		t.Errorf("want shortfall of 2,00,000 against 7,00,000, got %+v", compliance)
+	}
+	if compliance := NewDesignatedAccountCompliance(decimal.NewFromInt(1000000), decimal.NewFromInt(800000)); !compliance.Shortfall.IsZero() {
+		t.Errorf("want no shortfall on excess deposit, got %s", compliance.Shortfall)
+	}
+}
+
+func TestBankRequiredTransfer(t *testing.T) {
+	realised := decimal.NewFromInt(100000)
+	tests := []struct {
+		accountType custom.BankAccountType
+		want        decimal.Decimal
+	}{
+		{custom.BANK_ACCOUNT_COLLECTION, decimal.NewFromInt(70000)},
+		{custom.BANK_ACCOUNT_FREE, decimal.NewFromInt(70000)},
+		{custom.BANK_ACCOUNT_DESIGNATED, decimal.Zero},
+	}
+	for _, test := range tests {
+		if transfer := (Bank{AccountType: test.accountType}).RequiredTransfer(realised); !transfer.Equal(test.want) {
+			t.Errorf("%s: want %s, got %s", test.accountType, test.want, transfer)
+		}
+	}
+}
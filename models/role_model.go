@@ -0,0 +1,79 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+// PermissionList is the permissions of a role stored as jsonb
+type PermissionList []custom.Permission
+
+func (p PermissionList) Value() (driver.Value, error) {
+	if p == nil {
+		p = PermissionList{}
+	}
+	return json.Marshal(p)
+}
+
+func (p *PermissionList) Scan(value interface{}) error {
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal PermissionList: %v", value)
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// Role is a named set of permissions defined by the organization
+type Role struct {
+	Id           uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId        uuid.UUID      `gorm:"not null;uniqueIndex:idx_role_org_name" json:"orgId"`
+	Organization *Organization  `gorm:"foreignKey:OrgId;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"organization,omitempty"`
+	Name         string         `gorm:"not null;uniqueIndex:idx_role_org_name" json:"name"`
+	Description  string         `json:"description"`
+	Permissions  PermissionList `gorm:"type:jsonb;not null" json:"permissions"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (r Role) GetCreatedAt() time.Time {
+	return r.CreatedAt
+}
+
+// UserSociety is the membership of a user in a society, used only for users restricted to their societies
+type UserSociety struct {
+	UserEmail string    `gorm:"primaryKey" json:"userEmail"`
+	SocietyId string    `gorm:"primaryKey" json:"societyId"`
+	OrgId     uuid.UUID `gorm:"primaryKey" json:"orgId"`
+	Society   *Society  `gorm:"foreignKey:SocietyId,OrgId;references:ReraNumber,OrgId;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"society,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// ResolveUserAccess returns the access of a user with the role from the token
+// org-admin has every permission on every society, custom role replaces the default permissions of other roles
+// user is nil when the user has no record in the organization
+func ResolveUserAccess(role custom.UserRole, user *User) *custom.UserAccess {
+	access := &custom.UserAccess{
+		Permissions: make(map[custom.Permission]bool),
+	}
+
+	permissions := custom.DefaultRolePermissions[role]
+	if role != custom.ORGADMIN && user != nil && user.AccessRole != nil {
+		permissions = user.AccessRole.Permissions
+	}
+	for _, permission := range permissions {
+		access.Permissions[permission] = true
+	}
+
+	if role != custom.ORGADMIN && user != nil && user.RestrictSocieties {
+		access.Societies = make(map[string]bool)
+		for _, membership := range user.Societies {
+			access.Societies[membership.SocietyId] = true
+		}
+	}
+	return access
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+func TestResolveUserAccess(t *testing.T) {
+	role := &Role{Permissions: PermissionList{custom.PERMISSION_RECEIPT_CLEAR}}
+	user := &User{
+		AccessRole:        role,
+		RestrictSocieties: true,
+		Societies:         []UserSociety{{SocietyId: "RERA-1"}},
+	}
+
+	access := ResolveUserAccess(custom.ORGUSER, user)
+	if !access.Can(custom.PERMISSION_RECEIPT_CLEAR) || access.Can(custom.PERMISSION_RECEIPT_CREATE) {
+		t.Errorf("want custom role to replace default permissions, got %v", access.Permissions)
+	}
+	if !access.CanAccessSociety("RERA-1") || access.CanAccessSociety("RERA-2") {
+		t.Errorf("want access only to member society, got %v", access.Societies)
+	}
+
+	admin := ResolveUserAccess(custom.ORGADMIN, user)
+	if !admin.Can(custom.PERMISSION_SALE_DELETE) || !admin.CanAccessSociety("RERA-2") {
+		t.Error("want org admin to keep every permission on every society")
+	}
+
+	viewer := ResolveUserAccess(custom.ORGVIEWER, nil)
+	if !viewer.Can(custom.PERMISSION_REPORT_VIEW) || viewer.Can(custom.PERMISSION_REPORT_MASTER) {
+		t.Errorf("want default viewer permissions, got %v", viewer.Permissions)
+	}
+	if !viewer.CanAccessSociety("RERA-2") {
+		t.Error("want unrestricted user to access every society")
+	}
+}
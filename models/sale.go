@@ -1,11 +1,13 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"circledigital.in/real-state-erp/utils/custom"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type Sale struct {
@@ -16,8 +18,12 @@ type Sale struct {
 	SocietyId          string                `gorm:"not null;index" json:"societyId"`
 	OrgId              uuid.UUID             `gorm:"not null;index" json:"orgId"`
 	Society            *Society              `gorm:"foreignKey:SocietyId,OrgId;references:ReraNumber,OrgId;not null;constraint:OnUpdate:CASCADE" json:"society,omitempty"`
-	BrokerId           uuid.UUID             `gorm:"not null;index" json:"brokerId"`
-	Broker             *Broker               `gorm:"foreignKey:BrokerId;not null;constraint:OnUpdate:CASCADE" json:"broker,omitempty"`
+	Channel            custom.SaleChannel    `gorm:"not null;default:'broker'" json:"channel"`
+	BrokerId           *uuid.UUID            `gorm:"index" json:"brokerId,omitempty"` // set only for broker channel
+	Broker             *Broker               `gorm:"foreignKey:BrokerId;constraint:OnUpdate:CASCADE" json:"broker,omitempty"`
+	ReferrerCustomerId *uuid.UUID            `gorm:"index" json:"referrerCustomerId,omitempty"` // set only for customer referral
+	ReferrerCustomer   *Customer             `gorm:"foreignKey:ReferrerCustomerId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"referrerCustomer,omitempty"`
+	ReferrerEmployee   string                `json:"referrerEmployee,omitempty"` // set only for employee referral
 	PaymentPlanRatioId uuid.UUID             `json:"paymentPlanRatioId"`
 	PaymentPlanRatio   *PaymentPlanRatio     `gorm:"foreignKey:PaymentPlanRatioId;constraint:OnUpdate:CASCADE" json:"PaymentPlanRatio"`
 	TotalPrice         decimal.Decimal       `gorm:"not null;type:numeric" json:"totalPrice"`
@@ -30,14 +36,48 @@ type Sale struct {
 	Customers          []Customer            `gorm:"foreignKey:SaleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"owners,omitempty"`
 	CompanyCustomer    *CompanyCustomer      `gorm:"foreignKey:SaleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"companyCustomer,omitempty"`
 	Receipts           []Receipt             `gorm:"foreignKey:SaleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"receipts,omitempty"`
+	Allotment          *SaleAllotment        `gorm:"foreignKey:SaleId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"allotment,omitempty"`
 	//PaymentStatus  []SalePaymentStatus   `gorm:"foreignKey:SaleId" json:"paymentStatus,omitempty"`
-	//DeletedAt      gorm.DeletedAt `gorm:"index"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deletedAt,omitempty"`
+	DeletedBy string         `json:"deletedBy,omitempty"`
 }
 
 func (u Sale) GetCreatedAt() time.Time {
 	return u.CreatedAt
 }
 
+// GetChannelPartner returns name, aadhar and pan of the party the sale is booked through
+// blank for direct bookings
+func (u Sale) GetChannelPartner() (string, string, string) {
+	switch u.Channel {
+	case custom.CHANNEL_BROKER, "":
+		if u.Broker != nil {
+			return u.Broker.Name, u.Broker.AadharNumber, u.Broker.PanNumber
+		}
+	case custom.CHANNEL_EMPLOYEE_REFERRAL:
+		return u.ReferrerEmployee, "", ""
+	case custom.CHANNEL_CUSTOMER_REFERRAL:
+		if u.ReferrerCustomer != nil {
+			name := strings.Join(strings.Fields(u.ReferrerCustomer.FirstName+" "+u.ReferrerCustomer.MiddleName+" "+u.ReferrerCustomer.LastName), " ")
+			return name, u.ReferrerCustomer.AadharNumber, u.ReferrerCustomer.PanNumber
+		}
+	}
+	return "", "", ""
+}
+
+// OwnerNames returns the name of the company for company bookings, the names of the owners otherwise
+func (u Sale) OwnerNames() string {
+	if u.CompanyCustomer != nil {
+		return u.CompanyCustomer.Name
+	}
+
+	names := make([]string, 0, len(u.Customers))
+	for _, customer := range u.Customers {
+		names = append(names, strings.Join(strings.Fields(customer.FirstName+" "+customer.MiddleName+" "+customer.LastName), " "))
+	}
+	return strings.Join(names, ", ")
+}
+
 func (u Sale) Pending() decimal.Decimal {
 	return u.GetTotalPayableAmount().Sub(u.PaidAmount())
 }
@@ -54,6 +94,17 @@ func (u Sale) PaidAmount() decimal.Decimal {
 	return sum
 }
 
+// ClearedAmount is the total of the cleared receipts of the sale, adjustments included, as the sales reports count it
+func (u Sale) ClearedAmount() decimal.Decimal {
+	sum := decimal.Zero
+	for _, receipt := range u.Receipts {
+		if receipt.Cleared != nil {
+			sum = sum.Add(receipt.TotalAmount)
+		}
+	}
+	return sum
+}
+
 func (u Sale) GetTotalPayableAmount() decimal.Decimal {
 	payableAmount := u.TotalPrice
 
@@ -139,3 +190,43 @@ func (s Sale) GetTotalKrishiKalyanCess() string {
 	}
 	return total.String()
 }
+
+// GetPaymentPlanBreakDown distributes the paid amount over the payment plan items active in activationCtx
+// PaymentPlanRatio with its items must be loaded
+func (u Sale) GetPaymentPlanBreakDown(activationCtx PaymentActivationContext) PaymentPlanSaleBreakDown {
+	totalPaid := u.PaidAmount()
+	breakDown := PaymentPlanSaleBreakDown{
+		TotalAmount: decimal.Zero,
+		PaidAmount:  totalPaid,
+		Details:     make([]PaymentPlanItemBreakDown, 0),
+	}
+
+	if u.PaymentPlanRatio == nil {
+		breakDown.Remaining = breakDown.TotalAmount.Sub(totalPaid)
+		return breakDown
+	}
+
+	totalPayableAmount := u.GetTotalPayableAmount()
+	paidRemaining := totalPaid
+
+	// paid amount is distributed over active items in plan order
+	for _, item := range u.PaymentPlanRatio.Ratios {
+		detail := PaymentPlanItemBreakDown{
+			Item:       item,
+			Activation: item.GetActivation(activationCtx),
+		}
+
+		if detail.Activation != nil {
+			detail.Finance = item.GetAmountDetails(totalPayableAmount, paidRemaining)
+			if detail.Finance != nil {
+				breakDown.TotalAmount = breakDown.TotalAmount.Add(detail.Finance.Total)
+				paidRemaining = paidRemaining.Sub(detail.Finance.Paid)
+			}
+		}
+
+		breakDown.Details = append(breakDown.Details, detail)
+	}
+
+	breakDown.Remaining = breakDown.TotalAmount.Sub(totalPaid)
+	return breakDown
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// point in time helpers, reports use them to reproduce the financial position as it was on a past date
+// a receipt counts from its issue date, a clearance from the time it was recorded
+// a sale counts from its booking (creation) time
+
+// IsIssuedBy reports whether the receipt was issued on or before asOf
+func (r Receipt) IsIssuedBy(asOf time.Time) bool {
+	if !r.DateIssued.Valid {
+		return !r.CreatedAt.After(asOf)
+	}
+	return !r.DateIssued.Time.After(asOf)
+}
+
+// AsOf returns the receipt as it was on asOf, clearance recorded after asOf is dropped
+func (r Receipt) AsOf(asOf time.Time) Receipt {
+	if r.Cleared != nil && r.Cleared.CreatedAt.After(asOf) {
+		r.Cleared = nil
+	}
+	return r
+}
+
+// IsBookedBy reports whether the sale existed on asOf
+func (u Sale) IsBookedBy(asOf time.Time) bool {
+	return !u.CreatedAt.After(asOf)
+}
+
+// AsOf returns the sale as it was on asOf, receipts issued after asOf are dropped
+func (u Sale) AsOf(asOf time.Time) Sale {
+	if u.Receipts == nil {
+		return u
+	}
+
+	receipts := make([]Receipt, 0, len(u.Receipts))
+	for _, receipt := range u.Receipts {
+		if receipt.IsIssuedBy(asOf) {
+			receipts = append(receipts, receipt.AsOf(asOf))
+		}
+	}
+	u.Receipts = receipts
+
+	if u.Allotment != nil && u.Allotment.AllotmentDate.After(asOf) {
+		u.Allotment = nil
+	}
+	return u
+}
+
+// AsOf returns the flat as it was on asOf, a sale booked after asOf is dropped
+func (f Flat) AsOf(asOf time.Time) Flat {
+	if f.SaleDetail == nil {
+		return f
+	}
+
+	if !f.SaleDetail.IsBookedBy(asOf) {
+		f.SaleDetail = nil
+		return f
+	}
+
+	sale := f.SaleDetail.AsOf(asOf)
+	f.SaleDetail = &sale
+	return f
+}
+
+// AsOf returns the tower with all its flats as they were on asOf
+func (t Tower) AsOf(asOf time.Time) Tower {
+	if t.Flats == nil {
+		return t
+	}
+
+	flats := make([]Flat, 0, len(t.Flats))
+	for _, flat := range t.Flats {
+		flats = append(flats, flat.AsOf(asOf))
+	}
+	t.Flats = flats
+	return t
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+func TestFlatAsOf(t *testing.T) {
+	asOf := date(2025, time.March, 31).AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	receipt := func(amount int64, issued time.Time, cleared *time.Time) Receipt {
+		r := Receipt{
+			TotalAmount: decimal.NewFromInt(amount),
+			Mode:        custom.CHEQUE,
+			DateIssued:  pgtype.Date{Time: issued, Valid: true},
+		}
+		if cleared != nil {
+			r.Cleared = &ReceiptClear{CreatedAt: *cleared}
+		}
+		return r
+	}
+	clearedBefore := date(2025, time.March, 31).Add(18 * time.Hour)
+	clearedAfter := date(2025, time.April, 2)
+
+	flat := Flat{
+		SaleDetail: &Sale{
+			CreatedAt:  date(2025, time.January, 5),
+			TotalPrice: decimal.NewFromInt(1000),
+			Receipts: []Receipt{
+				receipt(100, date(2025, time.February, 1), &clearedBefore),
+				receipt(200, date(2025, time.March, 20), &clearedAfter),
+				receipt(300, date(2025, time.April, 1), &clearedAfter),
+			},
+		},
+	}
+
+	snapshot := flat.AsOf(asOf)
+	if len(snapshot.SaleDetail.Receipts) != 2 {
+		t.Fatalf("want 2 receipts issued by asOf, got %d", len(snapshot.SaleDetail.Receipts))
+	}
+	if paid := snapshot.SaleDetail.PaidAmount(); !paid.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("want paid 100 as of date, got %s", paid)
+	}
+	if paid := flat.SaleDetail.PaidAmount(); !paid.Equal(decimal.NewFromInt(600)) {
+		t.Errorf("snapshot must not modify the original sale, got paid %s", paid)
+	}
+
+	flat.SaleDetail.CreatedAt = date(2025, time.April, 10)
+	if flat.AsOf(asOf).SaleDetail != nil {
+		t.Error("want no sale for a flat booked after asOf")
+	}
+}
+
+func TestSaleClearedAmount(t *testing.T) {
+	cleared := &ReceiptClear{CreatedAt: date(2025, time.February, 2)}
+	sale := Sale{
+		TotalPrice: decimal.NewFromInt(1000),
+		Receipts: []Receipt{
+			{TotalAmount: decimal.NewFromInt(100), Mode: custom.CHEQUE, Cleared: cleared},
+			{TotalAmount: decimal.NewFromInt(50), Mode: custom.ADJUSTMENT, Cleared: cleared},
+			{TotalAmount: decimal.NewFromInt(200), Mode: custom.CHEQUE},
+		},
+	}
+	// the sales reports count cleared adjustments as paid unlike PaidAmount
+	if amount := sale.ClearedAmount(); !amount.Equal(decimal.NewFromInt(150)) {
+		t.Errorf("want 150 cleared, got %s", amount)
+	}
+	if paid := sale.PaidAmount(); !paid.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("want 100 paid, got %s", paid)
+	}
+}
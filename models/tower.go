@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 // Tower model
@@ -25,7 +26,8 @@ type Tower struct {
 	UnsoldFlats                 int64                `gorm:"-" json:"unsoldFlats"`
 	Flats                       []Flat               `gorm:"foreignKey:TowerId" json:"flats,omitempty"`
 	ActivePaymentPlanRatioItems []TowerPaymentStatus `gorm:"foreignKey:TowerId" json:"-"`
-	//DeletedAt  gorm.DeletedAt `gorm:"index"`
+	DeletedAt                   gorm.DeletedAt       `gorm:"index" json:"deletedAt,omitempty"`
+	DeletedBy                   string               `json:"deletedBy,omitempty"`
 }
 
 func (u Tower) GetCreatedAt() time.Time {
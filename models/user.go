@@ -16,6 +16,12 @@ type User struct {
 	CreatedAt      time.Time       `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt      time.Time       `gorm:"autoUpdateTime" json:"updatedAt"`
 	ProfilePicture string          `json:"profilePicture"`
+	// RoleId is the custom role of the user, default permissions of the role are used when not set
+	RoleId     *uuid.UUID `gorm:"type:uuid;index" json:"roleId,omitempty"`
+	AccessRole *Role      `gorm:"foreignKey:RoleId;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"accessRole,omitempty"`
+	// RestrictSocieties limits the user to the societies the user is member of
+	RestrictSocieties bool          `gorm:"not null;default:false" json:"restrictSocieties"`
+	Societies         []UserSociety `gorm:"foreignKey:UserEmail;references:Email;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"societies,omitempty"`
 	//DeletedAt      gorm.DeletedAt  `gorm:"index"`
 }
 
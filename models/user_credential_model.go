@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+// CredentialToken is the purpose of the one time token of a credential
+type CredentialToken string
+
+const (
+	CREDENTIAL_INVITE CredentialToken = "invite"
+	CREDENTIAL_RESET  CredentialToken = "reset"
+)
+
+// UserCredential is the login of a user when users are managed in the database
+// password and token are stored hashed, token is cleared once used
+type UserCredential struct {
+	Email          string          `gorm:"primaryKey" json:"email"`
+	OrgId          string          `json:"orgId"`
+	Role           custom.UserRole `gorm:"not null" json:"role"`
+	PasswordHash   string          `json:"-"`
+	TokenHash      *string         `gorm:"uniqueIndex" json:"-"`
+	TokenPurpose   CredentialToken `json:"-"`
+	TokenExpiresAt *time.Time      `json:"-"`
+	CreatedAt      time.Time       `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time       `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// TokenValid checks if the credential holds an unexpired token of the purpose
+func (c *UserCredential) TokenValid(purpose CredentialToken, now time.Time) bool {
+	return c.TokenHash != nil && c.TokenPurpose == purpose && c.TokenExpiresAt != nil && now.Before(*c.TokenExpiresAt)
+}
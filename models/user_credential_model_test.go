@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserCredentialTokenValid(t *testing.T) {
+	now := time.Now()
+	hash := "hash"
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	invite := UserCredential{TokenHash: &hash, TokenPurpose: CREDENTIAL_INVITE, TokenExpiresAt: &future}
+	if !invite.TokenValid(CREDENTIAL_INVITE, now) {
+		t.Error("want unexpired invite token valid")
+	}
+	if invite.TokenValid(CREDENTIAL_RESET, now) {
+		t.Error("want invite token rejected for reset")
+	}
+
+	expired := UserCredential{TokenHash: &hash, TokenPurpose: CREDENTIAL_RESET, TokenExpiresAt: &past}
+	if expired.TokenValid(CREDENTIAL_RESET, now) {
+		t.Error("want expired token rejected")
+	}
+
+	used := UserCredential{TokenPurpose: CREDENTIAL_RESET, TokenExpiresAt: &future}
+	if used.TokenValid(CREDENTIAL_RESET, now) {
+		t.Error("want cleared token rejected")
+	}
+}
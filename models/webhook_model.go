@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+// WebhookEndpoint is a url of the organization receiving the subscribed events of the allowed societies,
+// events of every society and of the organization when empty
+// secret signs the deliveries so it is kept in clear and never returned after creation
+type WebhookEndpoint struct {
+	Id           uuid.UUID     `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId        uuid.UUID     `gorm:"not null;index" json:"orgId"`
+	Organization *Organization `gorm:"foreignKey:OrgId;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"organization,omitempty"`
+	Url          string        `gorm:"not null" json:"url"`
+	Description  string        `json:"description"`
+	Events       StringList    `gorm:"type:jsonb;not null" json:"events"`
+	Societies    StringList    `gorm:"type:jsonb;not null;default:'[]'" json:"societies"`
+	Secret       string        `gorm:"not null" json:"-"`
+	Enabled      bool          `gorm:"not null;default:true" json:"enabled"`
+	CreatedBy    string        `json:"createdBy"`
+	CreatedAt    time.Time     `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time     `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (w WebhookEndpoint) GetCreatedAt() time.Time {
+	return w.CreatedAt
+}
+
+// Subscribed reports whether the endpoint receives the event
+func (w *WebhookEndpoint) Subscribed(event custom.WebhookEvent) bool {
+	for _, e := range w.Events {
+		if custom.WebhookEvent(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessibleBy reports whether the user can manage the endpoint, users restricted to societies manage only
+// the endpoints allowed on some of their societies and no other
+func (w *WebhookEndpoint) AccessibleBy(access *custom.UserAccess) bool {
+	if access == nil || access.Societies == nil {
+		return true
+	}
+	if len(w.Societies) == 0 {
+		return false
+	}
+	for _, society := range w.Societies {
+		if !access.CanAccessSociety(society) {
+			return false
+		}
+	}
+	return true
+}
+
+// OutboxEvent is a domain event written in the transaction of the change it describes
+// dispatcher creates the deliveries of the event and marks it dispatched
+type OutboxEvent struct {
+	Id           uuid.UUID           `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId        uuid.UUID           `gorm:"not null;index" json:"orgId"`
+	SocietyId    string              `json:"societyId,omitempty"`
+	Event        custom.WebhookEvent `gorm:"not null" json:"event"`
+	EntityId     string              `json:"entityId"`
+	Data         RawJSON             `gorm:"type:jsonb" json:"data"`
+	DispatchedAt *time.Time          `gorm:"index" json:"dispatchedAt,omitempty"`
+	CreatedAt    time.Time           `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+// WebhookDelivery is the delivery of an event to an endpoint with the result of its last attempt
+type WebhookDelivery struct {
+	Id             uuid.UUID                    `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"id"`
+	OrgId          uuid.UUID                    `gorm:"not null;index" json:"orgId"`
+	EndpointId     uuid.UUID                    `gorm:"not null;index" json:"endpointId"`
+	Endpoint       *WebhookEndpoint             `gorm:"foreignKey:EndpointId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"endpoint,omitempty"`
+	EventId        uuid.UUID                    `gorm:"not null;index" json:"eventId"`
+	OutboxEvent    *OutboxEvent                 `gorm:"foreignKey:EventId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"event,omitempty"`
+	Status         custom.WebhookDeliveryStatus `gorm:"not null;index" json:"status"`
+	Attempts       int                          `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time                   `gorm:"index" json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time                   `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int                          `json:"responseStatus,omitempty"`
+	ResponseBody   string                       `json:"responseBody,omitempty"`
+	Error          string                       `json:"error,omitempty"`
+	ReplayOf       *uuid.UUID                   `gorm:"type:uuid" json:"replayOf,omitempty"`
+	CreatedAt      time.Time                    `gorm:"autoCreateTime;index" json:"createdAt"`
+	UpdatedAt      time.Time                    `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (d WebhookDelivery) GetCreatedAt() time.Time {
+	return d.CreatedAt
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+func TestWebhookEndpointAccessibleBy(t *testing.T) {
+	unrestricted := &custom.UserAccess{}
+	restricted := &custom.UserAccess{Societies: map[string]bool{"rera-1": true}}
+
+	orgWide := WebhookEndpoint{}
+	if !orgWide.AccessibleBy(unrestricted) {
+		t.Error("want endpoint of every society accessible without restriction")
+	}
+	if orgWide.AccessibleBy(restricted) {
+		t.Error("want endpoint of every society hidden from restricted user")
+	}
+
+	own := WebhookEndpoint{Societies: StringList{"rera-1"}}
+	if !own.AccessibleBy(restricted) {
+		t.Error("want endpoint of member society accessible")
+	}
+
+	shared := WebhookEndpoint{Societies: StringList{"rera-1", "rera-2"}}
+	if shared.AccessibleBy(restricted) {
+		t.Error("want endpoint with a society the user is not member of hidden")
+	}
+}
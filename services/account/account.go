@@ -0,0 +1,20 @@
+package account
+
+import (
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/identity"
+)
+
+// accountService handles login, invites and password reset of users managed in the database
+// routes are public and are not exposed when another identity provider is used
+type accountService struct {
+	identity *identity.DatabaseProvider
+}
+
+// CreateAccountService is an abstract factory to create account service
+func CreateAccountService(app common.IApp) common.IService {
+	provider, _ := app.GetIdentityProvider().(*identity.DatabaseProvider)
+	return &accountService{
+		identity: provider,
+	}
+}
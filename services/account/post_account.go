@@ -0,0 +1,81 @@
+package account
+
+import (
+	"net/http"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+)
+
+type hLogin struct {
+	Email    string `validate:"required,email"`
+	Password string `validate:"required"`
+}
+
+// session is the token of the logged in user
+type session struct {
+	Token string `json:"token"`
+}
+
+func (s *accountService) login(w http.ResponseWriter, r *http.Request) {
+	reqBody := payload.ValidateAndDecodeRequest[hLogin](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	token, err := s.identity.Login(reqBody.Email, reqBody.Password)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = session{Token: token}
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// hSetPassword sets password using an invite or reset token
+type hSetPassword struct {
+	Token    string `validate:"required"`
+	Password string `validate:"required"`
+}
+
+func (s *accountService) acceptInvite(w http.ResponseWriter, r *http.Request) {
+	reqBody := payload.ValidateAndDecodeRequest[hSetPassword](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	err := s.identity.AcceptInvite(reqBody.Token, reqBody.Password)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully accepted invite."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *accountService) resetPassword(w http.ResponseWriter, r *http.Request) {
+	reqBody := payload.ValidateAndDecodeRequest[hSetPassword](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	err := s.identity.CompleteReset(reqBody.Token, reqBody.Password)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully reset password."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,20 @@
+package account
+
+import "github.com/go-chi/chi/v5"
+
+func (s *accountService) GetBasePath() string {
+	return "/account"
+}
+
+func (s *accountService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	if s.identity == nil {
+		return mux
+	}
+
+	mux.Post("/login", s.login)
+	mux.Post("/invite/accept", s.acceptInvite)
+	mux.Post("/password/reset", s.resetPassword)
+
+	return mux
+}
@@ -0,0 +1,179 @@
+package analytics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type analyticsService struct {
+	db *gorm.DB
+}
+
+// CreateAnalyticsService creates service for the dashboard analytics of the organization and its societies
+// every figure is computed with sql aggregates over the societies the user is member of
+func CreateAnalyticsService(app common.IApp) common.IService {
+	return &analyticsService{
+		db: app.GetDBClient(),
+	}
+}
+
+const (
+	monthLayout = "2006-01"
+	// maxMonths limits the months of a series
+	maxMonths = 60
+)
+
+// analyticsFilter selects the societies, tower and months of the analytics
+type analyticsFilter struct {
+	orgId string
+	// societies is nil for every society of the organization
+	societies []string
+	towerId   string
+	// from and to are the first and the last month of a series
+	from time.Time
+	to   time.Time
+	asOf time.Time
+}
+
+// parseAnalyticsFilter reads the filter from the query, society and tower are optional
+// months are YYYY-MM, the series defaults to the 12 months up to asOf
+func parseAnalyticsFilter(r *http.Request) (*analyticsFilter, error) {
+	query := r.URL.Query()
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	asOf, err := common.ParseAsOfDate(query.Get("asOf"))
+	if err != nil {
+		return nil, err
+	}
+	filter := analyticsFilter{
+		orgId:     orgId,
+		societies: access.SocietyIds(),
+		asOf:      asOf,
+	}
+
+	if society := strings.TrimSpace(query.Get("society")); society != "" {
+		if !access.CanAccessSociety(society) {
+			return nil, &custom.RequestError{
+				Status:  http.StatusForbidden,
+				Message: "Not a member of the society.",
+			}
+		}
+		filter.societies = []string{society}
+	} else if access == nil {
+		filter.societies = []string{}
+	}
+
+	if tower := strings.TrimSpace(query.Get("tower")); tower != "" {
+		if _, err := uuid.Parse(tower); err != nil {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid tower.",
+			}
+		}
+		filter.towerId = tower
+	}
+
+	filter.to = startOfMonth(asOf)
+	if value := strings.TrimSpace(query.Get("to")); value != "" {
+		if filter.to, err = parseMonth(value, "to"); err != nil {
+			return nil, err
+		}
+	}
+	filter.from = filter.to.AddDate(0, -11, 0)
+	if value := strings.TrimSpace(query.Get("from")); value != "" {
+		if filter.from, err = parseMonth(value, "from"); err != nil {
+			return nil, err
+		}
+	}
+	if filter.from.After(filter.to) {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "from must not be after to.",
+		}
+	}
+	if len(filter.months()) > maxMonths {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Series can not be longer than 60 months.",
+		}
+	}
+	return &filter, nil
+}
+
+func parseMonth(value, name string) (time.Time, error) {
+	month, err := time.Parse(monthLayout, value)
+	if err != nil {
+		return time.Time{}, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid " + name + " month (expected YYYY-MM)",
+		}
+	}
+	return month, nil
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// months returns the months of the series in order as YYYY-MM
+func (f *analyticsFilter) months() []string {
+	var months []string
+	for month := f.from; !month.After(f.to); month = month.AddDate(0, 1, 0) {
+		months = append(months, month.Format(monthLayout))
+	}
+	return months
+}
+
+// until is the end of the last month of the series, not after asOf
+func (f *analyticsFilter) until() time.Time {
+	end := f.to.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	if end.After(f.asOf) {
+		return f.asOf
+	}
+	return end
+}
+
+// scope returns the condition selecting the towers and flats of the filter with its named args
+// queries alias towers as t and flats as f
+func (f *analyticsFilter) scope() (string, map[string]any) {
+	conditions := []string{"t.org_id = @orgId", "t.deleted_at IS NULL", "f.deleted_at IS NULL"}
+	args := map[string]any{
+		"orgId": f.orgId,
+		"asOf":  f.asOf,
+		"from":  f.from,
+		"until": f.until(),
+	}
+	if f.societies != nil {
+		// a user without societies gets IN (NULL) which matches no tower
+		conditions = append(conditions, "t.society_id IN @societies")
+		args["societies"] = f.societies
+	}
+	if f.towerId != "" {
+		conditions = append(conditions, "t.id = @towerId")
+		args["towerId"] = f.towerId
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// parseLimit reads the number of rows to return, 1 to 100
+func parseLimit(value string, fallback int) (int, error) {
+	if strings.TrimSpace(value) == "" {
+		return fallback, nil
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || limit < 1 || limit > 100 {
+		return 0, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid limit, must be between 1 and 100.",
+		}
+	}
+	return limit, nil
+}
@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+func analyticsRequest(target string, access *custom.UserAccess) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	ctx := context.WithValue(r.Context(), custom.OrganizationIDKey, "org")
+	ctx = context.WithValue(ctx, custom.UserAccessKey, access)
+	return r.WithContext(ctx)
+}
+
+func TestParseAnalyticsFilter(t *testing.T) {
+	everySociety := &custom.UserAccess{}
+	member := &custom.UserAccess{Societies: map[string]bool{"RERA-1": true}}
+
+	filter, err := parseAnalyticsFilter(analyticsRequest("/bookings?asOf=2024-06-15", everySociety))
+	if err != nil {
+		t.Fatal(err)
+	}
+	months := filter.months()
+	if len(months) != 12 || months[0] != "2023-07" || months[11] != "2024-06" {
+		t.Errorf("want 12 months up to asOf, got %v", months)
+	}
+	if filter.societies != nil {
+		t.Errorf("want every society, got %v", filter.societies)
+	}
+	if until := filter.until(); !until.Equal(filter.asOf) {
+		t.Errorf("want series until asOf, got %s", until)
+	}
+
+	filter, err = parseAnalyticsFilter(analyticsRequest("/bookings?from=2024-01&to=2024-03&asOf=2024-12-31", member))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filter.months(); !slices.Equal(got, []string{"2024-01", "2024-02", "2024-03"}) {
+		t.Errorf("unexpected months %v", got)
+	}
+	if got := filter.until().Format("2006-01-02"); got != "2024-03-31" {
+		t.Errorf("want series until end of march, got %s", got)
+	}
+	if !slices.Equal(filter.societies, []string{"RERA-1"}) {
+		t.Errorf("want societies of the member, got %v", filter.societies)
+	}
+
+	scope, args := filter.scope()
+	if scope != "t.org_id = @orgId AND t.deleted_at IS NULL AND f.deleted_at IS NULL AND t.society_id IN @societies" || args["orgId"] != "org" {
+		t.Errorf("unexpected scope %q %v", scope, args)
+	}
+
+	tests := []struct {
+		target string
+		access *custom.UserAccess
+		status int
+	}{
+		{"/bookings?society=RERA-2", member, http.StatusForbidden},
+		{"/bookings?tower=abc", everySociety, http.StatusBadRequest},
+		{"/bookings?from=2024-13", everySociety, http.StatusBadRequest},
+		{"/bookings?from=2024-06&to=2024-01", everySociety, http.StatusBadRequest},
+		{"/bookings?from=2010-01&to=2024-01", everySociety, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		_, err := parseAnalyticsFilter(analyticsRequest(tt.target, tt.access))
+		var requestErr *custom.RequestError
+		if !errors.As(err, &requestErr) || requestErr.Status != tt.status {
+			t.Errorf("%s: want status %d, got %v", tt.target, tt.status, err)
+		}
+	}
+}
+
+func TestAgeingBucketCase(t *testing.T) {
+	got := ageingBucketCase("days")
+	want := "CASE WHEN days BETWEEN 0 AND 30 THEN '0-30' WHEN days BETWEEN 31 AND 60 THEN '31-60'" +
+		" WHEN days BETWEEN 61 AND 90 THEN '61-90' WHEN days >= 91 THEN '90+' END"
+	if got != want {
+		t.Errorf("ageingBucketCase = %q, want %q", got, want)
+	}
+}
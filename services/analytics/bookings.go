@@ -0,0 +1,151 @@
+package analytics
+
+import (
+	"net/http"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// monthlyBookings is the bookings of a month
+type monthlyBookings struct {
+	Month     string          `json:"month"`
+	Bookings  int64           `json:"bookings"`
+	SaleValue decimal.Decimal `json:"saleValue"`
+	Area      decimal.Decimal `json:"area"`
+}
+
+type hGetBookings struct{}
+
+// execute returns the bookings of every month of the series, months without bookings are zero
+func (h *hGetBookings) execute(db *gorm.DB, filter *analyticsFilter) ([]monthlyBookings, error) {
+	scope, args := filter.scope()
+
+	var rows []monthlyBookings
+	err := db.Raw(`
+		SELECT
+			to_char(date_trunc('month', s.created_at), 'YYYY-MM') AS month,
+			COUNT(s.id) AS bookings,
+			COALESCE(SUM(s.total_price), 0) AS sale_value,
+			COALESCE(SUM(f.saleable_area), 0) AS area
+		FROM sales s
+		JOIN flats f ON f.id = s.flat_id
+		JOIN towers t ON t.id = f.tower_id
+		WHERE `+scope+` AND s.deleted_at IS NULL AND s.created_at >= @from AND s.created_at <= @until
+		GROUP BY 1
+		ORDER BY 1
+	`, args).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byMonth := make(map[string]monthlyBookings, len(rows))
+	for _, row := range rows {
+		byMonth[row.Month] = row
+	}
+	series := make([]monthlyBookings, 0, len(filter.months()))
+	for _, month := range filter.months() {
+		row, ok := byMonth[month]
+		if !ok {
+			row = monthlyBookings{Month: month, SaleValue: decimal.Zero, Area: decimal.Zero}
+		}
+		series = append(series, row)
+	}
+	return series, nil
+}
+
+func (s *analyticsService) getBookings(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAnalyticsFilter(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	bookings := hGetBookings{}
+	res, err := bookings.execute(s.db, filter)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// brokerRank is the bookings of a broker over the series
+type brokerRank struct {
+	BrokerId  string          `json:"brokerId"`
+	Name      string          `json:"name"`
+	Bookings  int64           `json:"bookings"`
+	SaleValue decimal.Decimal `json:"saleValue"`
+	Area      decimal.Decimal `json:"area"`
+	Collected decimal.Decimal `json:"collected"`
+}
+
+const defaultLeaderboardSize = 10
+
+type hGetBrokerLeaderboard struct{}
+
+// execute ranks the brokers by the value of their bookings in the series, collected is what their buyers paid by asOf
+func (h *hGetBrokerLeaderboard) execute(db *gorm.DB, filter *analyticsFilter, limit int) ([]brokerRank, error) {
+	scope, args := filter.scope()
+	args["limit"] = limit
+
+	var rows []brokerRank
+	err := db.Raw(`
+		WITH collected AS (
+			SELECT cr.sale_id, SUM(cr.total_amount) AS amount
+			FROM (`+collectedReceipts+`) cr
+			GROUP BY cr.sale_id
+		)
+		SELECT
+			b.id::text AS broker_id,
+			b.name,
+			COUNT(s.id) AS bookings,
+			COALESCE(SUM(s.total_price), 0) AS sale_value,
+			COALESCE(SUM(f.saleable_area), 0) AS area,
+			COALESCE(SUM(c.amount), 0) AS collected
+		FROM sales s
+		JOIN brokers b ON b.id = s.broker_id
+		JOIN flats f ON f.id = s.flat_id
+		JOIN towers t ON t.id = f.tower_id
+		LEFT JOIN collected c ON c.sale_id = s.id
+		WHERE `+scope+` AND s.deleted_at IS NULL AND s.channel = 'broker'
+			AND s.created_at >= @from AND s.created_at <= @until
+		GROUP BY b.id, b.name
+		ORDER BY sale_value DESC, bookings DESC, b.name
+		LIMIT @limit
+	`, args).Scan(&rows).Error
+	return rows, err
+}
+
+func (s *analyticsService) getBrokerLeaderboard(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAnalyticsFilter(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	limit, err := parseLimit(r.URL.Query().Get("limit"), defaultLeaderboardSize)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	leaderboard := hGetBrokerLeaderboard{}
+	res, err := leaderboard.execute(s.db, filter, limit)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
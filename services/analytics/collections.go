@@ -0,0 +1,106 @@
+package analytics
+
+import (
+	"net/http"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// collectedReceipts selects the receipts collected by asOf, cleared and not adjustments, with the month they were issued in
+// queries join it to sales as s
+const collectedReceipts = `
+	SELECT
+		r.sale_id,
+		r.mode,
+		r.total_amount,
+		COALESCE(b.name, '') AS bank,
+		to_char(date_trunc('month', COALESCE(r.date_issued, r.created_at::date)), 'YYYY-MM') AS month,
+		COALESCE(r.date_issued, r.created_at::date) AS issued_on
+	FROM receipts r
+	JOIN receipt_clears rc ON rc.receipt_id = r.id
+	LEFT JOIN banks b ON b.id = rc.bank_id
+	WHERE r.mode <> 'adjustment' AND rc.created_at <= @asOf AND COALESCE(r.date_issued, r.created_at::date) <= @asOf
+`
+
+// collectionSplit is the collection of a month through a mode into a bank
+type collectionSplit struct {
+	Month    string          `json:"month"`
+	Mode     string          `json:"mode"`
+	Bank     string          `json:"bank"`
+	Receipts int64           `json:"receipts"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+// monthlyCollection is the collection of a month with its split by mode and bank
+type monthlyCollection struct {
+	Month    string            `json:"month"`
+	Receipts int64             `json:"receipts"`
+	Amount   decimal.Decimal   `json:"amount"`
+	Splits   []collectionSplit `json:"splits"`
+}
+
+type hGetCollections struct{}
+
+// execute returns the collection of every month of the series by the issue date of the receipts
+func (h *hGetCollections) execute(db *gorm.DB, filter *analyticsFilter) ([]monthlyCollection, error) {
+	scope, args := filter.scope()
+
+	var splits []collectionSplit
+	err := db.Raw(`
+		WITH collected AS (`+collectedReceipts+`)
+		SELECT c.month, c.mode, c.bank, COUNT(*) AS receipts, SUM(c.total_amount) AS amount
+		FROM collected c
+		JOIN sales s ON s.id = c.sale_id
+		JOIN flats f ON f.id = s.flat_id
+		JOIN towers t ON t.id = f.tower_id
+		WHERE `+scope+` AND s.deleted_at IS NULL AND c.issued_on >= @from AND c.issued_on <= @until
+		GROUP BY c.month, c.mode, c.bank
+		ORDER BY c.month, amount DESC
+	`, args).Scan(&splits).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byMonth := make(map[string]*monthlyCollection)
+	series := make([]monthlyCollection, 0, len(filter.months()))
+	for _, month := range filter.months() {
+		series = append(series, monthlyCollection{Month: month, Amount: decimal.Zero, Splits: []collectionSplit{}})
+	}
+	for i := range series {
+		byMonth[series[i].Month] = &series[i]
+	}
+	for _, split := range splits {
+		month, ok := byMonth[split.Month]
+		if !ok {
+			continue
+		}
+		month.Receipts += split.Receipts
+		month.Amount = month.Amount.Add(split.Amount)
+		month.Splits = append(month.Splits, split)
+	}
+	return series, nil
+}
+
+func (s *analyticsService) getCollections(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAnalyticsFilter(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	collections := hGetCollections{}
+	res, err := collections.execute(s.db, filter)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,171 @@
+package analytics
+
+import (
+	"net/http"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// unitTypeMix is the sold and unsold inventory of a unit type as of asOf
+type unitTypeMix struct {
+	UnitType   string          `json:"unitType"`
+	Flats      int64           `json:"flats"`
+	Sold       int64           `json:"sold"`
+	Unsold     int64           `json:"unsold"`
+	SoldArea   decimal.Decimal `json:"soldArea"`
+	UnsoldArea decimal.Decimal `json:"unsoldArea"`
+}
+
+type hGetUnitMix struct{}
+
+func (h *hGetUnitMix) execute(db *gorm.DB, filter *analyticsFilter) ([]unitTypeMix, error) {
+	scope, args := filter.scope()
+
+	var rows []unitTypeMix
+	err := db.Raw(`
+		SELECT
+			f.unit_type,
+			COUNT(f.id) AS flats,
+			COUNT(s.id) AS sold,
+			COUNT(f.id) - COUNT(s.id) AS unsold,
+			COALESCE(SUM(f.saleable_area) FILTER (WHERE s.id IS NOT NULL), 0) AS sold_area,
+			COALESCE(SUM(f.saleable_area) FILTER (WHERE s.id IS NULL), 0) AS unsold_area
+		FROM flats f
+		JOIN towers t ON t.id = f.tower_id
+		LEFT JOIN sales s ON s.flat_id = f.id AND s.deleted_at IS NULL AND s.created_at <= @asOf
+		WHERE `+scope+`
+		GROUP BY f.unit_type
+		ORDER BY f.unit_type
+	`, args).Scan(&rows).Error
+	return rows, err
+}
+
+func (s *analyticsService) getUnitMix(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAnalyticsFilter(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	mix := hGetUnitMix{}
+	res, err := mix.execute(s.db, filter)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// realisation is the average price realised per sq ft of the flats sold by asOf
+type realisation struct {
+	Sold      int64           `json:"sold"`
+	SoldArea  decimal.Decimal `json:"soldArea"`
+	SaleValue decimal.Decimal `json:"saleValue"`
+	PerSqFt   decimal.Decimal `json:"perSqFt"`
+}
+
+type towerRealisation struct {
+	TowerId string `json:"towerId"`
+	Tower   string `json:"tower"`
+	realisation
+}
+
+type unitTypeRealisation struct {
+	UnitType string `json:"unitType"`
+	realisation
+}
+
+// realisationReport is the realisation of the scope with its split by tower and by unit type
+type realisationReport struct {
+	Overall    realisation           `json:"overall"`
+	ByTower    []towerRealisation    `json:"byTower"`
+	ByUnitType []unitTypeRealisation `json:"byUnitType"`
+}
+
+type hGetRealisation struct{}
+
+// execute computes the realisation as the sale value over the saleable area of the sold flats
+func (h *hGetRealisation) execute(db *gorm.DB, filter *analyticsFilter) (*realisationReport, error) {
+	scope, args := filter.scope()
+
+	type realisationRow struct {
+		TowerId    string
+		Tower      string
+		UnitType   string
+		ByTower    bool
+		ByUnitType bool
+		Sold       int64
+		SoldArea   decimal.Decimal
+		SaleValue  decimal.Decimal
+		PerSqFt    decimal.Decimal
+	}
+	var rows []realisationRow
+	err := db.Raw(`
+		SELECT
+			COALESCE(t.id::text, '') AS tower_id,
+			COALESCE(t.name, '') AS tower,
+			COALESCE(f.unit_type, '') AS unit_type,
+			GROUPING(t.id, t.name) = 0 AS by_tower,
+			GROUPING(f.unit_type) = 0 AS by_unit_type,
+			COUNT(s.id) AS sold,
+			COALESCE(SUM(f.saleable_area), 0) AS sold_area,
+			COALESCE(SUM(s.total_price), 0) AS sale_value,
+			COALESCE(ROUND(SUM(s.total_price) / NULLIF(SUM(f.saleable_area), 0), 2), 0) AS per_sq_ft
+		FROM sales s
+		JOIN flats f ON f.id = s.flat_id
+		JOIN towers t ON t.id = f.tower_id
+		WHERE `+scope+` AND s.deleted_at IS NULL AND s.created_at <= @asOf
+		GROUP BY GROUPING SETS ((t.id, t.name), (f.unit_type), ())
+		ORDER BY 2, 3
+	`, args).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := realisationReport{
+		Overall:    realisation{SoldArea: decimal.Zero, SaleValue: decimal.Zero, PerSqFt: decimal.Zero},
+		ByTower:    []towerRealisation{},
+		ByUnitType: []unitTypeRealisation{},
+	}
+	for _, row := range rows {
+		value := realisation{Sold: row.Sold, SoldArea: row.SoldArea, SaleValue: row.SaleValue, PerSqFt: row.PerSqFt}
+		switch {
+		case row.ByTower:
+			report.ByTower = append(report.ByTower, towerRealisation{TowerId: row.TowerId, Tower: row.Tower, realisation: value})
+		case row.ByUnitType:
+			report.ByUnitType = append(report.ByUnitType, unitTypeRealisation{UnitType: row.UnitType, realisation: value})
+		default:
+			report.Overall = value
+		}
+	}
+	return &report, nil
+}
+
+func (s *analyticsService) getRealisation(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAnalyticsFilter(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	realisation := hGetRealisation{}
+	res, err := realisation.execute(s.db, filter)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,266 @@
+package analytics
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// demandedItems selects the payment plan items of the sales of the scope that became payable by asOf with the amount demanded
+// activation follows PaymentPlanRatioItem.GetActivation, the amount is the ratio of the price payable with adjustments
+// paid amounts are allocated to the items in plan order like Sale.GetPaymentPlanBreakDown, outstanding is what is left of an item
+// the scope condition is appended where the sales are selected
+const demandedItems = `
+	scoped_sales AS (
+		SELECT
+			s.id,
+			s.created_at,
+			s.payment_plan_ratio_id,
+			s.flat_id,
+			f.tower_id,
+			s.total_price + COALESCE((
+				SELECT SUM(r.total_amount) FROM receipts r
+				WHERE r.sale_id = s.id AND r.mode = 'adjustment' AND COALESCE(r.date_issued, r.created_at::date) <= @asOf
+			), 0) AS payable
+		FROM sales s
+		JOIN flats f ON f.id = s.flat_id
+		JOIN towers t ON t.id = f.tower_id
+		WHERE %s AND s.deleted_at IS NULL AND s.created_at <= @asOf
+	),
+	activations AS (
+		SELECT
+			ss.id AS sale_id,
+			i.id AS item_id,
+			i.created_at AS item_created_at,
+			ss.payable * CASE WHEN i.ratio ~ '^[0-9]+(\.[0-9]+){0,1}$' THEN i.ratio::numeric ELSE 0 END / 100 AS amount,
+			CASE
+				WHEN i.scope = 'sale' AND i.condition_type = 'on-booking' THEN ss.created_at::date
+				WHEN i.scope = 'sale' AND i.condition_type = 'within-days' THEN i.created_at::date + COALESCE(i.condition_value, 0)
+				WHEN i.scope = 'sale' AND i.condition_type = 'on-allotment' THEN a.allotment_date
+				WHEN i.scope = 'flat' THEN COALESCE(fps.effective_date, fps.created_at::date)
+				WHEN i.scope = 'tower' THEN COALESCE(tps.effective_date, tps.created_at::date)
+			END AS effective_date,
+			CASE
+				WHEN i.scope = 'flat' THEN COALESCE(fps.due_date, fps.effective_date, fps.created_at::date)
+				WHEN i.scope = 'tower' THEN COALESCE(tps.due_date, tps.effective_date, tps.created_at::date)
+			END AS stage_due_date
+		FROM scoped_sales ss
+		JOIN payment_plan_ratio_items i ON i.payment_plan_ratio_id = ss.payment_plan_ratio_id
+		LEFT JOIN sale_allotments a ON a.sale_id = ss.id AND a.allotment_date <= @asOf
+		LEFT JOIN flat_payment_statuses fps ON i.scope = 'flat' AND fps.payment_id = i.id AND fps.flat_id = ss.flat_id
+		LEFT JOIN tower_payment_statuses tps ON i.scope = 'tower' AND tps.payment_id = i.id AND tps.tower_id = ss.tower_id
+	),
+	demands AS (
+		SELECT
+			sale_id, item_id, item_created_at, amount, effective_date,
+			COALESCE(stage_due_date, effective_date) AS due_date
+		FROM activations
+		WHERE effective_date IS NOT NULL AND effective_date <= @asOf
+	),
+	paid AS (
+		SELECT cr.sale_id, SUM(cr.total_amount) AS amount
+		FROM (` + collectedReceipts + `) cr
+		GROUP BY cr.sale_id
+	),
+	allocated AS (
+		SELECT
+			d.*,
+			d.amount - GREATEST(0, LEAST(d.amount, COALESCE(p.amount, 0) - COALESCE(SUM(d.amount) OVER (
+				PARTITION BY d.sale_id ORDER BY d.item_created_at, d.item_id
+				ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+			), 0))) AS outstanding
+		FROM demands d
+		LEFT JOIN paid p ON p.sale_id = d.sale_id
+	)
+`
+
+// demandPoint is the demand raised and the collection of a month with their running totals
+type demandPoint struct {
+	Month                string          `json:"month"`
+	Demand               decimal.Decimal `json:"demand"`
+	Collection           decimal.Decimal `json:"collection"`
+	CumulativeDemand     decimal.Decimal `json:"cumulativeDemand"`
+	CumulativeCollection decimal.Decimal `json:"cumulativeCollection"`
+}
+
+type hGetDemandCollection struct{}
+
+// execute returns the demand versus collection curve of the series
+// running totals include everything before the series so the curve starts where the project stands
+func (h *hGetDemandCollection) execute(db *gorm.DB, filter *analyticsFilter) ([]demandPoint, error) {
+	scope, args := filter.scope()
+	// demand and collection of months after the series are not part of the curve
+	args["asOf"] = filter.until()
+
+	type monthAmount struct {
+		Month      string
+		Demand     decimal.Decimal
+		Collection decimal.Decimal
+	}
+	var rows []monthAmount
+	err := db.Raw(`
+		WITH `+fmt.Sprintf(demandedItems, scope)+`,
+		monthly_demand AS (
+			SELECT to_char(date_trunc('month', effective_date), 'YYYY-MM') AS month, SUM(amount) AS amount
+			FROM demands
+			GROUP BY 1
+		),
+		monthly_collection AS (
+			SELECT cr.month, SUM(cr.total_amount) AS amount
+			FROM (`+collectedReceipts+`) cr
+			JOIN scoped_sales ss ON ss.id = cr.sale_id
+			GROUP BY cr.month
+		)
+		SELECT
+			COALESCE(d.month, c.month) AS month,
+			COALESCE(d.amount, 0) AS demand,
+			COALESCE(c.amount, 0) AS collection
+		FROM monthly_demand d
+		FULL JOIN monthly_collection c ON c.month = d.month
+		ORDER BY 1
+	`, args).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	from := filter.from.Format(monthLayout)
+	cumulativeDemand := decimal.Zero
+	cumulativeCollection := decimal.Zero
+	byMonth := make(map[string]monthAmount, len(rows))
+	for _, row := range rows {
+		if row.Month < from {
+			cumulativeDemand = cumulativeDemand.Add(row.Demand)
+			cumulativeCollection = cumulativeCollection.Add(row.Collection)
+			continue
+		}
+		byMonth[row.Month] = row
+	}
+
+	series := make([]demandPoint, 0, len(filter.months()))
+	for _, month := range filter.months() {
+		row := byMonth[month]
+		cumulativeDemand = cumulativeDemand.Add(row.Demand)
+		cumulativeCollection = cumulativeCollection.Add(row.Collection)
+		series = append(series, demandPoint{
+			Month:                month,
+			Demand:               row.Demand,
+			Collection:           row.Collection,
+			CumulativeDemand:     cumulativeDemand,
+			CumulativeCollection: cumulativeCollection,
+		})
+	}
+	return series, nil
+}
+
+func (s *analyticsService) getDemandCollection(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAnalyticsFilter(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	curve := hGetDemandCollection{}
+	res, err := curve.execute(s.db, filter)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// ageingBucket is the outstanding amount of the items in a bucket of days past due
+type ageingBucket struct {
+	Bucket string          `json:"bucket"`
+	Sales  int64           `json:"sales"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// ageingBucketCase is the sql of the label of the bucket of models.AgeingBuckets of the days past due,
+// null for the items not due yet like models.AgeingBucketOf
+func ageingBucketCase(days string) string {
+	var sql strings.Builder
+	sql.WriteString("CASE")
+	for _, bucket := range models.AgeingBuckets {
+		if bucket.To == 0 {
+			fmt.Fprintf(&sql, " WHEN %s >= %d THEN '%s'", days, bucket.From, bucket.Label)
+		} else {
+			fmt.Fprintf(&sql, " WHEN %s BETWEEN %d AND %d THEN '%s'", days, bucket.From, bucket.To, bucket.Label)
+		}
+	}
+	sql.WriteString(" END")
+	return sql.String()
+}
+
+type hGetAgeing struct{}
+
+// execute returns the outstanding amount of the payable items as of asOf by days past their due date,
+// items not due yet are not aged like in the receivables ageing report
+func (h *hGetAgeing) execute(db *gorm.DB, filter *analyticsFilter) ([]ageingBucket, error) {
+	scope, args := filter.scope()
+
+	var rows []ageingBucket
+	err := db.Raw(`
+		WITH `+fmt.Sprintf(demandedItems, scope)+`,
+		aged AS (
+			SELECT `+ageingBucketCase("CAST(@asOf AS date) - due_date")+` AS bucket, sale_id, outstanding
+			FROM allocated
+			WHERE outstanding > 0
+		)
+		SELECT
+			bucket,
+			COUNT(DISTINCT sale_id) AS sales,
+			SUM(outstanding) AS amount
+		FROM aged
+		WHERE bucket IS NOT NULL
+		GROUP BY 1
+	`, args).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byBucket := make(map[string]ageingBucket, len(rows))
+	for _, row := range rows {
+		byBucket[row.Bucket] = row
+	}
+	buckets := make([]ageingBucket, 0, len(models.AgeingBuckets))
+	for _, bucket := range models.AgeingBuckets {
+		row, ok := byBucket[bucket.Label]
+		if !ok {
+			row = ageingBucket{Bucket: bucket.Label, Amount: decimal.Zero}
+		}
+		buckets = append(buckets, row)
+	}
+	return buckets, nil
+}
+
+func (s *analyticsService) getAgeing(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAnalyticsFilter(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	ageing := hGetAgeing{}
+	res, err := ageing.execute(s.db, filter)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
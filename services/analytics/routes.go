@@ -0,0 +1,33 @@
+package analytics
+
+import (
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *analyticsService) GetBasePath() string {
+	return "/analytics"
+}
+
+// GetRoutes exposes the analytics of the organization, society and tower query params narrow them down
+func (s *analyticsService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
+
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.Use(permission(custom.PERMISSION_ANALYTICS_VIEW))
+
+		router.Get("/bookings", s.getBookings)
+		router.Get("/collections", s.getCollections)
+		router.Get("/demand-collection", s.getDemandCollection)
+		router.Get("/ageing", s.getAgeing)
+		router.Get("/unit-mix", s.getUnitMix)
+		router.Get("/realisation", s.getRealisation)
+		router.Get("/brokers", s.getBrokerLeaderboard)
+	})
+
+	return mux
+}
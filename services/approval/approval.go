@@ -0,0 +1,18 @@
+package approval
+
+import (
+	"circledigital.in/real-state-erp/utils/common"
+	"gorm.io/gorm"
+)
+
+type approvalService struct {
+	db *gorm.DB
+}
+
+// CreateApprovalService creates service for maker-checker approval policies and requests
+// operations are executed by the executors their services register with utils/approval
+func CreateApprovalService(app common.IApp) common.IService {
+	return &approvalService{
+		db: app.GetDBClient(),
+	}
+}
@@ -0,0 +1,166 @@
+package approval
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	appApproval "circledigital.in/real-state-erp/utils/approval"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type hDecideApproval struct {
+	Remarks string
+	access  *custom.UserAccess
+}
+
+// lockPending loads the pending request for update, decisions by the requester are rejected
+func (h *hDecideApproval) lockPending(tx *gorm.DB, orgId, approvalId, approver string) (*models.ApprovalRequest, error) {
+	var request models.ApprovalRequest
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? and org_id = ?", approvalId, orgId).
+		First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !h.access.CanAccessSociety(request.SocietyId)) {
+		return nil, approvalNotFoundError
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Status != custom.APPROVAL_PENDING {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Approval request is already " + string(request.Status) + ".",
+		}
+	}
+	if request.RequestedBy == approver {
+		return nil, &custom.RequestError{
+			Status:  http.StatusForbidden,
+			Message: "Request must be approved by a user other than the requester.",
+		}
+	}
+	return &request, nil
+}
+
+// decide records the decision with the approver and decision time
+func (h *hDecideApproval) decide(tx *gorm.DB, request *models.ApprovalRequest, status custom.ApprovalStatus, approver, executionError string) error {
+	now := time.Now()
+	request.Status = status
+	request.ApprovedBy = approver
+	request.DecidedAt = &now
+	request.Remarks = h.Remarks
+	request.ExecutionError = executionError
+
+	return tx.Model(request).Updates(map[string]any{
+		"status":          status,
+		"approved_by":     approver,
+		"decided_at":      now,
+		"remarks":         h.Remarks,
+		"execution_error": executionError,
+	}).Error
+}
+
+// approve executes the pending operation and records the approval in the same transaction
+// operation rejected by its own validation marks the request failed, the operation is not retried
+func (h *hDecideApproval) approve(db *gorm.DB, orgId, approvalId, approver string) (*models.ApprovalRequest, error) {
+	var request *models.ApprovalRequest
+	var executionErr error
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = h.lockPending(tx, orgId, approvalId, approver)
+		if err != nil {
+			return err
+		}
+
+		executionErr = appApproval.Execute(tx, request)
+		if executionErr != nil {
+			return executionErr
+		}
+		return h.decide(tx, request, custom.APPROVAL_APPROVED, approver, "")
+	})
+
+	var reqErr *custom.RequestError
+	if executionErr != nil && errors.As(executionErr, &reqErr) {
+		if err := h.decide(db, request, custom.APPROVAL_FAILED, approver, reqErr.Message); err != nil {
+			log.Printf("Error marking approval request %s as failed: %v\n", approvalId, err)
+		}
+	}
+	return request, err
+}
+
+func (h *hDecideApproval) reject(db *gorm.DB, orgId, approvalId, approver string) (*models.ApprovalRequest, error) {
+	var request *models.ApprovalRequest
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		request, err = h.lockPending(tx, orgId, approvalId, approver)
+		if err != nil {
+			return err
+		}
+		return h.decide(tx, request, custom.APPROVAL_REJECTED, approver, "")
+	})
+	return request, err
+}
+
+// decodeDecision decodes the optional remarks of a decision
+func decodeDecision(w http.ResponseWriter, r *http.Request) *hDecideApproval {
+	if r.ContentLength == 0 {
+		return &hDecideApproval{}
+	}
+	return payload.ValidateAndDecodeRequest[hDecideApproval](w, r)
+}
+
+func (s *approvalService) approveRequest(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	approvalId := chi.URLParam(r, "approvalId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+
+	decision := decodeDecision(w, r)
+	if decision == nil {
+		return
+	}
+	decision.access, _ = r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	request, err := decision.approve(s.db, orgId, approvalId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Request approved and executed."
+	response.Data = request
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *approvalService) rejectRequest(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	approvalId := chi.URLParam(r, "approvalId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+
+	decision := decodeDecision(w, r)
+	if decision == nil {
+		return
+	}
+	decision.access, _ = r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	request, err := decision.reject(s.db, orgId, approvalId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Request rejected."
+	response.Data = request
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
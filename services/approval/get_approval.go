@@ -0,0 +1,115 @@
+package approval
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+var approvalNotFoundError = &custom.RequestError{
+	Status:  http.StatusNotFound,
+	Message: "Approval request not found.",
+}
+
+// hGetApprovalRequests lists approval requests of the organization, filtered by status, action and society
+type hGetApprovalRequests struct {
+	Status  string
+	Action  string
+	Society string
+}
+
+func (h *hGetApprovalRequests) execute(db *gorm.DB, orgId, cursor string, access *custom.UserAccess) (*custom.PaginatedData, error) {
+	query := db.Where("org_id = ?", orgId)
+	if access != nil && access.Societies != nil {
+		query = query.Where("society_id IN ?", access.SocietyIds())
+	}
+
+	if h.Status != "" {
+		if !custom.ApprovalStatus(h.Status).IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid approval status.",
+			}
+		}
+		query = query.Where("status = ?", h.Status)
+	}
+	if h.Action != "" {
+		query = query.Where("action = ?", h.Action)
+	}
+	if h.Society != "" {
+		query = query.Where("society_id = ?", h.Society)
+	}
+
+	query = query.Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	var requests []models.ApprovalRequest
+	err := query.Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return common.CreatePaginatedResponse(&requests), nil
+}
+
+func (s *approvalService) getApprovalRequests(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	cursor := r.URL.Query().Get("cursor")
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	requests := hGetApprovalRequests{
+		Status:  strings.TrimSpace(r.URL.Query().Get("status")),
+		Action:  strings.TrimSpace(r.URL.Query().Get("action")),
+		Society: strings.TrimSpace(r.URL.Query().Get("society")),
+	}
+	res, err := requests.execute(s.db, orgId, cursor, access)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// findApprovalRequest returns the request of the organization, requests of societies the user is not member of are not found
+func findApprovalRequest(db *gorm.DB, orgId, approvalId string, access *custom.UserAccess) (*models.ApprovalRequest, error) {
+	var request models.ApprovalRequest
+	err := db.Where("id = ? and org_id = ?", approvalId, orgId).First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !access.CanAccessSociety(request.SocietyId)) {
+		return nil, approvalNotFoundError
+	}
+	return &request, err
+}
+
+func (s *approvalService) getApprovalRequestById(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	approvalId := chi.URLParam(r, "approvalId")
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	request, err := findApprovalRequest(s.db, orgId, approvalId, access)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = request
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
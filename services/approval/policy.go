@@ -0,0 +1,89 @@
+package approval
+
+import (
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// hUpdateApprovalPolicy enables or disables approval of an action
+// threshold applies to amount based actions, without threshold every operation requires approval
+type hUpdateApprovalPolicy struct {
+	Enabled   bool
+	Threshold *float64 `validate:"omitempty,gte=0"`
+}
+
+func (h *hUpdateApprovalPolicy) execute(db *gorm.DB, orgId, action, updatedBy string) (*models.ApprovalPolicy, error) {
+	approvalAction := custom.ApprovalAction(action)
+	if !approvalAction.IsValid() {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid approval action.",
+		}
+	}
+
+	policy := models.ApprovalPolicy{
+		OrgId:     uuid.MustParse(orgId),
+		Action:    approvalAction,
+		Enabled:   h.Enabled,
+		UpdatedBy: updatedBy,
+	}
+	if h.Threshold != nil {
+		threshold := decimal.NewFromFloat(*h.Threshold)
+		policy.Threshold = &threshold
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "org_id"}, {Name: "action"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "threshold", "updated_by", "updated_at"}),
+	}).Create(&policy).Error
+	return &policy, err
+}
+
+func (s *approvalService) updateApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	action := chi.URLParam(r, "action")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+
+	reqBody := payload.ValidateAndDecodeRequest[hUpdateApprovalPolicy](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	policy, err := reqBody.execute(s.db, orgId, action, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated approval policy."
+	response.Data = policy
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *approvalService) getApprovalPolicies(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	var policies []models.ApprovalPolicy
+	err := s.db.Where("org_id = ?", orgId).Order("action").Find(&policies).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = policies
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
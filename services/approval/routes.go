@@ -0,0 +1,31 @@
+package approval
+
+import (
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *approvalService) GetBasePath() string {
+	return "/approval"
+}
+
+func (s *approvalService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
+
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+
+		router.With(permission(custom.PERMISSION_APPROVAL_POLICY)).Get("/policy", s.getApprovalPolicies)
+		router.With(permission(custom.PERMISSION_APPROVAL_POLICY)).Put("/policy/{action}", s.updateApprovalPolicy)
+		router.With(permission(custom.PERMISSION_APPROVAL_DECIDE)).Post("/{approvalId}/approve", s.approveRequest)
+		router.With(permission(custom.PERMISSION_APPROVAL_DECIDE)).Post("/{approvalId}/reject", s.rejectRequest)
+
+		router.With(permission(custom.PERMISSION_APPROVAL_VIEW)).Get("/", s.getApprovalRequests)
+		router.With(permission(custom.PERMISSION_APPROVAL_VIEW)).Get("/{approvalId}", s.getApprovalRequestById)
+	})
+
+	return mux
+}
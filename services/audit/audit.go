@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"circledigital.in/real-state-erp/utils/common"
+	"gorm.io/gorm"
+)
+
+type auditService struct {
+	db *gorm.DB
+}
+
+func CreateAuditService(app common.IApp) common.IService {
+	return &auditService{
+		db: app.GetDBClient(),
+	}
+}
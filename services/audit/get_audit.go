@@ -0,0 +1,219 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxAuditExportRows limits the rows of a single csv export
+const maxAuditExportRows = 10000
+
+// hAuditFilter filters audit logs of the organization the user can access, dates are inclusive (YYYY-MM-DD)
+type hAuditFilter struct {
+	Society    string
+	Actor      string
+	ApiKey     string
+	EntityType string
+	EntityId   string
+	Method     string
+	From       string
+	Till       string
+	access     *custom.UserAccess
+}
+
+func newAuditFilter(query url.Values, access *custom.UserAccess) hAuditFilter {
+	return hAuditFilter{
+		Society:    strings.TrimSpace(query.Get("society")),
+		Actor:      strings.TrimSpace(query.Get("actor")),
+		ApiKey:     strings.TrimSpace(query.Get("apiKey")),
+		EntityType: strings.TrimSpace(query.Get("entityType")),
+		EntityId:   strings.TrimSpace(query.Get("entityId")),
+		Method:     strings.ToUpper(strings.TrimSpace(query.Get("method"))),
+		From:       strings.TrimSpace(query.Get("from")),
+		Till:       strings.TrimSpace(query.Get("till")),
+		access:     access,
+	}
+}
+
+func (h *hAuditFilter) apply(db *gorm.DB, orgId string) (*gorm.DB, error) {
+	query := db.Where("org_id = ?", orgId)
+	if h.access != nil && h.access.Societies != nil {
+		query = query.Where("society_id IN ?", h.access.SocietyIds())
+	}
+
+	if h.Society != "" {
+		if h.access != nil && !h.access.CanAccessSociety(h.Society) {
+			return nil, &custom.RequestError{
+				Status:  http.StatusForbidden,
+				Message: "Not a member of the society.",
+			}
+		}
+		query = query.Where("society_id = ?", h.Society)
+	}
+	if h.Actor != "" {
+		query = query.Where("actor_email = ? OR actor_sub = ?", h.Actor, h.Actor)
+	}
+	if h.ApiKey != "" {
+		if _, err := uuid.Parse(h.ApiKey); err != nil {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid api key id.",
+			}
+		}
+		query = query.Where("api_key_id = ?", h.ApiKey)
+	}
+	if h.EntityType != "" {
+		query = query.Where("entity_type = ?", h.EntityType)
+	}
+	if h.EntityId != "" {
+		query = query.Where("entity_id = ?", h.EntityId)
+	}
+	if h.Method != "" {
+		query = query.Where("method = ?", h.Method)
+	}
+	if h.From != "" {
+		from, err := time.Parse("2006-01-02", h.From)
+		if err != nil {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid from date (expected YYYY-MM-DD)",
+			}
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if h.Till != "" {
+		till, err := common.ParseAsOfDate(h.Till)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("created_at <= ?", till)
+	}
+
+	return query, nil
+}
+
+func (h *hAuditFilter) execute(db *gorm.DB, orgId, cursor string) (*custom.PaginatedData, error) {
+	query, err := h.apply(db, orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	query = query.Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	var auditLogs []models.AuditLog
+	err = query.Find(&auditLogs).Error
+	if err != nil {
+		return nil, err
+	}
+	return common.CreatePaginatedResponse(&auditLogs), nil
+}
+
+func (h *hAuditFilter) export(db *gorm.DB, orgId string) (*bytes.Buffer, error) {
+	query, err := h.apply(db, orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	var auditLogs []models.AuditLog
+	err = query.Order("created_at DESC").Limit(maxAuditExportRows).Find(&auditLogs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	err = writer.Write([]string{
+		"Time", "Actor Email", "Actor Sub", "Actor Role", "Method", "Route", "Path",
+		"Society", "Entity Type", "Entity Id", "Status", "Changes",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, auditLog := range auditLogs {
+		err = writer.Write([]string{
+			auditLog.CreatedAt.Format(time.RFC3339),
+			auditLog.ActorEmail,
+			auditLog.ActorSub,
+			auditLog.ActorRole,
+			auditLog.Method,
+			auditLog.Route,
+			auditLog.Path,
+			auditLog.SocietyId,
+			auditLog.EntityType,
+			auditLog.EntityId,
+			strconv.Itoa(auditLog.StatusCode),
+			string(auditLog.Diff),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return &buf, writer.Error()
+}
+
+func (s *auditService) getAuditLogs(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	cursor := r.URL.Query().Get("cursor")
+
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	filter := newAuditFilter(r.URL.Query(), access)
+	res, err := filter.execute(s.db, orgId, cursor)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *auditService) exportAuditLogs(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	filter := newAuditFilter(r.URL.Query(), access)
+	file, err := filter.export(s.db, orgId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=audit_log_%d.csv", time.Now().Unix()),
+	)
+	w.Header().Set("Content-Length", fmt.Sprint(file.Len()))
+
+	if _, err := w.Write(file.Bytes()); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+}
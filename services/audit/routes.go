@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *auditService) GetBasePath() string {
+	return "/audit"
+}
+
+func (s *auditService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.Use(authorizationMiddleware.Permission(custom.PERMISSION_AUDIT_VIEW))
+
+		router.Get("/", s.getAuditLogs)
+		router.Get("/export", s.exportAuditLogs)
+	})
+
+	return mux
+}
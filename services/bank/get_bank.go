@@ -5,6 +5,8 @@ import (
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -63,7 +65,8 @@ func (h *hGetBankReport) validate(db *gorm.DB, orgId, society, bankId string) er
 	return common.IsSameSociety(bankSocietyInfo, orgId, society)
 }
 
-func (h *hGetBankReport) execute(db *gorm.DB, orgId, society, bankId string) (*models.BankReport, error) {
+// execute builds the report as of asOf, records created after asOf are excluded
+func (h *hGetBankReport) execute(db *gorm.DB, orgId, society, bankId string, asOf time.Time) (*models.BankReport, error) {
 	err := h.validate(db, orgId, society, bankId)
 	if err != nil {
 		return nil, err
@@ -73,6 +76,8 @@ func (h *hGetBankReport) execute(db *gorm.DB, orgId, society, bankId string) (*m
 		Id: uuid.MustParse(bankId),
 	}
 	err = db.Preload("ClearedReceipts", func(db *gorm.DB) *gorm.DB {
+		db = db.Where("created_at <= ?", asOf)
+
 		if h.RecordsTill.IsZero() && h.RecordsFrom.IsZero() {
 			return db.Order("created_at DESC")
 		}
@@ -100,9 +105,16 @@ func (h *hGetBankReport) execute(db *gorm.DB, orgId, society, bankId string) (*m
 		First(&bankModel).Error
 
 	totalCleared := decimal.Zero
+	clearedReceipts := make([]models.ReceiptClear, 0, len(bankModel.ClearedReceipts))
 	for _, clearReceipt := range bankModel.ClearedReceipts {
+		// receipts of deleted sales are in recycle bin
+		if clearReceipt.Receipt == nil || clearReceipt.Receipt.Sale == nil || !clearReceipt.Receipt.IsIssuedBy(asOf) {
+			continue
+		}
+		clearedReceipts = append(clearedReceipts, clearReceipt)
 		totalCleared = totalCleared.Add(clearReceipt.Receipt.TotalAmount)
 	}
+	bankModel.ClearedReceipts = clearedReceipts
 
 	return &models.BankReport{
 		TotalAmount: totalCleared,
@@ -110,6 +122,45 @@ func (h *hGetBankReport) execute(db *gorm.DB, orgId, society, bankId string) (*m
 	}, err
 }
 
+// table returns a row per receipt cleared in the bank, the rows add up to the total amount of the report
+func (h *hGetBankReport) table(report *models.BankReport) *tabular.Table {
+	columns := []tabular.Column{
+		{Path: []string{"Receipt Details", "Receipt Number"}},
+		{Path: []string{"Receipt Details", "Date Issued"}},
+		{Path: []string{"Receipt Details", "Mode"}},
+		{Path: []string{"Receipt Details", "Transaction Number"}},
+		{Path: []string{"Receipt Details", "Cleared On"}},
+		{Path: []string{"Receipt Details", "Amount"}, Monetary: true},
+		{Path: []string{models.HeadingSale, "Sale Number"}},
+		{Path: []string{models.HeadingSale, "Flat"}},
+		{Path: []string{models.HeadingSale, "Customer"}},
+	}
+
+	rows := make([][]any, 0, len(report.Details.ClearedReceipts))
+	for _, cleared := range report.Details.ClearedReceipts {
+		receipt := cleared.Receipt
+		var dateIssued, flatName any
+		if receipt.DateIssued.Valid {
+			dateIssued = receipt.DateIssued.Time
+		}
+		if receipt.Sale.Flat != nil {
+			flatName = receipt.Sale.Flat.Name
+		}
+		rows = append(rows, []any{
+			receipt.ReceiptNumber,
+			dateIssued,
+			string(receipt.Mode),
+			receipt.TransactionNumber,
+			cleared.CreatedAt,
+			receipt.TotalAmount,
+			receipt.Sale.SaleNumber,
+			flatName,
+			receipt.Sale.OwnerNames(),
+		})
+	}
+	return tabular.Rows("Bank Report", columns, rows)
+}
+
 func (s *bankService) getBankReport(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
@@ -120,12 +171,29 @@ func (s *bankService) getBankReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	report, err := reqBody.execute(s.db, orgId, societyRera, bankId)
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	format, err := tabular.Negotiate(r)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
 	}
 
+	report, err := reqBody.execute(s.db, orgId, societyRera, bankId, asOf)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	if !format.IsDefault() {
+		tabular.Respond(w, format, fmt.Sprintf("bank_%s_report_%d", bankId, asOf.Unix()), reqBody.table(report))
+		return
+	}
+
 	var response custom.JSONResponse
 	response.Error = false
 	response.Data = report
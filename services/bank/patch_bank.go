@@ -10,12 +10,58 @@ import (
 	"net/http"
 )
 
+// empty account type keeps the type of the account,
+// the type is fixed once receipts are cleared or transfers are recorded in the account as the designated account compliance of past quarters depends on it
 type hUpdateBankAccountDetails struct {
 	Name          string `validate:"required"`
 	AccountNumber string `validate:"required,bank-account-number"`
+	AccountType   string
+}
+
+func (h *hUpdateBankAccountDetails) validate(db *gorm.DB, orgId, societyRera, bankId string) error {
+	if h.AccountType == "" {
+		return nil
+	}
+	if !custom.BankAccountType(h.AccountType).IsValid() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid bank account type.",
+		}
+	}
+
+	var bankModel models.Bank
+	result := db.Where("id = ? and org_id = ? and society_id = ?", bankId, orgId, societyRera).Limit(1).Find(&bankModel)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 || bankModel.AccountType == custom.BankAccountType(h.AccountType) {
+		return nil
+	}
+
+	var clearances, transfers int64
+	err := db.Model(&models.ReceiptClear{}).Where("bank_id = ?", bankId).Count(&clearances).Error
+	if err != nil {
+		return err
+	}
+	err = db.Model(&models.BankTransfer{}).Where("from_bank_id = ? or to_bank_id = ?", bankId, bankId).Count(&transfers).Error
+	if err != nil {
+		return err
+	}
+	if clearances > 0 || transfers > 0 {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Account type can't be changed once receipts are cleared or transfers are recorded in the account.",
+		}
+	}
+	return nil
 }
 
 func (h *hUpdateBankAccountDetails) execute(db *gorm.DB, orgId, societyRera, bankId string) error {
+	err := h.validate(db, orgId, societyRera, bankId)
+	if err != nil {
+		return err
+	}
+
 	return db.
 		Model(&models.Bank{
 			Id: uuid.MustParse(bankId),
@@ -24,6 +70,7 @@ func (h *hUpdateBankAccountDetails) execute(db *gorm.DB, orgId, societyRera, ban
 		Updates(models.Bank{
 			Name:          h.Name,
 			AccountNumber: h.AccountNumber,
+			AccountType:   custom.BankAccountType(h.AccountType),
 		}).Error
 }
 
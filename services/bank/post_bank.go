@@ -10,20 +10,41 @@ import (
 	"net/http"
 )
 
+// empty account type adds a collection account
 type hAddBankAccountToSociety struct {
 	Name          string `validate:"required"`
 	AccountNumber string `validate:"required,bank-account-number"`
+	AccountType   string
+}
+
+func (h *hAddBankAccountToSociety) validate() error {
+	if h.AccountType == "" {
+		h.AccountType = string(custom.BANK_ACCOUNT_COLLECTION)
+	}
+	if !custom.BankAccountType(h.AccountType).IsValid() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid bank account type.",
+		}
+	}
+	return nil
 }
 
 func (h *hAddBankAccountToSociety) execute(db *gorm.DB, orgId, society string) (*models.Bank, error) {
+	err := h.validate()
+	if err != nil {
+		return nil, err
+	}
+
 	bankModel := models.Bank{
 		OrgId:         uuid.MustParse(orgId),
 		SocietyId:     society,
 		Name:          h.Name,
 		AccountNumber: h.AccountNumber,
+		AccountType:   custom.BankAccountType(h.AccountType),
 	}
 
-	err := db.Create(&bankModel).Error
+	err = db.Create(&bankModel).Error
 	return &bankModel, err
 }
 
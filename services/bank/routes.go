@@ -1,6 +1,7 @@
 package bank
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,28 +13,19 @@ func (s *bankService) GetBasePath() string {
 func (s *bankService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
-
-		router.Post("/", s.addBankAccountToSociety)
-		router.Patch("/{bankId}", s.updateBankAccountDetails)
-	})
+	permission := authorizationMiddleware.Permission
 
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Get("/", s.getAllSocietyBankAccounts)
-		//router.Post("/{bankId}/report", s.getBankReport)
-	})
-
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAndViewerAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.With(permission(custom.PERMISSION_BANK_MANAGE)).Post("/", s.addBankAccountToSociety)
+		router.With(permission(custom.PERMISSION_BANK_MANAGE)).Patch("/{bankId}", s.updateBankAccountDetails)
+		router.With(permission(custom.PERMISSION_BANK_MANAGE)).Post("/transfer", s.createBankTransfer)
 
-		router.Post("/{bankId}/report", s.getBankReport)
+		router.With(permission(custom.PERMISSION_BANK_VIEW)).Get("/", s.getAllSocietyBankAccounts)
+		router.With(permission(custom.PERMISSION_BANK_VIEW)).Get("/transfer", s.getBankTransfers)
 
+		router.With(permission(custom.PERMISSION_REPORT_VIEW)).Post("/{bankId}/report", s.getBankReport)
 	})
 	return mux
 }
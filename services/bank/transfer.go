@@ -0,0 +1,170 @@
+package bank
+
+import (
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type hCreateBankTransfer struct {
+	FromBankId   string          `validate:"required,uuid"`
+	ToBankId     string          `validate:"required,uuid"`
+	Amount       float64         `validate:"required,gt=0"`
+	TransferDate custom.DateOnly `validate:"required"`
+	Reference    string
+	Remarks      string
+}
+
+func (h *hCreateBankTransfer) validate(db *gorm.DB, orgId, society string) error {
+	if h.TransferDate.IsZero() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Required missing values: Transfer Date",
+		}
+	}
+
+	if h.FromBankId == h.ToBankId {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Transfer must be between two different bank accounts.",
+		}
+	}
+
+	for _, bankId := range []string{h.FromBankId, h.ToBankId} {
+		bankSocietyInfo := CreateBankSocietyInfoService(db, uuid.MustParse(bankId))
+		if err := common.IsSameSociety(bankSocietyInfo, orgId, society); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *hCreateBankTransfer) execute(db *gorm.DB, orgId, society, createdBy string) (*models.BankTransfer, error) {
+	err := h.validate(db, orgId, society)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := models.BankTransfer{
+		OrgId:        uuid.MustParse(orgId),
+		SocietyId:    society,
+		FromBankId:   uuid.MustParse(h.FromBankId),
+		ToBankId:     uuid.MustParse(h.ToBankId),
+		Amount:       decimal.NewFromFloat(h.Amount),
+		TransferDate: h.TransferDate,
+		Reference:    strings.TrimSpace(h.Reference),
+		Remarks:      strings.TrimSpace(h.Remarks),
+		CreatedBy:    createdBy,
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&transfer).Error; err != nil {
+			return err
+		}
+		return tx.Preload("FromBank").Preload("ToBank").First(&transfer, "id = ?", transfer.Id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (s *bankService) createBankTransfer(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	societyRera := chi.URLParam(r, "society")
+
+	reqBody := payload.ValidateAndDecodeRequest[hCreateBankTransfer](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	transfer, err := reqBody.execute(s.db, orgId, societyRera, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully recorded bank transfer."
+	response.Data = transfer
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
+
+// empty bank id lists the transfers of every account of the society, else the transfers in and out of the account
+type hGetBankTransfers struct{}
+
+func (h *hGetBankTransfers) validate(db *gorm.DB, orgId, society, bankId string) error {
+	if bankId == "" {
+		return nil
+	}
+	if uuid.Validate(bankId) != nil {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid bank id.",
+		}
+	}
+	bankSocietyInfo := CreateBankSocietyInfoService(db, uuid.MustParse(bankId))
+	return common.IsSameSociety(bankSocietyInfo, orgId, society)
+}
+
+func (h *hGetBankTransfers) execute(db *gorm.DB, orgId, society, bankId, cursor string) (*custom.PaginatedData, error) {
+	err := h.validate(db, orgId, society, bankId)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []models.BankTransfer
+
+	query := db.
+		Preload("FromBank").
+		Preload("ToBank").
+		Where("org_id = ? and society_id = ?", orgId, society).
+		Order("created_at DESC").
+		Limit(custom.LIMIT + 1)
+	if bankId != "" {
+		query = query.Where("from_bank_id = ? or to_bank_id = ?", bankId, bankId)
+	}
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	err = query.Find(&transfers).Error
+	if err != nil {
+		return nil, err
+	}
+	return common.CreatePaginatedResponse(&transfers), nil
+}
+
+func (s *bankService) getBankTransfers(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	bankId := strings.TrimSpace(r.URL.Query().Get("bankId"))
+	cursor := r.URL.Query().Get("cursor")
+
+	transfers := hGetBankTransfers{}
+	res, err := transfers.execute(s.db, orgId, societyRera, bankId, cursor)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,221 @@
+package broker
+
+import (
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// findCommissionSchedule returns the schedule applicable to a sale
+// schedules must be ordered by effective from descending, broker specific schedules take precedence over society default
+func findCommissionSchedule(schedules []models.BrokerCommissionSchedule, sale models.Sale) *models.BrokerCommissionSchedule {
+	var societyDefault *models.BrokerCommissionSchedule
+	for i, schedule := range schedules {
+		if schedule.EffectiveFrom.After(sale.CreatedAt) {
+			continue
+		}
+
+		if schedule.BrokerId != nil && sale.BrokerId != nil && *schedule.BrokerId == *sale.BrokerId {
+			return &schedules[i]
+		}
+
+		if schedule.BrokerId == nil && societyDefault == nil {
+			societyDefault = &schedules[i]
+		}
+	}
+	return societyDefault
+}
+
+// accrueBrokerCommissions recomputes commission for every sale of the broker, paid commissions are left untouched
+// unpaid commissions of deleted sales are cancelled
+func accrueBrokerCommissions(db *gorm.DB, orgId, society, brokerId string) error {
+	var schedules []models.BrokerCommissionSchedule
+	err := db.
+		Where("org_id = ? and society_id = ?", orgId, society).
+		Where("broker_id IS NULL OR broker_id = ?", brokerId).
+		Order("effective_from DESC").
+		Find(&schedules).Error
+	if err != nil {
+		return err
+	}
+
+	var sales []models.Sale
+	err = db.
+		Preload("Receipts").
+		Preload("Receipts.Cleared").
+		Where("org_id = ? and society_id = ? and channel = ? and broker_id = ?", orgId, society, custom.CHANNEL_BROKER, brokerId).
+		Order("created_at ASC").
+		Find(&sales).Error
+	if err != nil {
+		return err
+	}
+
+	var existing []models.BrokerCommission
+	err = db.Where("broker_id = ?", brokerId).Find(&existing).Error
+	if err != nil {
+		return err
+	}
+
+	commissionBySale := make(map[uuid.UUID]models.BrokerCommission, len(existing))
+	for _, commission := range existing {
+		commissionBySale[commission.SaleId] = commission
+	}
+
+	// sales are loaded without the deleted ones, their commissions must not be paid out
+	saleIds := make([]uuid.UUID, 0, len(sales))
+	for _, sale := range sales {
+		saleIds = append(saleIds, sale.Id)
+	}
+	query := db.Model(&models.BrokerCommission{}).
+		Where("broker_id = ? and status IN ?", brokerId, []custom.CommissionStatus{custom.COMMISSION_ACCRUED, custom.COMMISSION_ELIGIBLE})
+	if len(saleIds) > 0 {
+		query = query.Where("sale_id NOT IN ?", saleIds)
+	}
+	err = query.Update("status", custom.COMMISSION_CANCELLED).Error
+	if err != nil {
+		return err
+	}
+
+	for i, sale := range sales {
+		commission, ok := commissionBySale[sale.Id]
+		if ok && commission.Status == custom.COMMISSION_PAID {
+			continue
+		}
+
+		schedule := findCommissionSchedule(schedules, sale)
+		if schedule == nil {
+			continue
+		}
+
+		basicCost := sale.PriceBreakdown.GetBasicCost()
+		commission.SaleId = sale.Id
+		commission.BrokerId = uuid.MustParse(brokerId)
+		commission.SocietyId = sale.SocietyId
+		commission.OrgId = sale.OrgId
+		commission.ScheduleId = schedule.Id
+		commission.BasicCost = basicCost
+		commission.Amount = schedule.CalcCommission(basicCost, i+1)
+		commission.EligibleOn = schedule.GetEligibleDate(sale)
+		commission.Status = custom.COMMISSION_ACCRUED
+		if commission.EligibleOn != nil {
+			commission.Status = custom.COMMISSION_ELIGIBLE
+		}
+
+		err = db.Save(&commission).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type hAccrueBrokerCommission struct{}
+
+func (h *hAccrueBrokerCommission) validate(db *gorm.DB, orgId, society, brokerId string) error {
+	brokerSocietyInfo := CreateBrokerSocietyInfoService(db, uuid.MustParse(brokerId))
+	return common.IsSameSociety(brokerSocietyInfo, orgId, society)
+}
+
+func (h *hAccrueBrokerCommission) execute(db *gorm.DB, orgId, society, brokerId string) error {
+	err := h.validate(db, orgId, society, brokerId)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return accrueBrokerCommissions(tx, orgId, society, brokerId)
+	})
+}
+
+func (s *brokerService) accrueBrokerCommission(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	brokerId := chi.URLParam(r, "brokerId")
+
+	commission := hAccrueBrokerCommission{}
+	err := commission.execute(s.db, orgId, societyRera, brokerId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully accrued broker commission."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+type hGetBrokerCommissions struct{}
+
+func (h *hGetBrokerCommissions) validate(db *gorm.DB, orgId, society, brokerId string) error {
+	brokerSocietyInfo := CreateBrokerSocietyInfoService(db, uuid.MustParse(brokerId))
+	return common.IsSameSociety(brokerSocietyInfo, orgId, society)
+}
+
+func (h *hGetBrokerCommissions) execute(db *gorm.DB, orgId, society, brokerId, status, cursor string) (*custom.PaginatedData, error) {
+	err := h.validate(db, orgId, society, brokerId)
+	if err != nil {
+		return nil, err
+	}
+
+	var commissions []models.BrokerCommission
+
+	query := db.
+		Preload("Sale").
+		Preload("Sale.Flat").
+		Preload("Schedule").
+		Where("broker_id = ?", brokerId).
+		Order("created_at DESC").
+		Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(status) != "" {
+		if !custom.CommissionStatus(status).IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid commission status.",
+			}
+		}
+		query = query.Where("status = ?", status)
+	}
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	err = query.Find(&commissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return common.CreatePaginatedResponse(&commissions), nil
+}
+
+func (s *brokerService) getBrokerCommissions(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	brokerId := chi.URLParam(r, "brokerId")
+	cursor := r.URL.Query().Get("cursor")
+	status := r.URL.Query().Get("status")
+
+	commissions := hGetBrokerCommissions{}
+	res, err := commissions.execute(s.db, orgId, societyRera, brokerId, status, cursor)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,185 @@
+package broker
+
+import (
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type commissionSlab struct {
+	MinBookings int     `validate:"required,gte=1"`
+	Rate        float64 `validate:"gte=0,lte=100"`
+}
+
+type hCreateCommissionSchedule struct {
+	BrokerId           string           `validate:"omitempty,uuid"`
+	Name               string           `validate:"required"`
+	Type               string           `validate:"required"`
+	Rate               float64          `validate:"gte=0"`
+	Slabs              []commissionSlab `validate:"omitempty,dive"`
+	EligibilityPercent float64          `validate:"gte=0,lte=100"`
+	EffectiveFrom      custom.DateOnly  `validate:"required"`
+}
+
+func (h *hCreateCommissionSchedule) validate(db *gorm.DB, orgId, society string) error {
+	if h.EffectiveFrom.IsZero() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Required missing values: Effective From",
+		}
+	}
+
+	commissionType := custom.CommissionType(h.Type)
+	if !commissionType.IsValid() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid commission type.",
+		}
+	}
+
+	switch commissionType {
+	case custom.COMMISSION_PERCENT:
+		if h.Rate <= 0 || h.Rate > 100 {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Commission percentage should be between 0 and 100.",
+			}
+		}
+	case custom.COMMISSION_FLAT:
+		if h.Rate <= 0 {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Commission amount should be greater than 0.",
+			}
+		}
+	case custom.COMMISSION_SLAB:
+		if len(h.Slabs) == 0 {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Slab commission requires at least one slab.",
+			}
+		}
+	}
+
+	if strings.TrimSpace(h.BrokerId) != "" {
+		brokerSocietyInfo := CreateBrokerSocietyInfoService(db, uuid.MustParse(h.BrokerId))
+		if err := common.IsSameSociety(brokerSocietyInfo, orgId, society); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *hCreateCommissionSchedule) execute(db *gorm.DB, orgId, society string) (*models.BrokerCommissionSchedule, error) {
+	err := h.validate(db, orgId, society)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := models.BrokerCommissionSchedule{
+		SocietyId:          society,
+		OrgId:              uuid.MustParse(orgId),
+		Name:               strings.TrimSpace(h.Name),
+		Type:               custom.CommissionType(h.Type),
+		Rate:               decimal.NewFromFloat(h.Rate),
+		EligibilityPercent: decimal.NewFromFloat(h.EligibilityPercent),
+		EffectiveFrom:      h.EffectiveFrom,
+	}
+
+	if strings.TrimSpace(h.BrokerId) != "" {
+		brokerId := uuid.MustParse(h.BrokerId)
+		schedule.BrokerId = &brokerId
+	}
+
+	if schedule.Type == custom.COMMISSION_SLAB {
+		schedule.Rate = decimal.Zero
+		for _, slab := range h.Slabs {
+			schedule.Slabs = append(schedule.Slabs, models.CommissionSlab{
+				MinBookings: slab.MinBookings,
+				Rate:        decimal.NewFromFloat(slab.Rate),
+			})
+		}
+	}
+
+	err = db.Create(&schedule).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (s *brokerService) createCommissionSchedule(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+
+	reqBody := payload.ValidateAndDecodeRequest[hCreateCommissionSchedule](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	schedule, err := reqBody.execute(s.db, orgId, societyRera)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully created commission schedule."
+	response.Data = schedule
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
+
+type hGetCommissionSchedules struct{}
+
+func (h *hGetCommissionSchedules) execute(db *gorm.DB, orgId, society, cursor string) (*custom.PaginatedData, error) {
+	var schedules []models.BrokerCommissionSchedule
+
+	query := db.
+		Preload("Broker").
+		Where("org_id = ? and society_id = ?", orgId, society).
+		Order("created_at DESC").
+		Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	err := query.Find(&schedules).Error
+	if err != nil {
+		return nil, err
+	}
+	return common.CreatePaginatedResponse(&schedules), nil
+}
+
+func (s *brokerService) getCommissionSchedules(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	cursor := r.URL.Query().Get("cursor")
+	societyRera := chi.URLParam(r, "society")
+
+	schedules := hGetCommissionSchedules{}
+	res, err := schedules.execute(s.db, orgId, societyRera, cursor)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
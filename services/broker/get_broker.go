@@ -5,6 +5,8 @@ import (
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -53,6 +55,33 @@ func (s *brokerService) getAllSocietyBrokers(w http.ResponseWriter, r *http.Requ
 	payload.EncodeJSON(w, http.StatusOK, response)
 }
 
+// filterSaleRecords limits sales to the records window, sales booked after asOf are excluded
+func filterSaleRecords(recordsFrom, recordsTill, asOf time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("created_at <= ?", asOf)
+
+		if recordsTill.IsZero() && recordsFrom.IsZero() {
+			return db.Order("created_at DESC")
+		}
+
+		if recordsTill.IsZero() {
+			return db.
+				Where("created_at >= ?", recordsFrom).
+				Order("created_at DESC")
+		}
+
+		if recordsFrom.IsZero() {
+			return db.
+				Where("created_at <= ?", recordsTill.Add(time.Hour*24)).
+				Order("created_at DESC")
+		}
+
+		return db.
+			Where("created_at >= ? AND created_at <= ?", recordsFrom, recordsTill.Add(time.Hour*24)).
+			Order("created_at DESC")
+	}
+}
+
 // start time should be greater than end time
 // filtering is based on the past
 type hGetBrokerReport struct {
@@ -65,7 +94,8 @@ func (h *hGetBrokerReport) validate(db *gorm.DB, orgId, society, brokerId string
 	return common.IsSameSociety(brokerSocietyInfo, orgId, society)
 }
 
-func (h *hGetBrokerReport) execute(db *gorm.DB, orgId, society, brokerId string) (*models.BrokerReport, error) {
+// execute builds the report as of asOf, records created after asOf are excluded
+func (h *hGetBrokerReport) execute(db *gorm.DB, orgId, society, brokerId string, asOf time.Time) (*models.BrokerReport, error) {
 	err := h.validate(db, orgId, society, brokerId)
 	if err != nil {
 		return nil, err
@@ -74,27 +104,8 @@ func (h *hGetBrokerReport) execute(db *gorm.DB, orgId, society, brokerId string)
 	brokerModel := models.Broker{
 		Id: uuid.MustParse(brokerId),
 	}
-	err = db.Preload("Sales", func(db *gorm.DB) *gorm.DB {
-		if h.RecordsTill.IsZero() && h.RecordsFrom.IsZero() {
-			return db.Order("created_at DESC")
-		}
-
-		if h.RecordsTill.IsZero() {
-			return db.
-				Where("created_at >= ?", h.RecordsFrom).
-				Order("created_at DESC")
-		}
-
-		if h.RecordsFrom.IsZero() {
-			return db.
-				Where("created_at <= ?", h.RecordsTill.Add(time.Hour*24)).
-				Order("created_at DESC")
-		}
-
-		return db.
-			Where("created_at >= ? AND created_at <= ?", h.RecordsFrom, h.RecordsTill.Add(time.Hour*24)).
-			Order("created_at DESC")
-	}).Preload("Sales.Flat").
+	err = db.Preload("Sales", filterSaleRecords(h.RecordsFrom, h.RecordsTill, asOf)).
+		Preload("Sales.Flat").
 		Preload("Sales.Customers").
 		Preload("Sales.CompanyCustomer").
 		Preload("Sales.Receipts").
@@ -103,7 +114,8 @@ func (h *hGetBrokerReport) execute(db *gorm.DB, orgId, society, brokerId string)
 		First(&brokerModel).Error
 
 	totalAmount := decimal.Zero
-	for _, sale := range brokerModel.Sales {
+	for i, sale := range brokerModel.Sales {
+		brokerModel.Sales[i] = sale.AsOf(asOf)
 		totalAmount = totalAmount.Add(sale.TotalPrice)
 	}
 
@@ -113,6 +125,37 @@ func (h *hGetBrokerReport) execute(db *gorm.DB, orgId, society, brokerId string)
 	}, err
 }
 
+// table returns a row per sale of the broker, the total prices add up to the total amount of the report
+func (h *hGetBrokerReport) table(report *models.BrokerReport) *tabular.Table {
+	columns := []tabular.Column{
+		{Path: []string{models.HeadingSale, "Sale Number"}},
+		{Path: []string{models.HeadingSale, "Booked On"}},
+		{Path: []string{models.HeadingSale, "Flat"}},
+		{Path: []string{models.HeadingSale, "Customer"}},
+		{Path: []string{"Payment", "Total Price"}, Monetary: true},
+		{Path: []string{"Payment", "Paid"}, Monetary: true},
+		{Path: []string{"Payment", "Pending"}, Monetary: true},
+	}
+
+	rows := make([][]any, 0, len(report.Details.Sales))
+	for _, sale := range report.Details.Sales {
+		var flatName any
+		if sale.Flat != nil {
+			flatName = sale.Flat.Name
+		}
+		rows = append(rows, []any{
+			sale.SaleNumber,
+			sale.CreatedAt,
+			flatName,
+			sale.OwnerNames(),
+			sale.TotalPrice,
+			sale.PaidAmount(),
+			sale.Pending(),
+		})
+	}
+	return tabular.Rows("Broker Report", columns, rows)
+}
+
 func (s *brokerService) getBrokerReport(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
@@ -123,7 +166,97 @@ func (s *brokerService) getBrokerReport(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	report, err := reqBody.execute(s.db, orgId, societyRera, brokerId)
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	format, err := tabular.Negotiate(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	report, err := reqBody.execute(s.db, orgId, societyRera, brokerId, asOf)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	if !format.IsDefault() {
+		tabular.Respond(w, format, fmt.Sprintf("broker_%s_report_%d", brokerId, asOf.Unix()), reqBody.table(report))
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = report
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// hGetChannelReport reports sales of a channel, for broker channel use broker report for a single broker
+type hGetChannelReport struct {
+	RecordsFrom time.Time
+	RecordsTill time.Time
+}
+
+func (h *hGetChannelReport) execute(db *gorm.DB, orgId, society, channel string, asOf time.Time) (*models.SaleChannelReport, error) {
+	saleChannel := custom.SaleChannel(channel)
+	if !saleChannel.IsValid() {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid sale channel.",
+		}
+	}
+
+	var sales []models.Sale
+	err := filterSaleRecords(h.RecordsFrom, h.RecordsTill, asOf)(db).
+		Preload("Flat").
+		Preload("Broker").
+		Preload("ReferrerCustomer").
+		Preload("Customers").
+		Preload("CompanyCustomer").
+		Preload("Receipts").
+		Preload("Receipts.Cleared").
+		Preload("Receipts.Cleared.Bank").
+		Where("org_id = ? and society_id = ? and channel = ?", orgId, society, saleChannel).
+		Find(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totalAmount := decimal.Zero
+	for i, sale := range sales {
+		sales[i] = sale.AsOf(asOf)
+		totalAmount = totalAmount.Add(sale.TotalPrice)
+	}
+
+	return &models.SaleChannelReport{
+		Channel:     saleChannel,
+		TotalAmount: totalAmount,
+		Sales:       sales,
+	}, nil
+}
+
+func (s *brokerService) getChannelReport(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	channel := chi.URLParam(r, "channel")
+
+	reqBody := payload.ValidateAndDecodeRequest[hGetChannelReport](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	report, err := reqBody.execute(s.db, orgId, societyRera, channel, asOf)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
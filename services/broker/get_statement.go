@@ -0,0 +1,293 @@
+package broker
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/document"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// brokerStatement holds the commissions and payouts of a broker
+type brokerStatement struct {
+	Broker      models.Broker
+	Commissions []models.BrokerCommission
+	Payouts     []models.BrokerPayout
+}
+
+var commissionStatementHeaders = []string{
+	"Sale Number", "Flat", "Booking Date", "Basic Cost", "Schedule", "Commission", "Status", "Eligible On", "Payout Reference",
+}
+
+var payoutStatementHeaders = []string{
+	"Payout Date", "Reference", "Gross Amount", "TDS Rate (%)", "TDS Amount", "Net Amount",
+}
+
+func (s brokerStatement) commissionRows() [][]string {
+	payoutReference := make(map[uuid.UUID]string, len(s.Payouts))
+	for _, payout := range s.Payouts {
+		payoutReference[payout.Id] = payout.Reference
+	}
+
+	rows := make([][]string, 0, len(s.Commissions))
+	for _, commission := range s.Commissions {
+		saleNumber, flat, bookingDate := "", "", ""
+		if commission.Sale != nil {
+			saleNumber = commission.Sale.SaleNumber
+			bookingDate = commission.Sale.CreatedAt.Format("02-01-2006")
+			if commission.Sale.Flat != nil {
+				flat = commission.Sale.Flat.Name
+			}
+		}
+
+		schedule := ""
+		if commission.Schedule != nil {
+			schedule = commission.Schedule.Name
+		}
+
+		eligibleOn := ""
+		if commission.EligibleOn != nil {
+			eligibleOn = commission.EligibleOn.Format("02-01-2006")
+		}
+
+		reference := ""
+		if commission.PayoutId != nil {
+			reference = payoutReference[*commission.PayoutId]
+		}
+
+		rows = append(rows, []string{
+			saleNumber,
+			flat,
+			bookingDate,
+			commission.BasicCost.StringFixed(2),
+			schedule,
+			commission.Amount.StringFixed(2),
+			string(commission.Status),
+			eligibleOn,
+			reference,
+		})
+	}
+	return rows
+}
+
+func (s brokerStatement) payoutRows() [][]string {
+	rows := make([][]string, 0, len(s.Payouts))
+	for _, payout := range s.Payouts {
+		rows = append(rows, []string{
+			payout.PayoutDate.Format("02-01-2006"),
+			payout.Reference,
+			payout.GrossAmount.StringFixed(2),
+			payout.TdsRate.String(),
+			payout.TdsAmount.StringFixed(2),
+			payout.NetAmount.StringFixed(2),
+		})
+	}
+	return rows
+}
+
+// summary returns the totals of the statement as label value pairs
+func (s brokerStatement) summary() [][2]string {
+	accrued, eligible, paid := decimal.Zero, decimal.Zero, decimal.Zero
+	for _, commission := range s.Commissions {
+		switch commission.Status {
+		case custom.COMMISSION_ACCRUED:
+			accrued = accrued.Add(commission.Amount)
+		case custom.COMMISSION_ELIGIBLE:
+			eligible = eligible.Add(commission.Amount)
+		case custom.COMMISSION_PAID:
+			paid = paid.Add(commission.Amount)
+		}
+	}
+
+	tds, net := decimal.Zero, decimal.Zero
+	for _, payout := range s.Payouts {
+		tds = tds.Add(payout.TdsAmount)
+		net = net.Add(payout.NetAmount)
+	}
+
+	return [][2]string{
+		{"Accrued (not eligible)", accrued.StringFixed(2)},
+		{"Eligible (unpaid)", eligible.StringFixed(2)},
+		{"Paid (gross)", paid.StringFixed(2)},
+		{"TDS Deducted", tds.StringFixed(2)},
+		{"Net Paid", net.StringFixed(2)},
+	}
+}
+
+func writeStatementSheet(file *excelize.File, sheet string, headers []string, rows [][]string) error {
+	if _, err := file.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	for col, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(col+1, 1)
+		if err := file.SetCellValue(sheet, cell, header); err != nil {
+			return err
+		}
+	}
+
+	for rowIndex, row := range rows {
+		for col, value := range row {
+			cell, _ := excelize.CoordinatesToCellName(col+1, rowIndex+2)
+			if err := file.SetCellValue(sheet, cell, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s brokerStatement) xlsx() (*bytes.Buffer, error) {
+	file := excelize.NewFile()
+
+	summary := make([][]string, 0, 7)
+	summary = append(summary, []string{"Broker", s.Broker.Name}, []string{"PAN", s.Broker.PanNumber})
+	for _, item := range s.summary() {
+		summary = append(summary, []string{item[0], item[1]})
+	}
+
+	if err := writeStatementSheet(file, "Summary", []string{"Particular", "Value"}, summary); err != nil {
+		return nil, err
+	}
+	if err := writeStatementSheet(file, "Commissions", commissionStatementHeaders, s.commissionRows()); err != nil {
+		return nil, err
+	}
+	if err := writeStatementSheet(file, "Payouts", payoutStatementHeaders, s.payoutRows()); err != nil {
+		return nil, err
+	}
+
+	if err := file.DeleteSheet("Sheet1"); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+func (s brokerStatement) pdf() (*bytes.Buffer, error) {
+	doc := document.NewPDF("Broker Commission Statement")
+
+	doc.KeyValues([][2]string{
+		{"Broker", s.Broker.Name},
+		{"PAN", s.Broker.PanNumber},
+		{"Society", s.Broker.SocietyId},
+		{"Generated On", time.Now().Format("02-01-2006")},
+	})
+
+	doc.Heading("Summary")
+	doc.KeyValues(s.summary())
+
+	doc.Heading("Commissions")
+	doc.Table(commissionStatementHeaders, s.commissionRows())
+
+	doc.Heading("Payouts")
+	doc.Table(payoutStatementHeaders, s.payoutRows())
+
+	return doc.Bytes()
+}
+
+type hGetBrokerStatement struct{}
+
+func (h *hGetBrokerStatement) validate(db *gorm.DB, orgId, society, brokerId, format string) error {
+	if format != "xlsx" && format != "pdf" {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid statement format, expected xlsx or pdf.",
+		}
+	}
+
+	brokerSocietyInfo := CreateBrokerSocietyInfoService(db, uuid.MustParse(brokerId))
+	return common.IsSameSociety(brokerSocietyInfo, orgId, society)
+}
+
+func (h *hGetBrokerStatement) execute(db *gorm.DB, orgId, society, brokerId, format string) (*bytes.Buffer, *models.Broker, error) {
+	err := h.validate(db, orgId, society, brokerId, format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var statement brokerStatement
+	err = db.First(&statement.Broker, "id = ?", brokerId).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = db.
+		Preload("Sale").
+		Preload("Sale.Flat").
+		Preload("Schedule").
+		Where("broker_id = ?", brokerId).
+		Order("created_at ASC").
+		Find(&statement.Commissions).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = db.
+		Where("broker_id = ?", brokerId).
+		Order("payout_date ASC").
+		Find(&statement.Payouts).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var file *bytes.Buffer
+	if format == "pdf" {
+		file, err = statement.pdf()
+	} else {
+		file, err = statement.xlsx()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, &statement.Broker, nil
+}
+
+func (s *brokerService) getBrokerStatement(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	brokerId := chi.URLParam(r, "brokerId")
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "xlsx"
+	}
+
+	statement := hGetBrokerStatement{}
+	file, broker, err := statement.execute(s.db, orgId, societyRera, brokerId, format)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	contentType := "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	if format == "pdf" {
+		contentType = "application/pdf"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=broker_statement_%s_%d.%s", strings.ReplaceAll(broker.Name, " ", "_"), time.Now().Unix(), format),
+	)
+	w.Header().Set("Content-Length", fmt.Sprint(file.Len()))
+
+	if _, err := w.Write(file.Bytes()); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+}
@@ -0,0 +1,214 @@
+package broker
+
+import (
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// empty commission ids pays out every eligible commission of the broker
+type hCreateBrokerPayout struct {
+	CommissionIds []string        `validate:"omitempty,dive,uuid"`
+	PayoutDate    custom.DateOnly `validate:"required"`
+	Reference     string
+	TdsRate       *float64 `validate:"omitempty,gte=0,lte=100"`
+}
+
+func (h *hCreateBrokerPayout) validate(db *gorm.DB, orgId, society, brokerId string) error {
+	if h.PayoutDate.IsZero() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Required missing values: Payout Date",
+		}
+	}
+
+	brokerSocietyInfo := CreateBrokerSocietyInfoService(db, uuid.MustParse(brokerId))
+	return common.IsSameSociety(brokerSocietyInfo, orgId, society)
+}
+
+func (h *hCreateBrokerPayout) execute(db *gorm.DB, orgId, society, brokerId, createdBy string) (*models.BrokerPayout, error) {
+	err := h.validate(db, orgId, society, brokerId)
+	if err != nil {
+		return nil, err
+	}
+
+	tdsRate := models.TDS194HRate
+	if h.TdsRate != nil {
+		tdsRate = decimal.NewFromFloat(*h.TdsRate)
+	}
+
+	var payout models.BrokerPayout
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// eligibility depends on collections, recompute before paying out
+		if err := accrueBrokerCommissions(tx, orgId, society, brokerId); err != nil {
+			return err
+		}
+
+		// commissions of deleted sales are never paid out
+		var commissions []models.BrokerCommission
+		query := tx.
+			Joins("JOIN sales ON sales.id = broker_commissions.sale_id AND sales.deleted_at IS NULL").
+			Where("broker_commissions.broker_id = ? and broker_commissions.status = ? and broker_commissions.payout_id IS NULL", brokerId, custom.COMMISSION_ELIGIBLE)
+		if len(h.CommissionIds) > 0 {
+			query = query.Where("broker_commissions.id IN ?", h.CommissionIds)
+		}
+		if err := query.Find(&commissions).Error; err != nil {
+			return err
+		}
+
+		if len(commissions) == 0 {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "No eligible commission to pay out.",
+			}
+		}
+
+		if len(h.CommissionIds) > 0 && len(commissions) != len(h.CommissionIds) {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "One or more commissions are not eligible for payout.",
+			}
+		}
+
+		gross := decimal.Zero
+		commissionIds := make([]uuid.UUID, 0, len(commissions))
+		for _, commission := range commissions {
+			gross = gross.Add(commission.Amount)
+			commissionIds = append(commissionIds, commission.Id)
+		}
+
+		// payouts earlier in the same financial year decide if the 194H threshold is crossed
+		var previous struct {
+			Gross decimal.Decimal
+			Tds   decimal.Decimal
+		}
+		err := tx.Model(&models.BrokerPayout{}).
+			Select("COALESCE(SUM(gross_amount), 0) AS gross, COALESCE(SUM(tds_amount), 0) AS tds").
+			Where("broker_id = ? and payout_date >= ? and payout_date <= ?", brokerId, models.GetFinancialYearStart(h.PayoutDate.Time), h.PayoutDate).
+			Scan(&previous).Error
+		if err != nil {
+			return err
+		}
+
+		tds := models.CalcTDS194H(gross, previous.Gross, previous.Tds, tdsRate)
+		payout = models.BrokerPayout{
+			BrokerId:    uuid.MustParse(brokerId),
+			SocietyId:   society,
+			OrgId:       uuid.MustParse(orgId),
+			PayoutDate:  h.PayoutDate,
+			Reference:   strings.TrimSpace(h.Reference),
+			GrossAmount: gross,
+			TdsRate:     tdsRate,
+			TdsAmount:   tds,
+			NetAmount:   gross.Sub(tds),
+			CreatedBy:   createdBy,
+		}
+		if err := tx.Create(&payout).Error; err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.BrokerCommission{}).
+			Where("id IN ?", commissionIds).
+			Updates(map[string]any{
+				"status":    custom.COMMISSION_PAID,
+				"payout_id": payout.Id,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Preload("Commissions").First(&payout, "id = ?", payout.Id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payout, nil
+}
+
+func (s *brokerService) createBrokerPayout(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	brokerId := chi.URLParam(r, "brokerId")
+
+	reqBody := payload.ValidateAndDecodeRequest[hCreateBrokerPayout](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	payout, err := reqBody.execute(s.db, orgId, societyRera, brokerId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully created broker payout."
+	response.Data = payout
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
+
+type hGetBrokerPayouts struct{}
+
+func (h *hGetBrokerPayouts) validate(db *gorm.DB, orgId, society, brokerId string) error {
+	brokerSocietyInfo := CreateBrokerSocietyInfoService(db, uuid.MustParse(brokerId))
+	return common.IsSameSociety(brokerSocietyInfo, orgId, society)
+}
+
+func (h *hGetBrokerPayouts) execute(db *gorm.DB, orgId, society, brokerId, cursor string) (*custom.PaginatedData, error) {
+	err := h.validate(db, orgId, society, brokerId)
+	if err != nil {
+		return nil, err
+	}
+
+	var payouts []models.BrokerPayout
+
+	query := db.
+		Preload("Commissions").
+		Where("broker_id = ?", brokerId).
+		Order("created_at DESC").
+		Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	err = query.Find(&payouts).Error
+	if err != nil {
+		return nil, err
+	}
+	return common.CreatePaginatedResponse(&payouts), nil
+}
+
+func (s *brokerService) getBrokerPayouts(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	brokerId := chi.URLParam(r, "brokerId")
+	cursor := r.URL.Query().Get("cursor")
+
+	payouts := hGetBrokerPayouts{}
+	res, err := payouts.execute(s.db, orgId, societyRera, brokerId, cursor)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -1,6 +1,7 @@
 package broker
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,28 +13,25 @@ func (s *brokerService) GetBasePath() string {
 func (s *brokerService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
-
-		router.Post("/", s.addBrokerToSociety)
-		router.Patch("/{brokerId}", s.updateBrokerDetails)
-	})
+	permission := authorizationMiddleware.Permission
 
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Get("/", s.getAllSocietyBrokers)
-		//router.Post("/{brokerId}/report", s.getBrokerReport)
-	})
-
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAndViewerAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.With(permission(custom.PERMISSION_BROKER_MANAGE)).Post("/", s.addBrokerToSociety)
+		router.With(permission(custom.PERMISSION_BROKER_MANAGE)).Patch("/{brokerId}", s.updateBrokerDetails)
+		router.With(permission(custom.PERMISSION_COMMISSION_MANAGE)).Post("/commission-schedule", s.createCommissionSchedule)
+		router.With(permission(custom.PERMISSION_COMMISSION_MANAGE)).Post("/{brokerId}/payout", s.createBrokerPayout)
 
-		router.Post("/{brokerId}/report", s.getBrokerReport)
+		router.With(permission(custom.PERMISSION_BROKER_VIEW)).Get("/", s.getAllSocietyBrokers)
+		router.With(permission(custom.PERMISSION_BROKER_VIEW)).Get("/commission-schedule", s.getCommissionSchedules)
+		router.With(permission(custom.PERMISSION_COMMISSION_ACCRUE)).Post("/{brokerId}/commission/accrue", s.accrueBrokerCommission)
+		router.With(permission(custom.PERMISSION_BROKER_VIEW)).Get("/{brokerId}/commission", s.getBrokerCommissions)
+		router.With(permission(custom.PERMISSION_BROKER_VIEW)).Get("/{brokerId}/payout", s.getBrokerPayouts)
 
+		router.With(permission(custom.PERMISSION_REPORT_VIEW)).Post("/{brokerId}/report", s.getBrokerReport)
+		router.With(permission(custom.PERMISSION_REPORT_VIEW)).Post("/channel/{channel}/report", s.getChannelReport)
+		router.With(permission(custom.PERMISSION_REPORT_VIEW)).Get("/{brokerId}/statement", s.getBrokerStatement)
 	})
 
 	return mux
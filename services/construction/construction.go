@@ -0,0 +1,16 @@
+package construction
+
+import (
+	"circledigital.in/real-state-erp/utils/common"
+	"gorm.io/gorm"
+)
+
+type constructionService struct {
+	db *gorm.DB
+}
+
+func CreateConstructionService(app common.IApp) common.IService {
+	return &constructionService{
+		db: app.GetDBClient(),
+	}
+}
@@ -0,0 +1,160 @@
+package construction
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/services/tower"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// omitPhotoContent skips loading photo bytes when listing milestones
+func omitPhotoContent(db *gorm.DB) *gorm.DB {
+	return db.Omit("content")
+}
+
+type hGetTowerMilestones struct{}
+
+func (h *hGetTowerMilestones) validate(db *gorm.DB, orgId, society, towerId string) error {
+	towerSocietyInfoService := tower.CreateTowerSocietyInfoService(db, uuid.MustParse(towerId))
+	return common.IsSameSociety(towerSocietyInfoService, orgId, society)
+}
+
+func (h *hGetTowerMilestones) execute(db *gorm.DB, orgId, society, towerId string) ([]models.ConstructionMilestone, error) {
+	err := h.validate(db, orgId, society, towerId)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestones []models.ConstructionMilestone
+	err = db.
+		Preload("PaymentPlanItems").
+		Preload("Photos", omitPhotoContent).
+		Where("tower_id = ?", towerId).
+		Order("floor_number ASC NULLS FIRST").
+		Order("planned_date ASC NULLS LAST").
+		Order("created_at ASC").
+		Find(&milestones).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return milestones, nil
+}
+
+func (s *constructionService) getTowerMilestones(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	towerId := chi.URLParam(r, "towerId")
+
+	milestones := hGetTowerMilestones{}
+	res, err := milestones.execute(s.db, orgId, societyRera, towerId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+type hGetMilestoneById struct{}
+
+func (h *hGetMilestoneById) validate(db *gorm.DB, orgId, society, milestoneId string) error {
+	milestoneSocietyInfoService := CreateMilestoneSocietyInfoService(db, uuid.MustParse(milestoneId))
+	return common.IsSameSociety(milestoneSocietyInfoService, orgId, society)
+}
+
+func (h *hGetMilestoneById) execute(db *gorm.DB, orgId, society, milestoneId string) (*models.ConstructionMilestone, error) {
+	err := h.validate(db, orgId, society, milestoneId)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestone models.ConstructionMilestone
+	err = db.
+		Preload("Tower").
+		Preload("PaymentPlanItems").
+		Preload("Photos", omitPhotoContent).
+		First(&milestone, "id = ?", milestoneId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &milestone, nil
+}
+
+func (s *constructionService) getMilestoneById(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	milestoneId := chi.URLParam(r, "milestoneId")
+
+	milestone := hGetMilestoneById{}
+	res, err := milestone.execute(s.db, orgId, societyRera, milestoneId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+type hGetMilestonePhoto struct{}
+
+func (h *hGetMilestonePhoto) execute(db *gorm.DB, orgId, society, milestoneId, photoId string) (*models.ConstructionMilestonePhoto, error) {
+	milestoneSocietyInfoService := CreateMilestoneSocietyInfoService(db, uuid.MustParse(milestoneId))
+	if err := common.IsSameSociety(milestoneSocietyInfoService, orgId, society); err != nil {
+		return nil, err
+	}
+
+	var photo models.ConstructionMilestonePhoto
+	err := db.First(&photo, "id = ? AND milestone_id = ?", photoId, milestoneId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &custom.RequestError{
+				Status:  http.StatusNotFound,
+				Message: "Photo not found",
+			}
+		}
+		return nil, err
+	}
+
+	return &photo, nil
+}
+
+func (s *constructionService) getMilestonePhoto(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	milestoneId := chi.URLParam(r, "milestoneId")
+	photoId := chi.URLParam(r, "photoId")
+
+	handler := hGetMilestonePhoto{}
+	photo, err := handler.execute(s.db, orgId, societyRera, milestoneId, photoId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", photo.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", photo.FileName))
+	w.Header().Set("Content-Length", fmt.Sprint(len(photo.Content)))
+
+	if _, err := w.Write(photo.Content); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+}
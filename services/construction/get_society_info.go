@@ -0,0 +1,37 @@
+package construction
+
+import (
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type milestoneSocietyInfoService struct {
+	db          *gorm.DB
+	milestoneId uuid.UUID
+}
+
+func (s *milestoneSocietyInfoService) GetSocietyInfo() (*common.SocietyInfo, error) {
+	// fetch from db and return
+	milestone := models.ConstructionMilestone{
+		Id: s.milestoneId,
+	}
+
+	err := s.db.First(&milestone).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.SocietyInfo{
+		OrgId:       milestone.OrgId,
+		SocietyRera: milestone.SocietyId,
+	}, nil
+}
+
+func CreateMilestoneSocietyInfoService(db *gorm.DB, milestoneId uuid.UUID) common.ISocietyInfo {
+	return &milestoneSocietyInfoService{
+		db:          db,
+		milestoneId: milestoneId,
+	}
+}
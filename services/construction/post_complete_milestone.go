@@ -0,0 +1,187 @@
+package construction
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
+	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type hCompleteMilestone struct {
+	CompletedOn          custom.DateOnly `validate:"required"`
+	CertificateReference string          `validate:"required"`
+	CertifiedBy          string          `validate:"required"`
+	CreditPeriod         int             `validate:"gte=0"`
+}
+
+func (h *hCompleteMilestone) validate(db *gorm.DB, orgId, society, milestoneId string) error {
+	if h.CompletedOn.IsZero() || strings.TrimSpace(h.CertificateReference) == "" || strings.TrimSpace(h.CertifiedBy) == "" {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Required missing values: Completed On, Certificate Reference or Certified By",
+		}
+	}
+
+	if h.CompletedOn.After(time.Now()) {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Completion date can't be in the future.",
+		}
+	}
+
+	milestoneSocietyInfoService := CreateMilestoneSocietyInfoService(db, uuid.MustParse(milestoneId))
+	return common.IsSameSociety(milestoneSocietyInfoService, orgId, society)
+}
+
+func (h *hCompleteMilestone) execute(db *gorm.DB, orgId, society, milestoneId, completedBy string) (*models.ConstructionMilestone, error) {
+	err := h.validate(db, orgId, society, milestoneId)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestone models.ConstructionMilestone
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Preload("PaymentPlanItems").First(&milestone, "id = ?", milestoneId).Error
+		if err != nil {
+			return err
+		}
+
+		if milestone.IsCompleted() {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Milestone is already completed.",
+			}
+		}
+
+		milestone.CompletedOn = &h.CompletedOn
+		milestone.CertificateReference = strings.TrimSpace(h.CertificateReference)
+		milestone.CertifiedBy = strings.TrimSpace(h.CertifiedBy)
+		milestone.CompletedBy = completedBy
+
+		err = tx.Model(&milestone).Updates(map[string]any{
+			"completed_on":          milestone.CompletedOn,
+			"certificate_reference": milestone.CertificateReference,
+			"certified_by":          milestone.CertifiedBy,
+			"completed_by":          milestone.CompletedBy,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return activateMilestonePaymentPlanItems(tx, orgId, society, milestone, h.CreditPeriod)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &milestone, nil
+}
+
+// activateMilestonePaymentPlanItems marks the mapped payment plan items active from the certified completion date
+// tower stage items are activated for the tower, flat stage items for the flats of the milestone floor or the whole tower,
+// items already activated keep their original activation date, every new activation is published and the buyers
+// of the activated flats are notified of the demand like on a manual activation
+func activateMilestonePaymentPlanItems(tx *gorm.DB, orgId, society string, milestone models.ConstructionMilestone, creditPeriod int) error {
+	activation := models.NewPaymentActivation(milestone.CompletedOn.Time, creditPeriod)
+
+	var flatIds []uuid.UUID
+	for _, item := range milestone.PaymentPlanItems {
+		switch item.Scope {
+		case custom.SCOPE_TOWER:
+			status := models.TowerPaymentStatus{
+				TowerId:           milestone.TowerId,
+				PaymentId:         item.Id,
+				PaymentActivation: activation,
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&status)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			towerFlats := tx.Model(&models.Flat{}).Select("id").Where("tower_id = ?", milestone.TowerId)
+			if err := notification.EnqueueDemandDue(tx, orgId, item.Id.String(), activation, towerFlats); err != nil {
+				return err
+			}
+			err := webhook.Publish(tx, orgId, society, custom.EVENT_PAYMENT_PLAN_ITEM_ACTIVATED, item.Id.String(), status)
+			if err != nil {
+				return err
+			}
+		case custom.SCOPE_FLAT:
+			if flatIds == nil {
+				query := tx.Model(&models.Flat{}).Where("tower_id = ?", milestone.TowerId)
+				if milestone.FloorNumber != nil {
+					query = query.Where("floor_number = ?", *milestone.FloorNumber)
+				}
+				if err := query.Pluck("id", &flatIds).Error; err != nil {
+					return err
+				}
+			}
+
+			var activatedFlatIds []uuid.UUID
+			for _, flatId := range flatIds {
+				status := models.FlatPaymentStatus{
+					FlatId:            flatId,
+					PaymentId:         item.Id,
+					PaymentActivation: activation,
+				}
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&status)
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected == 0 {
+					continue
+				}
+				activatedFlatIds = append(activatedFlatIds, flatId)
+				err := webhook.Publish(tx, orgId, society, custom.EVENT_PAYMENT_PLAN_ITEM_ACTIVATED, item.Id.String(), status)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(activatedFlatIds) > 0 {
+				if err := notification.EnqueueDemandDue(tx, orgId, item.Id.String(), activation, activatedFlatIds); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *constructionService) completeMilestone(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail := r.Context().Value(custom.UserEmailKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	milestoneId := chi.URLParam(r, "milestoneId")
+
+	reqBody := payload.ValidateAndDecodeRequest[hCompleteMilestone](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	milestone, err := reqBody.execute(s.db, orgId, societyRera, milestoneId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Milestone completed, mapped payment plan items are now active."
+	response.Data = milestone
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
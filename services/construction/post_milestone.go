@@ -0,0 +1,160 @@
+package construction
+
+import (
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/services/tower"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type hCreateMilestone struct {
+	Stage            string `validate:"required"`
+	FloorNumber      *int   `validate:"omitempty,gte=0"`
+	Description      string
+	PlannedDate      *custom.DateOnly
+	PaymentPlanItems []string `validate:"omitempty,dive,uuid"`
+}
+
+func (h *hCreateMilestone) validate(db *gorm.DB, orgId, society, towerId string) error {
+	stage := custom.ConstructionStage(h.Stage)
+	if !stage.IsValid() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid construction stage.",
+		}
+	}
+
+	towerSocietyInfoService := tower.CreateTowerSocietyInfoService(db, uuid.MustParse(towerId))
+	if err := common.IsSameSociety(towerSocietyInfoService, orgId, society); err != nil {
+		return err
+	}
+
+	if stage.RequiresFloor() && h.FloorNumber == nil {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Floor number is required for slab milestone.",
+		}
+	}
+
+	if h.FloorNumber != nil {
+		var towerModel models.Tower
+		if err := db.First(&towerModel, "id = ?", towerId).Error; err != nil {
+			return err
+		}
+
+		if *h.FloorNumber > towerModel.FloorCount {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Floor number exceeds tower floor count.",
+			}
+		}
+	}
+
+	// an item repeated in the request is mapped once
+	h.PaymentPlanItems = uniqueItemIds(h.PaymentPlanItems)
+	return validatePaymentPlanItems(db, orgId, society, h.PaymentPlanItems)
+}
+
+// uniqueItemIds returns the ids without repeats in their order, ids must be valid uuids
+func uniqueItemIds(itemIds []string) []string {
+	unique := make([]string, 0, len(itemIds))
+	seen := make(map[uuid.UUID]bool, len(itemIds))
+	for _, itemId := range itemIds {
+		id := uuid.MustParse(itemId)
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id.String())
+		}
+	}
+	return unique
+}
+
+// validatePaymentPlanItems checks all items belong to the society and are activated by a construction stage
+func validatePaymentPlanItems(db *gorm.DB, orgId, society string, itemIds []string) error {
+	if len(itemIds) == 0 {
+		return nil
+	}
+
+	var count int64
+	err := db.Model(&models.PaymentPlanRatioItem{}).
+		Joins("JOIN payment_plan_ratios ppr ON ppr.id = payment_plan_ratio_items.payment_plan_ratio_id").
+		Joins("JOIN payment_plan_groups ppg ON ppg.id = ppr.payment_plan_group_id").
+		Where("payment_plan_ratio_items.id IN ?", itemIds).
+		Where("ppg.org_id = ? AND ppg.society_id = ?", orgId, society).
+		Where("payment_plan_ratio_items.condition_type IN ?", []custom.PaymentPlanCondition{custom.ONTOWERSTAGE, custom.ONFlatSTAGE}).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if int(count) != len(itemIds) {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Milestones can only be mapped to on-tower-stage or on-flat-stage payment plan items of the society.",
+		}
+	}
+
+	return nil
+}
+
+func (h *hCreateMilestone) execute(db *gorm.DB, orgId, society, towerId string) (*models.ConstructionMilestone, error) {
+	err := h.validate(db, orgId, society, towerId)
+	if err != nil {
+		return nil, err
+	}
+
+	milestone := models.ConstructionMilestone{
+		TowerId:     uuid.MustParse(towerId),
+		SocietyId:   society,
+		OrgId:       uuid.MustParse(orgId),
+		Stage:       custom.ConstructionStage(h.Stage),
+		FloorNumber: h.FloorNumber,
+		Description: strings.TrimSpace(h.Description),
+		PlannedDate: h.PlannedDate,
+	}
+
+	for _, itemId := range h.PaymentPlanItems {
+		milestone.PaymentPlanItems = append(milestone.PaymentPlanItems, models.PaymentPlanRatioItem{
+			Id: uuid.MustParse(itemId),
+		})
+	}
+
+	// only create the join rows, mapped payment plan items already exist
+	err = db.Omit("PaymentPlanItems.*").Create(&milestone).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &milestone, nil
+}
+
+func (s *constructionService) createMilestone(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	towerId := chi.URLParam(r, "towerId")
+
+	reqBody := payload.ValidateAndDecodeRequest[hCreateMilestone](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	milestone, err := reqBody.execute(s.db, orgId, societyRera, towerId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully created construction milestone."
+	response.Data = milestone
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
@@ -0,0 +1,102 @@
+package construction
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type hUploadMilestonePhoto struct{}
+
+func (h *hUploadMilestonePhoto) validate(db *gorm.DB, r *http.Request, orgId, society, milestoneId string) (multipart.File, *multipart.FileHeader, error) {
+	milestoneSocietyInfoService := CreateMilestoneSocietyInfoService(db, uuid.MustParse(milestoneId))
+	if err := common.IsSameSociety(milestoneSocietyInfoService, orgId, society); err != nil {
+		return nil, nil, err
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Missing file in form data",
+		}
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		_ = file.Close()
+		return nil, nil, &custom.RequestError{
+			Status:  http.StatusUnsupportedMediaType,
+			Message: "Only image files are allowed",
+		}
+	}
+
+	return file, header, nil
+}
+
+func (h *hUploadMilestonePhoto) execute(db *gorm.DB, r *http.Request, orgId, society, milestoneId, uploadedBy string) (*models.ConstructionMilestonePhoto, error) {
+	file, header, err := h.validate(db, r, orgId, society, milestoneId)
+	if err != nil {
+		return nil, err
+	}
+	defer func(file multipart.File) {
+		_ = file.Close()
+	}(file)
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	photo := models.ConstructionMilestonePhoto{
+		MilestoneId: uuid.MustParse(milestoneId),
+		FileName:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Caption:     strings.TrimSpace(r.FormValue("caption")),
+		Content:     content,
+		UploadedBy:  uploadedBy,
+	}
+
+	err = db.Create(&photo).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &photo, nil
+}
+
+func (s *constructionService) uploadMilestonePhoto(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail := r.Context().Value(custom.UserEmailKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	milestoneId := chi.URLParam(r, "milestoneId")
+
+	err := payload.ParseMultipartForm(w, r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	handler := hUploadMilestonePhoto{}
+	photo, err := handler.execute(s.db, r, orgId, societyRera, milestoneId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully uploaded milestone photo."
+	response.Data = photo
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
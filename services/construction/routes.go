@@ -0,0 +1,31 @@
+package construction
+
+import (
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *constructionService) GetBasePath() string {
+	return "/society/{society}/construction"
+}
+
+func (s *constructionService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
+
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+
+		router.With(permission(custom.PERMISSION_CONSTRUCTION_MANAGE)).Post("/tower/{towerId}", s.createMilestone)
+		router.With(permission(custom.PERMISSION_CONSTRUCTION_MANAGE)).Post("/{milestoneId}/complete", s.completeMilestone)
+		router.With(permission(custom.PERMISSION_CONSTRUCTION_MANAGE)).Post("/{milestoneId}/photo", s.uploadMilestonePhoto)
+
+		router.With(permission(custom.PERMISSION_CONSTRUCTION_VIEW)).Get("/tower/{towerId}", s.getTowerMilestones)
+		router.With(permission(custom.PERMISSION_CONSTRUCTION_VIEW)).Get("/{milestoneId}", s.getMilestoneById)
+		router.With(permission(custom.PERMISSION_CONSTRUCTION_VIEW)).Get("/{milestoneId}/photo/{photoId}", s.getMilestonePhoto)
+	})
+
+	return mux
+}
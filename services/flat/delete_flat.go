@@ -15,28 +15,53 @@ type hDeleteFlat struct{}
 
 func (h *hDeleteFlat) validate(db *gorm.DB, orgId, societyRera, flatId string) error {
 	flatSocietyInfo := CreateFlatSocietyInfoService(db, uuid.MustParse(flatId))
-	return common.IsSameSociety(flatSocietyInfo, orgId, societyRera)
+	err := common.IsSameSociety(flatSocietyInfo, orgId, societyRera)
+	if err != nil {
+		return err
+	}
+
+	var activeSales int64
+	err = db.Model(&models.Sale{}).Where("flat_id = ?", flatId).Count(&activeSales).Error
+	if err != nil {
+		return err
+	}
+	if activeSales > 0 {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Flat has an active sale, delete the sale first.",
+		}
+	}
+	return nil
 }
 
-func (h *hDeleteFlat) execute(db *gorm.DB, orgId, societyRera, flatId string) error {
+// execute soft deletes the flat, it can be restored from recycle bin until purged
+func (h *hDeleteFlat) execute(db *gorm.DB, orgId, societyRera, flatId, deletedBy string) error {
 	err := h.validate(db, orgId, societyRera, flatId)
 	if err != nil {
 		return err
 	}
 
-	return db.Model(&models.Flat{
+	flatModel := models.Flat{
 		Id: uuid.MustParse(flatId),
-	}).Delete(&models.Flat{}).Error
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&flatModel).Update("deleted_by", deletedBy).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&flatModel).Error
+	})
 }
 
 func (s *flatService) deleteFlat(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	flatId := chi.URLParam(r, "flat")
 	societyRera := chi.URLParam(r, "society")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
 
 	flat := hDeleteFlat{}
 
-	err := flat.execute(s.db, orgId, societyRera, flatId)
+	err := flat.execute(s.db, orgId, societyRera, flatId, userEmail)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
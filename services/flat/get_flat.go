@@ -53,6 +53,8 @@ func (h *hGetAllSocietyFlats) execute(db *gorm.DB, orgId, societyRera, cursor, f
 		Preload("SaleDetail.Customers").
 		Preload("SaleDetail.CompanyCustomer").
 		Preload("SaleDetail.Broker").
+		Preload("SaleDetail.ReferrerCustomer").
+		Preload("SaleDetail.Allotment").
 		Preload("SaleDetail.Receipts").
 		Preload("SaleDetail.Receipts.Cleared").
 		Preload("SaleDetail.Receipts.Cleared.Bank").
@@ -69,9 +71,9 @@ func (h *hGetAllSocietyFlats) execute(db *gorm.DB, orgId, societyRera, cursor, f
 	if filter == "1" || filter == "2" {
 		// 1 -> sold and 2 -> unsold
 		if filter == "1" {
-			query = query.Where("EXISTS (SELECT 1 FROM sales WHERE sales.flat_id = flats.id)")
+			query = query.Where("EXISTS (SELECT 1 FROM sales WHERE sales.flat_id = flats.id AND sales.deleted_at IS NULL)")
 		} else {
-			query = query.Where("NOT EXISTS (SELECT 1 FROM sales WHERE sales.flat_id = flats.id)")
+			query = query.Where("NOT EXISTS (SELECT 1 FROM sales WHERE sales.flat_id = flats.id AND sales.deleted_at IS NULL)")
 		}
 	}
 
@@ -116,6 +118,8 @@ func (h *hGetAllTowerFlats) execute(db *gorm.DB, orgId, societyRera, towerId, cu
 		Preload("SaleDetail.Customers").
 		Preload("SaleDetail.CompanyCustomer").
 		Preload("SaleDetail.Broker").
+		Preload("SaleDetail.ReferrerCustomer").
+		Preload("SaleDetail.Allotment").
 		Preload("SaleDetail.Receipts").
 		Preload("SaleDetail.Receipts.Cleared").
 		Preload("SaleDetail.Receipts.Cleared.Bank").
@@ -132,9 +136,9 @@ func (h *hGetAllTowerFlats) execute(db *gorm.DB, orgId, societyRera, towerId, cu
 	if filter == "1" || filter == "2" {
 		// 1 -> sold and 2 -> unsold
 		if filter == "1" {
-			query = query.Where("EXISTS (SELECT 1 FROM sales WHERE sales.flat_id = flats.id)")
+			query = query.Where("EXISTS (SELECT 1 FROM sales WHERE sales.flat_id = flats.id AND sales.deleted_at IS NULL)")
 		} else {
-			query = query.Where("NOT EXISTS (SELECT 1 FROM sales WHERE sales.flat_id = flats.id)")
+			query = query.Where("NOT EXISTS (SELECT 1 FROM sales WHERE sales.flat_id = flats.id AND sales.deleted_at IS NULL)")
 		}
 	}
 
@@ -188,6 +192,8 @@ func (h *hGetSocietyFlatByName) execute(db *gorm.DB, orgId, society, name, curso
 		Preload("SaleDetail.Customers").
 		Preload("SaleDetail.CompanyCustomer").
 		Preload("SaleDetail.Broker").
+		Preload("SaleDetail.ReferrerCustomer").
+		Preload("SaleDetail.Allotment").
 		Preload("SaleDetail.Receipts").
 		Preload("SaleDetail.Receipts.Cleared").
 		Preload("SaleDetail.Receipts.Cleared.Bank").
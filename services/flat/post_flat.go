@@ -6,6 +6,7 @@ import (
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/webhook"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -95,9 +96,15 @@ func (h *hCreateFlat) execute(db *gorm.DB, orgId, society string) (*models.Flat,
 		Facing:       custom.Facing(h.Facing),
 	}
 
-	result := db.Create(&flat)
-	if result.Error != nil {
-		return nil, result.Error
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&flat).Error
+		if err != nil {
+			return err
+		}
+		return webhook.Publish(tx, orgId, society, custom.EVENT_FLAT_CREATED, flat.Id.String(), flat)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &flat, nil
@@ -175,7 +182,7 @@ func (h *hBulkCreateFlats) validate(r *http.Request, db *gorm.DB, orgId, society
 	return file, towerDetails.Name, nil
 }
 
-func (h *hBulkCreateFlats) getFlatsDataFromFile(file multipart.File, towerId, towerName string, db *gorm.DB) ([]*models.Flat, error) {
+func (h *hBulkCreateFlats) getFlatsDataFromFile(file multipart.File, orgId, society, towerId, towerName string, db *gorm.DB) ([]*models.Flat, error) {
 	fileBytes, err := io.ReadAll(file)
 	if err != nil {
 		return nil, err
@@ -210,8 +217,25 @@ func (h *hBulkCreateFlats) getFlatsDataFromFile(file multipart.File, towerId, to
 		return nil, err
 	}
 
-	err = db.Create(flats).Error
-	return flats, nil
+	if len(flats) == 0 {
+		return flats, nil
+	}
+
+	// flats and their events are created together
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(flats).Error
+		if err != nil {
+			return err
+		}
+		for _, flat := range flats {
+			err := webhook.Publish(tx, orgId, society, custom.EVENT_FLAT_CREATED, flat.Id.String(), flat)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return flats, err
 }
 
 // getColumnMap maps required headers to their corresponding Excel column letters
@@ -349,7 +373,7 @@ func (h *hBulkCreateFlats) execute(db *gorm.DB, r *http.Request, orgId, society,
 		_ = file.Close()
 	}(file)
 
-	flats, err := h.getFlatsDataFromFile(file, towerId, towerName, db)
+	flats, err := h.getFlatsDataFromFile(file, orgId, society, towerId, towerName, db)
 	if err != nil {
 		if strings.Contains(err.Error(), "tower_flat_unique") {
 			return nil, &custom.RequestError{
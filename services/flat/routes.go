@@ -1,6 +1,7 @@
 package flat
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,26 +13,19 @@ func (s *flatService) GetBasePath() string {
 func (s *flatService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
 
-	// org admin authorization
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Post("/", s.createNewFlat)
-		router.Post("/tower/{towerId}/bulk", s.createBulkFlats)
-		router.Delete("/{flat}", s.deleteFlat)
-		router.Patch("/{flatId}", s.updateFlatDetails)
-	})
-
-	// org admin and user
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.With(permission(custom.PERMISSION_FLAT_MANAGE)).Post("/", s.createNewFlat)
+		router.With(permission(custom.PERMISSION_FLAT_MANAGE)).Post("/tower/{towerId}/bulk", s.createBulkFlats)
+		router.With(permission(custom.PERMISSION_FLAT_MANAGE)).Delete("/{flat}", s.deleteFlat)
+		router.With(permission(custom.PERMISSION_FLAT_MANAGE)).Patch("/{flatId}", s.updateFlatDetails)
 
-		router.Get("/", s.getAllSocietyFlats)
-		router.Get("/tower/{tower}", s.getAllTowerFlats)
-		router.Get("/search", s.getSocietyFlatByName)
+		router.With(permission(custom.PERMISSION_SOCIETY_VIEW)).Get("/", s.getAllSocietyFlats)
+		router.With(permission(custom.PERMISSION_SOCIETY_VIEW)).Get("/tower/{tower}", s.getAllTowerFlats)
+		router.With(permission(custom.PERMISSION_SOCIETY_VIEW)).Get("/search", s.getSocietyFlatByName)
 	})
 
 	return mux
@@ -0,0 +1,201 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/blob"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/jobs"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// findJob returns the job of the organization if the user can see it, a job the user cannot see is not found
+func findJob(db *gorm.DB, r *http.Request) (*models.Job, error) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	jobId := chi.URLParam(r, "jobId")
+
+	notFound := &custom.RequestError{
+		Status:  http.StatusNotFound,
+		Message: "Job not found.",
+	}
+	if _, err := uuid.Parse(jobId); err != nil {
+		return nil, notFound
+	}
+
+	var job models.Job
+	err := db.Where("id = ? and org_id = ?", jobId, orgId).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !access.Can(job.Permission) || (job.SocietyId != "" && !access.CanAccessSociety(job.SocietyId)) {
+		return nil, notFound
+	}
+	return &job, nil
+}
+
+// getJobs lists the jobs of the organization the user can see, newest first
+func (s *jobService) getJobs(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	cursor := r.URL.Query().Get("cursor")
+
+	var permissions []custom.Permission
+	if access != nil {
+		for permission, ok := range access.Permissions {
+			if ok {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	if len(permissions) == 0 {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusForbidden,
+			Message: http.StatusText(http.StatusForbidden),
+		})
+		return
+	}
+
+	query := s.db.Where("org_id = ? and permission in ?", orgId, permissions)
+	if societies := access.SocietyIds(); societies != nil {
+		query = query.Where("(society_id = '' or society_id in ?)", append(societies, ""))
+	}
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		if !custom.JobStatus(status).IsValid() {
+			payload.HandleError(w, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid job status.",
+			})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+	if jobType := strings.TrimSpace(r.URL.Query().Get("type")); jobType != "" {
+		query = query.Where("type = ?", jobType)
+	}
+	query = query.Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	var jobList []models.Job
+	err := query.Find(&jobList).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = common.CreatePaginatedResponse(&jobList)
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *jobService) getJob(w http.ResponseWriter, r *http.Request) {
+	job, err := findJob(s.db, r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = job
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// cancelJob cancels a queued job, a running job stops at its next heartbeat
+func (s *jobService) cancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := findJob(s.db, r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	cancelled, err := jobs.Cancel(s.db, job)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	if !cancelled {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusConflict,
+			Message: fmt.Sprintf("Job has already %s.", job.Status),
+		})
+		return
+	}
+
+	if err := s.db.Where("id = ?", job.Id).First(job).Error; err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully requested cancellation of job."
+	response.Data = job
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// downloadJob streams the result of a succeeded job from the blob store
+func (s *jobService) downloadJob(w http.ResponseWriter, r *http.Request) {
+	job, err := findJob(s.db, r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	if !job.Downloadable(time.Now()) {
+		message := "Job has no result to download."
+		if job.Status == custom.JOB_SUCCEEDED && job.ResultName != "" {
+			message = "Job result has expired."
+		}
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusConflict,
+			Message: message,
+		})
+		return
+	}
+
+	reader, err := s.store.Get(r.Context(), job.ResultKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusGone,
+			Message: "Job result has expired.",
+		})
+		return
+	}
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	defer reader.Close()
+
+	w.Header().Set("Content-Type", job.ResultContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", job.ResultName))
+	if job.ResultSize > 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(job.ResultSize))
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, reader)
+}
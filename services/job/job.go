@@ -0,0 +1,21 @@
+package job
+
+import (
+	"circledigital.in/real-state-erp/utils/blob"
+	"circledigital.in/real-state-erp/utils/common"
+	"gorm.io/gorm"
+)
+
+type jobService struct {
+	db    *gorm.DB
+	store blob.Store
+}
+
+// CreateJobService creates service for the progress, cancellation and download of background jobs
+// jobs are queued by the services producing them and run by the workers of utils/jobs
+func CreateJobService(app common.IApp) common.IService {
+	return &jobService{
+		db:    app.GetDBClient(),
+		store: app.GetBlobStore(),
+	}
+}
@@ -0,0 +1,27 @@
+package job
+
+import (
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *jobService) GetBasePath() string {
+	return "/job"
+}
+
+// GetRoutes exposes the jobs of the organization, each job is visible only to users with the permission it was queued with
+func (s *jobService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+
+		router.Get("/", s.getJobs)
+		router.Get("/{jobId}", s.getJob)
+		router.Post("/{jobId}/cancel", s.cancelJob)
+		router.Get("/{jobId}/download", s.downloadJob)
+	})
+
+	return mux
+}
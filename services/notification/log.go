@@ -0,0 +1,171 @@
+package notification
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// hGetNotificationLog lists the notifications of the organization, newest first
+type hGetNotificationLog struct {
+	Society   string
+	Sale      string
+	Kind      string
+	Channel   string
+	Status    string
+	Recipient string
+}
+
+func newNotificationLogFilter(query url.Values) hGetNotificationLog {
+	return hGetNotificationLog{
+		Society:   strings.TrimSpace(query.Get("society")),
+		Sale:      strings.TrimSpace(query.Get("sale")),
+		Kind:      strings.TrimSpace(query.Get("kind")),
+		Channel:   strings.TrimSpace(query.Get("channel")),
+		Status:    strings.TrimSpace(query.Get("status")),
+		Recipient: strings.TrimSpace(query.Get("recipient")),
+	}
+}
+
+func (h *hGetNotificationLog) execute(db *gorm.DB, orgId, cursor string, access *custom.UserAccess) (*custom.PaginatedData, error) {
+	query := db.Where("org_id = ?", orgId)
+	if access != nil && access.Societies != nil {
+		query = query.Where("society_id IN ?", access.SocietyIds())
+	}
+
+	if h.Society != "" {
+		query = query.Where("society_id = ?", h.Society)
+	}
+	if h.Sale != "" {
+		if _, err := uuid.Parse(h.Sale); err != nil {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid sale id.",
+			}
+		}
+		query = query.Where("sale_id = ?", h.Sale)
+	}
+	if h.Kind != "" {
+		if !custom.NotificationKind(h.Kind).IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid notification kind.",
+			}
+		}
+		query = query.Where("kind = ?", h.Kind)
+	}
+	if h.Channel != "" {
+		if !custom.NotificationChannel(h.Channel).IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid notification channel.",
+			}
+		}
+		query = query.Where("channel = ?", h.Channel)
+	}
+	if h.Status != "" {
+		if !custom.NotificationStatus(h.Status).IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid notification status.",
+			}
+		}
+		query = query.Where("status = ?", h.Status)
+	}
+	if h.Recipient != "" {
+		query = query.Where("recipient = ?", h.Recipient)
+	}
+
+	query = query.Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	var notifications []models.NotificationLog
+	err := query.Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return common.CreatePaginatedResponse(&notifications), nil
+}
+
+func (s *notificationService) getNotificationLog(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	cursor := r.URL.Query().Get("cursor")
+
+	filter := newNotificationLogFilter(r.URL.Query())
+	res, err := filter.execute(s.db, orgId, cursor, access)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// retryNotification queues a failed notification again with a fresh set of attempts
+// skipped notifications are not retried, the recipient has to be removed from opt outs first
+func (s *notificationService) retryNotification(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	notificationId := chi.URLParam(r, "notificationId")
+
+	var notification models.NotificationLog
+	err := s.db.Where("id = ? and org_id = ?", notificationId, orgId).First(&notification).Error
+	if _, parseErr := uuid.Parse(notificationId); parseErr != nil || errors.Is(err, gorm.ErrRecordNotFound) ||
+		(err == nil && !access.CanAccessSociety(notification.SocietyId)) {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Notification not found.",
+		})
+		return
+	}
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	if notification.Status != custom.NOTIFICATION_FAILED || notification.Body == "" {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusConflict,
+			Message: "Only failed notifications that were rendered can be retried.",
+		})
+		return
+	}
+
+	now := time.Now()
+	err = s.db.Model(&notification).Updates(map[string]any{
+		"status":          custom.NOTIFICATION_PENDING,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"error":           "",
+	}).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully queued notification."
+
+	payload.EncodeJSON(w, http.StatusAccepted, response)
+}
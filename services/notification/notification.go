@@ -0,0 +1,30 @@
+package notification
+
+import (
+	"circledigital.in/real-state-erp/utils/common"
+	"gorm.io/gorm"
+)
+
+type notificationService struct {
+	db *gorm.DB
+}
+
+// CreateNotificationService creates service for notification templates, the notification log and opt outs
+// notifications are written by other services with utils/notification and sent by its sender
+func CreateNotificationService(app common.IApp) common.IService {
+	return &notificationService{
+		db: app.GetDBClient(),
+	}
+}
+
+// unsubscribeService lets buyers opt out with the signed link sent in notifications
+type unsubscribeService struct {
+	db *gorm.DB
+}
+
+// CreateUnsubscribeService creates the public unsubscribe service, the link token authenticates the request
+func CreateUnsubscribeService(app common.IApp) common.IService {
+	return &unsubscribeService{
+		db: app.GetDBClient(),
+	}
+}
@@ -0,0 +1,155 @@
+package notification
+
+import (
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	appNotification "circledigital.in/real-state-erp/utils/notification"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// optOut records that the recipient receives no more notifications, opting out again keeps the first record
+func optOut(db *gorm.DB, recipient appNotification.Recipient, reason, createdBy string) (*models.NotificationOptOut, error) {
+	record := models.NotificationOptOut{
+		OrgId:     recipient.OrgId,
+		Channel:   recipient.Channel,
+		Address:   recipient.Address,
+		Reason:    reason,
+		CreatedBy: createdBy,
+	}
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error
+	return &record, err
+}
+
+func (s *notificationService) getOptOuts(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	cursor := r.URL.Query().Get("cursor")
+
+	query := s.db.Where("org_id = ?", orgId)
+	if channel := strings.TrimSpace(r.URL.Query().Get("channel")); channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+	query = query.Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	var optOuts []models.NotificationOptOut
+	err := query.Find(&optOuts).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = common.CreatePaginatedResponse(&optOuts)
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+type hCreateOptOut struct {
+	Channel string `validate:"required"`
+	Address string `validate:"required"`
+	Reason  string
+}
+
+func (h *hCreateOptOut) execute(db *gorm.DB, orgId, createdBy string) (*models.NotificationOptOut, error) {
+	channel := custom.NotificationChannel(h.Channel)
+	if !channel.IsValid() {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid notification channel.",
+		}
+	}
+
+	return optOut(db, appNotification.Recipient{
+		OrgId:   uuid.MustParse(orgId),
+		Channel: channel,
+		Address: appNotification.NormalizeAddress(channel, h.Address),
+	}, h.Reason, createdBy)
+}
+
+func (s *notificationService) createOptOut(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	reqBody := payload.ValidateAndDecodeRequest[hCreateOptOut](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	record, err := reqBody.execute(s.db, orgId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Recipient will not receive notifications."
+	response.Data = record
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
+
+// deleteOptOut lets the recipient receive notifications again, already skipped notifications are not sent
+func (s *notificationService) deleteOptOut(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	channel := custom.NotificationChannel(chi.URLParam(r, "channel"))
+	address := appNotification.NormalizeAddress(channel, chi.URLParam(r, "address"))
+
+	result := s.db.Where("org_id = ? and channel = ? and address = ?", orgId, channel, address).
+		Delete(&models.NotificationOptOut{})
+	if result.Error != nil {
+		payload.HandleError(w, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Opt out not found.",
+		})
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Recipient will receive notifications again."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// unsubscribe opts out the recipient of the signed link, the link is valid until the secret changes
+func (s *unsubscribeService) unsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+	recipient, err := appNotification.ParseUnsubscribeToken(appNotification.Secret(), token)
+	if err != nil {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid or expired unsubscribe link.",
+		})
+		return
+	}
+
+	_, err = optOut(s.db, recipient, "unsubscribed from link", recipient.Address)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "You will no longer receive these notifications."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,47 @@
+package notification
+
+import (
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *notificationService) GetBasePath() string {
+	return "/notification"
+}
+
+func (s *notificationService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
+
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+
+		router.With(permission(custom.PERMISSION_NOTIFICATION_VIEW)).Get("/template", s.getNotificationTemplates)
+		router.With(permission(custom.PERMISSION_NOTIFICATION_MANAGE)).Put("/template/{kind}/{channel}", s.updateNotificationTemplate)
+		router.With(permission(custom.PERMISSION_NOTIFICATION_MANAGE)).Delete("/template/{kind}/{channel}", s.resetNotificationTemplate)
+
+		router.With(permission(custom.PERMISSION_NOTIFICATION_VIEW)).Get("/log", s.getNotificationLog)
+		router.With(permission(custom.PERMISSION_NOTIFICATION_MANAGE)).Post("/log/{notificationId}/retry", s.retryNotification)
+
+		router.With(permission(custom.PERMISSION_NOTIFICATION_VIEW)).Get("/opt-out", s.getOptOuts)
+		router.With(permission(custom.PERMISSION_NOTIFICATION_MANAGE)).Post("/opt-out", s.createOptOut)
+		router.With(permission(custom.PERMISSION_NOTIFICATION_MANAGE)).Delete("/opt-out/{channel}/{address}", s.deleteOptOut)
+	})
+
+	return mux
+}
+
+func (s *unsubscribeService) GetBasePath() string {
+	return "/unsubscribe"
+}
+
+func (s *unsubscribeService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+
+	mux.Get("/", s.unsubscribe)
+	mux.Post("/", s.unsubscribe)
+
+	return mux
+}
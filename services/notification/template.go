@@ -0,0 +1,172 @@
+package notification
+
+import (
+	"net/http"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	appNotification "circledigital.in/real-state-erp/utils/notification"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// templateView is the template used for a notification, default template when the organization has not customized it
+type templateView struct {
+	Kind      custom.NotificationKind    `json:"kind"`
+	Channel   custom.NotificationChannel `json:"channel"`
+	Subject   string                     `json:"subject,omitempty"`
+	Body      string                     `json:"body"`
+	Enabled   bool                       `json:"enabled"`
+	Custom    bool                       `json:"custom"`
+	UpdatedBy string                     `json:"updatedBy,omitempty"`
+	UpdatedAt *time.Time                 `json:"updatedAt,omitempty"`
+}
+
+// parseTemplateParams validates the kind and channel route params
+func parseTemplateParams(r *http.Request) (custom.NotificationKind, custom.NotificationChannel, error) {
+	kind := custom.NotificationKind(chi.URLParam(r, "kind"))
+	channel := custom.NotificationChannel(chi.URLParam(r, "channel"))
+	if !kind.IsValid() || !channel.IsValid() {
+		return "", "", &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Notification template not found.",
+		}
+	}
+	return kind, channel, nil
+}
+
+func (s *notificationService) getNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	var overrides []models.NotificationTemplate
+	err := s.db.Where("org_id = ?", orgId).Find(&overrides).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	overridden := make(map[string]models.NotificationTemplate, len(overrides))
+	for _, override := range overrides {
+		overridden[string(override.Kind)+"/"+string(override.Channel)] = override
+	}
+
+	templates := make([]templateView, 0, len(custom.AllNotificationKinds)*len(appNotification.Channels))
+	for _, kind := range custom.AllNotificationKinds {
+		for _, channel := range appNotification.Channels {
+			view := templateView{Kind: kind, Channel: channel, Enabled: true}
+			if override, ok := overridden[string(kind)+"/"+string(channel)]; ok {
+				view.Subject = override.Subject
+				view.Body = override.Body
+				view.Enabled = override.Enabled
+				view.Custom = true
+				view.UpdatedBy = override.UpdatedBy
+				view.UpdatedAt = &override.UpdatedAt
+			} else {
+				tmpl := appNotification.DefaultTemplates[kind][channel]
+				view.Subject = tmpl.Subject
+				view.Body = tmpl.Body
+			}
+			templates = append(templates, view)
+		}
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = templates
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// hUpdateNotificationTemplate replaces the template of a notification for the organization
+// template is rendered with sample data before it is saved, disabling a template stops the notification on its channel
+type hUpdateNotificationTemplate struct {
+	Subject string
+	Body    string
+	Enabled *bool
+}
+
+func (h *hUpdateNotificationTemplate) execute(db *gorm.DB, orgId, updatedBy string, kind custom.NotificationKind, channel custom.NotificationChannel) (*models.NotificationTemplate, error) {
+	tmpl := appNotification.Template{Subject: h.Subject, Body: h.Body}
+	if channel == custom.CHANNEL_SMS {
+		tmpl.Subject = ""
+	}
+	if err := appNotification.Validate(channel, tmpl); err != nil {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid template: " + err.Error(),
+		}
+	}
+
+	enabled := true
+	if h.Enabled != nil {
+		enabled = *h.Enabled
+	}
+
+	template := models.NotificationTemplate{
+		OrgId:     uuid.MustParse(orgId),
+		Kind:      kind,
+		Channel:   channel,
+		Subject:   tmpl.Subject,
+		Body:      tmpl.Body,
+		Enabled:   enabled,
+		UpdatedBy: updatedBy,
+	}
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "org_id"}, {Name: "kind"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "body", "enabled", "updated_by", "updated_at"}),
+	}).Create(&template).Error
+	return &template, err
+}
+
+func (s *notificationService) updateNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	kind, channel, err := parseTemplateParams(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	reqBody := payload.ValidateAndDecodeRequest[hUpdateNotificationTemplate](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	template, err := reqBody.execute(s.db, orgId, userEmail, kind, channel)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated notification template."
+	response.Data = template
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// resetNotificationTemplate removes the template of the organization so the default template is used again
+func (s *notificationService) resetNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	kind, channel, err := parseTemplateParams(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	err = s.db.Where("org_id = ? and kind = ? and channel = ?", orgId, kind, channel).
+		Delete(&models.NotificationTemplate{}).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Notification template reset to default."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,190 @@
+package organization
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/identity"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// hCreateApiKey creates api key of the organization
+// scopes and societies are limited to the access of the creator, empty societies allow every society
+type hCreateApiKey struct {
+	Name      string   `validate:"required"`
+	Scopes    []string `validate:"required,min=1"`
+	Societies []string
+	ExpiresAt *time.Time
+	access    *custom.UserAccess
+}
+
+func (h *hCreateApiKey) validate(db *gorm.DB, orgId string) (models.PermissionList, error) {
+	scopes, err := validatePermissions(h.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if !h.access.Can(scope) {
+			return nil, &custom.RequestError{
+				Status:  http.StatusForbidden,
+				Message: "Api key can not have permission the creator lacks: " + string(scope),
+			}
+		}
+	}
+
+	if h.ExpiresAt != nil && !h.ExpiresAt.After(time.Now()) {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Api key expiry must be in future.",
+		}
+	}
+
+	if len(h.Societies) == 0 {
+		if h.access.Societies != nil {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Societies are required for api key created by user restricted to societies.",
+			}
+		}
+		return scopes, nil
+	}
+
+	for _, society := range h.Societies {
+		if !h.access.CanAccessSociety(society) {
+			return nil, &custom.RequestError{
+				Status:  http.StatusForbidden,
+				Message: "Api key can not access society the creator is not member of: " + society,
+			}
+		}
+	}
+	var count int64
+	err = db.Model(&models.Society{}).Where("org_id = ? and rera_number IN ?", orgId, h.Societies).Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if int(count) != len(h.Societies) {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid society in api key.",
+		}
+	}
+	return scopes, nil
+}
+
+// createdApiKey is the new api key, the key is returned only once
+type createdApiKey struct {
+	*models.ApiKey
+	Key string `json:"key"`
+}
+
+func (h *hCreateApiKey) execute(db *gorm.DB, orgId, createdBy string) (*createdApiKey, error) {
+	scopes, err := h.validate(db, orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	key, prefix, keyHash, err := identity.NewApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := models.ApiKey{
+		OrgId:     uuid.MustParse(orgId),
+		Name:      strings.TrimSpace(h.Name),
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopes,
+		Societies: models.StringList(h.Societies),
+		ExpiresAt: h.ExpiresAt,
+		CreatedBy: createdBy,
+	}
+	err = db.Create(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &createdApiKey{
+		ApiKey: &apiKey,
+		Key:    key,
+	}, nil
+}
+
+func (s *organizationService) createApiKey(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	reqBody := payload.ValidateAndDecodeRequest[hCreateApiKey](w, r)
+	if reqBody == nil {
+		return
+	}
+	reqBody.access, _ = r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	apiKey, err := reqBody.execute(s.db, orgId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully created api key. Store the key, it is not shown again."
+	response.Data = apiKey
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
+
+func (s *organizationService) getApiKeys(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	var apiKeys []models.ApiKey
+	err := s.db.Where("org_id = ?", orgId).Order("created_at DESC").Find(&apiKeys).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = apiKeys
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// revokeApiKey revokes the key, revoked keys are kept so audit logs keep referring to them
+func (s *organizationService) revokeApiKey(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	apiKeyId := chi.URLParam(r, "apiKeyId")
+	if _, err := uuid.Parse(apiKeyId); err != nil {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid api key id.",
+		})
+		return
+	}
+
+	result := s.db.Model(&models.ApiKey{}).
+		Where("id = ? and org_id = ? and revoked_at is null", apiKeyId, orgId).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		payload.HandleError(w, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Api key not found.",
+		})
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully revoked api key."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -2,9 +2,9 @@ package organization
 
 import (
 	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
-	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/go-chi/chi/v5"
 	"gorm.io/gorm"
 	"net/http"
@@ -12,7 +12,7 @@ import (
 
 type hRemoveUserFromOrganization struct{}
 
-func (h *hRemoveUserFromOrganization) execute(db *gorm.DB, cognito *cognitoidentityprovider.Client, user, orgId, userPool string) error {
+func (h *hRemoveUserFromOrganization) execute(db *gorm.DB, identity common.IdentityProvider, user, orgId string) error {
 	return db.Transaction(func(tx *gorm.DB) error {
 		userModel := models.User{
 			Email: user,
@@ -27,7 +27,7 @@ func (h *hRemoveUserFromOrganization) execute(db *gorm.DB, cognito *cognitoident
 				Message: "user not found",
 			}
 		}
-		return deleteUserFromCognito(cognito, user, userPool)
+		return identity.DeleteUser(user)
 	})
 }
 
@@ -36,7 +36,7 @@ func (s *organizationService) removeUserFromOrganization(w http.ResponseWriter,
 	user := chi.URLParam(r, "userEmail")
 
 	organization := hRemoveUserFromOrganization{}
-	err := organization.execute(s.db, s.cognito, user, orgId, s.userPool)
+	err := organization.execute(s.db, s.identity, user, orgId)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
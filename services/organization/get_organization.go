@@ -54,7 +54,8 @@ type hGetAllOrganizationUsers struct{}
 
 func (h *hGetAllOrganizationUsers) execute(db *gorm.DB, orgId, cursor string) (*custom.PaginatedData, error) {
 	var userData []models.User
-	query := db.Where("org_id = ?", orgId).Order("created_at DESC").Limit(custom.LIMIT + 1)
+	query := db.Preload("AccessRole").Preload("Societies").
+		Where("org_id = ?", orgId).Order("created_at DESC").Limit(custom.LIMIT + 1)
 	if strings.TrimSpace(cursor) != "" {
 		decodedCursor, err := common.DecodeCursor(cursor)
 		if err == nil {
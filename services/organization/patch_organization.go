@@ -2,9 +2,9 @@ package organization
 
 import (
 	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
-	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -133,7 +133,7 @@ func (h *hUpdateOrganizationUserRole) validate() error {
 	return nil
 }
 
-func (h *hUpdateOrganizationUserRole) execute(db *gorm.DB, cognito *cognitoidentityprovider.Client, user, orgId, userPool string) error {
+func (h *hUpdateOrganizationUserRole) execute(db *gorm.DB, identity common.IdentityProvider, user, orgId string) error {
 	err := h.validate()
 	if err != nil {
 		return err
@@ -155,15 +155,8 @@ func (h *hUpdateOrganizationUserRole) execute(db *gorm.DB, cognito *cognitoident
 			}
 		}
 
-		// update cognito
-		err := removeUserFromGroup(cognito, user, userPool)
-		if err != nil {
-			return err
-		}
-
-		// add user to a new group
-		err = addUserToGroup(cognito, user, h.Role, userPool)
-		return err
+		// update role with the identity provider
+		return identity.UpdateRole(user, custom.UserRole(h.Role))
 	})
 }
 
@@ -175,7 +168,7 @@ func (s *organizationService) updateOrganizationUserRole(w http.ResponseWriter,
 		return
 	}
 
-	err := reqBody.execute(s.db, s.cognito, user, orgId, s.userPool)
+	err := reqBody.execute(s.db, s.identity, user, orgId)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
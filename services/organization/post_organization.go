@@ -2,9 +2,9 @@ package organization
 
 import (
 	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
-	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"net/http"
@@ -16,22 +16,21 @@ type hCreateOrganization struct {
 	Email string `validate:"required,email"`
 }
 
-func (h *hCreateOrganization) execute(db *gorm.DB, cognito *cognitoidentityprovider.Client, userPool string) (*models.Organization, error) {
+// createdOrganization is the new organization with invite token of its admin
+type createdOrganization struct {
+	*models.Organization
+	InviteToken string `json:"inviteToken,omitempty"`
+}
+
+func (h *hCreateOrganization) execute(db *gorm.DB, identity common.IdentityProvider) (*createdOrganization, error) {
 	organization := models.Organization{
 		Status: custom.ACTIVE,
 		Name:   h.Name,
 	}
 
+	var inviteToken string
 	// perform db transaction for atomicity
 	err := db.Transaction(func(tx *gorm.DB) error {
-		// check if user exists or not
-		if userExists(cognito, h.Email, userPool) {
-			return &custom.RequestError{
-				Status:  http.StatusBadRequest,
-				Message: "User already exists.",
-			}
-		}
-
 		// create organization
 		result := tx.Create(&organization)
 		if result.Error != nil {
@@ -49,31 +48,19 @@ func (h *hCreateOrganization) execute(db *gorm.DB, cognito *cognitoidentityprovi
 			return result.Error
 		}
 
-		// create user credentials
-		err := createUserInCognito(cognito, h.Email, organization.Id.String(), userPool)
-		if err != nil {
-			return err
-		}
-
-		// add user to a group
-		err = addUserToGroup(cognito, h.Email, string(custom.ORGADMIN), userPool)
-		if err != nil {
-			// clean up from cognito
-			go func() {
-				err := deleteUserFromCognito(cognito, h.Email, userPool)
-				if err != nil {
-					return
-				}
-			}()
-			return err
-		}
-		return nil
+		// create user login
+		var err error
+		inviteToken, err = createUserLogin(identity, h.Email, organization.Id.String(), custom.ORGADMIN)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &organization, nil
+	return &createdOrganization{
+		Organization: &organization,
+		InviteToken:  inviteToken,
+	}, nil
 }
 
 func (s *organizationService) createOrganization(w http.ResponseWriter, r *http.Request) {
@@ -82,7 +69,7 @@ func (s *organizationService) createOrganization(w http.ResponseWriter, r *http.
 		return
 	}
 
-	org, err := reqBody.execute(s.db, s.cognito, s.userPool)
+	org, err := reqBody.execute(s.db, s.identity)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
@@ -101,18 +88,17 @@ type hAddUserToOrganization struct {
 	Email string `validate:"required,email"`
 }
 
-func (h *hAddUserToOrganization) execute(db *gorm.DB, cognito *cognitoidentityprovider.Client, orgId, userPool string) error {
-	return db.Transaction(func(tx *gorm.DB) error {
-		// check if user exists or not
-		if userExists(cognito, h.Email, userPool) {
-			return &custom.RequestError{
-				Status:  http.StatusBadRequest,
-				Message: "User already exists.",
-			}
-		}
+// addedUser is the invite token of the added user when the identity provider does not deliver invites
+type addedUser struct {
+	Email       string `json:"email"`
+	InviteToken string `json:"inviteToken,omitempty"`
+}
 
+func (h *hAddUserToOrganization) execute(db *gorm.DB, identity common.IdentityProvider, orgId string) (*addedUser, error) {
+	var inviteToken string
+	err := db.Transaction(func(tx *gorm.DB) error {
 		// create user
-		result := db.Create(&models.User{
+		result := tx.Create(&models.User{
 			OrgId: uuid.MustParse(orgId),
 			Name:  h.Email,
 			Email: h.Email,
@@ -122,27 +108,19 @@ func (h *hAddUserToOrganization) execute(db *gorm.DB, cognito *cognitoidentitypr
 			return result.Error
 		}
 
-		// create user credentials
-		err := createUserInCognito(cognito, h.Email, orgId, userPool)
-		if err != nil {
-			return err
-		}
-
-		// add user to a group
-		err = addUserToGroup(cognito, h.Email, string(custom.ORGUSER), userPool)
-		if err != nil {
-			// clean up from cognito
-			go func() {
-				err := deleteUserFromCognito(cognito, h.Email, userPool)
-				if err != nil {
-					return
-				}
-			}()
-			return err
-		}
-
-		return nil
+		// create user login
+		var err error
+		inviteToken, err = createUserLogin(identity, h.Email, orgId, custom.ORGUSER)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &addedUser{
+		Email:       h.Email,
+		InviteToken: inviteToken,
+	}, nil
 }
 
 func (s *organizationService) addUserToOrganization(w http.ResponseWriter, r *http.Request) {
@@ -152,7 +130,7 @@ func (s *organizationService) addUserToOrganization(w http.ResponseWriter, r *ht
 		return
 	}
 
-	err := reqBody.execute(s.db, s.cognito, orgId, s.userPool)
+	user, err := reqBody.execute(s.db, s.identity, orgId)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
@@ -161,6 +139,7 @@ func (s *organizationService) addUserToOrganization(w http.ResponseWriter, r *ht
 	var response custom.JSONResponse
 	response.Error = false
 	response.Message = "Successfully added user to organization."
+	response.Data = user
 
 	payload.EncodeJSON(w, http.StatusCreated, response)
 }
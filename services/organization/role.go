@@ -0,0 +1,205 @@
+package organization
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var roleNotFoundError = &custom.RequestError{
+	Status:  http.StatusNotFound,
+	Message: "Role not found.",
+}
+
+// validatePermissions returns the permissions as a list, duplicates are removed
+func validatePermissions(permissions []string) (models.PermissionList, error) {
+	list := make(models.PermissionList, 0, len(permissions))
+	seen := make(map[custom.Permission]bool)
+	for _, p := range permissions {
+		permission := custom.Permission(strings.TrimSpace(p))
+		if !permission.IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid permission: " + p,
+			}
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			list = append(list, permission)
+		}
+	}
+	return list, nil
+}
+
+// saveRole saves the role, duplicate role names of an organization are rejected
+func saveRole(db *gorm.DB, role *models.Role) error {
+	err := db.Save(role).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Role with same name already exists.",
+		}
+	}
+	return err
+}
+
+type hCreateRole struct {
+	Name        string `validate:"required"`
+	Description string
+	Permissions []string `validate:"required,min=1"`
+}
+
+func (h *hCreateRole) execute(db *gorm.DB, orgId string) (*models.Role, error) {
+	permissions, err := validatePermissions(h.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := models.Role{
+		OrgId:       uuid.MustParse(orgId),
+		Name:        strings.TrimSpace(h.Name),
+		Description: h.Description,
+		Permissions: permissions,
+	}
+	return &role, saveRole(db, &role)
+}
+
+func (s *organizationService) createRole(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	reqBody := payload.ValidateAndDecodeRequest[hCreateRole](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	role, err := reqBody.execute(s.db, orgId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully created role."
+	response.Data = role
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
+
+// hUpdateRole updates the provided fields of a role, permissions replace the existing permissions
+type hUpdateRole struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+func (h *hUpdateRole) execute(db *gorm.DB, orgId, roleId string) (*models.Role, error) {
+	var role models.Role
+	err := db.Where("id = ? and org_id = ?", roleId, orgId).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, roleNotFoundError
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(h.Name) != "" {
+		role.Name = strings.TrimSpace(h.Name)
+	}
+	if strings.TrimSpace(h.Description) != "" {
+		role.Description = h.Description
+	}
+	if h.Permissions != nil {
+		role.Permissions, err = validatePermissions(h.Permissions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &role, saveRole(db, &role)
+}
+
+func (s *organizationService) updateRole(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	roleId := chi.URLParam(r, "roleId")
+	reqBody := payload.ValidateAndDecodeRequest[hUpdateRole](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	role, err := reqBody.execute(s.db, orgId, roleId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated role."
+	response.Data = role
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// deleteRole deletes the role, its users fall back to the default permissions of their role
+func (s *organizationService) deleteRole(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	roleId := chi.URLParam(r, "roleId")
+
+	result := s.db.Where("id = ? and org_id = ?", roleId, orgId).Delete(&models.Role{})
+	if result.Error != nil {
+		payload.HandleError(w, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		payload.HandleError(w, roleNotFoundError)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully deleted role."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *organizationService) getRoles(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	var roles []models.Role
+	err := s.db.Where("org_id = ?", orgId).Order("name").Find(&roles).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = roles
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// permissionCatalog is every permission with the default permissions of the fixed roles
+type permissionCatalog struct {
+	Permissions []custom.Permission                     `json:"permissions"`
+	Defaults    map[custom.UserRole][]custom.Permission `json:"defaults"`
+}
+
+func (s *organizationService) getPermissions(w http.ResponseWriter, r *http.Request) {
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = permissionCatalog{
+		Permissions: custom.AllPermissions,
+		Defaults:    custom.DefaultRolePermissions,
+	}
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
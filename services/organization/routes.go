@@ -1,6 +1,7 @@
 package organization
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,6 +13,7 @@ func (s *organizationService) GetBasePath() string {
 func (s *organizationService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
 
 	// admin role routes
 	mux.Group(func(router chi.Router) {
@@ -22,24 +24,29 @@ func (s *organizationService) GetRoutes() *chi.Mux {
 		router.Get("/", s.getAllOrganizations)
 	})
 
-	// organization admin routes
+	// organization routes
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Post("/user", s.addUserToOrganization)
-		router.Patch("/details", s.updateOrganizationDetails)
-		router.Patch("/user/{userEmail}", s.updateOrganizationUserRole)
-		router.Get("/users", s.getAllOrganizationUsers)
-		router.Delete("/user/{userEmail}", s.removeUserFromOrganization)
-	})
-
-	// organization user and admin route
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
-
-		router.Get("/self", s.getCurrentUserOrganization)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Post("/user", s.addUserToOrganization)
+		router.With(permission(custom.PERMISSION_ORGANIZATION_MANAGE)).Patch("/details", s.updateOrganizationDetails)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Patch("/user/{userEmail}", s.updateOrganizationUserRole)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Put("/user/{userEmail}/access", s.updateOrganizationUserAccess)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Post("/user/{userEmail}/password-reset", s.resetOrganizationUserPassword)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Get("/users", s.getAllOrganizationUsers)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Delete("/user/{userEmail}", s.removeUserFromOrganization)
+
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Get("/permission", s.getPermissions)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Get("/role", s.getRoles)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Post("/role", s.createRole)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Patch("/role/{roleId}", s.updateRole)
+		router.With(permission(custom.PERMISSION_USER_MANAGE)).Delete("/role/{roleId}", s.deleteRole)
+
+		router.With(permission(custom.PERMISSION_API_KEY_MANAGE)).Get("/api-key", s.getApiKeys)
+		router.With(permission(custom.PERMISSION_API_KEY_MANAGE)).Post("/api-key", s.createApiKey)
+		router.With(permission(custom.PERMISSION_API_KEY_MANAGE)).Delete("/api-key/{apiKeyId}", s.revokeApiKey)
+
+		router.With(permission(custom.PERMISSION_ORGANIZATION_VIEW)).Get("/self", s.getCurrentUserOrganization)
 	})
 
 	return mux
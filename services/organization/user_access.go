@@ -0,0 +1,130 @@
+package organization
+
+import (
+	"errors"
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// hUpdateUserAccess assigns custom role and society membership to a user
+// empty role id removes the custom role, societies replace the existing membership
+type hUpdateUserAccess struct {
+	RoleId            string `validate:"omitempty,uuid"`
+	RestrictSocieties bool
+	Societies         []string
+}
+
+func (h *hUpdateUserAccess) validate(db *gorm.DB, orgId string) error {
+	if h.RoleId != "" {
+		var count int64
+		err := db.Model(&models.Role{}).Where("id = ? and org_id = ?", h.RoleId, orgId).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return roleNotFoundError
+		}
+	}
+
+	if len(h.Societies) > 0 {
+		var count int64
+		err := db.Model(&models.Society{}).Where("org_id = ? and rera_number IN ?", orgId, h.Societies).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if int(count) != len(h.Societies) {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid society in user access.",
+			}
+		}
+	}
+	return nil
+}
+
+func (h *hUpdateUserAccess) execute(db *gorm.DB, orgId, userEmail string) (*models.User, error) {
+	err := h.validate(db, orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err = db.Where("email = ? and org_id = ?", userEmail, orgId).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "user not found",
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var roleId *uuid.UUID
+	if h.RoleId != "" {
+		id := uuid.MustParse(h.RoleId)
+		roleId = &id
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&user).Updates(map[string]any{
+			"role_id":            roleId,
+			"restrict_societies": h.RestrictSocieties,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Where("user_email = ?", userEmail).Delete(&models.UserSociety{}).Error
+		if err != nil {
+			return err
+		}
+		if len(h.Societies) == 0 {
+			return nil
+		}
+
+		memberships := make([]models.UserSociety, 0, len(h.Societies))
+		for _, society := range h.Societies {
+			memberships = append(memberships, models.UserSociety{
+				UserEmail: userEmail,
+				SocietyId: society,
+				OrgId:     user.OrgId,
+			})
+		}
+		return tx.Create(&memberships).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Preload("AccessRole").Preload("Societies").First(&user, "email = ?", userEmail).Error
+	return &user, err
+}
+
+func (s *organizationService) updateOrganizationUserAccess(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	user := chi.URLParam(r, "userEmail")
+	reqBody := payload.ValidateAndDecodeRequest[hUpdateUserAccess](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	userModel, err := reqBody.execute(s.db, orgId, user)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated user access."
+	response.Data = userModel
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,52 @@
+package organization
+
+import (
+	"errors"
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// passwordReset is the user whose password reset is started, the reset token is delivered to the user only
+type passwordReset struct {
+	Email string `json:"email"`
+}
+
+// resetOrganizationUserPassword starts password reset of a user of the organization,
+// the identity provider delivers the token so that the caller can't log in as the user
+func (s *organizationService) resetOrganizationUserPassword(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	user := chi.URLParam(r, "userEmail")
+
+	err := s.db.Where("email = ? and org_id = ?", user, orgId).First(&models.User{}).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "user not found",
+		})
+		return
+	}
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	err = s.identity.ResetPassword(user)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully started password reset."
+	response.Data = passwordReset{
+		Email: user,
+	}
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
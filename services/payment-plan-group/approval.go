@@ -0,0 +1,83 @@
+package payment_plan_group
+
+import (
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/approval"
+	"circledigital.in/real-state-erp/utils/custom"
+	"gorm.io/gorm"
+)
+
+// activationApproval is the payload of a payment plan item activation pending approval
+// effective date is fixed when submitted so approval later does not shift the due date
+type activationApproval struct {
+	PaymentPlanItemId string
+	TowerId           string `json:",omitempty"`
+	FlatId            string `json:",omitempty"`
+	EffectiveDate     custom.DateOnly
+	CreditPeriod      int
+}
+
+func (h *hMarkPaymentPlanActiveForTower) submit(db *gorm.DB, orgId, society, paymentId, towerId, requestedBy string) (*models.ApprovalRequest, error) {
+	err := h.validate(db, orgId, society, paymentId, towerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return approval.Submit(db, approval.Submission{
+		OrgId:    orgId,
+		Society:  society,
+		Action:   custom.APPROVAL_PAYMENT_PLAN_EDIT,
+		EntityId: towerId,
+		Payload: &activationApproval{
+			PaymentPlanItemId: paymentId,
+			TowerId:           towerId,
+			EffectiveDate:     custom.DateOnly{Time: h.getEffectiveDate()},
+			CreditPeriod:      h.CreditPeriod,
+		},
+		RequestedBy: requestedBy,
+	})
+}
+
+func (h *hMarkPaymentPlanActiveForFlat) submit(db *gorm.DB, orgId, society, paymentId, flatId, requestedBy string) (*models.ApprovalRequest, error) {
+	err := h.validate(db, orgId, society, paymentId, flatId)
+	if err != nil {
+		return nil, err
+	}
+
+	return approval.Submit(db, approval.Submission{
+		OrgId:    orgId,
+		Society:  society,
+		Action:   custom.APPROVAL_PAYMENT_PLAN_EDIT,
+		EntityId: flatId,
+		Payload: &activationApproval{
+			PaymentPlanItemId: paymentId,
+			FlatId:            flatId,
+			EffectiveDate:     custom.DateOnly{Time: h.getEffectiveDate()},
+			CreditPeriod:      h.CreditPeriod,
+		},
+		RequestedBy: requestedBy,
+	})
+}
+
+// executeApprovedActivation activates the payment plan item of an approved request for its tower or flat
+func executeApprovedActivation(db *gorm.DB, request *models.ApprovalRequest) error {
+	activation := activationApproval{}
+	if err := approval.DecodePayload(request, &activation); err != nil {
+		return err
+	}
+
+	orgId := request.OrgId.String()
+	if activation.TowerId != "" {
+		towerPayment := hMarkPaymentPlanActiveForTower{
+			EffectiveDate: activation.EffectiveDate,
+			CreditPeriod:  activation.CreditPeriod,
+		}
+		return towerPayment.execute(db, orgId, request.SocietyId, activation.PaymentPlanItemId, activation.TowerId)
+	}
+
+	flatPayment := hMarkPaymentPlanActiveForFlat{
+		EffectiveDate: activation.EffectiveDate,
+		CreditPeriod:  activation.CreditPeriod,
+	}
+	return flatPayment.execute(db, orgId, request.SocietyId, activation.PaymentPlanItemId, activation.FlatId)
+}
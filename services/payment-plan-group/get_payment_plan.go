@@ -3,6 +3,7 @@ package payment_plan_group
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"circledigital.in/real-state-erp/models"
 	"circledigital.in/real-state-erp/utils/common"
@@ -68,7 +69,7 @@ func (s *paymentPlanService) getPaymentPlan(w http.ResponseWriter, r *http.Reque
 
 type hGetTowerPaymentPlans struct{}
 
-func (h *hGetTowerPaymentPlans) execute(db *gorm.DB, orgId, society, towerId, cursor string) (*custom.PaginatedData, error) {
+func (h *hGetTowerPaymentPlans) execute(db *gorm.DB, orgId, society, towerId, cursor string, asOf time.Time) (*custom.PaginatedData, error) {
 	var paymentPlans []models.PaymentPlanGroup
 
 	// Load groups with ratios and only tower-scoped items
@@ -109,19 +110,23 @@ func (h *hGetTowerPaymentPlans) execute(db *gorm.DB, orgId, society, towerId, cu
 		return nil, err
 	}
 
-	// Map active items
-	activeMap := make(map[uuid.UUID]bool)
+	// Map activated items
+	activationMap := make(map[uuid.UUID]models.PaymentActivation)
 	for _, st := range statuses {
-		activeMap[st.PaymentId] = true
+		activationMap[st.PaymentId] = st.GetActivation()
 	}
 
-	// Mark active items
+	// Mark items active as of the requested date
 	for gi := range paymentPlans {
 		for ri := range paymentPlans[gi].Ratios {
 			for ii := range paymentPlans[gi].Ratios[ri].Ratios {
 				item := &paymentPlans[gi].Ratios[ri].Ratios[ii]
-				val := activeMap[item.Id]
+				activation, ok := activationMap[item.Id]
+				val := ok && activation.IsEffective(asOf)
 				item.Active = &val
+				if ok {
+					item.Activation = &activation
+				}
 			}
 		}
 	}
@@ -135,8 +140,14 @@ func (s *paymentPlanService) getTowerPaymentPlan(w http.ResponseWriter, r *http.
 	societyRera := chi.URLParam(r, "society")
 	towerId := chi.URLParam(r, "towerId")
 
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
 	paymentPlans := hGetTowerPaymentPlans{}
-	res, err := paymentPlans.execute(s.db, orgId, societyRera, towerId, cursor)
+	res, err := paymentPlans.execute(s.db, orgId, societyRera, towerId, cursor, asOf)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
@@ -152,7 +163,7 @@ func (s *paymentPlanService) getTowerPaymentPlan(w http.ResponseWriter, r *http.
 
 type hGetFlatPaymentPlans struct{}
 
-func (h *hGetFlatPaymentPlans) execute(db *gorm.DB, orgId, society, flatID, cursor string) (*custom.PaginatedData, error) {
+func (h *hGetFlatPaymentPlans) execute(db *gorm.DB, orgId, society, flatID, cursor string, asOf time.Time) (*custom.PaginatedData, error) {
 	var paymentPlans []models.PaymentPlanGroup
 
 	// Load groups with ratios and only flat-scoped items
@@ -193,19 +204,23 @@ func (h *hGetFlatPaymentPlans) execute(db *gorm.DB, orgId, society, flatID, curs
 		return nil, err
 	}
 
-	// Map active items
-	activeMap := make(map[uuid.UUID]bool)
+	// Map activated items
+	activationMap := make(map[uuid.UUID]models.PaymentActivation)
 	for _, st := range statuses {
-		activeMap[st.PaymentId] = true
+		activationMap[st.PaymentId] = st.GetActivation()
 	}
 
-	// Mark active items
+	// Mark items active as of the requested date
 	for gi := range paymentPlans {
 		for ri := range paymentPlans[gi].Ratios {
 			for ii := range paymentPlans[gi].Ratios[ri].Ratios {
 				item := &paymentPlans[gi].Ratios[ri].Ratios[ii]
-				val := activeMap[item.Id]
+				activation, ok := activationMap[item.Id]
+				val := ok && activation.IsEffective(asOf)
 				item.Active = &val
+				if ok {
+					item.Activation = &activation
+				}
 			}
 		}
 	}
@@ -219,8 +234,14 @@ func (s *paymentPlanService) getFlatPaymentPlan(w http.ResponseWriter, r *http.R
 	societyRera := chi.URLParam(r, "society")
 	flatId := chi.URLParam(r, "flatId")
 
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
 	paymentPlans := hGetFlatPaymentPlans{}
-	res, err := paymentPlans.execute(s.db, orgId, societyRera, flatId, cursor)
+	res, err := paymentPlans.execute(s.db, orgId, societyRera, flatId, cursor, asOf)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
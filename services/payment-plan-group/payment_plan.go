@@ -1,7 +1,9 @@
 package payment_plan_group
 
 import (
+	"circledigital.in/real-state-erp/utils/approval"
 	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
 	"gorm.io/gorm"
 )
 
@@ -10,6 +12,8 @@ type paymentPlanService struct {
 }
 
 func CreatePaymentPlanService(app common.IApp) common.IService {
+	approval.RegisterExecutor(custom.APPROVAL_PAYMENT_PLAN_EDIT, executeApprovedActivation)
+
 	return &paymentPlanService{
 		db: app.GetDBClient(),
 	}
@@ -7,13 +7,17 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
 	"circledigital.in/real-state-erp/models"
 	"circledigital.in/real-state-erp/services/flat"
 	"circledigital.in/real-state-erp/services/tower"
+	"circledigital.in/real-state-erp/utils/approval"
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -147,7 +151,18 @@ func (s *paymentPlanService) createPaymentPlan(w http.ResponseWriter, r *http.Re
 
 func (s *paymentPlanService) addPaymentPlanRatio(w http.ResponseWriter, r *http.Request) {}
 
-type hMarkPaymentPlanActiveForTower struct{}
+type hMarkPaymentPlanActiveForTower struct {
+	EffectiveDate custom.DateOnly
+	CreditPeriod  int `validate:"gte=0"`
+}
+
+// getEffectiveDate returns the requested effective date, activation without a date is effective today
+func (h *hMarkPaymentPlanActiveForTower) getEffectiveDate() time.Time {
+	if h.EffectiveDate.IsZero() {
+		return time.Now()
+	}
+	return h.EffectiveDate.Time
+}
 
 func (h *hMarkPaymentPlanActiveForTower) validate(db *gorm.DB, orgId, society, paymentId, towerId string) error {
 	paymentUUID := uuid.MustParse(paymentId)
@@ -193,15 +208,22 @@ func (h *hMarkPaymentPlanActiveForTower) execute(db *gorm.DB, orgId, society, pa
 		return err
 	}
 
-	// insert TowerPaymentStatus (idempotent)
+	// insert TowerPaymentStatus, activating again updates the effective and due date
 	status := models.TowerPaymentStatus{
 		TowerId:   uuid.MustParse(towerId),
 		PaymentId: uuid.MustParse(paymentId),
 	}
-	if err := db.FirstOrCreate(&status, status).Error; err != nil {
-		return err
-	}
-	return nil
+	activation := models.NewPaymentActivation(h.getEffectiveDate(), h.CreditPeriod)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(status).Assign(models.TowerPaymentStatus{PaymentActivation: activation}).FirstOrCreate(&status).Error; err != nil {
+			return err
+		}
+		towerFlats := tx.Model(&models.Flat{}).Select("id").Where("tower_id = ?", towerId)
+		if err := notification.EnqueueDemandDue(tx, orgId, paymentId, activation, towerFlats); err != nil {
+			return err
+		}
+		return webhook.Publish(tx, orgId, society, custom.EVENT_PAYMENT_PLAN_ITEM_ACTIVATED, paymentId, status)
+	})
 }
 
 func (s *paymentPlanService) markPaymentPlanItemActiveForTower(w http.ResponseWriter, r *http.Request) {
@@ -209,9 +231,28 @@ func (s *paymentPlanService) markPaymentPlanItemActiveForTower(w http.ResponseWr
 	societyRera := chi.URLParam(r, "society")
 	paymentId := chi.URLParam(r, "paymentPlanItemId")
 	towerId := chi.URLParam(r, "towerId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+
+	// body is optional, activation without a body is effective today
+	towerPayment := &hMarkPaymentPlanActiveForTower{}
+	if r.ContentLength != 0 {
+		towerPayment = payload.ValidateAndDecodeRequest[hMarkPaymentPlanActiveForTower](w, r)
+		if towerPayment == nil {
+			return
+		}
+	}
 
-	towerPayment := hMarkPaymentPlanActiveForTower{}
-	err := towerPayment.execute(s.db, orgId, societyRera, paymentId, towerId)
+	pending, err := towerPayment.submit(s.db, orgId, societyRera, paymentId, towerId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	if pending != nil {
+		approval.RespondPending(w, pending)
+		return
+	}
+
+	err = towerPayment.execute(s.db, orgId, societyRera, paymentId, towerId)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
@@ -225,7 +266,18 @@ func (s *paymentPlanService) markPaymentPlanItemActiveForTower(w http.ResponseWr
 
 }
 
-type hMarkPaymentPlanActiveForFlat struct{}
+type hMarkPaymentPlanActiveForFlat struct {
+	EffectiveDate custom.DateOnly
+	CreditPeriod  int `validate:"gte=0"`
+}
+
+// getEffectiveDate returns the requested effective date, activation without a date is effective today
+func (h *hMarkPaymentPlanActiveForFlat) getEffectiveDate() time.Time {
+	if h.EffectiveDate.IsZero() {
+		return time.Now()
+	}
+	return h.EffectiveDate.Time
+}
 
 func (h *hMarkPaymentPlanActiveForFlat) validate(db *gorm.DB, orgId, society, paymentId, flatId string) error {
 	paymentUUID := uuid.MustParse(paymentId)
@@ -270,16 +322,21 @@ func (h *hMarkPaymentPlanActiveForFlat) execute(db *gorm.DB, orgId, society, pay
 		return err
 	}
 
-	// insert FlatPaymentStatus (idempotent)
+	// insert FlatPaymentStatus, activating again updates the effective and due date
 	status := models.FlatPaymentStatus{
 		FlatId:    uuid.MustParse(flatId),
 		PaymentId: uuid.MustParse(paymentId),
 	}
-	if err := db.FirstOrCreate(&status, status).Error; err != nil {
-		return err
-	}
-
-	return nil
+	activation := models.NewPaymentActivation(h.getEffectiveDate(), h.CreditPeriod)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(status).Assign(models.FlatPaymentStatus{PaymentActivation: activation}).FirstOrCreate(&status).Error; err != nil {
+			return err
+		}
+		if err := notification.EnqueueDemandDue(tx, orgId, paymentId, activation, []string{flatId}); err != nil {
+			return err
+		}
+		return webhook.Publish(tx, orgId, society, custom.EVENT_PAYMENT_PLAN_ITEM_ACTIVATED, paymentId, status)
+	})
 }
 
 func (s *paymentPlanService) markPaymentPlanItemActiveForFlat(w http.ResponseWriter, r *http.Request) {
@@ -287,9 +344,28 @@ func (s *paymentPlanService) markPaymentPlanItemActiveForFlat(w http.ResponseWri
 	societyRera := chi.URLParam(r, "society")
 	paymentId := chi.URLParam(r, "paymentPlanItemId")
 	flatId := chi.URLParam(r, "flatId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+
+	// body is optional, activation without a body is effective today
+	flatPayment := &hMarkPaymentPlanActiveForFlat{}
+	if r.ContentLength != 0 {
+		flatPayment = payload.ValidateAndDecodeRequest[hMarkPaymentPlanActiveForFlat](w, r)
+		if flatPayment == nil {
+			return
+		}
+	}
+
+	pending, err := flatPayment.submit(s.db, orgId, societyRera, paymentId, flatId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	if pending != nil {
+		approval.RespondPending(w, pending)
+		return
+	}
 
-	towerPayment := hMarkPaymentPlanActiveForFlat{}
-	err := towerPayment.execute(s.db, orgId, societyRera, paymentId, flatId)
+	err = flatPayment.execute(s.db, orgId, societyRera, paymentId, flatId)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
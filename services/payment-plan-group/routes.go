@@ -1,6 +1,7 @@
 package payment_plan_group
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,20 +13,20 @@ func (s *paymentPlanService) GetBasePath() string {
 func (s *paymentPlanService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
 
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Post("/", s.createPaymentPlan)
+		router.With(permission(custom.PERMISSION_PAYMENT_PLAN_MANAGE)).Post("/", s.createPaymentPlan)
 
-		router.Post("/{paymentPlanItemId}/tower/{towerId}", s.markPaymentPlanItemActiveForTower)
-		router.Post("/{paymentPlanItemId}/flat/{flatId}", s.markPaymentPlanItemActiveForFlat)
+		router.With(permission(custom.PERMISSION_PAYMENT_PLAN_MANAGE)).Post("/{paymentPlanItemId}/tower/{towerId}", s.markPaymentPlanItemActiveForTower)
+		router.With(permission(custom.PERMISSION_PAYMENT_PLAN_MANAGE)).Post("/{paymentPlanItemId}/flat/{flatId}", s.markPaymentPlanItemActiveForFlat)
 
-		router.Get("/", s.getPaymentPlan)
+		router.With(permission(custom.PERMISSION_PAYMENT_PLAN_VIEW)).Get("/", s.getPaymentPlan)
 
-		router.Get("/tower/{towerId}", s.getTowerPaymentPlan)
-		router.Get("/flat/{flatId}", s.getFlatPaymentPlan)
+		router.With(permission(custom.PERMISSION_PAYMENT_PLAN_VIEW)).Get("/tower/{towerId}", s.getTowerPaymentPlan)
+		router.With(permission(custom.PERMISSION_PAYMENT_PLAN_VIEW)).Get("/flat/{flatId}", s.getFlatPaymentPlan)
 	})
 
 	return mux
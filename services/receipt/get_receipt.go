@@ -33,6 +33,7 @@ func (h *hGetReceiptById) execute(db *gorm.DB, orgId, society, receiptId string)
 		Preload("Sale.Customers").
 		Preload("Sale.CompanyCustomer").
 		Preload("Sale.Broker").
+		Preload("Sale.ReferrerCustomer").
 		Preload("Sale.Flat").
 		First(&receipt, "id = ?", receiptId).
 		Error
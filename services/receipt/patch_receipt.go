@@ -6,7 +6,9 @@ import (
 	"circledigital.in/real-state-erp/models"
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -25,11 +27,32 @@ func (h *hMarkReceiptAsFailed) execute(db *gorm.DB, orgId, society, receiptId st
 		return err
 	}
 
-	return db.Model(&models.Receipt{
-		Id: uuid.MustParse(receiptId),
-	}).Updates(models.Receipt{
-		Failed: true,
-	}).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Receipt{
+			Id: uuid.MustParse(receiptId),
+		}).Updates(models.Receipt{
+			Failed: true,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		var receipt models.Receipt
+		err = tx.First(&receipt, "id = ?", receiptId).Error
+		if err != nil {
+			return err
+		}
+		err = notification.Enqueue(tx, orgId, notification.Event{
+			Kind:      custom.NOTIFY_CHEQUE_BOUNCE,
+			SaleId:    receipt.SaleId,
+			Reference: receiptId,
+			Receipt:   &receipt,
+		})
+		if err != nil {
+			return err
+		}
+		return webhook.Publish(tx, orgId, society, custom.EVENT_RECEIPT_FAILED, receiptId, receipt)
+	})
 }
 
 func (s *receiptService) markReceiptAsFailed(w http.ResponseWriter, r *http.Request) {
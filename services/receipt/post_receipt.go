@@ -8,9 +8,12 @@ import (
 	"circledigital.in/real-state-erp/models"
 	"circledigital.in/real-state-erp/services/bank"
 	"circledigital.in/real-state-erp/services/sale"
+	"circledigital.in/real-state-erp/utils/approval"
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -127,20 +130,80 @@ func (h *hCreateSaleReceipt) execute(db *gorm.DB, orgId, society, saleId string)
 		}
 	}
 
-	err = db.Create(&receiptModel).Error
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&receiptModel).Error
+		if err != nil {
+			return err
+		}
+
+		err = notification.Enqueue(tx, orgId, notification.Event{
+			Kind:      custom.NOTIFY_RECEIPT_ACKNOWLEDGEMENT,
+			SaleId:    receiptModel.SaleId,
+			Reference: receiptModel.Id.String(),
+			Receipt:   &receiptModel,
+		})
+		if err != nil {
+			return err
+		}
+		return webhook.Publish(tx, orgId, society, custom.EVENT_RECEIPT_CREATED, receiptModel.Id.String(), receiptModel)
+	})
 	return &receiptModel, err
 }
 
+// submit creates a pending approval request if an adjustment requires approval, other modes are never gated
+func (h *hCreateSaleReceipt) submit(db *gorm.DB, orgId, society, saleId, requestedBy string) (*models.ApprovalRequest, error) {
+	if custom.ReceiptMode(h.Mode) != custom.ADJUSTMENT {
+		return nil, nil
+	}
+
+	err := h.validate(db, orgId, society, saleId)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := decimal.NewFromFloat(h.TotalAmount)
+	return approval.Submit(db, approval.Submission{
+		OrgId:       orgId,
+		Society:     society,
+		Action:      custom.APPROVAL_RECEIPT_ADJUSTMENT,
+		EntityId:    saleId,
+		Amount:      &amount,
+		Payload:     h,
+		RequestedBy: requestedBy,
+	})
+}
+
+// executeApprovedAdjustment creates the adjustment receipt of an approved request
+func executeApprovedAdjustment(db *gorm.DB, request *models.ApprovalRequest) error {
+	receipt := hCreateSaleReceipt{}
+	if err := approval.DecodePayload(request, &receipt); err != nil {
+		return err
+	}
+	_, err := receipt.execute(db, request.OrgId.String(), request.SocietyId, request.EntityId)
+	return err
+}
+
 func (s *receiptService) createSaleReceipt(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
 	saleId := chi.URLParam(r, "saleId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
 
 	reqBody := payload.ValidateAndDecodeRequest[hCreateSaleReceipt](w, r)
 	if reqBody == nil {
 		return
 	}
 
+	pending, err := reqBody.submit(s.db, orgId, societyRera, saleId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	if pending != nil {
+		approval.RespondPending(w, pending)
+		return
+	}
+
 	receipt, err := reqBody.execute(s.db, orgId, societyRera, saleId)
 	if err != nil {
 		payload.HandleError(w, err)
@@ -200,27 +263,110 @@ func (h *hClearSaleReceipt) execute(db *gorm.DB, orgId, society, receiptId strin
 		BankId:    uuid.MustParse(h.BankId),
 	}
 
-	err = db.Create(&receiptClearModel).Error
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// the designated share of the amount, taxes excluded, is due to the designated account unless it cleared there,
+		// an adjustment is not realised from the allottee
+		var cleared models.Receipt
+		err := tx.Select("amount", "mode").First(&cleared, "id = ?", receiptId).Error
+		if err != nil {
+			return err
+		}
+		receiptClearModel.RequiredTransfer = decimal.Zero
+		if cleared.Mode != custom.ADJUSTMENT {
+			var clearedIn models.Bank
+			err = tx.First(&clearedIn, "id = ?", h.BankId).Error
+			if err != nil {
+				return err
+			}
+			receiptClearModel.RequiredTransfer = clearedIn.RequiredTransfer(cleared.Amount)
+		}
+
+		err = tx.Create(&receiptClearModel).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Preload("Bank").
+			First(&receiptClearModel, "receipt_id = ?", receiptClearModel.ReceiptId).
+			Error
+		if err != nil {
+			return err
+		}
+
+		var receipt models.Receipt
+		err = tx.Preload("Cleared").First(&receipt, "id = ?", receiptId).Error
+		if err != nil {
+			return err
+		}
+		err = notification.Enqueue(tx, orgId, notification.Event{
+			Kind:      custom.NOTIFY_RECEIPT_ACKNOWLEDGEMENT,
+			SaleId:    receipt.SaleId,
+			Reference: receiptId,
+			Receipt:   &receipt,
+		})
+		if err != nil {
+			return err
+		}
+		return webhook.Publish(tx, orgId, society, custom.EVENT_RECEIPT_CLEARED, receiptId, receiptClearModel)
+	})
+	return &receiptClearModel, err
+}
+
+// submit creates a pending approval request if clearing the receipt requires approval, amount is the receipt total
+func (h *hClearSaleReceipt) submit(db *gorm.DB, orgId, society, receiptId, requestedBy string) (*models.ApprovalRequest, error) {
+	err := h.validate(db, orgId, society, receiptId)
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Preload("Bank").
-		First(&receiptClearModel, "receipt_id = ?", receiptClearModel.ReceiptId).
-		Error
-	return &receiptClearModel, err
+	var receipt models.Receipt
+	err = db.Select("total_amount").First(&receipt, "id = ?", receiptId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return approval.Submit(db, approval.Submission{
+		OrgId:       orgId,
+		Society:     society,
+		Action:      custom.APPROVAL_RECEIPT_CLEAR,
+		EntityId:    receiptId,
+		Amount:      &receipt.TotalAmount,
+		Payload:     h,
+		RequestedBy: requestedBy,
+	})
+}
+
+// executeApprovedClear clears the receipt of an approved request
+func executeApprovedClear(db *gorm.DB, request *models.ApprovalRequest) error {
+	receipt := hClearSaleReceipt{}
+	if err := approval.DecodePayload(request, &receipt); err != nil {
+		return err
+	}
+	_, err := receipt.execute(db, request.OrgId.String(), request.SocietyId, request.EntityId)
+	return err
 }
 
 func (s *receiptService) clearSaleReceipt(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
 	receiptId := chi.URLParam(r, "receiptId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
 
 	reqBody := payload.ValidateAndDecodeRequest[hClearSaleReceipt](w, r)
 	if reqBody == nil {
 		return
 	}
 
+	pending, err := reqBody.submit(s.db, orgId, societyRera, receiptId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	if pending != nil {
+		approval.RespondPending(w, pending)
+		return
+	}
+
 	receipt, err := reqBody.execute(s.db, orgId, societyRera, receiptId)
 	if err != nil {
 		payload.HandleError(w, err)
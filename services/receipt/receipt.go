@@ -1,7 +1,9 @@
 package receipt
 
 import (
+	"circledigital.in/real-state-erp/utils/approval"
 	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
 	"gorm.io/gorm"
 )
 
@@ -10,6 +12,9 @@ type receiptService struct {
 }
 
 func CreateReceiptService(app common.IApp) common.IService {
+	approval.RegisterExecutor(custom.APPROVAL_RECEIPT_CLEAR, executeApprovedClear)
+	approval.RegisterExecutor(custom.APPROVAL_RECEIPT_ADJUSTMENT, executeApprovedAdjustment)
+
 	return &receiptService{
 		db: app.GetDBClient(),
 	}
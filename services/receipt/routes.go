@@ -1,6 +1,7 @@
 package receipt
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,15 +13,15 @@ func (s *receiptService) GetBasePath() string {
 func (s *receiptService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
 
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Post("/sale/{saleId}", s.createSaleReceipt)
-		router.Post("/{receiptId}/clear", s.clearSaleReceipt)
-		router.Get("/{receiptId}", s.getReceiptById)
-		router.Patch("/{receiptId}/fail", s.markReceiptAsFailed)
+		router.With(permission(custom.PERMISSION_RECEIPT_CREATE)).Post("/sale/{saleId}", s.createSaleReceipt)
+		router.With(permission(custom.PERMISSION_RECEIPT_CLEAR)).Post("/{receiptId}/clear", s.clearSaleReceipt)
+		router.With(permission(custom.PERMISSION_RECEIPT_VIEW)).Get("/{receiptId}", s.getReceiptById)
+		router.With(permission(custom.PERMISSION_RECEIPT_FAIL)).Patch("/{receiptId}/fail", s.markReceiptAsFailed)
 	})
 
 	return mux
@@ -0,0 +1,91 @@
+package recycle_bin
+
+import (
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"gorm.io/gorm"
+)
+
+// paginate applies cursor pagination on the deleted records of table
+func paginate(query *gorm.DB, table, cursor string) *gorm.DB {
+	query = query.Order(table + ".created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where(table+".created_at < ?", decodedCursor)
+		}
+	}
+	return query
+}
+
+type hGetRecycleBin struct{}
+
+func (h *hGetRecycleBin) execute(db *gorm.DB, orgId, entity, society, cursor string, access *custom.UserAccess) (*custom.PaginatedData, error) {
+	switch entity {
+	case "sale":
+		var sales []models.Sale
+		err := paginate(deletedSales(db, orgId, society, access), "sales", cursor).
+			Preload("Flat", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Preload("Customers").
+			Preload("CompanyCustomer").
+			Find(&sales).Error
+		if err != nil {
+			return nil, err
+		}
+		return common.CreatePaginatedResponse(&sales), nil
+	case "flat":
+		var flats []models.Flat
+		err := paginate(deletedFlats(db, orgId, society, access), "flats", cursor).
+			Preload("Tower", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Find(&flats).Error
+		if err != nil {
+			return nil, err
+		}
+		return common.CreatePaginatedResponse(&flats), nil
+	case "tower":
+		var towers []models.Tower
+		err := paginate(deletedTowers(db, orgId, society, access), "towers", cursor).Find(&towers).Error
+		if err != nil {
+			return nil, err
+		}
+		return common.CreatePaginatedResponse(&towers), nil
+	case "society":
+		var societies []models.Society
+		err := paginate(deletedSocieties(db, orgId, society, access), "societies", cursor).Find(&societies).Error
+		if err != nil {
+			return nil, err
+		}
+		return common.CreatePaginatedResponse(&societies), nil
+	}
+
+	return nil, &custom.RequestError{
+		Status:  http.StatusBadRequest,
+		Message: "Invalid recycle bin type, expected sale, flat, tower or society.",
+	}
+}
+
+func (s *recycleBinService) getRecycleBin(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	cursor := r.URL.Query().Get("cursor")
+	entity := strings.TrimSpace(r.URL.Query().Get("type"))
+	society := strings.TrimSpace(r.URL.Query().Get("society"))
+
+	recycleBin := hGetRecycleBin{}
+	res, err := recycleBin.execute(s.db, orgId, entity, society, cursor, access)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
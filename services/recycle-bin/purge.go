@@ -0,0 +1,222 @@
+package recycle_bin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+type hPurgeRecord struct {
+	retention time.Duration
+}
+
+// checkRetention returns error if the record was deleted less than retention period ago
+func (h *hPurgeRecord) checkRetention(deletedAt gorm.DeletedAt) error {
+	purgeAfter := deletedAt.Time.Add(h.retention)
+	if time.Now().Before(purgeAfter) {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("Record can be purged after %s.", purgeAfter.Format("02-01-2006")),
+		}
+	}
+	return nil
+}
+
+// purge permanently deletes the record, sale purge cascades to its receipts and customers
+func purge(db *gorm.DB, value any, conds ...any) error {
+	err := db.Unscoped().Delete(value, conds...).Error
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Record is referenced by other records, purge them first.",
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (h *hPurgeRecord) sale(db *gorm.DB, orgId, saleId string, access *custom.UserAccess) error {
+	var sale models.Sale
+	err := findDeleted(deletedSales(db, orgId, "", access).Where("sales.id = ?", saleId), &sale)
+	if err != nil {
+		return err
+	}
+	if err := h.checkRetention(sale.DeletedAt); err != nil {
+		return err
+	}
+	return purge(db, &models.Sale{}, "id = ?", sale.Id)
+}
+
+func (h *hPurgeRecord) flat(db *gorm.DB, orgId, flatId string, access *custom.UserAccess) error {
+	var flat models.Flat
+	err := findDeleted(deletedFlats(db, orgId, "", access).Where("flats.id = ?", flatId), &flat)
+	if err != nil {
+		return err
+	}
+	if err := h.checkRetention(flat.DeletedAt); err != nil {
+		return err
+	}
+	return purge(db, &models.Flat{}, "id = ?", flat.Id)
+}
+
+func (h *hPurgeRecord) tower(db *gorm.DB, orgId, towerId string, access *custom.UserAccess) error {
+	var tower models.Tower
+	err := findDeleted(deletedTowers(db, orgId, "", access).Where("towers.id = ?", towerId), &tower)
+	if err != nil {
+		return err
+	}
+	if err := h.checkRetention(tower.DeletedAt); err != nil {
+		return err
+	}
+	return purge(db, &models.Tower{}, "id = ?", tower.Id)
+}
+
+func (h *hPurgeRecord) society(db *gorm.DB, orgId, society string, access *custom.UserAccess) error {
+	var societyModel models.Society
+	err := findDeleted(deletedSocieties(db, orgId, society, access), &societyModel)
+	if err != nil {
+		return err
+	}
+	if err := h.checkRetention(societyModel.DeletedAt); err != nil {
+		return err
+	}
+	return purge(db, &models.Society{}, "rera_number = ? and org_id = ?", societyModel.ReraNumber, societyModel.OrgId)
+}
+
+// purgeResult counts records purged and records kept because other records still reference them
+type purgeResult struct {
+	Purged  map[string]int `json:"purged"`
+	Skipped map[string]int `json:"skipped"`
+}
+
+// expired purges every record of the organization deleted before the retention period
+// children are purged before parents so a parent whose children are purged in the same run is purged as well
+func (h *hPurgeRecord) expired(db *gorm.DB, orgId string, access *custom.UserAccess) (*purgeResult, error) {
+	result := &purgeResult{
+		Purged:  make(map[string]int),
+		Skipped: make(map[string]int),
+	}
+	before := time.Now().Add(-h.retention)
+
+	record := func(entity string, err error) error {
+		var reqErr *custom.RequestError
+		if errors.As(err, &reqErr) {
+			result.Skipped[entity]++
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result.Purged[entity]++
+		return nil
+	}
+
+	var sales []models.Sale
+	if err := deletedSales(db, orgId, "", access).Where("sales.deleted_at < ?", before).Find(&sales).Error; err != nil {
+		return nil, err
+	}
+	for _, sale := range sales {
+		if err := record("sale", purge(db, &models.Sale{}, "id = ?", sale.Id)); err != nil {
+			return nil, err
+		}
+	}
+
+	var flats []models.Flat
+	if err := deletedFlats(db, orgId, "", access).Where("flats.deleted_at < ?", before).Find(&flats).Error; err != nil {
+		return nil, err
+	}
+	for _, flat := range flats {
+		if err := record("flat", purge(db, &models.Flat{}, "id = ?", flat.Id)); err != nil {
+			return nil, err
+		}
+	}
+
+	var towers []models.Tower
+	if err := deletedTowers(db, orgId, "", access).Where("towers.deleted_at < ?", before).Find(&towers).Error; err != nil {
+		return nil, err
+	}
+	for _, tower := range towers {
+		if err := record("tower", purge(db, &models.Tower{}, "id = ?", tower.Id)); err != nil {
+			return nil, err
+		}
+	}
+
+	var societies []models.Society
+	if err := deletedSocieties(db, orgId, "", access).Where("societies.deleted_at < ?", before).Find(&societies).Error; err != nil {
+		return nil, err
+	}
+	for _, society := range societies {
+		err := purge(db, &models.Society{}, "rera_number = ? and org_id = ?", society.ReraNumber, society.OrgId)
+		if err := record("society", err); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s *recycleBinService) purgeSale(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	saleId := chi.URLParam(r, "saleId")
+
+	record := hPurgeRecord{retention: s.retention}
+	respond(w, record.sale(s.db, orgId, saleId, access), "Successfully purged sale record.")
+}
+
+func (s *recycleBinService) purgeFlat(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	flatId := chi.URLParam(r, "flatId")
+
+	record := hPurgeRecord{retention: s.retention}
+	respond(w, record.flat(s.db, orgId, flatId, access), "Successfully purged flat.")
+}
+
+func (s *recycleBinService) purgeTower(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	towerId := chi.URLParam(r, "towerId")
+
+	record := hPurgeRecord{retention: s.retention}
+	respond(w, record.tower(s.db, orgId, towerId, access), "Successfully purged tower.")
+}
+
+func (s *recycleBinService) purgeSociety(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	society := chi.URLParam(r, "society")
+
+	record := hPurgeRecord{retention: s.retention}
+	respond(w, record.society(s.db, orgId, society, access), "Successfully purged society.")
+}
+
+func (s *recycleBinService) purgeExpired(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	record := hPurgeRecord{retention: s.retention}
+	res, err := record.expired(s.db, orgId, access)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully purged expired records."
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,78 @@
+package recycle_bin
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"gorm.io/gorm"
+)
+
+// defaultRetentionDays is used when RECYCLE_BIN_RETENTION_DAYS is not set
+const defaultRetentionDays = 30
+
+type recycleBinService struct {
+	db        *gorm.DB
+	retention time.Duration
+}
+
+// CreateRecycleBinService creates service for soft deleted sales, flats, towers and societies
+// deleted records can be restored any time and purged only after the retention period
+func CreateRecycleBinService(app common.IApp) common.IService {
+	retentionDays, err := strconv.Atoi(os.Getenv("RECYCLE_BIN_RETENTION_DAYS"))
+	if err != nil || retentionDays <= 0 {
+		retentionDays = defaultRetentionDays
+	}
+
+	return &recycleBinService{
+		db:        app.GetDBClient(),
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+	}
+}
+
+// inSocieties limits the query to the societies the user can access, column is the society of the record
+func inSocieties(query *gorm.DB, column string, access *custom.UserAccess) *gorm.DB {
+	if access != nil && access.Societies != nil {
+		return query.Where(column+" IN ?", access.SocietyIds())
+	}
+	return query
+}
+
+// deleted records of the organization the user can access, scoped to the society when provided
+
+func deletedSales(db *gorm.DB, orgId, society string, access *custom.UserAccess) *gorm.DB {
+	query := db.Unscoped().Model(&models.Sale{}).Where("sales.org_id = ? and sales.deleted_at IS NOT NULL", orgId)
+	if society != "" {
+		query = query.Where("sales.society_id = ?", society)
+	}
+	return inSocieties(query, "sales.society_id", access)
+}
+
+func deletedFlats(db *gorm.DB, orgId, society string, access *custom.UserAccess) *gorm.DB {
+	query := db.Unscoped().Model(&models.Flat{}).
+		Joins("JOIN towers ON towers.id = flats.tower_id").
+		Where("towers.org_id = ? and flats.deleted_at IS NOT NULL", orgId)
+	if society != "" {
+		query = query.Where("towers.society_id = ?", society)
+	}
+	return inSocieties(query, "towers.society_id", access)
+}
+
+func deletedTowers(db *gorm.DB, orgId, society string, access *custom.UserAccess) *gorm.DB {
+	query := db.Unscoped().Model(&models.Tower{}).Where("towers.org_id = ? and towers.deleted_at IS NOT NULL", orgId)
+	if society != "" {
+		query = query.Where("towers.society_id = ?", society)
+	}
+	return inSocieties(query, "towers.society_id", access)
+}
+
+func deletedSocieties(db *gorm.DB, orgId, society string, access *custom.UserAccess) *gorm.DB {
+	query := db.Unscoped().Model(&models.Society{}).Where("societies.org_id = ? and societies.deleted_at IS NOT NULL", orgId)
+	if society != "" {
+		query = query.Where("societies.rera_number = ?", society)
+	}
+	return inSocieties(query, "societies.rera_number", access)
+}
@@ -0,0 +1,173 @@
+package recycle_bin
+
+import (
+	"errors"
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+var notInRecycleBinError = &custom.RequestError{
+	Status:  http.StatusNotFound,
+	Message: "Record not found in recycle bin.",
+}
+
+// findDeleted loads the deleted record matched by query into dest
+func findDeleted(query *gorm.DB, dest any) error {
+	err := query.First(dest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notInRecycleBinError
+	}
+	return err
+}
+
+// undelete clears the soft delete of the record matched by query
+func undelete(query *gorm.DB) error {
+	return query.Updates(map[string]any{
+		"deleted_at": nil,
+		"deleted_by": "",
+	}).Error
+}
+
+// requireActive returns parentError if the parent record is deleted
+func requireActive(query *gorm.DB, parentError string) error {
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: parentError,
+		}
+	}
+	return nil
+}
+
+type hRestoreRecord struct{}
+
+// sale is restored only if its flat is active and not sold again
+func (h *hRestoreRecord) sale(db *gorm.DB, orgId, saleId string, access *custom.UserAccess) error {
+	var sale models.Sale
+	err := findDeleted(deletedSales(db, orgId, "", access).Where("sales.id = ?", saleId), &sale)
+	if err != nil {
+		return err
+	}
+
+	err = requireActive(db.Model(&models.Flat{}).Where("id = ?", sale.FlatId), "Restore the flat of the sale first.")
+	if err != nil {
+		return err
+	}
+
+	var activeSales int64
+	err = db.Model(&models.Sale{}).Where("flat_id = ?", sale.FlatId).Count(&activeSales).Error
+	if err != nil {
+		return err
+	}
+	if activeSales > 0 {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Flat is already sold, delete the active sale first.",
+		}
+	}
+
+	return undelete(db.Unscoped().Model(&models.Sale{}).Where("id = ?", saleId))
+}
+
+func (h *hRestoreRecord) flat(db *gorm.DB, orgId, flatId string, access *custom.UserAccess) error {
+	var flat models.Flat
+	err := findDeleted(deletedFlats(db, orgId, "", access).Where("flats.id = ?", flatId), &flat)
+	if err != nil {
+		return err
+	}
+
+	err = requireActive(db.Model(&models.Tower{}).Where("id = ?", flat.TowerId), "Restore the tower of the flat first.")
+	if err != nil {
+		return err
+	}
+
+	return undelete(db.Unscoped().Model(&models.Flat{}).Where("id = ?", flatId))
+}
+
+func (h *hRestoreRecord) tower(db *gorm.DB, orgId, towerId string, access *custom.UserAccess) error {
+	var tower models.Tower
+	err := findDeleted(deletedTowers(db, orgId, "", access).Where("towers.id = ?", towerId), &tower)
+	if err != nil {
+		return err
+	}
+
+	err = requireActive(
+		db.Model(&models.Society{}).Where("rera_number = ? and org_id = ?", tower.SocietyId, tower.OrgId),
+		"Restore the society of the tower first.",
+	)
+	if err != nil {
+		return err
+	}
+
+	return undelete(db.Unscoped().Model(&models.Tower{}).Where("id = ?", towerId))
+}
+
+func (h *hRestoreRecord) society(db *gorm.DB, orgId, society string, access *custom.UserAccess) error {
+	var societyModel models.Society
+	err := findDeleted(deletedSocieties(db, orgId, society, access), &societyModel)
+	if err != nil {
+		return err
+	}
+
+	return undelete(db.Unscoped().Model(&models.Society{}).Where("rera_number = ? and org_id = ?", society, orgId))
+}
+
+// respond writes the response of restore and purge handlers
+func respond(w http.ResponseWriter, err error, message string) {
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = message
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *recycleBinService) restoreSale(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	saleId := chi.URLParam(r, "saleId")
+
+	record := hRestoreRecord{}
+	respond(w, record.sale(s.db, orgId, saleId, access), "Successfully restored sale record.")
+}
+
+func (s *recycleBinService) restoreFlat(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	flatId := chi.URLParam(r, "flatId")
+
+	record := hRestoreRecord{}
+	respond(w, record.flat(s.db, orgId, flatId, access), "Successfully restored flat.")
+}
+
+func (s *recycleBinService) restoreTower(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	towerId := chi.URLParam(r, "towerId")
+
+	record := hRestoreRecord{}
+	respond(w, record.tower(s.db, orgId, towerId, access), "Successfully restored tower.")
+}
+
+func (s *recycleBinService) restoreSociety(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	society := chi.URLParam(r, "society")
+
+	record := hRestoreRecord{}
+	respond(w, record.society(s.db, orgId, society, access), "Successfully restored society.")
+}
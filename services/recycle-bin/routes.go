@@ -0,0 +1,35 @@
+package recycle_bin
+
+import (
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *recycleBinService) GetBasePath() string {
+	return "/recycle-bin"
+}
+
+func (s *recycleBinService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.Use(authorizationMiddleware.Permission(custom.PERMISSION_RECYCLE_BIN_MANAGE))
+
+		router.Get("/", s.getRecycleBin)
+
+		router.Post("/sale/{saleId}/restore", s.restoreSale)
+		router.Post("/flat/{flatId}/restore", s.restoreFlat)
+		router.Post("/tower/{towerId}/restore", s.restoreTower)
+		router.Post("/society/{society}/restore", s.restoreSociety)
+
+		router.Delete("/sale/{saleId}", s.purgeSale)
+		router.Delete("/flat/{flatId}", s.purgeFlat)
+		router.Delete("/tower/{towerId}", s.purgeTower)
+		router.Delete("/society/{society}", s.purgeSociety)
+		router.Post("/purge", s.purgeExpired)
+	})
+
+	return mux
+}
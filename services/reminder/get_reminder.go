@@ -0,0 +1,266 @@
+package reminder
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	appReminder "circledigital.in/real-state-erp/utils/reminder"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func (s *reminderService) getReminderPolicy(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+
+	policy, err := appReminder.Policy(s.db, uuid.MustParse(orgId), societyRera)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = policy
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// hUpdateReminderPolicy replaces the reminder stages of the society
+// stages reached before the update are not run again, new stages apply from the next run
+type hUpdateReminderPolicy struct {
+	Enabled *bool
+	Stages  models.ReminderStages `validate:"required,min=1"`
+}
+
+func (h *hUpdateReminderPolicy) execute(db *gorm.DB, orgId, society, updatedBy string) (*models.PaymentReminderPolicy, error) {
+	stages, err := h.Stages.Normalize()
+	if err != nil {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid reminder stages: " + err.Error(),
+		}
+	}
+
+	enabled := true
+	if h.Enabled != nil {
+		enabled = *h.Enabled
+	}
+
+	policy := models.PaymentReminderPolicy{
+		OrgId:     uuid.MustParse(orgId),
+		SocietyId: society,
+		Enabled:   enabled,
+		Stages:    stages,
+		UpdatedBy: updatedBy,
+	}
+	return &policy, db.Save(&policy).Error
+}
+
+func (s *reminderService) updateReminderPolicy(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	reqBody := payload.ValidateAndDecodeRequest[hUpdateReminderPolicy](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	policy, err := reqBody.execute(s.db, orgId, societyRera, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated reminder policy."
+	response.Data = policy
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// previewReminders returns the reminders the next run on date would send without recording them, tomorrow when not set
+func (s *reminderService) previewReminders(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+
+	date := time.Now().AddDate(0, 0, 1)
+	if value := strings.TrimSpace(r.URL.Query().Get("date")); value != "" {
+		var err error
+		date, err = common.ParseAsOfDate(value)
+		if err != nil {
+			payload.HandleError(w, err)
+			return
+		}
+	}
+
+	reminders, err := s.engine.Plan(uuid.MustParse(orgId), societyRera, date)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = map[string]any{
+		"date":      date.Format("2006-01-02"),
+		"reminders": reminders,
+	}
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// getReminders lists the reminders recorded for the society, newest first
+func (s *reminderService) getReminders(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	cursor := r.URL.Query().Get("cursor")
+
+	query := s.db.Where("org_id = ? and society_id = ?", orgId, societyRera)
+	if sale := strings.TrimSpace(r.URL.Query().Get("sale")); sale != "" {
+		if _, err := uuid.Parse(sale); err != nil {
+			payload.HandleError(w, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid sale id.",
+			})
+			return
+		}
+		query = query.Where("sale_id = ?", sale)
+	}
+	query = query.Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	var reminders []models.PaymentReminder
+	err := query.Find(&reminders).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = common.CreatePaginatedResponse(&reminders)
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// getCollectionTasks lists the collection tasks of the society, filtered by status
+func (s *reminderService) getCollectionTasks(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	cursor := r.URL.Query().Get("cursor")
+
+	query := s.db.Preload("Sale").Preload("Sale.Flat").Preload("Sale.Customers").
+		Where("org_id = ? and society_id = ?", orgId, societyRera)
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		if !custom.CollectionTaskStatus(status).IsValid() {
+			payload.HandleError(w, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid task status.",
+			})
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+	query = query.Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	var tasks []models.CollectionTask
+	err := query.Find(&tasks).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = common.CreatePaginatedResponse(&tasks)
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+type hUpdateCollectionTask struct {
+	Status string
+	Note   *string
+}
+
+func (h *hUpdateCollectionTask) execute(db *gorm.DB, orgId, society, taskId, updatedBy string) (*models.CollectionTask, error) {
+	var task models.CollectionTask
+	err := db.Where("id = ? and org_id = ? and society_id = ?", taskId, orgId, society).First(&task).Error
+	if _, parseErr := uuid.Parse(taskId); parseErr != nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Collection task not found.",
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Note != nil {
+		task.Note = *h.Note
+	}
+	if h.Status != "" {
+		status := custom.CollectionTaskStatus(h.Status)
+		if !status.IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid task status.",
+			}
+		}
+		if status != task.Status {
+			task.Status = status
+			if status == custom.TASK_CLOSED {
+				now := time.Now()
+				task.ClosedAt = &now
+				task.ClosedBy = updatedBy
+			} else {
+				task.ClosedAt = nil
+				task.ClosedBy = ""
+			}
+		}
+	}
+	return &task, db.Save(&task).Error
+}
+
+func (s *reminderService) updateCollectionTask(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	taskId := chi.URLParam(r, "taskId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	reqBody := payload.ValidateAndDecodeRequest[hUpdateCollectionTask](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	task, err := reqBody.execute(s.db, orgId, societyRera, taskId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated collection task."
+	response.Data = task
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
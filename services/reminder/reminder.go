@@ -0,0 +1,21 @@
+package reminder
+
+import (
+	"circledigital.in/real-state-erp/utils/common"
+	appReminder "circledigital.in/real-state-erp/utils/reminder"
+	"gorm.io/gorm"
+)
+
+type reminderService struct {
+	db     *gorm.DB
+	engine *appReminder.Engine
+}
+
+// CreateReminderService creates service for the payment reminder policy, reminder log and collection tasks of a society
+// reminders are run daily by the scheduler of utils/reminder
+func CreateReminderService(app common.IApp) common.IService {
+	return &reminderService{
+		db:     app.GetDBClient(),
+		engine: appReminder.NewEngine(app.GetDBClient()),
+	}
+}
@@ -0,0 +1,32 @@
+package reminder
+
+import (
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *reminderService) GetBasePath() string {
+	return "/society/{society}/reminder"
+}
+
+func (s *reminderService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
+
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+
+		router.With(permission(custom.PERMISSION_REMINDER_VIEW)).Get("/policy", s.getReminderPolicy)
+		router.With(permission(custom.PERMISSION_REMINDER_MANAGE)).Put("/policy", s.updateReminderPolicy)
+		router.With(permission(custom.PERMISSION_REMINDER_VIEW)).Get("/preview", s.previewReminders)
+
+		router.With(permission(custom.PERMISSION_REMINDER_VIEW)).Get("/task", s.getCollectionTasks)
+		router.With(permission(custom.PERMISSION_REMINDER_MANAGE)).Patch("/task/{taskId}", s.updateCollectionTask)
+
+		router.With(permission(custom.PERMISSION_REMINDER_VIEW)).Get("/", s.getReminders)
+	})
+
+	return mux
+}
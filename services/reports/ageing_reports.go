@@ -0,0 +1,323 @@
+package reports
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const ageingReportSheet = "Receivables Ageing"
+
+// ageingReportItem is the overdue balance of a payment plan item of a sale
+type ageingReportItem struct {
+	ItemId      uuid.UUID       `json:"itemId"`
+	Description string          `json:"description"`
+	DueDate     custom.DateOnly `json:"dueDate"`
+	DaysOverdue int             `json:"daysOverdue"`
+	Bucket      string          `json:"bucket"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+// ageingReportContact is how an owner of a sale is reached for collection
+type ageingReportContact struct {
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phoneNumber"`
+	Email       string `json:"email"`
+}
+
+// ageingReportSale is the overdue balance of a sale split by the buckets of models.AgeingBuckets
+type ageingReportSale struct {
+	SaleId          uuid.UUID             `json:"saleId"`
+	SaleNumber      string                `json:"saleNumber"`
+	Tower           string                `json:"tower"`
+	Flat            string                `json:"flat"`
+	Owners          string                `json:"owners"`
+	Contacts        []ageingReportContact `json:"contacts"`
+	LastReceiptDate *custom.DateOnly      `json:"lastReceiptDate"`
+	DaysOverdue     int                   `json:"daysOverdue"` // of the oldest overdue item
+	Buckets         []decimal.Decimal     `json:"buckets"`
+	Total           decimal.Decimal       `json:"total"`
+	Items           []ageingReportItem    `json:"items"`
+}
+
+// ageingReportBucket is the overdue balance of all sales in a bucket
+type ageingReportBucket struct {
+	models.AgeingBucket
+	Sales  int             `json:"sales"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// ageingReport lists the sales with overdue balance as of a date, oldest first
+type ageingReport struct {
+	AsOf    custom.DateOnly      `json:"asOf"`
+	Buckets []ageingReportBucket `json:"buckets"`
+	Total   decimal.Decimal      `json:"total"`
+	Sales   []ageingReportSale   `json:"sales"`
+}
+
+// newAgeingReport ages the overdue items of the sales of source as of asOf
+// sales whose oldest overdue item is less than minDays past due are left out
+func newAgeingReport(source masterReportSource, asOf time.Time, minDays int) (*ageingReport, error) {
+	report := &ageingReport{
+		AsOf:    custom.DateOnly{Time: asOf},
+		Buckets: make([]ageingReportBucket, len(models.AgeingBuckets)),
+		Total:   decimal.Zero,
+		Sales:   make([]ageingReportSale, 0),
+	}
+	for i, bucket := range models.AgeingBuckets {
+		report.Buckets[i] = ageingReportBucket{AgeingBucket: bucket, Amount: decimal.Zero}
+	}
+
+	err := source.each(func(tower *models.Tower, flats []models.Flat) error {
+		for _, flat := range flats {
+			sale := flat.SaleDetail
+			if sale == nil {
+				continue
+			}
+
+			// tower stage activations only apply to flats of the same tower
+			activationCtx := models.NewPaymentActivationContext(asOf, sale, flat.ActivePaymentPlanRatioItems, tower.ActivePaymentPlanRatioItems)
+			overdue := sale.OverdueItems(activationCtx)
+			if len(overdue) == 0 {
+				continue
+			}
+
+			row := ageingReportSale{
+				SaleId:     sale.Id,
+				SaleNumber: sale.SaleNumber,
+				Tower:      tower.Name,
+				Flat:       flat.Name,
+				Owners:     sale.OwnerNames(),
+				Contacts:   make([]ageingReportContact, 0, len(sale.Customers)),
+				Buckets:    make([]decimal.Decimal, len(models.AgeingBuckets)),
+				Total:      decimal.Zero,
+				Items:      make([]ageingReportItem, 0, len(overdue)),
+			}
+			for _, customer := range sale.Customers {
+				row.Contacts = append(row.Contacts, ageingReportContact{
+					Name:        strings.Join(strings.Fields(customer.FirstName+" "+customer.MiddleName+" "+customer.LastName), " "),
+					PhoneNumber: customer.PhoneNumber,
+					Email:       customer.Email,
+				})
+			}
+			if last := sale.LastReceiptDate(); last != nil {
+				row.LastReceiptDate = &custom.DateOnly{Time: *last}
+			}
+			for i := range row.Buckets {
+				row.Buckets[i] = decimal.Zero
+			}
+
+			for _, item := range overdue {
+				bucket := models.AgeingBucketOf(item.DaysOverdue)
+				row.Buckets[bucket] = row.Buckets[bucket].Add(item.Amount)
+				row.Total = row.Total.Add(item.Amount)
+				row.DaysOverdue = max(row.DaysOverdue, item.DaysOverdue)
+				row.Items = append(row.Items, ageingReportItem{
+					ItemId:      item.Item.Id,
+					Description: item.Item.Description,
+					DueDate:     item.DueDate,
+					DaysOverdue: item.DaysOverdue,
+					Bucket:      models.AgeingBuckets[bucket].Label,
+					Amount:      item.Amount,
+				})
+			}
+			if row.DaysOverdue < minDays {
+				continue
+			}
+
+			for i, amount := range row.Buckets {
+				if amount.IsPositive() {
+					report.Buckets[i].Sales++
+					report.Buckets[i].Amount = report.Buckets[i].Amount.Add(amount)
+				}
+			}
+			report.Total = report.Total.Add(row.Total)
+			report.Sales = append(report.Sales, row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// oldest and largest balances are called first
+	slices.SortStableFunc(report.Sales, func(a, b ageingReportSale) int {
+		if a.DaysOverdue != b.DaysOverdue {
+			return b.DaysOverdue - a.DaysOverdue
+		}
+		return b.Total.Cmp(a.Total)
+	})
+	return report, nil
+}
+
+// table returns a row per sale, xlsx workbooks also get a row with the totals of the buckets
+func (report *ageingReport) table(format tabular.Format) *tabular.Table {
+	columns := []tabular.Column{
+		{Path: []string{models.HeadingSale, "Tower"}},
+		{Path: []string{models.HeadingSale, "Flat"}},
+		{Path: []string{models.HeadingSale, "Sale Number"}},
+		{Path: []string{models.HeadingCustomer, "Owners"}},
+		{Path: []string{models.HeadingCustomer, "Phone Number"}},
+		{Path: []string{models.HeadingCustomer, "Email"}},
+		{Path: []string{"Collection", "Last Receipt Date"}},
+		{Path: []string{"Collection", "Days Overdue"}},
+	}
+	for _, bucket := range models.AgeingBuckets {
+		columns = append(columns, tabular.Column{Path: []string{"Overdue", bucket.Label + " Days"}, Monetary: true})
+	}
+	columns = append(columns, tabular.Column{Path: []string{"Overdue", "Total"}, Monetary: true})
+
+	rows := make([][]any, 0, len(report.Sales)+1)
+	for _, sale := range report.Sales {
+		phones := make([]string, 0, len(sale.Contacts))
+		emails := make([]string, 0, len(sale.Contacts))
+		for _, contact := range sale.Contacts {
+			if contact.PhoneNumber != "" {
+				phones = append(phones, contact.PhoneNumber)
+			}
+			if contact.Email != "" {
+				emails = append(emails, contact.Email)
+			}
+		}
+		var lastReceipt any
+		if sale.LastReceiptDate != nil {
+			lastReceipt = sale.LastReceiptDate.Time
+		}
+
+		row := []any{
+			sale.Tower,
+			sale.Flat,
+			sale.SaleNumber,
+			sale.Owners,
+			strings.Join(phones, ", "),
+			strings.Join(emails, ", "),
+			lastReceipt,
+			sale.DaysOverdue,
+		}
+		for _, amount := range sale.Buckets {
+			row = append(row, amount)
+		}
+		rows = append(rows, append(row, sale.Total))
+	}
+
+	if format == tabular.XLSX {
+		totals := []any{"Total", nil, nil, nil, nil, nil, nil, nil}
+		for _, bucket := range report.Buckets {
+			totals = append(totals, bucket.Amount)
+		}
+		rows = append(rows, append(totals, report.Total))
+	}
+	return tabular.Rows(ageingReportSheet, columns, rows)
+}
+
+type hGetAgeingReport struct {
+	tower   string
+	asOf    time.Time
+	minDays int
+	format  tabular.Format
+}
+
+func (h *hGetAgeingReport) parse(r *http.Request) error {
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		return err
+	}
+	h.asOf = asOf
+	h.tower = r.URL.Query().Get("tower")
+
+	if value := r.URL.Query().Get("minDays"); value != "" {
+		minDays, err := strconv.Atoi(value)
+		if err != nil || minDays < 0 {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "minDays must be a non negative number.",
+			}
+		}
+		h.minDays = minDays
+	}
+
+	h.format, err = tabular.Negotiate(r)
+	return err
+}
+
+// execute ages the receivables of the society, or of the tower when set, as they were on asOf
+func (h *hGetAgeingReport) execute(db *gorm.DB, orgId, society string) (*ageingReport, error) {
+	query := db.Where("org_id = ? AND society_id = ?", orgId, society)
+	if h.tower != "" {
+		query = query.Where("name = ?", h.tower)
+	}
+
+	var towers []models.Tower
+	err := query.
+		Preload("ActivePaymentPlanRatioItems").
+		Order("name").
+		Find(&towers).Error
+	if err != nil {
+		return nil, err
+	}
+	if h.tower != "" && len(towers) == 0 {
+		return nil, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "No tower found",
+		}
+	}
+
+	source := &dbMasterReportSource{
+		db:        db,
+		towers:    towers,
+		asOf:      h.asOf,
+		batchSize: masterReportBatchSize,
+	}
+	return newAgeingReport(source, h.asOf, h.minDays)
+}
+
+// fileName is the name of the report file without extension
+func (h *hGetAgeingReport) fileName(society string, generatedAt time.Time) string {
+	fileNameBase := society
+	if h.tower != "" {
+		fileNameBase = fmt.Sprintf("tower_%s", h.tower)
+	}
+	return fmt.Sprintf("%s_ageing_report_%d", fileNameBase, generatedAt.Unix())
+}
+
+// generateAgeingReport lists the overdue balance of every sale by days past due, json unless a format is negotiated
+func (s *reportService) generateAgeingReport(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+
+	h := hGetAgeingReport{}
+	if err := h.parse(r); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	report, err := h.execute(s.db, orgId, societyRera)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	if !h.format.IsDefault() {
+		tabular.Respond(w, h.format, h.fileName(societyRera, time.Now()), report.table(h.format))
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = report
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
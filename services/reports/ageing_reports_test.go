@@ -0,0 +1,80 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/shopspring/decimal"
+)
+
+func TestAgeingReport(t *testing.T) {
+	// flat 0 is unsold, flat 1 paid two receipts and flat 2 three receipts of 5,18,000
+	source := newSyntheticSource(1, 3, 2)
+	asOf := time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	report, err := newAgeingReport(source, asOf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Sales) != 2 {
+		t.Fatalf("want 2 sales with overdue balance, got %d", len(report.Sales))
+	}
+
+	// items within 30 days are due on 2024-02-09 and within 90 days on 2024-04-09
+	sale := report.Sales[0]
+	if sale.Flat != "A-0001" || sale.DaysOverdue != 142 {
+		t.Errorf("want A-0001 142 days overdue first, got %s %d", sale.Flat, sale.DaysOverdue)
+	}
+	want := []string{"0", "0", "2850000", "1814000"}
+	for i, amount := range sale.Buckets {
+		if !amount.Equal(decimal.RequireFromString(want[i])) {
+			t.Errorf("bucket %s: want %s, got %s", report.Buckets[i].Label, want[i], amount)
+		}
+	}
+	if len(sale.Items) != 2 || sale.LastReceiptDate == nil || sale.LastReceiptDate.Format("2006-01-02") != "2024-02-10" {
+		t.Errorf("want 2 items and last receipt on 2024-02-10, got %d items and %v", len(sale.Items), sale.LastReceiptDate)
+	}
+	if len(sale.Contacts) != 1 || sale.Contacts[0].PhoneNumber == "" {
+		t.Errorf("want contact of the owner, got %+v", sale.Contacts)
+	}
+
+	total := decimal.Zero
+	for _, bucket := range report.Buckets {
+		total = total.Add(bucket.Amount)
+	}
+	if !total.Equal(report.Total) || !report.Buckets[3].Amount.Equal(decimal.NewFromInt(1814000+1296000)) || report.Buckets[3].Sales != 2 {
+		t.Errorf("bucket totals do not add up: %+v total %s", report.Buckets, report.Total)
+	}
+
+	if rows := countRows(t, report.table(tabular.XLSX)); rows != 3 {
+		t.Errorf("want 2 sales and totals in xlsx, got %d rows", rows)
+	}
+	if rows := countRows(t, report.table(tabular.CSV)); rows != 2 {
+		t.Errorf("want only sales in csv, got %d rows", rows)
+	}
+
+	report, err = newAgeingReport(source, asOf, 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Sales) != 0 || !report.Total.IsZero() {
+		t.Errorf("want no sale 150 days overdue, got %d", len(report.Sales))
+	}
+}
+
+func countRows(t *testing.T, table *tabular.Table) int {
+	t.Helper()
+	count := 0
+	err := table.Each(func(row []any) error {
+		if len(row) != len(table.Columns) {
+			t.Errorf("want %d values, got %d", len(table.Columns), len(row))
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
@@ -0,0 +1,492 @@
+package reports
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	cashFlowSheet         = "Cash Flow Projection"
+	cashFlowMonthLayout   = "2006-01"
+	defaultCashFlowMonths = 12
+	maxCashFlowMonths     = 60
+	maxCashFlowDelay      = 36
+)
+
+// cashFlowMonth is the collection expected in a month from sold and unsold flats
+type cashFlowMonth struct {
+	Month  string          `json:"month"`
+	Sold   decimal.Decimal `json:"sold"`
+	Unsold decimal.Decimal `json:"unsold"`
+	Total  decimal.Decimal `json:"total"`
+}
+
+func newCashFlowMonth(month string) cashFlowMonth {
+	return cashFlowMonth{Month: month, Sold: decimal.Zero, Unsold: decimal.Zero, Total: decimal.Zero}
+}
+
+func (m *cashFlowMonth) add(sold, unsold decimal.Decimal) {
+	m.Sold = m.Sold.Add(sold)
+	m.Unsold = m.Unsold.Add(unsold)
+	m.Total = m.Sold.Add(m.Unsold)
+}
+
+// cashFlowScenario is the projection with every expected collection delayed by DelayMonths
+type cashFlowScenario struct {
+	Name        string          `json:"name"`
+	DelayMonths int             `json:"delayMonths"`
+	Months      []cashFlowMonth `json:"months"`
+	// Later is expected after the projected months, Unscheduled has no planned date
+	Later       cashFlowMonth   `json:"later"`
+	Unscheduled cashFlowMonth   `json:"unscheduled"`
+	Total       decimal.Decimal `json:"total"` // of the projected months
+}
+
+// cashFlowProjection is the monthly collection expected from the payment plans of the society
+type cashFlowProjection struct {
+	From          string             `json:"from"`
+	Months        int                `json:"months"`
+	Overdue       decimal.Decimal    `json:"overdue"` // due before the first month, expected in the first month on time
+	UnsoldFlats   int                `json:"unsoldFlats"`
+	Absorption    int                `json:"absorption"`
+	BasicRate     decimal.Decimal    `json:"basicRate"`
+	PaymentPlanId *uuid.UUID         `json:"paymentPlanId,omitempty"`
+	Scenarios     []cashFlowScenario `json:"scenarios"`
+}
+
+// cashFlowAssumptions are the inputs of the projection besides the flats
+type cashFlowAssumptions struct {
+	asOf     time.Time // payments are counted till asOf
+	start    time.Time // first day of the first projected month
+	months   int
+	delays   []int
+	schedule models.ConstructionSchedule
+	// unsold flats are priced by priceList, booked absorption a month on plan, they are left out when absorption is 0
+	priceList  models.PriceList
+	plan       *models.PaymentPlanRatio
+	absorption *int
+}
+
+// cashFlowSeries is the expected collection by month at no delay, index 0 includes the overdue balance
+type cashFlowSeries struct {
+	months      []decimal.Decimal
+	later       decimal.Decimal
+	unscheduled decimal.Decimal
+}
+
+func newCashFlowSeries(months int) *cashFlowSeries {
+	series := &cashFlowSeries{months: make([]decimal.Decimal, months), later: decimal.Zero, unscheduled: decimal.Zero}
+	for i := range series.months {
+		series.months[i] = decimal.Zero
+	}
+	return series
+}
+
+func (s *cashFlowSeries) add(start time.Time, collections []models.ExpectedCollection) {
+	for _, collection := range collections {
+		if collection.Due == nil {
+			s.unscheduled = s.unscheduled.Add(collection.Amount)
+			continue
+		}
+		month := max(monthsBetween(start, *collection.Due), 0)
+		if month >= len(s.months) {
+			s.later = s.later.Add(collection.Amount)
+			continue
+		}
+		s.months[month] = s.months[month].Add(collection.Amount)
+	}
+}
+
+// monthsBetween returns the number of calendar months from the month of from to the month of to
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// unsoldFlat is a flat without sale with its tower for the tower stage activations
+type unsoldFlat struct {
+	tower *models.Tower
+	flat  models.Flat
+}
+
+// newCashFlowProjection projects the expected collections of the flats of source from assumptions.start
+// flats must be as of now, the basic rate and payment plan of unsold flats default to the average and most used of the sales
+func newCashFlowProjection(source masterReportSource, assumptions cashFlowAssumptions) (*cashFlowProjection, error) {
+	start := assumptions.start
+	sold := newCashFlowSeries(assumptions.months)
+	unsold := newCashFlowSeries(assumptions.months)
+	overdue := decimal.Zero
+
+	var unsoldFlats []unsoldFlat
+	basicCost, area := decimal.Zero, decimal.Zero
+	plans := make(map[uuid.UUID]*models.PaymentPlanRatio)
+	planSales := make(map[uuid.UUID]int)
+
+	err := source.each(func(tower *models.Tower, flats []models.Flat) error {
+		for _, flat := range flats {
+			sale := flat.SaleDetail
+			if sale == nil {
+				unsoldFlats = append(unsoldFlats, unsoldFlat{tower: tower, flat: flat})
+				continue
+			}
+
+			activationCtx := models.NewPaymentActivationContext(assumptions.asOf, sale, flat.ActivePaymentPlanRatioItems, tower.ActivePaymentPlanRatioItems)
+			collections := sale.ExpectedCollections(activationCtx, assumptions.schedule.For(flat))
+			sold.add(start, collections)
+			for _, collection := range collections {
+				if collection.Due != nil && collection.Due.Before(start) {
+					overdue = overdue.Add(collection.Amount)
+				}
+			}
+
+			if flat.SaleableArea.IsPositive() {
+				basicCost = basicCost.Add(sale.PriceBreakdown.GetBasicCost())
+				area = area.Add(flat.SaleableArea)
+			}
+			if sale.PaymentPlanRatio != nil {
+				plans[sale.PaymentPlanRatio.Id] = sale.PaymentPlanRatio
+				planSales[sale.PaymentPlanRatio.Id]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	priceList := assumptions.priceList
+	if priceList.BasicRate.IsZero() && area.IsPositive() {
+		priceList.BasicRate = basicCost.Div(area).Round(2)
+	}
+	plan := assumptions.plan
+	if plan == nil {
+		for id, count := range planSales {
+			if plan == nil || count > planSales[plan.Id] || (count == planSales[plan.Id] && id.String() < plan.Id.String()) {
+				plan = plans[id]
+			}
+		}
+	}
+	absorption := 0
+	if assumptions.absorption != nil {
+		absorption = *assumptions.absorption
+	} else if len(unsoldFlats) > 0 {
+		absorption = (len(unsoldFlats) + assumptions.months - 1) / assumptions.months
+	}
+
+	projection := &cashFlowProjection{
+		From:        start.Format(cashFlowMonthLayout),
+		Months:      assumptions.months,
+		Overdue:     overdue,
+		UnsoldFlats: len(unsoldFlats),
+		BasicRate:   priceList.BasicRate,
+		Scenarios:   make([]cashFlowScenario, 0, len(assumptions.delays)),
+	}
+
+	// unsold flats are booked in order on the first of the month at the current price list
+	if plan != nil && absorption > 0 && priceList.BasicRate.IsPositive() {
+		projection.Absorption = absorption
+		projection.PaymentPlanId = &plan.Id
+		for i, unsoldFlat := range unsoldFlats {
+			sale := models.Sale{
+				CreatedAt:        start.AddDate(0, i/absorption, 0),
+				TotalPrice:       priceList.Price(unsoldFlat.flat),
+				PaymentPlanRatio: plan,
+			}
+			activationCtx := models.NewPaymentActivationContext(sale.CreatedAt, &sale, unsoldFlat.flat.ActivePaymentPlanRatioItems, unsoldFlat.tower.ActivePaymentPlanRatioItems)
+			unsold.add(start, sale.ExpectedCollections(activationCtx, assumptions.schedule.For(unsoldFlat.flat)))
+		}
+	}
+
+	for _, delay := range assumptions.delays {
+		scenario := cashFlowScenario{
+			Name:        "On time",
+			DelayMonths: delay,
+			Months:      make([]cashFlowMonth, assumptions.months),
+			Later:       newCashFlowMonth("Later"),
+			Unscheduled: newCashFlowMonth("Unscheduled"),
+			Total:       decimal.Zero,
+		}
+		if delay > 0 {
+			scenario.Name = fmt.Sprintf("Delayed by %d months", delay)
+		}
+		for i := range scenario.Months {
+			scenario.Months[i] = newCashFlowMonth(start.AddDate(0, i, 0).Format(cashFlowMonthLayout))
+		}
+
+		for i := range sold.months {
+			if i+delay < assumptions.months {
+				scenario.Months[i+delay].add(sold.months[i], unsold.months[i])
+			} else {
+				scenario.Later.add(sold.months[i], unsold.months[i])
+			}
+		}
+		scenario.Later.add(sold.later, unsold.later)
+		scenario.Unscheduled.add(sold.unscheduled, unsold.unscheduled)
+		for _, month := range scenario.Months {
+			scenario.Total = scenario.Total.Add(month.Total)
+		}
+		projection.Scenarios = append(projection.Scenarios, scenario)
+	}
+	return projection, nil
+}
+
+// table returns a row per month with the sold, unsold and total collection of every scenario
+// later and unscheduled collections follow the months, xlsx workbooks also get a row with the totals of the months
+func (p *cashFlowProjection) table(format tabular.Format) *tabular.Table {
+	columns := []tabular.Column{{Path: []string{"Month"}}}
+	for _, scenario := range p.Scenarios {
+		columns = append(columns,
+			tabular.Column{Path: []string{scenario.Name, "Sold"}, Monetary: true},
+			tabular.Column{Path: []string{scenario.Name, "Unsold"}, Monetary: true},
+			tabular.Column{Path: []string{scenario.Name, "Total"}, Monetary: true},
+		)
+	}
+
+	row := func(label string, month func(scenario cashFlowScenario) cashFlowMonth) []any {
+		values := []any{label}
+		for _, scenario := range p.Scenarios {
+			m := month(scenario)
+			values = append(values, m.Sold, m.Unsold, m.Total)
+		}
+		return values
+	}
+
+	rows := make([][]any, 0, p.Months+3)
+	for i := 0; i < p.Months; i++ {
+		rows = append(rows, row(p.Scenarios[0].Months[i].Month, func(scenario cashFlowScenario) cashFlowMonth {
+			return scenario.Months[i]
+		}))
+	}
+	rows = append(rows,
+		row("Later", func(scenario cashFlowScenario) cashFlowMonth { return scenario.Later }),
+		row("Unscheduled", func(scenario cashFlowScenario) cashFlowMonth { return scenario.Unscheduled }),
+	)
+
+	if format == tabular.XLSX {
+		rows = append(rows, row("Total", func(scenario cashFlowScenario) cashFlowMonth {
+			total := newCashFlowMonth("Total")
+			for _, month := range scenario.Months {
+				total.add(month.Sold, month.Unsold)
+			}
+			return total
+		}))
+	}
+	return tabular.Rows(cashFlowSheet, columns, rows)
+}
+
+type hGetCashFlowProjection struct {
+	tower       string
+	months      int
+	delays      []int
+	basicRate   decimal.Decimal
+	paymentPlan *uuid.UUID
+	absorption  *int
+	format      tabular.Format
+}
+
+func (h *hGetCashFlowProjection) parse(r *http.Request) error {
+	query := r.URL.Query()
+	h.tower = query.Get("tower")
+
+	h.months = defaultCashFlowMonths
+	if value := query.Get("months"); value != "" {
+		months, err := strconv.Atoi(value)
+		if err != nil || months < 1 || months > maxCashFlowMonths {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("months must be between 1 and %d.", maxCashFlowMonths),
+			}
+		}
+		h.months = months
+	}
+
+	// every projection has the on time scenario, delayed scenarios follow in the order asked
+	h.delays = []int{0}
+	for _, value := range strings.Split(query.Get("delays"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		delay, err := strconv.Atoi(value)
+		if err != nil || delay < 0 || delay > maxCashFlowDelay {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("delays must be months between 0 and %d.", maxCashFlowDelay),
+			}
+		}
+		if !slices.Contains(h.delays, delay) {
+			h.delays = append(h.delays, delay)
+		}
+	}
+
+	if value := query.Get("basicRate"); value != "" {
+		basicRate, err := decimal.NewFromString(value)
+		if err != nil || !basicRate.IsPositive() {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid basic rate.",
+			}
+		}
+		h.basicRate = basicRate
+	}
+
+	if value := query.Get("paymentPlan"); value != "" {
+		paymentPlan, err := uuid.Parse(value)
+		if err != nil {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid payment plan.",
+			}
+		}
+		h.paymentPlan = &paymentPlan
+	}
+
+	if value := query.Get("absorption"); value != "" {
+		absorption, err := strconv.Atoi(value)
+		if err != nil || absorption < 0 {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "absorption must be a non negative number of flats a month.",
+			}
+		}
+		h.absorption = &absorption
+	}
+
+	var err error
+	h.format, err = tabular.Negotiate(r)
+	return err
+}
+
+// execute projects the collections of the society, or of the tower when set, from the current month
+func (h *hGetCashFlowProjection) execute(db *gorm.DB, orgId, society string) (*cashFlowProjection, error) {
+	query := db.Where("org_id = ? AND society_id = ?", orgId, society)
+	if h.tower != "" {
+		query = query.Where("name = ?", h.tower)
+	}
+
+	var towers []models.Tower
+	err := query.
+		Preload("ActivePaymentPlanRatioItems").
+		Order("name").
+		Find(&towers).Error
+	if err != nil {
+		return nil, err
+	}
+	if h.tower != "" && len(towers) == 0 {
+		return nil, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "No tower found",
+		}
+	}
+
+	var milestones []models.ConstructionMilestone
+	err = db.Preload("PaymentPlanItems").
+		Where("org_id = ? AND society_id = ? AND completed_on IS NULL AND planned_date IS NOT NULL", orgId, society).
+		Find(&milestones).Error
+	if err != nil {
+		return nil, err
+	}
+
+	priceList := models.PriceList{BasicRate: h.basicRate}
+	err = db.Where("org_id = ? AND society_id = ?", orgId, society).Find(&priceList.LocationCharges).Error
+	if err != nil {
+		return nil, err
+	}
+	err = db.Where("org_id = ? AND society_id = ?", orgId, society).Find(&priceList.OtherCharges).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var plan *models.PaymentPlanRatio
+	if h.paymentPlan != nil {
+		plan, err = findCashFlowPaymentPlan(db, orgId, society, *h.paymentPlan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	source := &dbMasterReportSource{
+		db:        db,
+		towers:    towers,
+		asOf:      now,
+		batchSize: masterReportBatchSize,
+	}
+	return newCashFlowProjection(source, cashFlowAssumptions{
+		asOf:       now,
+		start:      time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+		months:     h.months,
+		delays:     h.delays,
+		schedule:   models.NewConstructionSchedule(milestones),
+		priceList:  priceList,
+		plan:       plan,
+		absorption: h.absorption,
+	})
+}
+
+// findCashFlowPaymentPlan returns the payment plan ratio with its items if it belongs to the society
+func findCashFlowPaymentPlan(db *gorm.DB, orgId, society string, id uuid.UUID) (*models.PaymentPlanRatio, error) {
+	var plan models.PaymentPlanRatio
+	result := db.
+		Preload("Ratios", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Joins("JOIN payment_plan_groups ON payment_plan_groups.id = payment_plan_ratios.payment_plan_group_id").
+		Where("payment_plan_ratios.id = ? AND payment_plan_groups.org_id = ? AND payment_plan_groups.society_id = ?", id, orgId, society).
+		Limit(1).
+		Find(&plan)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Payment plan not found.",
+		}
+	}
+	return &plan, nil
+}
+
+// generateCashFlowProjection projects the monthly collections of the payment plans, json unless a format is negotiated
+func (s *reportService) generateCashFlowProjection(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+
+	h := hGetCashFlowProjection{}
+	if err := h.parse(r); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	projection, err := h.execute(s.db, orgId, societyRera)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	if !h.format.IsDefault() {
+		fileNameBase := societyRera
+		if h.tower != "" {
+			fileNameBase = fmt.Sprintf("tower_%s", h.tower)
+		}
+		tabular.Respond(w, h.format, fmt.Sprintf("%s_cash_flow_%d", fileNameBase, time.Now().Unix()), projection.table(h.format))
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = projection
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
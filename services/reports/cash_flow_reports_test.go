@@ -0,0 +1,82 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/shopspring/decimal"
+)
+
+func TestCashFlowProjection(t *testing.T) {
+	// flat 0 is unsold, flats 1 and 2 have paid booking and part of within 30 days, within 90 days is overdue
+	source := newSyntheticSource(1, 3, 2)
+	start := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	projection, err := newCashFlowProjection(source, cashFlowAssumptions{
+		asOf:      start,
+		start:     start,
+		months:    12,
+		delays:    []int{0, 3},
+		priceList: models.PriceList{BasicRate: decimal.NewFromInt(8000)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projection.UnsoldFlats != 1 || projection.Absorption != 1 || projection.PaymentPlanId == nil {
+		t.Fatalf("want the unsold flat booked on the plan of the sales, got %+v", projection)
+	}
+	if !projection.Overdue.Equal(decimal.NewFromInt(8810000)) {
+		t.Errorf("want overdue 88,10,000, got %s", projection.Overdue)
+	}
+
+	// the unsold flat of 1250 sq ft is priced 1,00,00,000 and booked in june,
+	// its within-days items count from their creation in january and are due on booking
+	onTime, delayed := projection.Scenarios[0], projection.Scenarios[1]
+	tests := []struct {
+		scenario cashFlowScenario
+		month    int
+		sold     int64
+		unsold   int64
+	}{
+		{onTime, 0, 8810000, 6000000},
+		{onTime, 1, 0, 0},
+		{delayed, 0, 0, 0},
+		{delayed, 3, 8810000, 6000000},
+		{delayed, 4, 0, 0},
+	}
+	for _, test := range tests {
+		month := test.scenario.Months[test.month]
+		if !month.Sold.Equal(decimal.NewFromInt(test.sold)) || !month.Unsold.Equal(decimal.NewFromInt(test.unsold)) {
+			t.Errorf("%s %s: want %d sold %d unsold, got %s %s", test.scenario.Name, month.Month, test.sold, test.unsold, month.Sold, month.Unsold)
+		}
+	}
+	if delayed.Name != "Delayed by 3 months" || !delayed.Total.Equal(onTime.Total) {
+		t.Errorf("want every collection within the months in both scenarios, got %s and %s", onTime.Total, delayed.Total)
+	}
+
+	// on allotment items have no planned date
+	if !onTime.Unscheduled.Sold.Equal(decimal.NewFromInt(7600000)) || !onTime.Unscheduled.Unsold.Equal(decimal.NewFromInt(4000000)) {
+		t.Errorf("unexpected unscheduled %+v", onTime.Unscheduled)
+	}
+
+	if rows := countRows(t, projection.table(tabular.XLSX)); rows != 12+3 {
+		t.Errorf("want months, later, unscheduled and total in xlsx, got %d rows", rows)
+	}
+
+	absorption := 0
+	projection, err = newCashFlowProjection(source, cashFlowAssumptions{
+		asOf:       start,
+		start:      start,
+		months:     6,
+		delays:     []int{0},
+		absorption: &absorption,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !projection.Scenarios[0].Total.Equal(decimal.NewFromInt(8810000)) || projection.PaymentPlanId != nil {
+		t.Errorf("want only sold flats without absorption, got %s", projection.Scenarios[0].Total)
+	}
+}
@@ -0,0 +1,273 @@
+package reports
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const designatedAccountReportSheet = "Designated Account"
+
+// designatedAccountMovement is the money realised and moved through the designated accounts of a society in a quarter,
+// realised amounts exclude taxes and count on clearance of the receipt, transfers count on their transfer date
+type designatedAccountMovement struct {
+	Quarter         string
+	Realised        decimal.Decimal // in any account of the society
+	ClearedDirectly decimal.Decimal // realised in a designated account
+	TransferredIn   decimal.Decimal // from the other accounts into a designated account
+	Withdrawn       decimal.Decimal // from a designated account to the other accounts
+}
+
+// designatedAccountMovements returns the movements of every quarter with any before until, in order of the quarter
+// transfers between two designated accounts or between two other accounts do not move the deposit,
+// the type of an account can't change once it has clearances or transfers so past quarters keep their figures,
+// quarters are of UTC like the collections of the rera report whatever the timezone of the session
+func designatedAccountMovements(db *gorm.DB, orgId, society string, until time.Time) ([]designatedAccountMovement, error) {
+	var movements []designatedAccountMovement
+	err := db.Raw(`
+		WITH movements AS (
+			SELECT
+				rc.created_at AT TIME ZONE 'UTC' AS moved_at,
+				r.amount AS realised,
+				CASE WHEN b.account_type = @designated THEN r.amount ELSE 0 END AS cleared_directly,
+				0 AS transferred_in,
+				0 AS withdrawn
+			FROM receipt_clears rc
+			JOIN banks b ON b.id = rc.bank_id
+			JOIN receipts r ON r.id = rc.receipt_id AND r.mode <> 'adjustment' AND NOT r.failed
+			WHERE b.org_id = @orgId AND b.society_id = @society AND rc.created_at < @until
+			UNION ALL
+			SELECT
+				CAST(t.transfer_date AS timestamp),
+				0,
+				0,
+				CASE WHEN f.account_type <> @designated AND d.account_type = @designated THEN t.amount ELSE 0 END,
+				CASE WHEN f.account_type = @designated AND d.account_type <> @designated THEN t.amount ELSE 0 END
+			FROM bank_transfers t
+			JOIN banks f ON f.id = t.from_bank_id
+			JOIN banks d ON d.id = t.to_bank_id
+			WHERE t.org_id = @orgId AND t.society_id = @society AND t.transfer_date < CAST(@untilDate AS date)
+		)
+		SELECT
+			to_char(date_trunc('quarter', moved_at), 'YYYY-"Q"Q') AS quarter,
+			SUM(realised) AS realised,
+			SUM(cleared_directly) AS cleared_directly,
+			SUM(transferred_in) AS transferred_in,
+			SUM(withdrawn) AS withdrawn
+		FROM movements
+		GROUP BY 1
+		ORDER BY 1
+	`, map[string]any{
+		"orgId":      orgId,
+		"society":    society,
+		"until":      until,
+		"untilDate":  until.UTC().Format(time.DateOnly),
+		"designated": custom.BANK_ACCOUNT_DESIGNATED,
+	}).Scan(&movements).Error
+	return movements, err
+}
+
+// designatedAccountQuarter is the compliance of the deposit in the designated account in a quarter and till its end,
+// the balance is what was deposited and not withdrawn
+type designatedAccountQuarter struct {
+	Quarter         string                             `json:"quarter"`
+	From            custom.DateOnly                    `json:"from"`
+	To              custom.DateOnly                    `json:"to"`
+	InQuarter       models.DesignatedAccountCompliance `json:"inQuarter"`
+	ClearedDirectly decimal.Decimal                    `json:"clearedDirectly"`
+	TransferredIn   decimal.Decimal                    `json:"transferredIn"`
+	Withdrawn       decimal.Decimal                    `json:"withdrawn"`
+	TillQuarter     models.DesignatedAccountCompliance `json:"tillQuarter"`
+	Balance         decimal.Decimal                    `json:"balance"`
+}
+
+type designatedAccountReport struct {
+	DesignatedShare decimal.Decimal            `json:"designatedShare"`
+	Accounts        []models.Bank              `json:"accounts"`
+	Quarters        []designatedAccountQuarter `json:"quarters"`
+	Shortfall       decimal.Decimal            `json:"shortfall"` // till the end of the last quarter
+}
+
+// newDesignatedAccountReport lays the movements out by quarter from from to to,
+// movements of the quarters before from carry into the amounts till the quarter
+func newDesignatedAccountReport(movements []designatedAccountMovement, from, to models.Quarter) *designatedAccountReport {
+	byQuarter := make(map[string]designatedAccountMovement, len(movements))
+	for _, movement := range movements {
+		byQuarter[movement.Quarter] = movement
+	}
+
+	realised, deposited, withdrawn := decimal.Zero, decimal.Zero, decimal.Zero
+	for _, movement := range movements {
+		if movement.Quarter < from.String() {
+			realised = realised.Add(movement.Realised)
+			deposited = deposited.Add(movement.ClearedDirectly).Add(movement.TransferredIn)
+			withdrawn = withdrawn.Add(movement.Withdrawn)
+		}
+	}
+
+	report := &designatedAccountReport{
+		DesignatedShare: models.ReraDesignatedShare,
+		Accounts:        []models.Bank{},
+		Quarters:        []designatedAccountQuarter{},
+		Shortfall:       decimal.Zero,
+	}
+	for quarter := from; !quarter.Start().After(to.Start()); quarter = models.QuarterOf(quarter.End()) {
+		movement := byQuarter[quarter.String()]
+		depositedInQuarter := movement.ClearedDirectly.Add(movement.TransferredIn)
+		realised = realised.Add(movement.Realised)
+		deposited = deposited.Add(depositedInQuarter)
+		withdrawn = withdrawn.Add(movement.Withdrawn)
+
+		tillQuarter := models.NewDesignatedAccountCompliance(realised, deposited)
+		report.Quarters = append(report.Quarters, designatedAccountQuarter{
+			Quarter:         quarter.String(),
+			From:            custom.DateOnly{Time: quarter.Start()},
+			To:              custom.DateOnly{Time: quarter.End().AddDate(0, 0, -1)},
+			InQuarter:       models.NewDesignatedAccountCompliance(movement.Realised, depositedInQuarter),
+			ClearedDirectly: movement.ClearedDirectly,
+			TransferredIn:   movement.TransferredIn,
+			Withdrawn:       movement.Withdrawn,
+			TillQuarter:     tillQuarter,
+			Balance:         deposited.Sub(withdrawn),
+		})
+		report.Shortfall = tillQuarter.Shortfall
+	}
+	return report
+}
+
+func (report *designatedAccountReport) table() *tabular.Table {
+	share := report.DesignatedShare.Mul(decimal.NewFromInt(100)).String()
+	columns := []tabular.Column{
+		{Path: []string{"Quarter"}},
+		{Path: []string{"In Quarter", "Realised"}, Monetary: true},
+		{Path: []string{"In Quarter", fmt.Sprintf("Required (%s%%)", share)}, Monetary: true},
+		{Path: []string{"In Quarter", "Cleared in Designated Account"}, Monetary: true},
+		{Path: []string{"In Quarter", "Transferred to Designated Account"}, Monetary: true},
+		{Path: []string{"In Quarter", "Deposited"}, Monetary: true},
+		{Path: []string{"In Quarter", "Shortfall"}, Monetary: true},
+		{Path: []string{"In Quarter", "Withdrawn"}, Monetary: true},
+		{Path: []string{"Till Quarter", "Realised"}, Monetary: true},
+		{Path: []string{"Till Quarter", fmt.Sprintf("Required (%s%%)", share)}, Monetary: true},
+		{Path: []string{"Till Quarter", "Deposited"}, Monetary: true},
+		{Path: []string{"Till Quarter", "Shortfall"}, Monetary: true},
+		{Path: []string{"Till Quarter", "Balance"}, Monetary: true},
+	}
+
+	rows := make([][]any, 0, len(report.Quarters))
+	for _, quarter := range report.Quarters {
+		rows = append(rows, []any{
+			quarter.Quarter,
+			quarter.InQuarter.Realised,
+			quarter.InQuarter.Required,
+			quarter.ClearedDirectly,
+			quarter.TransferredIn,
+			quarter.InQuarter.Deposited,
+			quarter.InQuarter.Shortfall,
+			quarter.Withdrawn,
+			quarter.TillQuarter.Realised,
+			quarter.TillQuarter.Required,
+			quarter.TillQuarter.Deposited,
+			quarter.TillQuarter.Shortfall,
+			quarter.Balance,
+		})
+	}
+	return tabular.Rows(designatedAccountReportSheet, columns, rows)
+}
+
+// from and to are quarters, the last four quarters till the current one by default
+type hGetDesignatedAccountReport struct {
+	from   models.Quarter
+	to     models.Quarter
+	format tabular.Format
+}
+
+func (h *hGetDesignatedAccountReport) parse(r *http.Request) error {
+	query := r.URL.Query()
+
+	h.to = models.QuarterOf(time.Now())
+	if value := strings.TrimSpace(query.Get("to")); value != "" {
+		to, err := models.ParseQuarter(value)
+		if err != nil {
+			return err
+		}
+		h.to = to
+	}
+
+	h.from = models.QuarterOf(h.to.Start().AddDate(0, -9, 0))
+	if value := strings.TrimSpace(query.Get("from")); value != "" {
+		from, err := models.ParseQuarter(value)
+		if err != nil {
+			return err
+		}
+		h.from = from
+	}
+	if h.from.Start().After(h.to.Start()) {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "from must not be after to.",
+		}
+	}
+
+	format, err := tabular.Negotiate(r)
+	if err != nil {
+		return err
+	}
+	h.format = format
+	return nil
+}
+
+// execute checks the deposit in the designated accounts of the society against the share required of every quarter
+func (h *hGetDesignatedAccountReport) execute(db *gorm.DB, orgId, society string) (*designatedAccountReport, error) {
+	movements, err := designatedAccountMovements(db, orgId, society, h.to.End())
+	if err != nil {
+		return nil, err
+	}
+
+	report := newDesignatedAccountReport(movements, h.from, h.to)
+	err = db.
+		Where("org_id = ? and society_id = ? and account_type = ?", orgId, society, custom.BANK_ACCOUNT_DESIGNATED).
+		Order("name").
+		Find(&report.Accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *reportService) generateDesignatedAccountReport(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+
+	h := hGetDesignatedAccountReport{}
+	if err := h.parse(r); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	report, err := h.execute(s.db, orgId, societyRera)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	if !h.format.IsDefault() {
+		fileName := fmt.Sprintf("%s_designated_account_%s_%s", societyRera, h.from, h.to)
+		tabular.Respond(w, h.format, fileName, report.table())
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = report
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,70 @@
+package reports
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestDesignatedAccountReport(t *testing.T) {
+	movements := []designatedAccountMovement{
+		{Quarter: "2024-Q4", Realised: decimal.NewFromInt(1000000), ClearedDirectly: decimal.NewFromInt(200000), TransferredIn: decimal.NewFromInt(600000)},
+		{Quarter: "2025-Q1", Realised: decimal.NewFromInt(2000000), TransferredIn: decimal.NewFromInt(1000000), Withdrawn: decimal.NewFromInt(300000)},
+		{Quarter: "2025-Q3", Realised: decimal.NewFromInt(500000), ClearedDirectly: decimal.NewFromInt(500000)},
+	}
+	report := newDesignatedAccountReport(movements, models.Quarter{Year: 2025, Number: 1}, models.Quarter{Year: 2025, Number: 3})
+
+	if len(report.Quarters) != 3 {
+		t.Fatalf("want a row for every quarter, got %d", len(report.Quarters))
+	}
+	tests := []struct {
+		quarter       string
+		shortfall     int64
+		tillRealised  int64
+		tillShortfall int64
+		balance       int64
+	}{
+		// the excess of 2024-Q4 carries into the deposit till the quarter
+		{"2025-Q1", 400000, 3000000, 300000, 1500000},
+		{"2025-Q2", 0, 3000000, 300000, 1500000},
+		{"2025-Q3", 0, 3500000, 150000, 2000000},
+	}
+	for i, test := range tests {
+		quarter := report.Quarters[i]
+		if quarter.Quarter != test.quarter ||
+			!quarter.InQuarter.Shortfall.Equal(decimal.NewFromInt(test.shortfall)) ||
+			!quarter.TillQuarter.Realised.Equal(decimal.NewFromInt(test.tillRealised)) ||
+			!quarter.TillQuarter.Shortfall.Equal(decimal.NewFromInt(test.tillShortfall)) ||
+			!quarter.Balance.Equal(decimal.NewFromInt(test.balance)) {
+			t.Errorf("%s: unexpected %+v", test.quarter, quarter)
+		}
+	}
+	if !report.Shortfall.Equal(decimal.NewFromInt(150000)) {
+		t.Errorf("want shortfall of 1,50,000 at the end, got %s", report.Shortfall)
+	}
+	if rows := countRows(t, report.table()); rows != 3 {
+		t.Errorf("want a row per quarter, got %d", rows)
+	}
+}
+
+func TestDesignatedAccountReportParse(t *testing.T) {
+	h := hGetDesignatedAccountReport{}
+	if err := h.parse(httptest.NewRequest(http.MethodGet, "/designated-account", nil)); err != nil {
+		t.Fatal(err)
+	}
+	current := models.QuarterOf(time.Now())
+	if h.to != current || h.from != current.Previous().Previous().Previous() {
+		t.Errorf("want the last four quarters, got %s to %s", h.from, h.to)
+	}
+
+	for _, target := range []string{"/designated-account?from=2025-Q3&to=2025-Q1", "/designated-account?to=2025"} {
+		h := hGetDesignatedAccountReport{}
+		if err := h.parse(httptest.NewRequest(http.MethodGet, target, nil)); err == nil {
+			t.Errorf("%s: want error", target)
+		}
+	}
+}
@@ -3,35 +3,30 @@ package reports
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
 )
 
 // Indian number format without symbol: 1,00,00,000
-const IndianNumberFormat = `[>=10000000]##\,##\,##\,##0;[>=100000]##\,##\,##0;##,##0`
+const IndianNumberFormat = tabular.IndianNumberFormat
 
 // Alternative format with decimals: 1,00,00,000.00
 const IndianNumberFormatDecimal = `[>=10000000]##\,##\,##\,##0.00;[>=100000]##\,##\,##0.00;##,##0.00`
 
-// List of monetary field keywords to identify money columns
-var monetaryFieldKeywords = []string{
-	"amount", "price", "total", "paid", "pending", "payable",
-	"cgst", "sgst", "service tax", "swathch bharat cess", "krishi kalyan cess",
-	"tax", "cess", "gst", "fee", "charges", "cost", "value", "payment",
-	"collection", "receipt", "balance", "due", "outstanding", "security",
-	"maintenance", "ifms", "₹", "rs", "inr",
-}
-
 type paymentPlanItemInfo struct {
 	ID          uuid.UUID
 	Description string
@@ -48,82 +43,6 @@ func (p paymentPlanInfo) getHeading() string {
 	return fmt.Sprintf("%s (%s)", p.Name, p.Ratio)
 }
 
-func (p paymentPlanInfo) getItems() []models.Header {
-	items := make([]models.Header, 0, len(p.Items))
-	for _, item := range p.Items {
-		items = append(items, models.Header{
-			ID:      &item.ID,
-			Heading: item.Description,
-			Items: []models.Header{
-				{Heading: "Collection Date"},
-				{Heading: "Total Amount", IsMonetary: true},
-				{Heading: "Paid", IsMonetary: true},
-				{Heading: "Pending", IsMonetary: true},
-			},
-		})
-	}
-
-	return items
-}
-
-// isMonetaryColumn checks if a column header represents a monetary field
-func isMonetaryColumn(heading string) bool {
-	headingLower := strings.ToLower(heading)
-	for _, keyword := range monetaryFieldKeywords {
-		if strings.Contains(headingLower, keyword) {
-			return true
-		}
-	}
-	return false
-}
-
-// getMonetaryColumnIndices returns a map of column indices that contain monetary data
-func getMonetaryColumnIndices(headers []models.Header) map[int]bool {
-	monetaryColumns := make(map[int]bool)
-	colIndex := 1 // Start from 1 (Excel columns are 1-indexed)
-
-	var traverse func(hs []models.Header)
-	traverse = func(hs []models.Header) {
-		for _, h := range hs {
-			if len(h.Items) > 0 {
-				traverse(h.Items)
-			} else {
-				// Leaf node - check if monetary
-				if h.IsMonetary || isMonetaryColumn(h.Heading) {
-					monetaryColumns[colIndex] = true
-				}
-				colIndex++
-			}
-		}
-	}
-	traverse(headers)
-
-	return monetaryColumns
-}
-
-// getYellowMonetaryColumnIndices returns indices of monetary columns with yellow color
-func getYellowMonetaryColumnIndices(headers []models.Header) map[int]bool {
-	yellowColumns := make(map[int]bool)
-	colIndex := 1
-
-	var traverse func(hs []models.Header)
-	traverse = func(hs []models.Header) {
-		for _, h := range hs {
-			if len(h.Items) > 0 {
-				traverse(h.Items)
-			} else {
-				if (h.IsMonetary || isMonetaryColumn(h.Heading)) && strings.ToLower(h.Color) == "yellow" {
-					yellowColumns[colIndex] = true
-				}
-				colIndex++
-			}
-		}
-	}
-	traverse(headers)
-
-	return yellowColumns
-}
-
 // createNumberStyle creates an Excel style for Indian number format (no symbol)
 func createNumberStyle(file *excelize.File) (int, error) {
 	return file.NewStyle(&excelize.Style{
@@ -158,15 +77,15 @@ func createNumberStyleWithColor(file *excelize.File, colorHex string) (int, erro
 	})
 }
 
-// parseToFloat converts a string value to float64
-// Returns the float value and true if successful, 0 and false otherwise
-func parseToFloat(val string) (float64, bool) {
+// parseAmount converts a monetary value of a report row to a decimal
+// returns false for placeholders and values that are not numbers
+func parseAmount(val string) (decimal.Decimal, bool) {
 	// Trim whitespace
 	s := strings.TrimSpace(val)
 
 	// Skip empty or placeholder values
 	if s == "" || s == "-" || s == "N/A" || s == "NA" || s == "null" || s == "nil" {
-		return 0, false
+		return decimal.Zero, false
 	}
 
 	// Remove currency symbols and formatting
@@ -177,395 +96,365 @@ func parseToFloat(val string) (float64, bool) {
 	s = strings.ReplaceAll(s, " ", "")
 	s = strings.TrimSpace(s)
 
-	// Try to parse as float
-	if f, err := strconv.ParseFloat(s, 64); err == nil {
-		return f, true
+	amount, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Zero, false
 	}
-
-	return 0, false
+	return amount, true
 }
 
-func newMasterReportSheetManual(file *excelize.File, tower models.Tower) error {
-	sheet := tower.Name
-	_, err := file.NewSheet(sheet)
-	if err != nil {
-		return err
-	}
+// masterReportSheet is the single sheet of the master report
+const masterReportSheet = "Master Report"
 
-	// base headers with IsMonetary flag for monetary columns
-	baseHeaders := []models.Header{
-		{
-			Heading: models.HeadingFlat,
-			Items: []models.Header{
-				{Heading: "Tower"},
-				{Heading: "Floor"},
-				{Heading: "Flat"},
-				{Heading: "Facing"},
-				{Heading: "Unit Type"},
-				{Heading: "Saleable Area"},
-			},
-		},
-		{
-			Heading: models.HeadingPaymentPlan,
-			Items: []models.Header{
-				{Heading: "Name"},
-			},
-		},
-		{
-			Heading: models.HeadingCustomer,
-			Items: []models.Header{
-				{Heading: "Name"},
-				{Heading: "Email"},
-				{Heading: "Phone Number"},
-				{Heading: "Nationality"},
-				{Heading: "Aadhar"},
-				{Heading: "PAN"},
-				{Heading: "Passport Number"},
-				{Heading: "Profession"},
-				{Heading: "Company Name"},
-			},
-		},
-		{
-			Heading: models.HeadingCompanyCustomer,
-			Items: []models.Header{
-				{Heading: "Name"},
-				{Heading: "Company PAN"},
-				{Heading: "GST"},
-				{Heading: "Aadhar"},
-				{Heading: "PAN"},
-			},
-		},
-	}
+// masterReportBatchSize is the number of flats loaded at a time, memory used by the report is bounded by a batch
+const masterReportBatchSize = 250
 
-	// get unique sale price breakdown values while preserving order
-	salePriceBreakdownSet := make(map[string]bool)
-	salePriceBreakDownSlice := make([]models.Header, 0)
-	var ifmsHeader *models.Header
+// masterReportSource yields the flats of the master report tower by tower in batches
+// each is called twice, once to collect the headers and once to write the rows
+type masterReportSource interface {
+	each(fn func(tower *models.Tower, flats []models.Flat) error) error
+}
 
-	for _, flat := range tower.Flats {
-		if flat.SaleDetail != nil {
-			for _, priceBreakdownItem := range flat.SaleDetail.PriceBreakdown {
-				summary := priceBreakdownItem.Summary
-				if !salePriceBreakdownSet[summary] {
-					salePriceBreakdownSet[summary] = true
+// dbMasterReportSource loads the flats of the towers in keyset paginated batches ordered by flat name
+// flats are returned as they were on asOf
+type dbMasterReportSource struct {
+	db        *gorm.DB
+	towers    []models.Tower
+	asOf      time.Time
+	batchSize int
+}
 
-					// All price breakdown items are monetary
-					header := models.Header{
-						Heading:    summary,
-						IsMonetary: true,
-					}
+func (s *dbMasterReportSource) each(fn func(tower *models.Tower, flats []models.Flat) error) error {
+	for i := range s.towers {
+		tower := &s.towers[i]
 
-					if summary == "Intrest Free Maintenance Security (IFMS)" {
-						ifmsHeader = &header
-					} else {
-						salePriceBreakDownSlice = append(salePriceBreakDownSlice, header)
-					}
-				}
+		var lastName string
+		var lastId uuid.UUID
+		for {
+			query := s.db.Where("tower_id = ?", tower.Id)
+			if lastId != uuid.Nil {
+				query = query.Where("(name, id) > (?, ?)", lastName, lastId)
+			}
+
+			var flats []models.Flat
+			err := query.
+				Preload("ActivePaymentPlanRatioItems").
+				Preload("SaleDetail").
+				Preload("SaleDetail.PaymentPlanRatio").
+				Preload("SaleDetail.PaymentPlanRatio.PaymentPlanGroup").
+				Preload("SaleDetail.PaymentPlanRatio.Ratios").
+				Preload("SaleDetail.Receipts").
+				Preload("SaleDetail.Receipts.Cleared").
+				Preload("SaleDetail.Broker").
+				Preload("SaleDetail.ReferrerCustomer").
+				Preload("SaleDetail.Allotment").
+				Preload("SaleDetail.Customers").
+				Preload("SaleDetail.CompanyCustomer").
+				Order("name, id").
+				Limit(s.batchSize).
+				Find(&flats).Error
+			if err != nil {
+				return err
+			}
+			if len(flats) == 0 {
+				break
+			}
+			lastName, lastId = flats[len(flats)-1].Name, flats[len(flats)-1].Id
+
+			// reproduce sales and receipts as they were on asOf
+			for j := range flats {
+				flats[j] = flats[j].AsOf(s.asOf)
+			}
+			if err := fn(tower, flats); err != nil {
+				return err
+			}
+			if len(flats) < s.batchSize {
+				break
 			}
 		}
 	}
+	return nil
+}
+
+// masterReportHeaders collects the columns that depend on the sales of the report
+type masterReportHeaders struct {
+	priceBreakdownSet map[string]bool
+	priceBreakdown    []models.Header
+	ifms              *models.Header
+	paymentPlanSet    map[uuid.UUID]bool
+	paymentPlans      []paymentPlanInfo
+	installmentCount  int
+}
 
-	if ifmsHeader != nil {
-		salePriceBreakDownSlice = append(salePriceBreakDownSlice, *ifmsHeader)
+func newMasterReportHeaders() *masterReportHeaders {
+	return &masterReportHeaders{
+		priceBreakdownSet: make(map[string]bool),
+		paymentPlanSet:    make(map[uuid.UUID]bool),
 	}
+}
 
-	baseHeaders = append(baseHeaders, models.Header{
-		Heading: models.HeadingPricebreakdown,
-		Items:   salePriceBreakDownSlice,
-	})
+// add collects the price breakdown, payment plans and installments of the flats
+func (c *masterReportHeaders) add(flats []models.Flat) {
+	for _, flat := range flats {
+		if flat.SaleDetail == nil {
+			continue
+		}
+
+		// unique sale price breakdown values while preserving order, ifms is kept last
+		for _, priceBreakdownItem := range flat.SaleDetail.PriceBreakdown {
+			summary := priceBreakdownItem.Summary
+			if c.priceBreakdownSet[summary] {
+				continue
+			}
+			c.priceBreakdownSet[summary] = true
+
+			header := models.Header{
+				Heading:    summary,
+				IsMonetary: true,
+			}
+			if summary == "Intrest Free Maintenance Security (IFMS)" {
+				c.ifms = &header
+			} else {
+				c.priceBreakdown = append(c.priceBreakdown, header)
+			}
+		}
 
-	// add broker details - mark monetary columns
-	baseHeaders = append(baseHeaders,
-		[]models.Header{
-			{
-				Heading: models.HeadingSale,
-				Items: []models.Header{
-					{Heading: "Total Price", IsMonetary: true},
-					{Heading: "Total Payable Amount", IsMonetary: true},
-					{Heading: "Total Paid Amount", IsMonetary: true},
-					{Heading: "Paid Amount", Color: "yellow", IsMonetary: true},
-					{Heading: "CGST", Color: "yellow", IsMonetary: true},
-					{Heading: "SGST", Color: "yellow", IsMonetary: true},
-					{Heading: "Service Tax", Color: "yellow", IsMonetary: true},
-					{Heading: "Swathch Bharat Cess", Color: "yellow", IsMonetary: true},
-					{Heading: "Krishi Kalyan Cess", Color: "yellow", IsMonetary: true},
-					{Heading: "Pending Amount", IsMonetary: true},
-				},
-			},
-			{
-				Heading: models.HeadingBroker,
-				Items: []models.Header{
-					{Heading: "Name"},
-					{Heading: "Aadhar"},
-					{Heading: "PAN"},
-				},
-			},
-		}...,
-	)
-
-	// get unique payment plans
-	paymentPlanDetails := make(map[uuid.UUID]paymentPlanInfo)
-	for _, flat := range tower.Flats {
-		if flat.SaleDetail != nil && flat.SaleDetail.PaymentPlanRatio != nil {
-			ratioKey := flat.SaleDetail.PaymentPlanRatioId
-			ratioItems := make([]paymentPlanItemInfo, 0, len(flat.SaleDetail.PaymentPlanRatio.Ratios))
-
-			for _, ratioItem := range flat.SaleDetail.PaymentPlanRatio.Ratios {
+		if ratio := flat.SaleDetail.PaymentPlanRatio; ratio != nil && !c.paymentPlanSet[flat.SaleDetail.PaymentPlanRatioId] {
+			c.paymentPlanSet[flat.SaleDetail.PaymentPlanRatioId] = true
+
+			ratioItems := make([]paymentPlanItemInfo, 0, len(ratio.Ratios))
+			for _, ratioItem := range ratio.Ratios {
 				ratioItems = append(ratioItems, paymentPlanItemInfo{
 					ID:          ratioItem.Id,
 					Description: ratioItem.Description,
 				})
 			}
 
-			paymentPlanDetails[ratioKey] = paymentPlanInfo{
-				ID:    ratioKey,
-				Name:  flat.SaleDetail.PaymentPlanRatio.PaymentPlanGroup.Name,
-				Ratio: flat.SaleDetail.PaymentPlanRatio.Ratio,
-				Items: ratioItems,
+			name := ""
+			if ratio.PaymentPlanGroup != nil {
+				name = ratio.PaymentPlanGroup.Name
 			}
+			c.paymentPlans = append(c.paymentPlans, paymentPlanInfo{
+				ID:    flat.SaleDetail.PaymentPlanRatioId,
+				Name:  name,
+				Ratio: ratio.Ratio,
+				Items: ratioItems,
+			})
 		}
-	}
 
-	// add payment plan headers (these have monetary sub-items defined in getItems())
-	for _, item := range paymentPlanDetails {
-		baseHeaders = append(baseHeaders, models.Header{
-			ID:      &item.ID,
-			Heading: item.getHeading(),
-			Items:   item.getItems(),
-		})
-	}
-
-	// get max valid installment number
-	installmentCount := 0
-	for _, flat := range tower.Flats {
-		if flat.SaleDetail != nil {
-			installmentCount = max(installmentCount, flat.SaleDetail.GetValidReceiptsCount())
-		}
+		c.installmentCount = max(c.installmentCount, flat.SaleDetail.GetValidReceiptsCount())
 	}
+}
 
-	if installmentCount > 0 {
-		installmentItems := make([]models.Header, 0, installmentCount)
-
-		for i := 1; i <= installmentCount; i++ {
-			installmentItems = append(installmentItems, models.Header{
-				Heading: strconv.Itoa(i),
-				Items: []models.Header{
-					{Heading: "Number"},
-					{Heading: "Date"},
-					{Heading: "Amount", IsMonetary: true},
-					{Heading: "Type"},
-					{Heading: "CGST", IsMonetary: true},
-					{Heading: "SGST", IsMonetary: true},
-					{Heading: "Service Tax", IsMonetary: true},
-					{Heading: "Swathch Bharat Cess", IsMonetary: true},
-					{Heading: "Krishi Kalyan Cess", IsMonetary: true},
-					{Heading: "Status"},
-					{Heading: "Cleared At"},
-				},
-			})
+// headers returns the header tree of the report for the layout, repeated groups are expanded with the collected items
+func (c *masterReportHeaders) headers(layout models.ReportLayout) []models.Header {
+	var headers []models.Header
+	for _, group := range layout.Resolve() {
+		columns := models.ReportColumnHeaders(group.Columns)
+
+		switch group.Id {
+		case models.ColumnGroupMember:
+			// member id is a single column without group heading
+			headers = append(headers, columns...)
+		case models.ColumnGroupPriceBreakdown:
+			// a column for each price breakdown item, ifms is kept last
+			summaries := append([]models.Header{}, c.priceBreakdown...)
+			if c.ifms != nil {
+				summaries = append(summaries, *c.ifms)
+			}
+			items := make([]models.Header, 0, len(summaries)*len(columns))
+			for _, summary := range summaries {
+				for _, column := range columns {
+					column.Param = summary.Heading
+					column.Heading = summary.Heading
+					items = append(items, column)
+				}
+			}
+			headers = append(headers, models.Header{Key: group.Id, Heading: group.Heading, Items: items})
+		case models.ColumnGroupPaymentPlanItems:
+			// a heading for each payment plan of the sales, headings of plans come from the plans
+			for _, plan := range c.paymentPlans {
+				items := make([]models.Header, 0, len(plan.Items))
+				for _, item := range plan.Items {
+					items = append(items, models.Header{
+						ID:      &item.ID,
+						Heading: item.Description,
+						Items:   columns,
+					})
+				}
+				headers = append(headers, models.Header{
+					ID:      &plan.ID,
+					Key:     group.Id,
+					Heading: plan.getHeading(),
+					Items:   items,
+				})
+			}
+		case models.ColumnGroupInstallments:
+			if c.installmentCount == 0 {
+				continue
+			}
+			items := make([]models.Header, 0, c.installmentCount)
+			for i := 1; i <= c.installmentCount; i++ {
+				items = append(items, models.Header{
+					Param:   strconv.Itoa(i),
+					Heading: strconv.Itoa(i),
+					Items:   columns,
+				})
+			}
+			headers = append(headers, models.Header{Key: group.Id, Heading: group.Heading, Items: items})
+		default:
+			headers = append(headers, models.Header{Key: group.Id, Heading: group.Heading, Items: columns})
 		}
-
-		baseHeaders = append(baseHeaders, models.Header{
-			Heading: models.HeadingInstallment,
-			Items:   installmentItems,
-		})
 	}
+	return headers
+}
 
-	// Create base style for headers
-	headerStyle, err := file.NewStyle(&excelize.Style{
-		Font:      &excelize.Font{Bold: true},
-		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
-	})
-	if err != nil {
-		return err
-	}
+// masterReportColumn is the style of a leaf column, precomputed from the header tree
+type masterReportColumn struct {
+	monetary bool
+	style    int
+}
 
-	// Create number style for monetary cells (Indian format without symbol)
+// newMasterReportColumns returns the leaf columns of the header tree in order
+// monetary columns are written as numbers in indian format, yellow ones with yellow background
+func newMasterReportColumns(file *excelize.File, headers []models.Header) ([]masterReportColumn, error) {
 	numberStyle, err := createNumberStyle(file)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Create number style with yellow background
 	numberStyleYellow, err := createNumberStyleWithColor(file, "#FFFF00")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	maxDepth := getMaxDepth(baseHeaders, 1)
-	colIndex := 1
+	var columns []masterReportColumn
+	var traverse func(hs []models.Header)
+	traverse = func(hs []models.Header) {
+		for _, h := range hs {
+			if len(h.Items) > 0 {
+				traverse(h.Items)
+				continue
+			}
 
-	headers := []models.Header{
-		{
-			Heading: "Member ID",
-		},
+			column := masterReportColumn{monetary: h.IsMonetary}
+			if column.monetary {
+				column.style = numberStyle
+				if strings.ToLower(h.Color) == "yellow" {
+					column.style = numberStyleYellow
+				}
+			}
+			columns = append(columns, column)
+		}
 	}
+	traverse(headers)
 
-	headers = append(headers, baseHeaders...)
+	return columns, nil
+}
 
-	_, err = renderHeaders(file, sheet, headers, 1, &colIndex, maxDepth, headerStyle)
-	if err != nil {
-		return err
-	}
+// headerColors maps header colors to their fill
+var headerColors = map[string]string{
+	"yellow": "#FFFF00",
+	"green":  "#00FF00",
+	"red":    "#FF0000",
+	"blue":   "#007BFF",
+	"gray":   "#D3D3D3",
+}
 
-	// Set column widths
-	for i := 1; i < colIndex; i++ {
-		colName, _ := excelize.ColumnNumberToName(i)
-		maxWidth := getMaxColumnWidth(file, sheet, colName, maxDepth)
-		file.SetColWidth(sheet, colName, colName, maxWidth)
+// layoutHeaders lays the header tree out as maxDepth rows of cells with the cell ranges to merge
+// a parent spans the columns of its items, a leaf spans the rows down to maxDepth
+func layoutHeaders(file *excelize.File, headers []models.Header, columnCount, maxDepth, baseStyle int) ([][]any, [][2]string, error) {
+	rows := make([][]any, maxDepth)
+	for i := range rows {
+		rows[i] = make([]any, columnCount)
 	}
+	var merges [][2]string
+	styleCache := make(map[string]int)
 
-	// Get monetary column indices
-	monetaryColumns := getMonetaryColumnIndices(headers)
-
-	// Get columns that should have yellow background
-	yellowMonetaryColumns := getYellowMonetaryColumnIndices(headers)
-
-	startRow := maxDepth + 1
-
-	for i, flat := range tower.Flats {
-		rowNum := startRow + i
-		values := flat.GetRowData(baseHeaders, sheet, models.SafePrint{
-			ShouldPrint: i == 0 && sheet == "A",
-		}, tower.ActivePaymentPlanRatioItems)
-
-		for colIdx, val := range values {
-			colNum := colIdx + 1
-			colName, _ := excelize.ColumnNumberToName(colNum)
-			cell := fmt.Sprintf("%s%d", colName, rowNum)
-
-			// Check if this is a monetary column
-			if monetaryColumns[colNum] {
-				// Try to convert string value to float for proper Excel number formatting
-				if numVal, ok := parseToFloat(val); ok {
-					// Set as numeric value - this is KEY for Excel formatting to work
-					file.SetCellFloat(sheet, cell, numVal, -1, 64)
+	colorStyle := func(color string) (int, error) {
+		colorName := strings.ToLower(color)
+		if cached, ok := styleCache[colorName]; ok {
+			return cached, nil
+		}
 
-					// Apply appropriate number style
-					if yellowMonetaryColumns[colNum] {
-						file.SetCellStyle(sheet, cell, cell, numberStyleYellow)
-					} else {
-						file.SetCellStyle(sheet, cell, cell, numberStyle)
-					}
-				} else {
-					// If not a valid number (like "-"), set as string
-					file.SetCellValue(sheet, cell, val)
-				}
-			} else {
-				file.SetCellValue(sheet, cell, val)
-			}
+		colorHex := headerColors[colorName]
+		if colorHex == "" {
+			colorHex = color
 		}
-	}
 
-	return nil
-}
+		baseStyleDef, err := file.GetStyle(baseStyle)
+		if err != nil {
+			return 0, err
+		}
+		style := *baseStyleDef
+		style.Fill = excelize.Fill{
+			Type:    "pattern",
+			Color:   []string{colorHex},
+			Pattern: 1,
+		}
+		style.Border = []excelize.Border{
+			{Type: "left", Color: "000000", Style: 1},
+			{Type: "right", Color: "000000", Style: 1},
+			{Type: "top", Color: "000000", Style: 1},
+			{Type: "bottom", Color: "000000", Style: 1},
+		}
 
-// Helper: find max string length in a column
-func getMaxColumnWidth(f *excelize.File, sheet, col string, maxRow int) float64 {
-	maxLen := 0
-	for row := 1; row <= maxRow; row++ {
-		cell := fmt.Sprintf("%s%d", col, row)
-		val, _ := f.GetCellValue(sheet, cell)
-		if len(val) > maxLen {
-			maxLen = len(val)
+		styleID, err := file.NewStyle(&style)
+		if err != nil {
+			return 0, err
 		}
+		styleCache[colorName] = styleID
+		return styleID, nil
 	}
-	// Multiply by 1.2 for padding, min 12 for currency columns
-	width := float64(min(maxLen, 15)) * 1.2
-	if width < 12 {
-		width = 12 // Minimum width for currency display
-	}
-	return width
-}
 
-func renderHeaders(f *excelize.File, sheet string, headers []models.Header, row int, colIndex *int, maxDepth int, baseStyle int) (int, error) {
-	styleCache := make(map[string]int)
-
-	var applyHeader func([]models.Header, int, string) (int, error)
-	applyHeader = func(hs []models.Header, currentRow int, parent string) (int, error) {
+	colIndex := 1
+	var apply func(hs []models.Header, row int) error
+	apply = func(hs []models.Header, row int) error {
 		for _, h := range hs {
-			startCol := *colIndex
+			startCol := colIndex
 			if len(h.Items) > 0 {
-				_, err := applyHeader(h.Items, currentRow+1, h.Heading)
-				if err != nil {
-					return 0, err
+				if err := apply(h.Items, row+1); err != nil {
+					return err
 				}
 			} else {
-				*colIndex++
+				colIndex++
 			}
-			endCol := *colIndex - 1
+			endCol := colIndex - 1
 
-			startColName, _ := excelize.ColumnNumberToName(startCol)
-			endColName, _ := excelize.ColumnNumberToName(endCol)
-
-			if endCol > startCol {
-				if err := f.MergeCell(sheet, fmt.Sprintf("%s%d", startColName, currentRow),
-					fmt.Sprintf("%s%d", endColName, currentRow)); err != nil {
-					return 0, err
-				}
+			endRow := row
+			if len(h.Items) == 0 {
+				endRow = maxDepth
 			}
-
-			if len(h.Items) == 0 && currentRow < maxDepth {
-				if err := f.MergeCell(sheet,
-					fmt.Sprintf("%s%d", startColName, currentRow),
-					fmt.Sprintf("%s%d", startColName, maxDepth)); err != nil {
-					return 0, err
-				}
+			if endCol > startCol || endRow > row {
+				topLeft, _ := excelize.CoordinatesToCellName(startCol, row)
+				bottomRight, _ := excelize.CoordinatesToCellName(endCol, endRow)
+				merges = append(merges, [2]string{topLeft, bottomRight})
 			}
 
-			cell := fmt.Sprintf("%s%d", startColName, currentRow)
-			f.SetCellValue(sheet, cell, h.Heading)
-
 			styleID := baseStyle
 			if h.Color != "" {
-				colorName := strings.ToLower(h.Color)
-
-				if cached, ok := styleCache[colorName]; ok {
-					styleID = cached
-				} else {
-					colorHex := map[string]string{
-						"yellow": "#FFFF00",
-						"green":  "#00FF00",
-						"red":    "#FF0000",
-						"blue":   "#007BFF",
-						"gray":   "#D3D3D3",
-					}[colorName]
-					if colorHex == "" {
-						colorHex = h.Color
-					}
-
-					styleJSON, _ := f.GetStyle(baseStyle)
-					newStyle := *styleJSON
-
-					newStyle.Fill = excelize.Fill{
-						Type:    "pattern",
-						Color:   []string{colorHex},
-						Pattern: 1,
-					}
-
-					newStyle.Border = []excelize.Border{
-						{Type: "left", Color: "000000", Style: 1},
-						{Type: "right", Color: "000000", Style: 1},
-						{Type: "top", Color: "000000", Style: 1},
-						{Type: "bottom", Color: "000000", Style: 1},
-					}
-
-					newStyleID, err := f.NewStyle(&newStyle)
-					if err != nil {
-						return 0, err
-					}
-
-					styleCache[colorName] = newStyleID
-					styleID = newStyleID
+				var err error
+				if styleID, err = colorStyle(h.Color); err != nil {
+					return err
 				}
 			}
-
-			f.SetCellStyle(sheet, cell, cell, styleID)
+			rows[row-1][startCol-1] = excelize.Cell{StyleID: styleID, Value: h.Heading}
 		}
-		return *colIndex, nil
+		return nil
 	}
 
-	return applyHeader(headers, row, "")
+	return rows, merges, apply(headers, 1)
+}
+
+// headerColumnWidth fits the column to its header, at least 12 wide for currency display
+func headerColumnWidth(rows [][]any, col int) float64 {
+	maxLen := 0
+	for _, row := range rows {
+		if cell, ok := row[col].(excelize.Cell); ok {
+			maxLen = max(maxLen, len(fmt.Sprint(cell.Value)))
+		}
+	}
+	// Multiply by 1.2 for padding
+	return max(float64(min(maxLen, 15))*1.2, 12)
 }
 
 func getMaxDepth(headers []models.Header, depth int) int {
@@ -581,202 +470,89 @@ func getMaxDepth(headers []models.Header, depth int) int {
 	return max
 }
 
-func newMasterReportSheet(file *excelize.File, tower models.Tower) error {
-	return newMasterReportSheetManual(file, tower)
+// masterReport is the header tree of the master report with its rows, every format is written from the same rows
+type masterReport struct {
+	headers []models.Header
+	table   *tabular.Table
 }
 
-// newMasterReportSheetAllTowers creates a single sheet with all towers' data
-func newMasterReportSheetAllTowers(file *excelize.File, towers []models.Tower) error {
-	sheet := "Master Report"
-	_, err := file.NewSheet(sheet)
+// newMasterReport collects the headers in a first pass over source, rows are produced in a second pass as the table is written
+// monetary values are decimals and blank values nil
+// progress is called with the number of flats processed over both passes
+func newMasterReport(source masterReportSource, layout models.ReportLayout, asOf time.Time, progress func(processed int)) (*masterReport, error) {
+	processed := 0
+	collected := newMasterReportHeaders()
+	err := source.each(func(_ *models.Tower, flats []models.Flat) error {
+		collected.add(flats)
+		processed += len(flats)
+		progress(processed)
+		return nil
+	})
 	if err != nil {
-		return err
-	}
-
-	// Combine all flats from all towers for header generation
-	var allFlats []models.Flat
-	for _, tower := range towers {
-		allFlats = append(allFlats, tower.Flats...)
-	}
-
-	// base headers with IsMonetary flag for monetary columns
-	baseHeaders := []models.Header{
-		{
-			Heading: models.HeadingFlat,
-			Items: []models.Header{
-				{Heading: "Tower"},
-				{Heading: "Floor"},
-				{Heading: "Flat"},
-				{Heading: "Facing"},
-				{Heading: "Unit Type"},
-				{Heading: "Saleable Area"},
-			},
-		},
-		{
-			Heading: models.HeadingPaymentPlan,
-			Items: []models.Header{
-				{Heading: "Name"},
-			},
-		},
-		{
-			Heading: models.HeadingCustomer,
-			Items: []models.Header{
-				{Heading: "Name"},
-				{Heading: "Email"},
-				{Heading: "Phone Number"},
-				{Heading: "Nationality"},
-				{Heading: "Aadhar"},
-				{Heading: "PAN"},
-				{Heading: "Passport Number"},
-				{Heading: "Profession"},
-				{Heading: "Company Name"},
-			},
-		},
-		{
-			Heading: models.HeadingCompanyCustomer,
-			Items: []models.Header{
-				{Heading: "Name"},
-				{Heading: "Company PAN"},
-				{Heading: "GST"},
-				{Heading: "Aadhar"},
-				{Heading: "PAN"},
-			},
-		},
+		return nil, err
 	}
 
-	// get unique sale price breakdown values from ALL towers
-	salePriceBreakdownSet := make(map[string]bool)
-	salePriceBreakDownSlice := make([]models.Header, 0)
-	var ifmsHeader *models.Header
+	headers := collected.headers(layout)
+	columns := masterReportTableColumns(headers, nil)
 
-	for _, flat := range allFlats {
-		if flat.SaleDetail != nil {
-			for _, priceBreakdownItem := range flat.SaleDetail.PriceBreakdown {
-				summary := priceBreakdownItem.Summary
-				if !salePriceBreakdownSet[summary] {
-					salePriceBreakdownSet[summary] = true
+	// tower stage activations only apply to flats of the same tower
+	each := func(fn func(row []any) error) error {
+		row := make([]any, len(columns))
+		return source.each(func(tower *models.Tower, flats []models.Flat) error {
+			for _, flat := range flats {
+				values := flat.GetRowData(headers, tower.Name, models.SafePrint{}, tower.ActivePaymentPlanRatioItems, asOf)
 
-					header := models.Header{
-						Heading:    summary,
-						IsMonetary: true,
+				for colIdx := range row {
+					row[colIdx] = nil
+					if colIdx >= len(values) || values[colIdx] == "-" || values[colIdx] == "" {
+						continue
 					}
-
-					if summary == "Intrest Free Maintenance Security (IFMS)" {
-						ifmsHeader = &header
-					} else {
-						salePriceBreakDownSlice = append(salePriceBreakDownSlice, header)
+					row[colIdx] = values[colIdx]
+					if columns[colIdx].Monetary {
+						if amount, ok := parseAmount(values[colIdx]); ok {
+							row[colIdx] = amount
+						}
 					}
 				}
+				if err := fn(row); err != nil {
+					return err
+				}
 			}
-		}
-	}
-
-	if ifmsHeader != nil {
-		salePriceBreakDownSlice = append(salePriceBreakDownSlice, *ifmsHeader)
-	}
-
-	baseHeaders = append(baseHeaders, models.Header{
-		Heading: models.HeadingPricebreakdown,
-		Items:   salePriceBreakDownSlice,
-	})
-
-	// add sale and broker details
-	baseHeaders = append(baseHeaders,
-		[]models.Header{
-			{
-				Heading: models.HeadingSale,
-				Items: []models.Header{
-					{Heading: "Total Price", IsMonetary: true},
-					{Heading: "Total Payable Amount", IsMonetary: true},
-					{Heading: "Total Paid Amount", IsMonetary: true},
-					{Heading: "Paid Amount", Color: "yellow", IsMonetary: true},
-					{Heading: "CGST", Color: "yellow", IsMonetary: true},
-					{Heading: "SGST", Color: "yellow", IsMonetary: true},
-					{Heading: "Service Tax", Color: "yellow", IsMonetary: true},
-					{Heading: "Swathch Bharat Cess", Color: "yellow", IsMonetary: true},
-					{Heading: "Krishi Kalyan Cess", Color: "yellow", IsMonetary: true},
-					{Heading: "Pending Amount", IsMonetary: true},
-				},
-			},
-			{
-				Heading: models.HeadingBroker,
-				Items: []models.Header{
-					{Heading: "Name"},
-					{Heading: "Aadhar"},
-					{Heading: "PAN"},
-				},
-			},
-		}...,
-	)
-
-	// get unique payment plans from ALL towers
-	paymentPlanDetails := make(map[uuid.UUID]paymentPlanInfo)
-	for _, flat := range allFlats {
-		if flat.SaleDetail != nil && flat.SaleDetail.PaymentPlanRatio != nil {
-			ratioKey := flat.SaleDetail.PaymentPlanRatioId
-			ratioItems := make([]paymentPlanItemInfo, 0, len(flat.SaleDetail.PaymentPlanRatio.Ratios))
-
-			for _, ratioItem := range flat.SaleDetail.PaymentPlanRatio.Ratios {
-				ratioItems = append(ratioItems, paymentPlanItemInfo{
-					ID:          ratioItem.Id,
-					Description: ratioItem.Description,
-				})
-			}
-
-			paymentPlanDetails[ratioKey] = paymentPlanInfo{
-				ID:    ratioKey,
-				Name:  flat.SaleDetail.PaymentPlanRatio.PaymentPlanGroup.Name,
-				Ratio: flat.SaleDetail.PaymentPlanRatio.Ratio,
-				Items: ratioItems,
-			}
-		}
-	}
 
-	for _, item := range paymentPlanDetails {
-		baseHeaders = append(baseHeaders, models.Header{
-			ID:      &item.ID,
-			Heading: item.getHeading(),
-			Items:   item.getItems(),
+			processed += len(flats)
+			progress(processed)
+			return nil
 		})
 	}
 
-	// get max valid installment number from ALL towers
-	installmentCount := 0
-	for _, flat := range allFlats {
-		if flat.SaleDetail != nil {
-			installmentCount = max(installmentCount, flat.SaleDetail.GetValidReceiptsCount())
-		}
-	}
+	return &masterReport{
+		headers: headers,
+		table:   &tabular.Table{Sheet: masterReportSheet, Columns: columns, Each: each},
+	}, nil
+}
 
-	if installmentCount > 0 {
-		installmentItems := make([]models.Header, 0, installmentCount)
-
-		for i := 1; i <= installmentCount; i++ {
-			installmentItems = append(installmentItems, models.Header{
-				Heading: strconv.Itoa(i),
-				Items: []models.Header{
-					{Heading: "Number"},
-					{Heading: "Date"},
-					{Heading: "Amount", IsMonetary: true},
-					{Heading: "Type"},
-					{Heading: "CGST", IsMonetary: true},
-					{Heading: "SGST", IsMonetary: true},
-					{Heading: "Service Tax", IsMonetary: true},
-					{Heading: "Swathch Bharat Cess", IsMonetary: true},
-					{Heading: "Krishi Kalyan Cess", IsMonetary: true},
-					{Heading: "Status"},
-					{Heading: "Cleared At"},
-				},
-			})
+// masterReportTableColumns returns the leaf columns of the header tree with their heading path
+func masterReportTableColumns(headers []models.Header, parent []string) []tabular.Column {
+	var columns []tabular.Column
+	for _, h := range headers {
+		path := append(append([]string{}, parent...), h.Heading)
+		if len(h.Items) > 0 {
+			columns = append(columns, masterReportTableColumns(h.Items, path)...)
+			continue
 		}
+		columns = append(columns, tabular.Column{Path: path, Monetary: h.IsMonetary})
+	}
+	return columns
+}
 
-		baseHeaders = append(baseHeaders, models.Header{
-			Heading: models.HeadingInstallment,
-			Items:   installmentItems,
-		})
+// writeMasterReport writes the master report to its sheet with a stream writer
+// monetary values are written as numbers in indian format, blanks as "-"
+func writeMasterReport(file *excelize.File, report *masterReport) error {
+	if _, err := file.NewSheet(masterReportSheet); err != nil {
+		return err
 	}
 
-	// Create styles
+	headers := report.headers
 	headerStyle, err := file.NewStyle(&excelize.Style{
 		Font:      &excelize.Font{Bold: true},
 		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
@@ -784,108 +560,129 @@ func newMasterReportSheetAllTowers(file *excelize.File, towers []models.Tower) e
 	if err != nil {
 		return err
 	}
-
-	numberStyle, err := createNumberStyle(file)
+	columns, err := newMasterReportColumns(file, headers)
 	if err != nil {
 		return err
 	}
-
-	numberStyleYellow, err := createNumberStyleWithColor(file, "#FFFF00")
+	maxDepth := getMaxDepth(headers, 1)
+	headerRows, merges, err := layoutHeaders(file, headers, len(columns), maxDepth, headerStyle)
 	if err != nil {
 		return err
 	}
 
-	maxDepth := getMaxDepth(baseHeaders, 1)
-	colIndex := 1
-
-	headers := []models.Header{
-		{Heading: "Member ID"},
-	}
-	headers = append(headers, baseHeaders...)
-
-	_, err = renderHeaders(file, sheet, headers, 1, &colIndex, maxDepth, headerStyle)
+	writer, err := file.NewStreamWriter(masterReportSheet)
 	if err != nil {
 		return err
 	}
 
-	// Set column widths
-	for i := 1; i < colIndex; i++ {
-		colName, _ := excelize.ColumnNumberToName(i)
-		maxWidth := getMaxColumnWidth(file, sheet, colName, maxDepth)
-		file.SetColWidth(sheet, colName, colName, maxWidth)
+	// column widths must be set before the first row is written
+	for col := range columns {
+		if err := writer.SetColWidth(col+1, col+1, headerColumnWidth(headerRows, col)); err != nil {
+			return err
+		}
+	}
+	for i, row := range headerRows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := writer.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	for _, merge := range merges {
+		if err := writer.MergeCell(merge[0], merge[1]); err != nil {
+			return err
+		}
 	}
 
-	// Get monetary column indices
-	monetaryColumns := getMonetaryColumnIndices(headers)
-	yellowMonetaryColumns := getYellowMonetaryColumnIndices(headers)
-
-	startRow := maxDepth + 1
-	currentRow := startRow
+	rowNum := maxDepth + 1
+	cells := make([]any, len(columns))
+	err = report.table.Each(func(row []any) error {
+		for colIdx, value := range row {
+			switch v := value.(type) {
+			case nil:
+				cells[colIdx] = "-"
+			case decimal.Decimal:
+				// monetary values are written as numbers for excel formatting to work
+				cells[colIdx] = excelize.Cell{StyleID: columns[colIdx].style, Value: v.InexactFloat64()}
+			default:
+				cells[colIdx] = v
+			}
+		}
 
-	// Combine all active payment plan ratio items from all towers
-	allActivePaymentPlanRatioItems := make([]models.TowerPaymentStatus, 0)
-	for _, tower := range towers {
-		allActivePaymentPlanRatioItems = append(allActivePaymentPlanRatioItems, tower.ActivePaymentPlanRatioItems...)
+		cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+		rowNum++
+		return writer.SetRow(cell, cells)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Loop through ALL towers and their flats
-	for _, tower := range towers {
-		for i, flat := range tower.Flats {
-			rowNum := currentRow
-			values := flat.GetRowData(baseHeaders, tower.Name, models.SafePrint{
-				ShouldPrint: i == 0 && tower.Name == "A",
-			}, allActivePaymentPlanRatioItems)
+	return writer.Flush()
+}
 
-			for colIdx, val := range values {
-				colNum := colIdx + 1
-				colName, _ := excelize.ColumnNumberToName(colNum)
-				cell := fmt.Sprintf("%s%d", colName, rowNum)
+// masterReportParams select the towers, date, columns and format of the master report
+type masterReportParams struct {
+	Tower      string              `json:"tower,omitempty"`
+	AsOf       time.Time           `json:"asOf"`
+	TemplateId *uuid.UUID          `json:"templateId,omitempty"`
+	Layout     models.ReportLayout `json:"layout"`
+	Format     tabular.Format      `json:"format,omitempty"`
+}
 
-				if monetaryColumns[colNum] {
-					if numVal, ok := parseToFloat(val); ok {
-						file.SetCellFloat(sheet, cell, numVal, -1, 64)
+// fileName is the name of the report file without extension
+func (p masterReportParams) fileName(society string, generatedAt time.Time) string {
+	fileNameBase := society
+	if p.Tower != "" {
+		fileNameBase = fmt.Sprintf("tower_%s", p.Tower)
+	}
+	return fmt.Sprintf("%s_master_report_%d", fileNameBase, generatedAt.Unix())
+}
 
-						if yellowMonetaryColumns[colNum] {
-							file.SetCellStyle(sheet, cell, cell, numberStyleYellow)
-						} else {
-							file.SetCellStyle(sheet, cell, cell, numberStyle)
-						}
-					} else {
-						file.SetCellValue(sheet, cell, val)
-					}
-				} else {
-					file.SetCellValue(sheet, cell, val)
-				}
-			}
-			currentRow++
-		}
+// parseMasterReportParams reads the params of the master report from the query
+// columns are those of the template when set, the default columns otherwise, the format is xlsx unless negotiated
+func parseMasterReportParams(db *gorm.DB, orgId string, r *http.Request) (*masterReportParams, error) {
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		return nil, err
+	}
+	format, err := tabular.Negotiate(r)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = tabular.XLSX
 	}
 
-	return nil
+	params := masterReportParams{
+		Tower:  r.URL.Query().Get("tower"),
+		AsOf:   asOf,
+		Layout: models.DefaultReportLayout(),
+		Format: format,
+	}
+	if templateId := strings.TrimSpace(r.URL.Query().Get("template")); templateId != "" {
+		template, err := findReportTemplate(db, orgId, templateId)
+		if err != nil {
+			return nil, err
+		}
+		params.TemplateId = &template.Id
+		params.Layout = template.Layout
+	}
+	return &params, nil
 }
 
-func generateMasterReport(db *gorm.DB, orgId, society, tower string) (*bytes.Buffer, error) {
+// generateMasterReport generates the master report of the society, or of the tower when set, as it was on asOf
+// progress is optional and called with the flats processed out of total as the report is generated
+func generateMasterReport(db *gorm.DB, orgId, society string, params masterReportParams, progress func(done, total int)) (*bytes.Buffer, error) {
 	query := db.
 		Where("org_id = ? AND society_id = ?", orgId, society)
 
-	if tower != "" {
-		query = query.Where("name = ?", tower)
+	if params.Tower != "" {
+		query = query.Where("name = ?", params.Tower)
 	}
 
 	var towerData []models.Tower
 	err := query.
 		Preload("ActivePaymentPlanRatioItems").
-		Preload("Flats").
-		Preload("Flats.ActivePaymentPlanRatioItems").
-		Preload("Flats.SaleDetail").
-		Preload("Flats.SaleDetail.PaymentPlanRatio").
-		Preload("Flats.SaleDetail.PaymentPlanRatio.PaymentPlanGroup").
-		Preload("Flats.SaleDetail.PaymentPlanRatio.Ratios").
-		Preload("Flats.SaleDetail.Receipts").
-		Preload("Flats.SaleDetail.Receipts.Cleared").
-		Preload("Flats.SaleDetail.Broker").
-		Preload("Flats.SaleDetail.Customers").
-		Preload("Flats.SaleDetail.CompanyCustomer").
+		Order("name").
 		Find(&towerData).Error
 	if err != nil {
 		return nil, err
@@ -898,50 +695,80 @@ func generateMasterReport(db *gorm.DB, orgId, society, tower string) (*bytes.Buf
 		}
 	}
 
-	reportFile := excelize.NewFile()
+	towerIds := make([]uuid.UUID, 0, len(towerData))
+	for _, t := range towerData {
+		towerIds = append(towerIds, t.Id)
+	}
+	var flatCount int64
+	if err := db.Model(&models.Flat{}).Where("tower_id in ?", towerIds).Count(&flatCount).Error; err != nil {
+		return nil, err
+	}
 
-	// Create single sheet with all towers
-	sheetErr := newMasterReportSheetAllTowers(reportFile, towerData)
-	if sheetErr != nil {
-		return nil, sheetErr
+	source := &dbMasterReportSource{
+		db:        db,
+		towers:    towerData,
+		asOf:      params.AsOf,
+		batchSize: masterReportBatchSize,
 	}
 
-	if err := reportFile.DeleteSheet("Sheet1"); err != nil {
+	// every flat is processed twice, once for the headers and once for its row
+	// jobs queued before templates have no layout
+	layout := params.Layout
+	if len(layout) == 0 {
+		layout = models.DefaultReportLayout()
+	}
+
+	report, err := newMasterReport(source, layout, params.AsOf, func(processed int) {
+		if progress != nil {
+			progress(processed, 2*int(flatCount))
+		}
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	var buf bytes.Buffer
-	if err := reportFile.Write(&buf); err != nil {
+	switch params.Format {
+	case tabular.XLSX, "":
+		err = writeMasterReportFile(&buf, report)
+	default:
+		err = tabular.Write(&buf, params.Format, report.table)
+	}
+	if err != nil {
 		return nil, err
 	}
 	return &buf, nil
 }
 
+// writeMasterReportFile writes the master report as an xlsx workbook with the report as its only sheet
+func writeMasterReportFile(w io.Writer, report *masterReport) error {
+	reportFile := excelize.NewFile()
+	defer reportFile.Close()
+
+	if err := writeMasterReport(reportFile, report); err != nil {
+		return err
+	}
+	if err := reportFile.DeleteSheet("Sheet1"); err != nil {
+		return err
+	}
+	return reportFile.Write(w)
+}
+
 func (s *reportService) generateMasterReport(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
-	tower := r.URL.Query().Get("tower")
 
-	report, err := generateMasterReport(s.db, orgId, societyRera, tower)
+	params, err := parseMasterReportParams(s.db, orgId, r)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
 	}
 
-	fileNameBase := societyRera
-	if tower != "" {
-		fileNameBase = fmt.Sprintf("tower_%s", tower)
-	}
-
-	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	w.Header().Set(
-		"Content-Disposition",
-		fmt.Sprintf("attachment; filename=%s_master_report_%d.xlsx", fileNameBase, time.Now().Unix()),
-	)
-	w.Header().Set("Content-Length", fmt.Sprint(report.Len()))
-
-	if _, err := w.Write(report.Bytes()); err != nil {
+	report, err := generateMasterReport(s.db, orgId, societyRera, *params, nil)
+	if err != nil {
 		payload.HandleError(w, err)
 		return
 	}
-}
\ No newline at end of file
+
+	tabular.RespondFile(w, params.Format, params.fileName(societyRera, time.Now()), report)
+}
@@ -0,0 +1,408 @@
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// syntheticSource generates the flats of a society batch by batch so only a batch is held at a time
+type syntheticSource struct {
+	towers        []models.Tower
+	flatsPerTower int
+	batchSize     int
+	plan          *models.PaymentPlanRatio
+	bookedAt      time.Time
+}
+
+func newSyntheticSource(towerCount, flatsPerTower, batchSize int) *syntheticSource {
+	// within-days items count from their creation, the plan is created on the day of the bookings
+	bookedAt := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	group := &models.PaymentPlanGroup{Id: uuid.New(), Name: "Construction Linked"}
+	plan := &models.PaymentPlanRatio{Id: uuid.New(), PaymentPlanGroupId: group.Id, PaymentPlanGroup: group, Ratio: "10:90"}
+	plan.Ratios = []models.PaymentPlanRatioItem{
+		{Id: uuid.New(), PaymentPlanRatioId: plan.Id, Description: "On Booking", Ratio: "10", Scope: custom.SCOPE_SALE, ConditionType: custom.ONBOOKING},
+		{Id: uuid.New(), PaymentPlanRatioId: plan.Id, Description: "Within 30 days", Ratio: "20", Scope: custom.SCOPE_SALE, ConditionType: custom.WITHINDAYS, ConditionValue: 30, CreatedAt: bookedAt},
+		{Id: uuid.New(), PaymentPlanRatioId: plan.Id, Description: "Within 90 days", Ratio: "30", Scope: custom.SCOPE_SALE, ConditionType: custom.WITHINDAYS, ConditionValue: 90, CreatedAt: bookedAt},
+		{Id: uuid.New(), PaymentPlanRatioId: plan.Id, Description: "On Allotment", Ratio: "40", Scope: custom.SCOPE_SALE, ConditionType: custom.ONALLOTMENT},
+	}
+
+	towers := make([]models.Tower, towerCount)
+	for i := range towers {
+		towers[i] = models.Tower{Id: uuid.New(), Name: string(rune('A' + i))}
+	}
+	return &syntheticSource{
+		towers:        towers,
+		flatsPerTower: flatsPerTower,
+		batchSize:     batchSize,
+		plan:          plan,
+		bookedAt:      bookedAt,
+	}
+}
+
+func (s *syntheticSource) flat(tower *models.Tower, n int) models.Flat {
+	flat := models.Flat{
+		Id:           uuid.New(),
+		TowerId:      tower.Id,
+		Name:         fmt.Sprintf("%s-%04d", tower.Name, n),
+		FloorNumber:  n / 8,
+		Facing:       "Park",
+		SaleableArea: decimal.NewFromInt(1250),
+		UnitType:     "3BHK",
+	}
+	// every third flat is unsold
+	if n%3 == 0 {
+		return flat
+	}
+
+	sale := &models.Sale{
+		Id:                 uuid.New(),
+		SaleNumber:         fmt.Sprintf("%s%05d", tower.Name, n),
+		FlatId:             flat.Id,
+		Channel:            custom.CHANNEL_DIRECT,
+		PaymentPlanRatioId: s.plan.Id,
+		PaymentPlanRatio:   s.plan,
+		TotalPrice:         decimal.NewFromInt(9500000),
+		PriceBreakdown: models.PriceBreakdownDetails{
+			{Type: "basic", Summary: "Basic Sale Price", Price: decimal.NewFromInt(7000), Total: decimal.NewFromInt(8750000)},
+			{Type: "plc", Summary: "Preferential Location Charges", Price: decimal.NewFromInt(200), Total: decimal.NewFromInt(250000)},
+			{Type: "ifms", Summary: "Intrest Free Maintenance Security (IFMS)", Price: decimal.NewFromInt(400), Total: decimal.NewFromInt(500000)},
+		},
+		CreatedAt: s.bookedAt,
+		Customers: []models.Customer{{
+			Id:          uuid.New(),
+			FirstName:   "Customer",
+			LastName:    fmt.Sprint(n),
+			Email:       fmt.Sprintf("customer%d@example.com", n),
+			PhoneNumber: "9876543210",
+			PanNumber:   "ABCDE1234F",
+		}},
+	}
+
+	gst := decimal.NewFromInt(9000)
+	for r := 0; r < 1+n%6; r++ {
+		issued := s.bookedAt.AddDate(0, r, 0)
+		receipt := models.Receipt{
+			Id:            uuid.New(),
+			ReceiptNumber: fmt.Sprintf("R%d", r+1),
+			SaleId:        sale.Id,
+			TotalAmount:   decimal.NewFromInt(518000),
+			Amount:        decimal.NewFromInt(500000),
+			Mode:          custom.CHEQUE,
+			DateIssued:    pgtype.Date{Time: issued, Valid: true},
+			CGST:          &gst,
+			SGST:          &gst,
+			CreatedAt:     issued,
+		}
+		if r%4 != 3 {
+			receipt.Cleared = &models.ReceiptClear{ReceiptId: receipt.Id, CreatedAt: issued.AddDate(0, 0, 3)}
+		}
+		sale.Receipts = append(sale.Receipts, receipt)
+	}
+
+	flat.SaleDetail = sale
+	return flat
+}
+
+func (s *syntheticSource) each(fn func(tower *models.Tower, flats []models.Flat) error) error {
+	for i := range s.towers {
+		tower := &s.towers[i]
+		for start := 0; start < s.flatsPerTower; start += s.batchSize {
+			flats := make([]models.Flat, 0, s.batchSize)
+			for n := start; n < min(start+s.batchSize, s.flatsPerTower); n++ {
+				flats = append(flats, s.flat(tower, n))
+			}
+			if err := fn(tower, flats); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func generateSyntheticReport(source masterReportSource, layout models.ReportLayout, asOf time.Time) (*bytes.Buffer, error) {
+	report, err := newMasterReport(source, layout, asOf, func(int) {})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeMasterReportFile(&buf, report); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// peakHeap samples the heap in use while fn runs and returns the peak above the heap in use before fn
+func peakHeap(fn func()) uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	base := stats.HeapInuse
+
+	var peak atomic.Uint64
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			var sample runtime.MemStats
+			runtime.ReadMemStats(&sample)
+			if sample.HeapInuse > base && sample.HeapInuse-base > peak.Load() {
+				peak.Store(sample.HeapInuse - base)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	fn()
+	close(done)
+	wg.Wait()
+	return peak.Load()
+}
+
+func TestMasterReportColumns(t *testing.T) {
+	source := newSyntheticSource(1, 6, 4)
+	report, err := generateSyntheticReport(source, models.DefaultReportLayout(), time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := excelize.OpenReader(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows(masterReportSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 header rows for installment sub headings and a row per flat
+	if len(rows) != 3+6 {
+		t.Fatalf("want 9 rows, got %d", len(rows))
+	}
+	if rows[0][0] != "Member ID" {
+		t.Errorf("want member id heading, got %q", rows[0][0])
+	}
+
+	// ifms is the last price breakdown column
+	headers := newMasterReportHeaders()
+	source.each(func(_ *models.Tower, flats []models.Flat) error {
+		headers.add(flats)
+		return nil
+	})
+	for _, h := range headers.headers(models.DefaultReportLayout()) {
+		if h.Heading != models.HeadingPricebreakdown {
+			continue
+		}
+		if last := h.Items[len(h.Items)-1].Heading; last != "Intrest Free Maintenance Security (IFMS)" {
+			t.Errorf("want ifms last, got %q", last)
+		}
+	}
+
+	// total price of a sold flat is written as a number
+	columns, _ := newMasterReportColumns(file, headers.headers(models.DefaultReportLayout()))
+	totalPriceCol := 0
+	for col, heading := range rows[1] {
+		if heading == "Total Price" {
+			totalPriceCol = col
+		}
+	}
+	if totalPriceCol == 0 || !columns[totalPriceCol].monetary {
+		t.Fatalf("total price column not monetary")
+	}
+	cell, _ := excelize.CoordinatesToCellName(totalPriceCol+1, 5)
+	cellType, err := file.GetCellType(masterReportSheet, cell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cells without a type are numbers
+	if cellType == excelize.CellTypeInlineString || cellType == excelize.CellTypeSharedString {
+		t.Errorf("want total price as number, got type %v", cellType)
+	}
+	if value, _ := file.GetCellValue(masterReportSheet, cell, excelize.Options{RawCellValue: true}); value != "9500000" {
+		t.Errorf("want total price 9500000, got %q", value)
+	}
+}
+
+func TestMasterReportTemplateLayout(t *testing.T) {
+	source := newSyntheticSource(1, 3, 3)
+	layout := models.ReportLayout{
+		{Id: models.ColumnGroupFlat, Columns: []models.ReportLayoutColumn{{Id: "flat.name", Heading: "Unit"}}},
+		{Id: models.ColumnGroupCustomer, Heading: "Buyer", Columns: []models.ReportLayoutColumn{{Id: "customer.name"}, {Id: "customer.pan"}}},
+		{Id: models.ColumnGroupSale, Columns: []models.ReportLayoutColumn{{Id: "sale.total-price"}}},
+	}
+	if err := layout.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := generateSyntheticReport(source, layout, time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows(masterReportSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 header rows for the group headings and a row per flat
+	if len(rows) != 2+3 {
+		t.Fatalf("want 5 rows, got %d", len(rows))
+	}
+	wantGroups := []string{"Flat Details", "Buyer", "", "Sale Details"}
+	for i, want := range wantGroups {
+		if got := rows[0][i]; got != want {
+			t.Errorf("group heading %d: want %q, got %q", i, want, got)
+		}
+	}
+	wantColumns := []string{"Unit", "Name", "PAN", "Total Price"}
+	if len(rows[1]) != len(wantColumns) {
+		t.Fatalf("want columns %v, got %v", wantColumns, rows[1])
+	}
+	for i, want := range wantColumns {
+		if got := rows[1][i]; got != want {
+			t.Errorf("column %d: want %q, got %q", i, want, got)
+		}
+	}
+
+	// the first flat is unsold, the second is sold
+	if got := rows[2]; got[0] != "A-0000" || got[1] != "-" {
+		t.Errorf("unexpected unsold row %v", got)
+	}
+	if got := rows[3]; got[0] != "A-0001" || got[1] != "Customer 1" || got[2] != "ABCDE1234F" {
+		t.Errorf("unexpected sold row %v", got)
+	}
+	if value, _ := file.GetCellValue(masterReportSheet, "D4", excelize.Options{RawCellValue: true}); value != "9500000" {
+		t.Errorf("want total price 9500000, got %q", value)
+	}
+}
+
+// TestMasterReportFormatsAgree checks the csv rows are the xlsx rows under the flattened header paths
+func TestMasterReportFormatsAgree(t *testing.T) {
+	source := newSyntheticSource(2, 4, 3)
+	asOf := time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	xlsx, err := generateSyntheticReport(source, models.DefaultReportLayout(), asOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(xlsx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	sheetRows, err := file.GetRows(masterReportSheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := newMasterReport(source, models.DefaultReportLayout(), asOf, func(int) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tabular.WriteCSV(&buf, report.table); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if records[0][0] != "Member ID" {
+		t.Errorf("want member id heading, got %q", records[0][0])
+	}
+	if !slices.Contains(records[0], "Sale Details > Total Price") {
+		t.Errorf("want flattened sale heading in %v", records[0])
+	}
+
+	// 3 header rows in the sheet, 1 in the csv
+	dataRows := sheetRows[3:]
+	if len(records)-1 != len(dataRows) {
+		t.Fatalf("want %d csv rows, got %d", len(dataRows), len(records)-1)
+	}
+	for i, record := range records[1:] {
+		for col, value := range record {
+			want := ""
+			if col < len(dataRows[i]) && dataRows[i][col] != "-" {
+				want = dataRows[i][col]
+			}
+			if value != want {
+				t.Errorf("row %d column %q: csv %q, xlsx %q", i, records[0][col], value, want)
+			}
+		}
+	}
+}
+
+// TestMasterReportBoundedMemory generates the report of a 5,000 flat society within memory and time limits
+func TestMasterReportBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large report in short mode")
+	}
+
+	const (
+		maxHeap     = 128 << 20
+		maxDuration = 30 * time.Second
+	)
+
+	source := newSyntheticSource(5, 1000, masterReportBatchSize)
+	asOf := time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	var report *bytes.Buffer
+	var err error
+	start := time.Now()
+	peak := peakHeap(func() {
+		report, err = generateSyntheticReport(source, models.DefaultReportLayout(), asOf)
+	})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Logf("5000 flats: %s, peak heap %d MiB, report %d KiB", elapsed, peak>>20, report.Len()>>10)
+	if peak > maxHeap {
+		t.Errorf("peak heap %d MiB exceeds %d MiB", peak>>20, maxHeap>>20)
+	}
+	if elapsed > maxDuration {
+		t.Errorf("report took %s, limit %s", elapsed, maxDuration)
+	}
+}
+
+func BenchmarkMasterReport5000Flats(b *testing.B) {
+	source := newSyntheticSource(5, 1000, masterReportBatchSize)
+	asOf := time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := generateSyntheticReport(source, models.DefaultReportLayout(), asOf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
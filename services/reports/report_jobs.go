@@ -0,0 +1,89 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/jobs"
+	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// runMasterReportJob generates the master report of the job, queries stop when the job is cancelled
+func (s *reportService) runMasterReportJob(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+	var params masterReportParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, jobs.Permanent(err)
+	}
+
+	progress.Report(0, "Loading towers")
+	report, err := generateMasterReport(s.db.WithContext(ctx), job.OrgId.String(), job.SocietyId, params,
+		func(done, total int) {
+			if total > 0 {
+				progress.Report(done*90/total, "Generating report")
+			}
+		})
+	if err != nil {
+		var requestErr *custom.RequestError
+		if errors.As(err, &requestErr) {
+			return nil, jobs.Permanent(err)
+		}
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	progress.Report(90, "Saving report")
+
+	// jobs queued before formats are xlsx
+	format := params.Format
+	if format == "" {
+		format = tabular.XLSX
+	}
+	return &jobs.Result{
+		Name:        fmt.Sprintf("%s.%s", params.fileName(job.SocietyId, params.AsOf), format.Extension()),
+		ContentType: format.ContentType(),
+		Body:        report,
+		Size:        int64(report.Len()),
+	}, nil
+}
+
+// enqueueMasterReport queues the master report to be generated in background, the result is downloaded from the job
+func (s *reportService) enqueueMasterReport(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+
+	// the layout is kept with the job so later changes to the template do not change the report
+	params, err := parseMasterReportParams(s.db, orgId, r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	job := models.Job{
+		OrgId:      uuid.MustParse(orgId),
+		SocietyId:  societyRera,
+		Type:       custom.JOB_MASTER_REPORT,
+		Permission: custom.PERMISSION_REPORT_MASTER,
+		CreatedBy:  userEmail,
+	}
+	if err := jobs.Enqueue(s.db, &job, params); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Master report queued."
+	response.Data = job
+
+	payload.EncodeJSON(w, http.StatusAccepted, response)
+}
@@ -0,0 +1,221 @@
+package reports
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+type reportTemplateService struct {
+	db *gorm.DB
+}
+
+// CreateReportTemplateService creates service for the master report column registry and the saved report templates of the organization
+func CreateReportTemplateService(app common.IApp) common.IService {
+	return &reportTemplateService{
+		db: app.GetDBClient(),
+	}
+}
+
+var reportTemplateNotFoundError = &custom.RequestError{
+	Status:  http.StatusNotFound,
+	Message: "Report template not found.",
+}
+
+func findReportTemplate(db *gorm.DB, orgId, templateId string) (*models.ReportTemplate, error) {
+	if _, err := uuid.Parse(templateId); err != nil {
+		return nil, reportTemplateNotFoundError
+	}
+
+	var template models.ReportTemplate
+	err := db.Where("id = ? and org_id = ?", templateId, orgId).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, reportTemplateNotFoundError
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// saveReportTemplate validates the layout and saves the template, duplicate template names of an organization are rejected
+func saveReportTemplate(db *gorm.DB, template *models.ReportTemplate) error {
+	if err := template.Layout.Validate(); err != nil {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid layout: " + err.Error(),
+		}
+	}
+
+	err := db.Save(template).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Report template with same name already exists.",
+		}
+	}
+	return err
+}
+
+// getReportColumns returns the column registry with the default layout
+func (s *reportTemplateService) getReportColumns(w http.ResponseWriter, r *http.Request) {
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = map[string]any{
+		"groups":        models.MasterReportColumns,
+		"defaultLayout": models.DefaultReportLayout(),
+	}
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *reportTemplateService) getReportTemplates(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	var templates []models.ReportTemplate
+	err := s.db.Where("org_id = ?", orgId).Order("name").Find(&templates).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = templates
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *reportTemplateService) getReportTemplate(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	template, err := findReportTemplate(s.db, orgId, chi.URLParam(r, "templateId"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = template
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+type hCreateReportTemplate struct {
+	Name        string `validate:"required"`
+	Description string
+	Layout      models.ReportLayout `validate:"required,min=1"`
+}
+
+func (h *hCreateReportTemplate) execute(db *gorm.DB, orgId, createdBy string) (*models.ReportTemplate, error) {
+	template := models.ReportTemplate{
+		OrgId:       uuid.MustParse(orgId),
+		Name:        strings.TrimSpace(h.Name),
+		Description: h.Description,
+		Layout:      h.Layout,
+		CreatedBy:   createdBy,
+		UpdatedBy:   createdBy,
+	}
+	return &template, saveReportTemplate(db, &template)
+}
+
+func (s *reportTemplateService) createReportTemplate(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	reqBody := payload.ValidateAndDecodeRequest[hCreateReportTemplate](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	template, err := reqBody.execute(s.db, orgId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully created report template."
+	response.Data = template
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
+
+// hUpdateReportTemplate updates the provided fields of a report template, layout replaces the existing layout
+type hUpdateReportTemplate struct {
+	Name        string
+	Description *string
+	Layout      models.ReportLayout
+}
+
+func (h *hUpdateReportTemplate) execute(db *gorm.DB, orgId, templateId, updatedBy string) (*models.ReportTemplate, error) {
+	template, err := findReportTemplate(db, orgId, templateId)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(h.Name) != "" {
+		template.Name = strings.TrimSpace(h.Name)
+	}
+	if h.Description != nil {
+		template.Description = *h.Description
+	}
+	if h.Layout != nil {
+		template.Layout = h.Layout
+	}
+	template.UpdatedBy = updatedBy
+	return template, saveReportTemplate(db, template)
+}
+
+func (s *reportTemplateService) updateReportTemplate(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	reqBody := payload.ValidateAndDecodeRequest[hUpdateReportTemplate](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	template, err := reqBody.execute(s.db, orgId, chi.URLParam(r, "templateId"), userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated report template."
+	response.Data = template
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *reportTemplateService) deleteReportTemplate(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	template, err := findReportTemplate(s.db, orgId, chi.URLParam(r, "templateId"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	if err := s.db.Delete(template).Error; err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully deleted report template."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -2,6 +2,8 @@ package reports
 
 import (
 	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/jobs"
 	"gorm.io/gorm"
 )
 
@@ -10,7 +12,9 @@ type reportService struct {
 }
 
 func NewReportService(app common.IApp) common.IService {
-	return &reportService{
+	s := &reportService{
 		db: app.GetDBClient(),
 	}
+	jobs.Register(custom.JOB_MASTER_REPORT, s.runMasterReportJob)
+	return s
 }
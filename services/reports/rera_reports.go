@@ -0,0 +1,540 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/document"
+	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// reraUnits are the units of a tower booked till the end of the quarter
+type reraUnits struct {
+	Tower           string `json:"tower"`
+	TotalUnits      int64  `json:"totalUnits"`
+	BookedBefore    int64  `json:"bookedBefore"` // till the end of the previous quarter
+	BookedInQuarter int64  `json:"bookedInQuarter"`
+	Booked          int64  `json:"booked"`
+	Unsold          int64  `json:"unsold"`
+}
+
+// reraCollection is the amount realised in a bank account, amount excludes taxes collected with the receipts
+type reraCollection struct {
+	Bank                string          `json:"bank"`
+	AccountNumber       string          `json:"accountNumber"`
+	AccountType         string          `json:"accountType"`
+	TotalInQuarter      decimal.Decimal `json:"totalInQuarter"`
+	AmountInQuarter     decimal.Decimal `json:"amountInQuarter"`
+	AmountTillQuarter   decimal.Decimal `json:"amountTillQuarter"`
+	ReceiptsInQuarter   int64           `json:"receiptsInQuarter"`
+	ReceiptsTillQuarter int64           `json:"receiptsTillQuarter"`
+}
+
+// reraEscrow is the deposit required in the designated account out of the amount realised against the deposit made,
+// deposits and withdrawals are the clearances and transfers recorded in the designated accounts
+type reraEscrow struct {
+	RealisedInQuarter    decimal.Decimal `json:"realisedInQuarter"`
+	RequiredInQuarter    decimal.Decimal `json:"requiredInQuarter"`
+	DepositedInQuarter   decimal.Decimal `json:"depositedInQuarter"`
+	RealisedTillQuarter  decimal.Decimal `json:"realisedTillQuarter"`
+	RequiredTillQuarter  decimal.Decimal `json:"requiredTillQuarter"`
+	DepositedTillQuarter decimal.Decimal `json:"depositedTillQuarter"`
+	ShortfallTillQuarter decimal.Decimal `json:"shortfallTillQuarter"`
+	WithdrawnInQuarter   decimal.Decimal `json:"withdrawnInQuarter"`
+	Balance              decimal.Decimal `json:"balance"` // at the end of the quarter
+	DesignatedShare      decimal.Decimal `json:"designatedShare"`
+}
+
+// reraConstruction is the construction progress of a tower by its milestones
+type reraConstruction struct {
+	Tower              string           `json:"tower"`
+	Milestones         int64            `json:"milestones"`
+	Completed          int64            `json:"completed"` // till the end of the quarter
+	CompletedInQuarter int64            `json:"completedInQuarter"`
+	CompletionPercent  decimal.Decimal  `json:"completionPercent"`
+	StagesInQuarter    []string         `json:"stagesInQuarter"` // tower stages of the payment plans activated in the quarter
+	LastCompletedStage string           `json:"lastCompletedStage"`
+	LastCompletedOn    *custom.DateOnly `json:"lastCompletedOn"`
+}
+
+// reraMilestone is a construction milestone certified in the quarter
+type reraMilestone struct {
+	Tower                string          `json:"tower"`
+	Stage                string          `json:"stage"`
+	FloorNumber          *int            `json:"floorNumber"`
+	Description          string          `json:"description"`
+	CompletedOn          custom.DateOnly `json:"completedOn"`
+	CertificateReference string          `json:"certificateReference"`
+	CertifiedBy          string          `json:"certifiedBy"`
+}
+
+// reraQuarterlyReport is the quarterly progress of a registered project in the tabular format of the authority
+type reraQuarterlyReport struct {
+	ReraNumber   string             `json:"reraNumber"`
+	Project      string             `json:"project"`
+	Address      string             `json:"address"`
+	Promoter     string             `json:"promoter"`
+	Quarter      string             `json:"quarter"`
+	From         custom.DateOnly    `json:"from"`
+	To           custom.DateOnly    `json:"to"`
+	Units        []reraUnits        `json:"units"`
+	Collections  []reraCollection   `json:"collections"`
+	Escrow       reraEscrow         `json:"escrow"`
+	Construction []reraConstruction `json:"construction"`
+	Milestones   []reraMilestone    `json:"milestones"`
+}
+
+type hGetReraReport struct {
+	quarter models.Quarter
+	pdf     bool
+	format  tabular.Format
+}
+
+func (h *hGetReraReport) parse(r *http.Request) error {
+	query := r.URL.Query()
+
+	// the last completed quarter is filed by default
+	h.quarter = models.QuarterOf(time.Now()).Previous()
+	if value := strings.TrimSpace(query.Get("quarter")); value != "" {
+		quarter, err := models.ParseQuarter(value)
+		if err != nil {
+			return err
+		}
+		h.quarter = quarter
+	}
+
+	// pdf is a document rather than a table so it is negotiated here
+	if strings.EqualFold(strings.TrimSpace(query.Get("format")), "pdf") {
+		h.pdf = true
+		return nil
+	}
+	if query.Get("format") == "" {
+		for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+			if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && mediaType == "application/pdf" {
+				h.pdf = true
+				return nil
+			}
+		}
+	}
+
+	format, err := tabular.Negotiate(r)
+	if err != nil {
+		return err
+	}
+	if !format.IsDefault() && format != tabular.XLSX {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "RERA report is available as xlsx or pdf.",
+		}
+	}
+	h.format = format
+	return nil
+}
+
+// execute compiles the progress of the society in the quarter
+// units count from booking, amounts from clearance of the receipt and construction from certified completion
+func (h *hGetReraReport) execute(db *gorm.DB, orgId, society string) (*reraQuarterlyReport, error) {
+	var project models.Society
+	result := db.Preload("Organization").
+		Where("org_id = ? AND rera_number = ?", orgId, society).
+		Limit(1).
+		Find(&project)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Society not found.",
+		}
+	}
+
+	from, until := h.quarter.Start(), h.quarter.End()
+	report := &reraQuarterlyReport{
+		ReraNumber: project.ReraNumber,
+		Project:    project.Name,
+		Address:    project.Address,
+		Quarter:    h.quarter.String(),
+		From:       custom.DateOnly{Time: from},
+		To:         custom.DateOnly{Time: until.AddDate(0, 0, -1)},
+	}
+	if project.Organization != nil {
+		report.Promoter = project.Organization.Name
+	}
+	args := map[string]any{"orgId": orgId, "society": society, "from": from, "until": until}
+
+	err := db.Raw(`
+		SELECT
+			t.name AS tower,
+			COUNT(f.id) AS total_units,
+			COUNT(s.id) FILTER (WHERE s.created_at < @from) AS booked_before,
+			COUNT(s.id) FILTER (WHERE s.created_at >= @from) AS booked_in_quarter,
+			COUNT(s.id) AS booked,
+			COUNT(f.id) - COUNT(s.id) AS unsold
+		FROM towers t
+		LEFT JOIN flats f ON f.tower_id = t.id AND f.deleted_at IS NULL
+		LEFT JOIN sales s ON s.flat_id = f.id AND s.deleted_at IS NULL AND s.created_at < @until
+		WHERE t.org_id = @orgId AND t.society_id = @society AND t.deleted_at IS NULL
+		GROUP BY t.id, t.name
+		ORDER BY t.name
+	`, args).Scan(&report.Units).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Raw(`
+		SELECT
+			b.name AS bank,
+			b.account_number,
+			b.account_type,
+			COALESCE(SUM(r.total_amount) FILTER (WHERE rc.created_at >= @from), 0) AS total_in_quarter,
+			COALESCE(SUM(r.amount) FILTER (WHERE rc.created_at >= @from), 0) AS amount_in_quarter,
+			COALESCE(SUM(r.amount), 0) AS amount_till_quarter,
+			COUNT(r.id) FILTER (WHERE rc.created_at >= @from) AS receipts_in_quarter,
+			COUNT(r.id) AS receipts_till_quarter
+		FROM banks b
+		LEFT JOIN receipt_clears rc ON rc.bank_id = b.id AND rc.created_at < @until
+		LEFT JOIN receipts r ON r.id = rc.receipt_id AND r.mode <> 'adjustment' AND NOT r.failed
+		WHERE b.org_id = @orgId AND b.society_id = @society
+		GROUP BY b.id, b.name, b.account_number, b.account_type
+		ORDER BY b.name, b.account_number
+	`, args).Scan(&report.Collections).Error
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := designatedAccountMovements(db, orgId, society, until)
+	if err != nil {
+		return nil, err
+	}
+	designated := newDesignatedAccountReport(movements, h.quarter, h.quarter).Quarters[0]
+	report.Escrow = reraEscrow{
+		RealisedInQuarter:    designated.InQuarter.Realised,
+		RequiredInQuarter:    designated.InQuarter.Required,
+		DepositedInQuarter:   designated.InQuarter.Deposited,
+		RealisedTillQuarter:  designated.TillQuarter.Realised,
+		RequiredTillQuarter:  designated.TillQuarter.Required,
+		DepositedTillQuarter: designated.TillQuarter.Deposited,
+		ShortfallTillQuarter: designated.TillQuarter.Shortfall,
+		WithdrawnInQuarter:   designated.Withdrawn,
+		Balance:              designated.Balance,
+		DesignatedShare:      models.ReraDesignatedShare,
+	}
+
+	if err := h.construction(db, args, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// construction adds the progress of every tower by its milestones and the tower stages activated in the quarter
+func (h *hGetReraReport) construction(db *gorm.DB, args map[string]any, report *reraQuarterlyReport) error {
+	var towers []models.Tower
+	err := db.Where("org_id = ? AND society_id = ?", args["orgId"], args["society"]).
+		Order("name").
+		Find(&towers).Error
+	if err != nil {
+		return err
+	}
+
+	var milestones []models.ConstructionMilestone
+	err = db.Where("org_id = ? AND society_id = ?", args["orgId"], args["society"]).
+		Order("completed_on ASC NULLS LAST, planned_date ASC NULLS LAST, created_at ASC").
+		Find(&milestones).Error
+	if err != nil {
+		return err
+	}
+
+	var stages []struct {
+		TowerId     uuid.UUID
+		Description string
+	}
+	err = db.Raw(`
+		SELECT DISTINCT tps.tower_id, i.description
+		FROM tower_payment_statuses tps
+		JOIN towers t ON t.id = tps.tower_id
+		JOIN payment_plan_ratio_items i ON i.id = tps.payment_id
+		WHERE t.org_id = @orgId AND t.society_id = @society
+			AND COALESCE(tps.effective_date, tps.created_at::date) >= CAST(@from AS date)
+			AND COALESCE(tps.effective_date, tps.created_at::date) < CAST(@until AS date)
+		ORDER BY i.description
+	`, args).Scan(&stages).Error
+	if err != nil {
+		return err
+	}
+
+	from, until := h.quarter.Start(), h.quarter.End()
+	report.Construction = make([]reraConstruction, 0, len(towers))
+	report.Milestones = make([]reraMilestone, 0)
+	for _, tower := range towers {
+		progress := reraConstruction{Tower: tower.Name, CompletionPercent: decimal.Zero, StagesInQuarter: make([]string, 0)}
+		for _, milestone := range milestones {
+			if milestone.TowerId != tower.Id {
+				continue
+			}
+			progress.Milestones++
+			if !milestone.IsCompleted() || !milestone.CompletedOn.Before(until) {
+				continue
+			}
+
+			progress.Completed++
+			progress.LastCompletedStage = milestoneName(milestone)
+			progress.LastCompletedOn = milestone.CompletedOn
+			if milestone.CompletedOn.Before(from) {
+				continue
+			}
+			progress.CompletedInQuarter++
+			report.Milestones = append(report.Milestones, reraMilestone{
+				Tower:                tower.Name,
+				Stage:                string(milestone.Stage),
+				FloorNumber:          milestone.FloorNumber,
+				Description:          milestone.Description,
+				CompletedOn:          *milestone.CompletedOn,
+				CertificateReference: milestone.CertificateReference,
+				CertifiedBy:          milestone.CertifiedBy,
+			})
+		}
+		if progress.Milestones > 0 {
+			progress.CompletionPercent = decimal.NewFromInt(progress.Completed * 100).Div(decimal.NewFromInt(progress.Milestones)).Round(2)
+		}
+		for _, stage := range stages {
+			if stage.TowerId == tower.Id {
+				progress.StagesInQuarter = append(progress.StagesInQuarter, stage.Description)
+			}
+		}
+		report.Construction = append(report.Construction, progress)
+	}
+	return nil
+}
+
+// milestoneName is the stage of the milestone with its floor, like slab (floor 3)
+func milestoneName(milestone models.ConstructionMilestone) string {
+	name := string(milestone.Stage)
+	if milestone.FloorNumber != nil {
+		name = fmt.Sprintf("%s (floor %d)", name, *milestone.FloorNumber)
+	}
+	if milestone.Description != "" {
+		name = fmt.Sprintf("%s - %s", name, milestone.Description)
+	}
+	return name
+}
+
+// escrowRows are the particulars of the designated account deposit with their amounts
+func (report *reraQuarterlyReport) escrowRows() [][]any {
+	escrow := report.Escrow
+	share := escrow.DesignatedShare.Mul(decimal.NewFromInt(100)).String()
+	return [][]any{
+		{"Amount realised from allottees in the quarter", escrow.RealisedInQuarter},
+		{fmt.Sprintf("Deposit required in designated account in the quarter (%s%%)", share), escrow.RequiredInQuarter},
+		{"Amount deposited in designated account in the quarter", escrow.DepositedInQuarter},
+		{"Amount realised from allottees till the end of the quarter", escrow.RealisedTillQuarter},
+		{fmt.Sprintf("Deposit required in designated account till the end of the quarter (%s%%)", share), escrow.RequiredTillQuarter},
+		{"Amount deposited in designated account till the end of the quarter", escrow.DepositedTillQuarter},
+		{"Shortfall in designated account till the end of the quarter", escrow.ShortfallTillQuarter},
+		{"Amount withdrawn from designated account in the quarter", escrow.WithdrawnInQuarter},
+		{"Balance in designated account at the end of the quarter", escrow.Balance},
+	}
+}
+
+// tables returns the sections of the report as a sheet each, in the order of the authority's format
+func (report *reraQuarterlyReport) tables() []*tabular.Table {
+	project := tabular.Rows("Project", []tabular.Column{{Path: []string{"Particulars"}}, {Path: []string{"Details"}}}, [][]any{
+		{"RERA Registration Number", report.ReraNumber},
+		{"Project Name", report.Project},
+		{"Project Address", report.Address},
+		{"Promoter", report.Promoter},
+		{"Quarter", report.Quarter},
+		{"Period From", report.From.Time},
+		{"Period To", report.To.Time},
+	})
+
+	units := make([][]any, 0, len(report.Units))
+	for _, u := range report.Units {
+		units = append(units, []any{u.Tower, u.TotalUnits, u.BookedBefore, u.BookedInQuarter, u.Booked, u.Unsold})
+	}
+	inventory := tabular.Rows("Units", []tabular.Column{
+		{Path: []string{"Tower"}},
+		{Path: []string{"Total Units"}},
+		{Path: []string{"Booked", "Till Previous Quarter"}},
+		{Path: []string{"Booked", "In Quarter"}},
+		{Path: []string{"Booked", "Total"}},
+		{Path: []string{"Unsold"}},
+	}, units)
+
+	collectionRows := make([][]any, 0, len(report.Collections))
+	for _, c := range report.Collections {
+		collectionRows = append(collectionRows, []any{c.Bank, c.AccountNumber, c.AccountType, c.ReceiptsInQuarter, c.TotalInQuarter, c.AmountInQuarter, c.ReceiptsTillQuarter, c.AmountTillQuarter})
+	}
+	collections := tabular.Rows("Collections", []tabular.Column{
+		{Path: []string{"Bank"}},
+		{Path: []string{"Account Number"}},
+		{Path: []string{"Account Type"}},
+		{Path: []string{"In Quarter", "Receipts"}},
+		{Path: []string{"In Quarter", "Amount With Taxes"}, Monetary: true},
+		{Path: []string{"In Quarter", "Amount Realised"}, Monetary: true},
+		{Path: []string{"Till Quarter End", "Receipts"}},
+		{Path: []string{"Till Quarter End", "Amount Realised"}, Monetary: true},
+	}, collectionRows)
+
+	escrow := tabular.Rows("Designated Account", []tabular.Column{
+		{Path: []string{"Particulars"}},
+		{Path: []string{"Amount"}, Monetary: true},
+	}, report.escrowRows())
+
+	constructionRows := make([][]any, 0, len(report.Construction))
+	for _, c := range report.Construction {
+		var lastCompletedOn any
+		if c.LastCompletedOn != nil {
+			lastCompletedOn = c.LastCompletedOn.Time
+		}
+		constructionRows = append(constructionRows, []any{
+			c.Tower, c.Milestones, c.Completed, c.CompletedInQuarter, c.CompletionPercent,
+			c.LastCompletedStage, lastCompletedOn, strings.Join(c.StagesInQuarter, ", "),
+		})
+	}
+	construction := tabular.Rows("Construction", []tabular.Column{
+		{Path: []string{"Tower"}},
+		{Path: []string{"Milestones", "Total"}},
+		{Path: []string{"Milestones", "Completed"}},
+		{Path: []string{"Milestones", "In Quarter"}},
+		{Path: []string{"Completion %"}},
+		{Path: []string{"Last Completed", "Stage"}},
+		{Path: []string{"Last Completed", "On"}},
+		{Path: []string{"Payment Stages Activated In Quarter"}},
+	}, constructionRows)
+
+	milestoneRows := make([][]any, 0, len(report.Milestones))
+	for _, m := range report.Milestones {
+		var floor any
+		if m.FloorNumber != nil {
+			floor = *m.FloorNumber
+		}
+		milestoneRows = append(milestoneRows, []any{m.Tower, m.Stage, floor, m.Description, m.CompletedOn.Time, m.CertificateReference, m.CertifiedBy})
+	}
+	milestones := tabular.Rows("Milestones", []tabular.Column{
+		{Path: []string{"Tower"}},
+		{Path: []string{"Stage"}},
+		{Path: []string{"Floor"}},
+		{Path: []string{"Description"}},
+		{Path: []string{"Completed On"}},
+		{Path: []string{"Certificate Reference"}},
+		{Path: []string{"Certified By"}},
+	}, milestoneRows)
+
+	return []*tabular.Table{project, inventory, collections, escrow, construction, milestones}
+}
+
+// pdf renders the report with the same sections as the workbook
+func (report *reraQuarterlyReport) pdf() (*bytes.Buffer, error) {
+	doc := document.NewPDF("RERA Quarterly Progress Report")
+
+	doc.KeyValues([][2]string{
+		{"RERA Registration Number", report.ReraNumber},
+		{"Project Name", report.Project},
+		{"Project Address", report.Address},
+		{"Promoter", report.Promoter},
+		{"Quarter", fmt.Sprintf("%s (%s to %s)", report.Quarter, report.From.Format("02-01-2006"), report.To.Format("02-01-2006"))},
+	})
+
+	doc.Heading("Units")
+	units := make([][]string, 0, len(report.Units))
+	for _, u := range report.Units {
+		units = append(units, []string{u.Tower, fmt.Sprint(u.TotalUnits), fmt.Sprint(u.BookedBefore), fmt.Sprint(u.BookedInQuarter), fmt.Sprint(u.Booked), fmt.Sprint(u.Unsold)})
+	}
+	doc.Table([]string{"Tower", "Total Units", "Booked Before", "Booked In Quarter", "Total Booked", "Unsold"}, units)
+
+	doc.Heading("Collections")
+	collections := make([][]string, 0, len(report.Collections))
+	for _, c := range report.Collections {
+		collections = append(collections, []string{c.Bank, c.AccountNumber, c.AccountType, "Rs. " + c.TotalInQuarter.StringFixed(2), "Rs. " + c.AmountInQuarter.StringFixed(2), "Rs. " + c.AmountTillQuarter.StringFixed(2)})
+	}
+	doc.Table([]string{"Bank", "Account Number", "Account Type", "In Quarter With Taxes", "Realised In Quarter", "Realised Till Date"}, collections)
+
+	doc.Heading("Designated Account")
+	escrow := make([][]string, 0)
+	for _, row := range report.escrowRows() {
+		escrow = append(escrow, []string{row[0].(string), "Rs. " + row[1].(decimal.Decimal).StringFixed(2)})
+	}
+	doc.Table([]string{"Particulars", "Amount"}, escrow)
+
+	doc.Heading("Construction Status")
+	construction := make([][]string, 0, len(report.Construction))
+	for _, c := range report.Construction {
+		construction = append(construction, []string{c.Tower, fmt.Sprintf("%d / %d", c.Completed, c.Milestones), c.CompletionPercent.StringFixed(2) + "%", c.LastCompletedStage})
+	}
+	doc.Table([]string{"Tower", "Milestones Completed", "Completion", "Last Completed Stage"}, construction)
+
+	if len(report.Milestones) > 0 {
+		doc.Heading("Milestones Certified In Quarter")
+		milestones := make([][]string, 0, len(report.Milestones))
+		for _, m := range report.Milestones {
+			stage := m.Stage
+			if m.FloorNumber != nil {
+				stage = fmt.Sprintf("%s (floor %d)", stage, *m.FloorNumber)
+			}
+			milestones = append(milestones, []string{m.Tower, stage, m.CompletedOn.Format("02-01-2006"), m.CertificateReference, m.CertifiedBy})
+		}
+		doc.Table([]string{"Tower", "Stage", "Completed On", "Certificate", "Certified By"}, milestones)
+	}
+
+	doc.Signature(fmt.Sprintf("For %s", report.Promoter), "Authorised Signatory")
+	return doc.Bytes()
+}
+
+// generateReraReport compiles the quarterly progress report of the project, json unless xlsx or pdf is negotiated
+func (s *reportService) generateReraReport(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+
+	h := hGetReraReport{}
+	if err := h.parse(r); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	report, err := h.execute(s.db, orgId, societyRera)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("%s_rera_%s", societyRera, report.Quarter)
+	switch {
+	case h.pdf:
+		file, err := report.pdf()
+		if err != nil {
+			payload.HandleError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", fileName))
+		w.Header().Set("Content-Length", fmt.Sprint(file.Len()))
+		if _, err := w.Write(file.Bytes()); err != nil {
+			payload.HandleError(w, err)
+		}
+	case h.format == tabular.XLSX:
+		var buf bytes.Buffer
+		if err := tabular.WriteWorkbook(&buf, report.tables()...); err != nil {
+			payload.HandleError(w, err)
+			return
+		}
+		tabular.RespondFile(w, tabular.XLSX, fileName, &buf)
+	default:
+		var response custom.JSONResponse
+		response.Error = false
+		response.Data = report
+
+		payload.EncodeJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,118 @@
+package reports
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/tabular"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReraReportParse(t *testing.T) {
+	tests := []struct {
+		target string
+		accept string
+		pdf    bool
+		format tabular.Format
+		fails  bool
+	}{
+		{target: "/rera?quarter=2025-Q1"},
+		{target: "/rera?quarter=2025-Q1&format=pdf", pdf: true},
+		{target: "/rera?quarter=2025-Q1", accept: "application/pdf", pdf: true},
+		{target: "/rera?quarter=2025-Q1&format=xlsx", format: tabular.XLSX},
+		{target: "/rera?quarter=2025-Q1&format=csv", fails: true},
+		{target: "/rera?quarter=2025-1", fails: true},
+		{target: "/rera?quarter=2025-Q5", fails: true},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, test.target, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		h := hGetReraReport{}
+		err := h.parse(r)
+		if (err != nil) != test.fails {
+			t.Errorf("%s: want failure %v, got %v", test.target, test.fails, err)
+			continue
+		}
+		if !test.fails && (h.pdf != test.pdf || h.format != test.format) {
+			t.Errorf("%s: want pdf %v format %q, got %v %q", test.target, test.pdf, test.format, h.pdf, h.format)
+		}
+	}
+
+	h := hGetReraReport{}
+	if err := h.parse(httptest.NewRequest(http.MethodGet, "/rera", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if want := models.QuarterOf(time.Now()).Previous(); h.quarter != want {
+		t.Errorf("want last completed quarter %s, got %s", want, h.quarter)
+	}
+}
+
+func TestReraReportOutput(t *testing.T) {
+	floor := 4
+	report := &reraQuarterlyReport{
+		ReraNumber: "RERA-1",
+		Project:    "Green Acres",
+		Promoter:   "Circle Developers",
+		Quarter:    "2025-Q1",
+		From:       custom.DateOnly{Time: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		To:         custom.DateOnly{Time: time.Date(2025, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		Units:      []reraUnits{{Tower: "A", TotalUnits: 40, BookedBefore: 10, BookedInQuarter: 5, Booked: 15, Unsold: 25}},
+		Collections: []reraCollection{{
+			Bank: "State Bank", AccountNumber: "0001",
+			TotalInQuarter: decimal.NewFromInt(1050000), AmountInQuarter: decimal.NewFromInt(1000000), AmountTillQuarter: decimal.NewFromInt(4000000),
+		}},
+		Escrow: reraEscrow{
+			RealisedInQuarter:   decimal.NewFromInt(1000000),
+			RequiredInQuarter:   models.RequiredDesignatedDeposit(decimal.NewFromInt(1000000)),
+			RealisedTillQuarter: decimal.NewFromInt(4000000),
+			RequiredTillQuarter: models.RequiredDesignatedDeposit(decimal.NewFromInt(4000000)),
+			DepositedInQuarter:  decimal.NewFromInt(600000),
+			WithdrawnInQuarter:  decimal.NewFromInt(500000),
+			DesignatedShare:     models.ReraDesignatedShare,
+		},
+		Construction: []reraConstruction{{Tower: "A", Milestones: 10, Completed: 4, CompletedInQuarter: 1, CompletionPercent: decimal.NewFromInt(40)}},
+		Milestones: []reraMilestone{{
+			Tower: "A", Stage: "slab", FloorNumber: &floor,
+			CompletedOn: custom.DateOnly{Time: time.Date(2025, time.February, 14, 0, 0, 0, 0, time.UTC)}, CertificateReference: "ENG/12",
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := tabular.WriteWorkbook(&buf, report.tables()...); err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	want := []string{"Project", "Units", "Collections", "Designated Account", "Construction", "Milestones"}
+	sheets := file.GetSheetList()
+	if len(sheets) != len(want) {
+		t.Fatalf("want sheets %v, got %v", want, sheets)
+	}
+	rows, err := file.GetRows("Designated Account", excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 10 || rows[2][0] != "Deposit required in designated account in the quarter (70%)" || rows[2][1] != "700000" {
+		t.Errorf("unexpected designated account rows %v", rows)
+	}
+
+	pdf, err := report.pdf()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pdf.Bytes(), []byte("%PDF")) {
+		t.Error("want a pdf document")
+	}
+}
@@ -1,6 +1,7 @@
 package reports
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,21 +13,42 @@ func (s *reportService) GetBasePath() string {
 func (s *reportService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
 
-	// org admin authorization
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
+
+		router.With(permission(custom.PERMISSION_REPORT_MASTER)).Get("/", s.generateMasterReport)
+		router.With(permission(custom.PERMISSION_REPORT_MASTER)).Post("/job", s.enqueueMasterReport)
+		router.With(permission(custom.PERMISSION_REPORT_RECEIPT)).Get("/receipts", s.generateReceiptsReport)
+		router.With(permission(custom.PERMISSION_REPORT_PAYMENT_PLAN)).Get("/payment-plan", s.generatePaymentPlanReports)
+		router.With(permission(custom.PERMISSION_REPORT_AGEING)).Get("/ageing", s.generateAgeingReport)
+		router.With(permission(custom.PERMISSION_REPORT_CASH_FLOW)).Get("/cash-flow", s.generateCashFlowProjection)
+		router.With(permission(custom.PERMISSION_REPORT_RERA)).Get("/rera", s.generateReraReport)
+		router.With(permission(custom.PERMISSION_REPORT_RERA)).Get("/designated-account", s.generateDesignatedAccountReport)
 	})
 
-	// org admin and user
+	return mux
+}
+
+func (s *reportTemplateService) GetBasePath() string {
+	return "/report-template"
+}
+
+func (s *reportTemplateService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
+
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Get("/", s.generateMasterReport)
-		router.Get("/receipts", s.generateReceiptsReport)
-		router.Get("/payment-plan", s.generatePaymentPlanReports)
+		router.With(permission(custom.PERMISSION_REPORT_MASTER)).Get("/columns", s.getReportColumns)
+		router.With(permission(custom.PERMISSION_REPORT_MASTER)).Get("/", s.getReportTemplates)
+		router.With(permission(custom.PERMISSION_REPORT_MASTER)).Get("/{templateId}", s.getReportTemplate)
+		router.With(permission(custom.PERMISSION_REPORT_TEMPLATE_MANAGE)).Post("/", s.createReportTemplate)
+		router.With(permission(custom.PERMISSION_REPORT_TEMPLATE_MANAGE)).Put("/{templateId}", s.updateReportTemplate)
+		router.With(permission(custom.PERMISSION_REPORT_TEMPLATE_MANAGE)).Delete("/{templateId}", s.deleteReportTemplate)
 	})
 
 	return mux
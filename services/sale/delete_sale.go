@@ -2,9 +2,11 @@ package sale
 
 import (
 	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/approval"
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -18,7 +20,10 @@ func (h *hClearSaleRecord) validate(db *gorm.DB, orgId, society, saleId string)
 	return common.IsSameSociety(societyInfoService, orgId, society)
 
 }
-func (h *hClearSaleRecord) execute(db *gorm.DB, orgId, society, saleId string) error {
+
+// execute soft deletes the sale, receipts and customers are kept for restore until the sale is purged
+// unpaid broker commission of the sale is cancelled
+func (h *hClearSaleRecord) execute(db *gorm.DB, orgId, society, saleId, deletedBy string) error {
 	err := h.validate(db, orgId, society, saleId)
 	if err != nil {
 		return err
@@ -27,16 +32,76 @@ func (h *hClearSaleRecord) execute(db *gorm.DB, orgId, society, saleId string) e
 	saleModel := models.Sale{
 		Id: uuid.MustParse(saleId),
 	}
-	return db.Delete(&saleModel).Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&saleModel).Update("deleted_by", deletedBy).Error
+		if err != nil {
+			return err
+		}
+		err = tx.First(&saleModel).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&saleModel).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.BrokerCommission{}).
+			Where("sale_id = ? and status <> ?", saleId, custom.COMMISSION_PAID).
+			Update("status", custom.COMMISSION_CANCELLED).Error
+		if err != nil {
+			return err
+		}
+		return webhook.Publish(tx, orgId, society, custom.EVENT_SALE_DELETED, saleId, saleModel)
+	})
+}
+
+// submit creates a pending approval request if sale deletion requires approval, amount is the sale total price
+func (h *hClearSaleRecord) submit(db *gorm.DB, orgId, society, saleId, requestedBy string) (*models.ApprovalRequest, error) {
+	err := h.validate(db, orgId, society, saleId)
+	if err != nil {
+		return nil, err
+	}
+
+	var saleModel models.Sale
+	err = db.Select("total_price").First(&saleModel, "id = ?", saleId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return approval.Submit(db, approval.Submission{
+		OrgId:       orgId,
+		Society:     society,
+		Action:      custom.APPROVAL_SALE_DELETE,
+		EntityId:    saleId,
+		Amount:      &saleModel.TotalPrice,
+		RequestedBy: requestedBy,
+	})
+}
+
+// executeApprovedSaleDelete deletes the sale of an approved request on behalf of the requester
+func executeApprovedSaleDelete(db *gorm.DB, request *models.ApprovalRequest) error {
+	sale := hClearSaleRecord{}
+	return sale.execute(db, request.OrgId.String(), request.SocietyId, request.EntityId, request.RequestedBy)
 }
 
 func (s *saleService) clearSaleRecord(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
 	saleId := chi.URLParam(r, "saleId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
 
 	sale := hClearSaleRecord{}
-	err := sale.execute(s.db, orgId, societyRera, saleId)
+	pending, err := sale.submit(s.db, orgId, societyRera, saleId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	if pending != nil {
+		approval.RespondPending(w, pending)
+		return
+	}
+
+	err = sale.execute(s.db, orgId, societyRera, saleId, userEmail)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
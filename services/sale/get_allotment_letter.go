@@ -0,0 +1,154 @@
+package sale
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/document"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type hGetAllotmentLetter struct{}
+
+func (h *hGetAllotmentLetter) validate(db *gorm.DB, orgId, society, saleId string) error {
+	saleSocietyInfo := CreateSaleSocietyInfoService(db, uuid.MustParse(saleId))
+	return common.IsSameSociety(saleSocietyInfo, orgId, society)
+}
+
+func (h *hGetAllotmentLetter) execute(db *gorm.DB, orgId, society, saleId string) (*bytes.Buffer, *models.SaleAllotment, error) {
+	err := h.validate(db, orgId, society, saleId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sale models.Sale
+	err = db.
+		Preload("Allotment").
+		Preload("Flat").
+		Preload("Flat.Tower").
+		Preload("Customers").
+		Preload("CompanyCustomer").
+		Preload("Society").
+		Preload("Society.Organization").
+		First(&sale, "id = ?", saleId).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if sale.Allotment == nil {
+		return nil, nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Sale is not allotted yet.",
+		}
+	}
+
+	letter, err := createAllotmentLetter(sale)
+	if err != nil {
+		return nil, nil, err
+	}
+	return letter, sale.Allotment, nil
+}
+
+// createAllotmentLetter renders the allotment letter from sale, flat and buyer details
+func createAllotmentLetter(sale models.Sale) (*bytes.Buffer, error) {
+	if sale.Flat == nil || sale.Flat.Tower == nil || sale.Society == nil {
+		return nil, errors.New("allotment letter requires flat, tower and society details")
+	}
+
+	allotment := sale.Allotment
+	flat := sale.Flat
+	society := sale.Society
+
+	doc := document.NewPDF("Allotment Letter")
+
+	orgName := ""
+	if society.Organization != nil {
+		orgName = society.Organization.Name
+	}
+
+	doc.KeyValues([][2]string{
+		{"Letter Number", allotment.LetterNumber},
+		{"Date", allotment.AllotmentDate.Format("02-01-2006")},
+		{"Booking Number", sale.SaleNumber},
+	})
+
+	// buyers
+	doc.Heading("Allottee(s)")
+	if sale.CompanyCustomer != nil {
+		doc.KeyValues([][2]string{
+			{"Company", sale.CompanyCustomer.Name},
+			{"Company PAN", sale.CompanyCustomer.CompanyPan},
+			{"GST", sale.CompanyCustomer.CompanyGst},
+		})
+	} else {
+		rows := make([][]string, 0, len(sale.Customers))
+		for _, customer := range sale.Customers {
+			name := strings.Join(strings.Fields(fmt.Sprintf("%s %s %s %s", customer.Salutation, customer.FirstName, customer.MiddleName, customer.LastName)), " ")
+			rows = append(rows, []string{name, customer.PhoneNumber, customer.Email, customer.PanNumber})
+		}
+		doc.Table([]string{"Name", "Phone", "Email", "PAN"}, rows)
+	}
+
+	doc.Paragraph(fmt.Sprintf(
+		"We are pleased to inform you that the unit described below in our project %s (RERA No. %s), %s has been allotted to you on the terms and conditions of the booking and the payment plan opted by you.",
+		society.Name, society.ReraNumber, society.Address,
+	))
+
+	// unit
+	doc.Heading("Unit Details")
+	doc.KeyValues([][2]string{
+		{"Tower", flat.Tower.Name},
+		{"Unit", flat.Name},
+		{"Floor", fmt.Sprintf("%d", flat.FloorNumber)},
+		{"Unit Type", flat.UnitType},
+		{"Saleable Area", fmt.Sprintf("%s sq. ft.", flat.SaleableArea.String())},
+		{"Facing", string(flat.Facing)},
+	})
+
+	// consideration
+	doc.Heading("Consideration")
+	priceRows := make([][]string, 0, len(sale.PriceBreakdown)+1)
+	for _, item := range sale.PriceBreakdown {
+		priceRows = append(priceRows, []string{item.Summary, "Rs. " + item.Total.StringFixed(2)})
+	}
+	priceRows = append(priceRows, []string{"Total", "Rs. " + sale.TotalPrice.StringFixed(2)})
+	doc.Table([]string{"Component", "Amount"}, priceRows)
+
+	doc.Signature(fmt.Sprintf("For %s", orgName), "Authorised Signatory", allotment.IssuedBy)
+
+	return doc.Bytes()
+}
+
+func (s *saleService) getAllotmentLetter(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	saleId := chi.URLParam(r, "saleId")
+
+	letter := hGetAllotmentLetter{}
+	file, allotment, err := letter.execute(s.db, orgId, societyRera, saleId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=allotment_letter_%s.pdf", allotment.LetterNumber),
+	)
+	w.Header().Set("Content-Length", fmt.Sprint(file.Len()))
+
+	if _, err := w.Write(file.Bytes()); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+}
@@ -1,13 +1,16 @@
 package sale
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"circledigital.in/real-state-erp/models"
 	"circledigital.in/real-state-erp/services/tower"
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/tabular"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -25,101 +28,50 @@ func (h *hGetSalePaymentBreakDown) validate(db *gorm.DB, orgId, society, saleId
 	return common.IsSameSociety(saleSocietyInfo, orgId, society)
 }
 
-func (h *hGetSalePaymentBreakDown) execute(db *gorm.DB, orgId, society, saleId string) (*models.PaymentPlanSaleBreakDown, error) {
+func (h *hGetSalePaymentBreakDown) execute(db *gorm.DB, orgId, society, saleId string, asOf time.Time) (*models.PaymentPlanSaleBreakDown, error) {
 	err := h.validate(db, orgId, society, saleId)
 	if err != nil {
 		return nil, err
 	}
 
-	sale := models.Sale{
-		Id: uuid.MustParse(saleId),
-	}
-	err = db.Find(&sale).Error
+	var sale models.Sale
+	err = db.
+		Preload("PaymentPlanRatio").
+		Preload("PaymentPlanRatio.Ratios", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Receipts").
+		Preload("Receipts.Cleared").
+		Preload("Allotment").
+		Preload("Flat").
+		Preload("Flat.ActivePaymentPlanRatioItems").
+		Preload("Flat.Tower").
+		Preload("Flat.Tower.ActivePaymentPlanRatioItems").
+		First(&sale, "id = ?", saleId).Error
 	if err != nil {
 		return nil, err
 	}
 
-	// direct payment plans
-	// var directPlans []models.PaymentPlan
-	// err = db.
-	// 	Model(&models.PaymentPlan{}).
-	// 	Where("org_id = ? and society_id = ? and scope = ?", orgId, society, custom.DIRECT).
-	// 	Find(&directPlans).Error
-	// if err != nil {
-	// 	return nil, err
-	// }
-	//
-	// for i := range directPlans {
-	// 	plan := &directPlans[i]
-	// 	if plan.ConditionType == custom.WITHINDAYS {
-	// 		due := plan.CreatedAt.AddDate(0, 0, plan.ConditionValue)
-	// 		plan.Due = &due
-	// 	}
-	// }
-	//
-	// // tower active payment plans
-	// var paymentPlans []models.PaymentPlan
-	// err = db.
-	// 	Model(&models.PaymentPlan{}).
-	// 	Joins("JOIN tower_payment_statuses tps ON tps.payment_id = payment_plans.id").
-	// 	Joins("JOIN towers ON towers.id = tps.tower_id").
-	// 	Joins("JOIN flats ON flats.tower_id = towers.id").
-	// 	Joins("JOIN sales ON sales.flat_id = flats.id").
-	// 	Where("sales.id = ?", saleId).
-	// 	Select("payment_plans.*").
-	// 	Scan(&paymentPlans).Error
-	// if err != nil {
-	// 	return nil, err
-	// }
-	//
-	// paymentPlans = append(directPlans, paymentPlans...)
-	// paymentPlans = common.SortDbModels(paymentPlans)
-	//
-	// total amount paid
-	var totalPaid decimal.Decimal
-	err = db.Table("receipts AS r").
-		Select("COALESCE(SUM(r.total_amount), 0)").
-		Joins("JOIN receipt_clears c ON c.receipt_id = r.id").
-		Where("r.sale_id = ?", saleId).
-		Group("r.sale_id").
-		Scan(&totalPaid).Error
-	if err != nil {
-		return nil, err
+	if !sale.IsBookedBy(asOf) {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Sale was not booked on the requested date.",
+		}
+	}
+	sale = sale.AsOf(asOf)
+
+	var activeFlatPaymentPlans []models.FlatPaymentStatus
+	var activeTowerPaymentPlans []models.TowerPaymentStatus
+	if sale.Flat != nil {
+		activeFlatPaymentPlans = sale.Flat.ActivePaymentPlanRatioItems
+		if sale.Flat.Tower != nil {
+			activeTowerPaymentPlans = sale.Flat.Tower.ActivePaymentPlanRatioItems
+		}
 	}
 
-	// totalPaidCpy := totalPaid
-	// total amount according to active payment plans
-	var total = decimal.Zero
-	// for i, plan := range paymentPlans {
-	// 	percent := decimal.NewFromInt(int64(plan.Amount)) // Convert int to decimal
-	// 	amount := sale.TotalPrice.Mul(percent).Div(decimal.NewFromInt(100))
-	//
-	// 	paymentPlans[i].TotalAmount = &amount
-	// 	total = total.Add(amount)
-	//
-	// 	// Distribute totalPaidCpy
-	// 	if totalPaidCpy.GreaterThanOrEqual(amount) {
-	// 		paymentPlans[i].AmountPaid = &amount
-	// 		paymentPlans[i].Remaining = decimalPtr(decimal.Zero)
-	// 		totalPaidCpy = totalPaidCpy.Sub(amount)
-	// 	} else if totalPaidCpy.GreaterThan(decimal.Zero) {
-	// 		amountPaid := totalPaidCpy
-	// 		paymentPlans[i].AmountPaid = &amountPaid
-	// 		remaining := amount.Sub(totalPaidCpy)
-	// 		paymentPlans[i].Remaining = decimalPtr(remaining)
-	// 		totalPaidCpy = decimal.Zero
-	// 	} else {
-	// 		paymentPlans[i].AmountPaid = decimalPtr(decimal.Zero)
-	// 		paymentPlans[i].Remaining = decimalPtr(amount)
-	// 	}
-	// }
-	//
-	return &models.PaymentPlanSaleBreakDown{
-		TotalAmount: total,
-		PaidAmount:  totalPaid,
-		Remaining:   total.Sub(totalPaid),
-		// Details:     paymentPlans,
-	}, nil
+	activationCtx := models.NewPaymentActivationContext(asOf, &sale, activeFlatPaymentPlans, activeTowerPaymentPlans)
+	breakDown := sale.GetPaymentPlanBreakDown(activationCtx)
+	return &breakDown, nil
 }
 
 func (s *saleService) getSalePaymentBreakDown(w http.ResponseWriter, r *http.Request) {
@@ -127,8 +79,14 @@ func (s *saleService) getSalePaymentBreakDown(w http.ResponseWriter, r *http.Req
 	saleId := chi.URLParam(r, "saleId")
 	societyRera := chi.URLParam(r, "society")
 
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
 	details := hGetSalePaymentBreakDown{}
-	res, err := details.execute(s.db, orgId, societyRera, saleId)
+	res, err := details.execute(s.db, orgId, societyRera, saleId, asOf)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
@@ -143,29 +101,43 @@ func (s *saleService) getSalePaymentBreakDown(w http.ResponseWriter, r *http.Req
 
 type hGetSocietySalesReport struct{}
 
-func (h *hGetSocietySalesReport) execute(db *gorm.DB, orgId, society string) (*models.PaymentReport, error) {
-	var total decimal.Decimal
-	err := db.Model(&models.Sale{}).
-		Where("org_id = ? AND society_id = ?", orgId, society).
-		Select("COALESCE(SUM(total_price), 0)"). // Use COALESCE to avoid null
-		Scan(&total).Error
-
+// sales returns the sales of the society booked by asOf as they were on asOf
+func (h *hGetSocietySalesReport) sales(db *gorm.DB, orgId, society string, asOf time.Time) ([]models.Sale, error) {
+	var sales []models.Sale
+	err := db.
+		Preload("Flat").
+		Preload("Flat.Tower").
+		Preload("Customers").
+		Preload("CompanyCustomer").
+		Preload("Receipts").
+		Preload("Receipts.Cleared").
+		Where("org_id = ? AND society_id = ? AND created_at <= ?", orgId, society, asOf).
+		Order("created_at").
+		Find(&sales).Error
 	if err != nil {
 		return nil, err
 	}
 
-	var paid decimal.Decimal
-	err = db.
-		Table("receipts AS r").
-		Select("COALESCE(SUM(r.total_amount), 0) AS total_paid_amount").
-		Joins("JOIN receipt_clears c ON c.receipt_id = r.id").
-		Joins("JOIN sales s ON s.id = r.sale_id").
-		Where("s.society_id = ? AND s.org_id = ?", society, orgId).
-		Scan(&paid).Error
+	for i := range sales {
+		sales[i] = sales[i].AsOf(asOf)
+	}
+	return sales, nil
+}
+
+// execute computes the society position as of asOf from the sale price and the receipts cleared by then
+func (h *hGetSocietySalesReport) execute(db *gorm.DB, orgId, society string, asOf time.Time) (*models.PaymentReport, error) {
+	sales, err := h.sales(db, orgId, society, asOf)
 	if err != nil {
 		return nil, err
 	}
 
+	total := decimal.Zero
+	paid := decimal.Zero
+	for _, sale := range sales {
+		total = total.Add(sale.TotalPrice)
+		paid = paid.Add(sale.ClearedAmount())
+	}
+
 	return &models.PaymentReport{
 		Total:   total,
 		Paid:    paid,
@@ -173,12 +145,69 @@ func (h *hGetSocietySalesReport) execute(db *gorm.DB, orgId, society string) (*m
 	}, nil
 }
 
+// table returns a row per sale, the rows add up to the society position
+func (h *hGetSocietySalesReport) table(sales []models.Sale) *tabular.Table {
+	columns := []tabular.Column{
+		{Path: []string{models.HeadingSale, "Sale Number"}},
+		{Path: []string{models.HeadingSale, "Booked On"}},
+		{Path: []string{models.HeadingFlat, "Tower"}},
+		{Path: []string{models.HeadingFlat, "Flat"}},
+		{Path: []string{models.HeadingCustomer, "Name"}},
+		{Path: []string{"Payment", "Total Price"}, Monetary: true},
+		{Path: []string{"Payment", "Paid"}, Monetary: true},
+		{Path: []string{"Payment", "Pending"}, Monetary: true},
+	}
+
+	rows := make([][]any, 0, len(sales))
+	for _, sale := range sales {
+		var towerName, flatName any
+		if sale.Flat != nil {
+			flatName = sale.Flat.Name
+			if sale.Flat.Tower != nil {
+				towerName = sale.Flat.Tower.Name
+			}
+		}
+		rows = append(rows, []any{
+			sale.SaleNumber,
+			sale.CreatedAt,
+			towerName,
+			flatName,
+			sale.OwnerNames(),
+			sale.TotalPrice,
+			sale.ClearedAmount(),
+			sale.TotalPrice.Sub(sale.ClearedAmount()),
+		})
+	}
+	return tabular.Rows("Sales", columns, rows)
+}
+
 func (s *saleService) getSocietySalesReport(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
 
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	format, err := tabular.Negotiate(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
 	report := hGetSocietySalesReport{}
-	res, err := report.execute(s.db, orgId, societyRera)
+	if !format.IsDefault() {
+		sales, err := report.sales(s.db, orgId, societyRera, asOf)
+		if err != nil {
+			payload.HandleError(w, err)
+			return
+		}
+		tabular.Respond(w, format, fmt.Sprintf("%s_sales_report_%d", societyRera, asOf.Unix()), report.table(sales))
+		return
+	}
+
+	res, err := report.execute(s.db, orgId, societyRera, asOf)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
@@ -198,157 +227,89 @@ func (h *hGetTowerSalesReport) validate(db *gorm.DB, orgId, society, towerId str
 	return common.IsSameSociety(societyInfoService, orgId, society)
 }
 
-func (h *hGetTowerSalesReport) execute(db *gorm.DB, orgId, society, towerId string) (*models.TowerReport, error) {
+// towerFlatPosition is the position of a sold flat of the tower
+type towerFlatPosition struct {
+	flat             models.Flat
+	total            decimal.Decimal
+	paymentPlanTotal decimal.Decimal
+	paid             decimal.Decimal
+}
+
+// positions returns the position of every flat of the tower sold on asOf
+// payment plan totals only include items active on asOf
+func (h *hGetTowerSalesReport) positions(db *gorm.DB, orgId, society, towerId string, asOf time.Time) ([]towerFlatPosition, error) {
 	err := h.validate(db, orgId, society, towerId)
 	if err != nil {
 		return nil, err
 	}
 
-	// 1 -> get all tower sold flats
+	var towerModel models.Tower
+	err = db.Preload("ActivePaymentPlanRatioItems").First(&towerModel, "id = ?", towerId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 1 -> get all tower flats sold on asOf
 	var soldFlats []models.Flat
 	err = db.
 		//Preload("FlatType").
+		Preload("ActivePaymentPlanRatioItems").
 		Preload("SaleDetail").
 		Preload("SaleDetail.Customers").
 		Preload("SaleDetail.CompanyCustomer").
 		Preload("SaleDetail.Broker").
+		Preload("SaleDetail.ReferrerCustomer").
+		Preload("SaleDetail.Allotment").
+		Preload("SaleDetail.PaymentPlanRatio").
+		Preload("SaleDetail.PaymentPlanRatio.Ratios", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
 		Preload("SaleDetail.Receipts").
 		Preload("SaleDetail.Receipts.Cleared").
 		Preload("SaleDetail.Receipts.Cleared.Bank").
-		Joins("JOIN sales s ON s.flat_id = flats.id").
-		Where("flats.tower_id = ?", towerId).
+		Joins("JOIN sales s ON s.flat_id = flats.id AND s.deleted_at IS NULL").
+		Where("flats.tower_id = ? AND s.created_at <= ?", towerId, asOf).
 		Find(&soldFlats).Error
 	if err != nil {
 		return nil, err
 	}
 
-	// 2 -> get total sale amount
-	totalAmountTower := decimal.Zero
-	totalAmountTowerPaymentPlan := decimal.Zero
-	totalTowerPaid := decimal.Zero
-
-	// 3 -> create map flatId -> flatStatsInfo
-	type flatStatInfo struct {
-		total decimal.Decimal
-		paid  decimal.Decimal
-	}
-	flatsMap := make(map[uuid.UUID]flatStatInfo)
-
-	// populate map and tower amount
+	// 2 -> get sale and payment plan amount of each flat
+	positions := make([]towerFlatPosition, 0, len(soldFlats))
 	for _, flat := range soldFlats {
-		flatId := flat.Id
-		total := flat.SaleDetail.TotalPrice
-
-		paid := decimal.Zero
-		for _, receipt := range flat.SaleDetail.Receipts {
-			if receipt.Cleared != nil {
-				paid = paid.Add(receipt.TotalAmount)
-			}
-		}
+		flat = flat.AsOf(asOf)
+		sale := flat.SaleDetail
+
+		activationCtx := models.NewPaymentActivationContext(asOf, sale, flat.ActivePaymentPlanRatioItems, towerModel.ActivePaymentPlanRatioItems)
+		breakDown := sale.GetPaymentPlanBreakDown(activationCtx)
+
+		positions = append(positions, towerFlatPosition{
+			flat:             flat,
+			total:            sale.TotalPrice,
+			paymentPlanTotal: breakDown.TotalAmount,
+			paid:             sale.ClearedAmount(),
+		})
+	}
+	return positions, nil
+}
 
-		flatsMap[flatId] = flatStatInfo{
-			total: total,
-			paid:  paid,
-		}
-		totalAmountTower = totalAmountTower.Add(total)
+// execute computes the tower position as of asOf
+func (h *hGetTowerSalesReport) execute(db *gorm.DB, orgId, society, towerId string, asOf time.Time) (*models.TowerReport, error) {
+	positions, err := h.positions(db, orgId, society, towerId, asOf)
+	if err != nil {
+		return nil, err
 	}
 
-	// 4 -> get all direct plans
-	// var directPlans []models.PaymentPlan
-	// err = db.
-	// 	Model(&models.PaymentPlan{}).
-	// 	Where("org_id = ? and society_id = ? and scope = ?", orgId, society, custom.DIRECT).
-	// 	Find(&directPlans).Error
-	// if err != nil {
-	// 	return nil, err
-	// }
-	//
-	// // 5 -> get all tower active payment plans
-	// var paymentPlans []models.PaymentPlan
-	// err = db.
-	// 	Model(&models.PaymentPlan{}).
-	// 	Joins("JOIN tower_payment_statuses tps ON tps.payment_id = payment_plans.id").
-	// 	Where("tps.tower_id = ?", towerId).
-	// 	Select("payment_plans.*").
-	// 	Scan(&paymentPlans).Error
-	// if err != nil {
-	// 	return nil, err
-	// }
-	//
-	// // all payment plans combined and // sort them
-	// paymentPlans = append(directPlans, paymentPlans...)
-	// paymentPlans = common.SortDbModels(paymentPlans)
-
-	// create tower payment breakdown
-	// var towerReportPaymentBreakdown []models.TowerReportPaymentBreakdown
-	// for _, plan := range paymentPlans {
-	// 	totalPlanAmount := decimal.Zero
-	// 	totalPlanPaid := decimal.Zero
-	//
-	// 	percent := decimal.NewFromInt(int64(plan.Amount)).Div(decimal.NewFromInt(100))
-	//
-	// 	var paidItems []models.TowerReportPaymentBreakdownItem
-	// 	var unpaidItems []models.TowerReportPaymentBreakdownItem
-	//
-	// 	for _, flat := range soldFlats {
-	// 		flatId := flat.Id
-	// 		flatPaidRem := flatsMap[flatId].paid
-	// 		isPaid := false
-	//
-	// 		flatTotalPaymentPlan := flatsMap[flatId].total.Mul(percent)
-	// 		flatPaid := decimal.Zero
-	// 		flatRemaining := decimal.Zero
-	//
-	// 		if flatPaidRem.GreaterThanOrEqual(flatTotalPaymentPlan) {
-	// 			isPaid = true
-	//
-	// 			flatPaid = flatTotalPaymentPlan
-	// 			flatPaidRem = flatPaidRem.Sub(flatTotalPaymentPlan)
-	// 		} else if flatPaidRem.GreaterThan(decimal.Zero) {
-	// 			flatPaid = flatPaidRem
-	// 			flatPaidRem = decimal.Zero
-	// 			flatRemaining = flatTotalPaymentPlan.Sub(flatPaid)
-	// 		} else {
-	// 			flatRemaining = flatTotalPaymentPlan
-	// 		}
-	//
-	// 		totalPlanAmount = totalPlanAmount.Add(flatTotalPaymentPlan)
-	// 		totalPlanPaid = totalPlanPaid.Add(flatPaid)
-	//
-	// 		// update flat
-	// 		flat := flatsMap[flatId]
-	// 		flat.paid = flatPaidRem
-	// 		flatsMap[flatId] = flat
-	//
-	// 		// create payment plan item
-	// 		paymentPlanItem := models.TowerReportPaymentBreakdownItem{
-	// 			FlatId:    flatId,
-	// 			Total:     flatTotalPaymentPlan,
-	// 			Paid:      flatPaid,
-	// 			Remaining: flatRemaining,
-	// 		}
-	//
-	// 		// add to correct slice
-	// 		if isPaid {
-	// 			paidItems = append(paidItems, paymentPlanItem)
-	// 		} else {
-	// 			unpaidItems = append(unpaidItems, paymentPlanItem)
-	// 		}
-	// 	}
-	//
-	// 	totalAmountTowerPaymentPlan = totalAmountTowerPaymentPlan.Add(totalPlanAmount)
-	// 	totalTowerPaid = totalTowerPaid.Add(totalPlanPaid)
-	//
-	// 	towerPaymentPlanItem := models.TowerReportPaymentBreakdown{
-	// 		PaymentPlan: plan,
-	// 		Total:       totalPlanAmount,
-	// 		Paid:        totalPlanPaid,
-	// 		Remaining:   totalPlanAmount.Sub(totalPlanPaid),
-	// 		PaidItems:   paidItems,
-	// 		UnpaidItems: unpaidItems,
-	// 	}
-	// 	towerReportPaymentBreakdown = append(towerReportPaymentBreakdown, towerPaymentPlanItem)
-	// }
+	totalAmountTower := decimal.Zero
+	totalAmountTowerPaymentPlan := decimal.Zero
+	totalTowerPaid := decimal.Zero
+	soldFlats := make([]models.Flat, 0, len(positions))
+	for _, position := range positions {
+		soldFlats = append(soldFlats, position.flat)
+		totalAmountTower = totalAmountTower.Add(position.total)
+		totalAmountTowerPaymentPlan = totalAmountTowerPaymentPlan.Add(position.paymentPlanTotal)
+		totalTowerPaid = totalTowerPaid.Add(position.paid)
+	}
 
 	return &models.TowerReport{
 		Flats: soldFlats,
@@ -362,18 +323,73 @@ func (h *hGetTowerSalesReport) execute(db *gorm.DB, orgId, society, towerId stri
 			Paid:      totalTowerPaid,
 			Remaining: totalAmountTowerPaymentPlan.Sub(totalTowerPaid),
 		},
-		// PaymentBreakdown: towerReportPaymentBreakdown,
 	}, nil
 }
 
-// todo fix this
+// table returns a row per sold flat, the rows add up to the tower position
+func (h *hGetTowerSalesReport) table(positions []towerFlatPosition) *tabular.Table {
+	columns := []tabular.Column{
+		{Path: []string{models.HeadingFlat, "Flat"}},
+		{Path: []string{models.HeadingFlat, "Floor"}},
+		{Path: []string{models.HeadingSale, "Sale Number"}},
+		{Path: []string{models.HeadingSale, "Booked On"}},
+		{Path: []string{models.HeadingCustomer, "Name"}},
+		{Path: []string{"Overall", "Total"}, Monetary: true},
+		{Path: []string{"Overall", "Paid"}, Monetary: true},
+		{Path: []string{"Overall", "Remaining"}, Monetary: true},
+		{Path: []string{models.HeadingPaymentPlan, "Total"}, Monetary: true},
+		{Path: []string{models.HeadingPaymentPlan, "Paid"}, Monetary: true},
+		{Path: []string{models.HeadingPaymentPlan, "Remaining"}, Monetary: true},
+	}
+
+	rows := make([][]any, 0, len(positions))
+	for _, position := range positions {
+		sale := position.flat.SaleDetail
+		rows = append(rows, []any{
+			position.flat.Name,
+			position.flat.FloorNumber,
+			sale.SaleNumber,
+			sale.CreatedAt,
+			sale.OwnerNames(),
+			position.total,
+			position.paid,
+			position.total.Sub(position.paid),
+			position.paymentPlanTotal,
+			position.paid,
+			position.paymentPlanTotal.Sub(position.paid),
+		})
+	}
+	return tabular.Rows("Tower Sales", columns, rows)
+}
+
 func (s *saleService) getTowerSalesReport(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
 	towerId := chi.URLParam(r, "towerId")
 
+	asOf, err := common.ParseAsOfDate(r.URL.Query().Get("asOf"))
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	format, err := tabular.Negotiate(r)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
 	report := hGetTowerSalesReport{}
-	res, err := report.execute(s.db, orgId, societyRera, towerId)
+	if !format.IsDefault() {
+		positions, err := report.positions(s.db, orgId, societyRera, towerId, asOf)
+		if err != nil {
+			payload.HandleError(w, err)
+			return
+		}
+		tabular.Respond(w, format, fmt.Sprintf("tower_%s_sales_report_%d", towerId, asOf.Unix()), report.table(positions))
+		return
+	}
+
+	res, err := report.execute(s.db, orgId, societyRera, towerId, asOf)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
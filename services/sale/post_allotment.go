@@ -0,0 +1,103 @@
+package sale
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type hAllotSale struct {
+	LetterNumber  string          `validate:"required"`
+	AllotmentDate custom.DateOnly `validate:"required"`
+}
+
+func (h *hAllotSale) validate(db *gorm.DB, orgId, society, saleId string) error {
+	if strings.TrimSpace(h.LetterNumber) == "" || h.AllotmentDate.IsZero() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Required missing values: Letter Number or Allotment Date",
+		}
+	}
+
+	saleSocietyInfo := CreateSaleSocietyInfoService(db, uuid.MustParse(saleId))
+	err := common.IsSameSociety(saleSocietyInfo, orgId, society)
+	if err != nil {
+		return err
+	}
+
+	var existing models.SaleAllotment
+	err = db.Where("sale_id = ?", saleId).First(&existing).Error
+	if err == nil {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Sale is already allotted.",
+		}
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (h *hAllotSale) execute(db *gorm.DB, orgId, society, saleId, issuedBy string) (*models.SaleAllotment, error) {
+	err := h.validate(db, orgId, society, saleId)
+	if err != nil {
+		return nil, err
+	}
+
+	allotment := models.SaleAllotment{
+		SaleId:        uuid.MustParse(saleId),
+		SocietyId:     society,
+		OrgId:         uuid.MustParse(orgId),
+		LetterNumber:  strings.TrimSpace(h.LetterNumber),
+		AllotmentDate: h.AllotmentDate,
+		IssuedBy:      issuedBy,
+	}
+
+	err = db.Create(&allotment).Error
+	if err != nil {
+		if strings.Contains(err.Error(), "idx_society_allotment_letter") {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Duplicate allotment letter number.",
+			}
+		}
+		return nil, err
+	}
+
+	return &allotment, nil
+}
+
+func (s *saleService) allotSale(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail := r.Context().Value(custom.UserEmailKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	saleId := chi.URLParam(r, "saleId")
+
+	reqBody := payload.ValidateAndDecodeRequest[hAllotSale](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	allotment, err := reqBody.execute(s.db, orgId, societyRera, saleId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully allotted sale."
+	response.Data = allotment
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
@@ -2,6 +2,7 @@ package sale
 
 import (
 	"net/http"
+	"strings"
 
 	"circledigital.in/real-state-erp/models"
 	"circledigital.in/real-state-erp/services/broker"
@@ -9,7 +10,9 @@ import (
 	payment_plan_group "circledigital.in/real-state-erp/services/payment-plan-group"
 	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
 	"circledigital.in/real-state-erp/utils/payload"
+	"circledigital.in/real-state-erp/utils/webhook"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -30,7 +33,109 @@ type hCreateSale struct {
 	//OptionalCharges []string
 	OtherCharges []optionalChargesDetails `validate:"omitempty,dive"`
 	CompanyBuyer companyCustomerDetails   `validate:"omitempty"`
-	BrokerId     string                   `validate:"required,uuid"`
+	// empty channel defaults to broker when broker id is provided, direct otherwise
+	Channel            string
+	BrokerId           string `validate:"omitempty,uuid"`
+	ReferrerCustomerId string `validate:"omitempty,uuid"`
+	ReferrerEmployee   string
+}
+
+// calcPriceBreakdown returns the price breakdown and total price of a sale from basic cost and other charges
+func calcPriceBreakdown(salableArea, basicCost decimal.Decimal, otherCharges []optionalChargesDetails) (models.PriceBreakdownDetails, decimal.Decimal) {
+	var priceBreakdowns models.PriceBreakdownDetails
+	totalPrice := decimal.NewFromInt(0)
+
+	// basic cost
+	basicCostDetail := models.PriceBreakdownDetail{
+		Type:    "basic-cost",
+		Price:   basicCost,
+		Summary: "Basic flat cost",
+		// Total:     salableArea.Mul(basicCost),
+		Total:     basicCost,
+		SuperArea: salableArea,
+	}
+	totalPrice = totalPrice.Add(basicCostDetail.Total)
+	priceBreakdowns = append(priceBreakdowns, basicCostDetail)
+
+	// add other charges
+	for _, chargeDetails := range otherCharges {
+		priceDecimal := decimal.NewFromFloat(chargeDetails.TotalCost)
+		detail := models.PriceBreakdownDetail{
+			Type:      "other-charges",
+			Price:     priceDecimal,
+			Summary:   chargeDetails.Name,
+			SuperArea: salableArea,
+			Total:     priceDecimal,
+		}
+		totalPrice = totalPrice.Add(priceDecimal)
+		priceBreakdowns = append(priceBreakdowns, detail)
+	}
+	return priceBreakdowns, totalPrice
+}
+
+func (h *hCreateSale) getChannel() custom.SaleChannel {
+	if strings.TrimSpace(h.Channel) != "" {
+		return custom.SaleChannel(h.Channel)
+	}
+	if strings.TrimSpace(h.BrokerId) != "" {
+		return custom.CHANNEL_BROKER
+	}
+	return custom.CHANNEL_DIRECT
+}
+
+// validateChannel checks the party required by the sale channel belongs to the society
+func (h *hCreateSale) validateChannel(db *gorm.DB, orgId, society string) error {
+	channel := h.getChannel()
+	if !channel.IsValid() {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid sale channel.",
+		}
+	}
+
+	if channel != custom.CHANNEL_BROKER && strings.TrimSpace(h.BrokerId) != "" {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Broker can only be set for broker bookings.",
+		}
+	}
+
+	if channel != custom.CHANNEL_CUSTOMER_REFERRAL && strings.TrimSpace(h.ReferrerCustomerId) != "" {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Referrer customer can only be set for customer referral bookings.",
+		}
+	}
+
+	switch channel {
+	case custom.CHANNEL_BROKER:
+		if strings.TrimSpace(h.BrokerId) == "" {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Missing broker for broker booking.",
+			}
+		}
+		brokerSocietyInfoService := broker.CreateBrokerSocietyInfoService(db, uuid.MustParse(h.BrokerId))
+		return common.IsSameSociety(brokerSocietyInfoService, orgId, society)
+	case custom.CHANNEL_CUSTOMER_REFERRAL:
+		if strings.TrimSpace(h.ReferrerCustomerId) == "" {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Missing referrer customer for customer referral booking.",
+			}
+		}
+		customerSocietyInfo := CreateCustomerSocietyInfoService(db, uuid.MustParse(h.ReferrerCustomerId))
+		return common.IsSameSociety(customerSocietyInfo, orgId, society)
+	case custom.CHANNEL_EMPLOYEE_REFERRAL:
+		if strings.TrimSpace(h.ReferrerEmployee) == "" {
+			return &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Missing referrer employee for employee referral booking.",
+			}
+		}
+	}
+
+	return nil
 }
 
 func (h *hCreateSale) validate(db *gorm.DB, orgId, society, flatId, paymentId string) error {
@@ -75,8 +180,7 @@ func (h *hCreateSale) validate(db *gorm.DB, orgId, society, flatId, paymentId st
 		return err
 	}
 
-	brokerSocietyInfoService := broker.CreateBrokerSocietyInfoService(db, uuid.MustParse(h.BrokerId))
-	return common.IsSameSociety(brokerSocietyInfoService, orgId, society)
+	return h.validateChannel(db, orgId, society)
 }
 
 func (h *hCreateSale) execute(db *gorm.DB, orgId, society, flatId string) error {
@@ -144,23 +248,7 @@ func (h *hCreateSale) execute(db *gorm.DB, orgId, society, flatId string) error
 		//}
 
 		// price calculation
-		var priceBreakdowns []models.PriceBreakdownDetail
-		totalPrice := decimal.NewFromInt(0)
-
-		// basic cost
-		basicCostDetail := models.PriceBreakdownDetail{
-			Type:    "basic-cost",
-			Price:   basicCost,
-			Summary: "Basic flat cost",
-			// Total:     salableArea.Mul(basicCost),
-			Total:     basicCost,
-			SuperArea: salableArea,
-		}
-		totalPrice = totalPrice.Add(basicCostDetail.Total)
-		//log.Printf("total price: %v\n", totalPrice.String())
-		//log.Printf("basic cost detail: %v\n\n", basicCostDetail.Total.String())
-
-		priceBreakdowns = append(priceBreakdowns, basicCostDetail)
+		priceBreakdowns, totalPrice := calcPriceBreakdown(salableArea, basicCost, h.OtherCharges)
 
 		// Add location charges
 		//for _, charge := range locationCharges {
@@ -177,20 +265,6 @@ func (h *hCreateSale) execute(db *gorm.DB, orgId, society, flatId string) error
 		//	priceBreakdowns = append(priceBreakdowns, detail)
 		//}
 
-		// add other charges
-		for _, chargeDetails := range h.OtherCharges {
-			priceDecimal := decimal.NewFromFloat(chargeDetails.TotalCost)
-			detail := models.PriceBreakdownDetail{
-				Type:      "other-charges",
-				Price:     priceDecimal,
-				Summary:   chargeDetails.Name,
-				SuperArea: salableArea,
-				Total:     priceDecimal,
-			}
-			totalPrice = totalPrice.Add(priceDecimal)
-			priceBreakdowns = append(priceBreakdowns, detail)
-		}
-
 		// Helper to process other/optional charges
 		//processOtherCharges := func(charges []models.OtherCharge) {
 		//	for _, charge := range charges {
@@ -249,9 +323,19 @@ func (h *hCreateSale) execute(db *gorm.DB, orgId, society, flatId string) error
 			OrgId:              uuid.MustParse(orgId),
 			TotalPrice:         totalPrice,
 			PriceBreakdown:     priceBreakdowns,
-			BrokerId:           uuid.MustParse(h.BrokerId),
+			Channel:            h.getChannel(),
 			PaymentPlanRatioId: uuid.MustParse(h.PaymentId),
 		}
+		switch saleModel.Channel {
+		case custom.CHANNEL_BROKER:
+			brokerId := uuid.MustParse(h.BrokerId)
+			saleModel.BrokerId = &brokerId
+		case custom.CHANNEL_CUSTOMER_REFERRAL:
+			referrerId := uuid.MustParse(h.ReferrerCustomerId)
+			saleModel.ReferrerCustomerId = &referrerId
+		case custom.CHANNEL_EMPLOYEE_REFERRAL:
+			saleModel.ReferrerEmployee = strings.TrimSpace(h.ReferrerEmployee)
+		}
 		err = tx.Create(&saleModel).Error
 		if err != nil {
 			return err
@@ -284,7 +368,7 @@ func (h *hCreateSale) execute(db *gorm.DB, orgId, society, flatId string) error
 				}
 				customers = append(customers, customer)
 			}
-			return tx.Create(customers).Error
+			err = tx.Create(customers).Error
 		} else {
 			companyBuyer := models.CompanyCustomer{
 				SaleId:       saleModel.Id,
@@ -295,8 +379,21 @@ func (h *hCreateSale) execute(db *gorm.DB, orgId, society, flatId string) error
 				PanNumber:    h.CompanyBuyer.PanNumber,
 			}
 
-			return tx.Create(&companyBuyer).Error
+			err = tx.Create(&companyBuyer).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		err = notification.Enqueue(tx, orgId, notification.Event{
+			Kind:      custom.NOTIFY_BOOKING_WELCOME,
+			SaleId:    saleModel.Id,
+			Reference: saleModel.Id.String(),
+		})
+		if err != nil {
+			return err
 		}
+		return webhook.Publish(tx, orgId, society, custom.EVENT_SALE_CREATED, saleModel.Id.String(), saleModel)
 	})
 }
 
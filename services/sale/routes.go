@@ -1,6 +1,7 @@
 package sale
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,35 +13,24 @@ func (s *saleService) GetBasePath() string {
 func (s *saleService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
 
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Patch("/customer/{customerId}", s.updateSaleCustomerDetails)
-		router.Patch("/company-customer/{customerId}", s.updateSaleCompanyCustomerDetails)
-		router.Delete("/{saleId}", s.clearSaleRecord)
-	})
+		router.With(permission(custom.PERMISSION_SALE_UPDATE)).Patch("/customer/{customerId}", s.updateSaleCustomerDetails)
+		router.With(permission(custom.PERMISSION_SALE_UPDATE)).Patch("/company-customer/{customerId}", s.updateSaleCompanyCustomerDetails)
+		router.With(permission(custom.PERMISSION_SALE_DELETE)).Delete("/{saleId}", s.clearSaleRecord)
+		router.With(permission(custom.PERMISSION_SALE_PRICE_OVERRIDE)).Patch("/{saleId}/price", s.overrideSalePrice)
 
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
-
-		router.Post("/flat/{flat}", s.createSale)
+		router.With(permission(custom.PERMISSION_SALE_CREATE)).Post("/flat/{flat}", s.createSale)
 		//router.Post("/{saleId}/add-payment-installment/{paymentId}", s.addPaymentInstallmentForSale)
-		router.Get("/{saleId}/payment-breakdown", s.getSalePaymentBreakDown)
-		//router.Get("/report", s.getSocietySalesReport)
-		//router.Get("/tower/{towerId}/report", s.getTowerSalesReport)
-
-	})
-
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAndViewerAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
-
-		router.Get("/report", s.getSocietySalesReport)
-		router.Get("/tower/{towerId}/report", s.getTowerSalesReport)
+		router.With(permission(custom.PERMISSION_SALE_VIEW)).Get("/{saleId}/payment-breakdown", s.getSalePaymentBreakDown)
+		router.With(permission(custom.PERMISSION_SALE_ALLOT)).Post("/{saleId}/allotment", s.allotSale)
 
+		router.With(permission(custom.PERMISSION_REPORT_VIEW)).Get("/report", s.getSocietySalesReport)
+		router.With(permission(custom.PERMISSION_REPORT_VIEW)).Get("/tower/{towerId}/report", s.getTowerSalesReport)
+		router.With(permission(custom.PERMISSION_REPORT_VIEW)).Get("/{saleId}/allotment-letter", s.getAllotmentLetter)
 	})
 
 	return mux
@@ -1,7 +1,9 @@
 package sale
 
 import (
+	"circledigital.in/real-state-erp/utils/approval"
 	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
 	"gorm.io/gorm"
 )
 
@@ -26,6 +28,9 @@ type saleService struct {
 }
 
 func CreateSaleService(app common.IApp) common.IService {
+	approval.RegisterExecutor(custom.APPROVAL_SALE_DELETE, executeApprovedSaleDelete)
+	approval.RegisterExecutor(custom.APPROVAL_PRICE_OVERRIDE, executeApprovedPriceOverride)
+
 	return &saleService{
 		db: app.GetDBClient(),
 	}
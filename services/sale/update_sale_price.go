@@ -0,0 +1,125 @@
+package sale
+
+import (
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/approval"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// hOverrideSalePrice replaces the agreed price of a sale
+type hOverrideSalePrice struct {
+	BasicCost    float64                  `validate:"required"`
+	OtherCharges []optionalChargesDetails `validate:"omitempty,dive"`
+}
+
+func (h *hOverrideSalePrice) validate(db *gorm.DB, orgId, society, saleId string) error {
+	societyInfoService := CreateSaleSocietyInfoService(db, uuid.MustParse(saleId))
+	return common.IsSameSociety(societyInfoService, orgId, society)
+}
+
+// submit creates a pending approval request if price override requires approval
+// amount is the change in total price of the sale
+func (h *hOverrideSalePrice) submit(db *gorm.DB, orgId, society, saleId, requestedBy string) (*models.ApprovalRequest, error) {
+	err := h.validate(db, orgId, society, saleId)
+	if err != nil {
+		return nil, err
+	}
+
+	saleModel := models.Sale{}
+	err = db.Preload("Flat").First(&saleModel, "id = ?", saleId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	_, totalPrice := calcPriceBreakdown(saleModel.Flat.SaleableArea, decimal.NewFromFloat(h.BasicCost), h.OtherCharges)
+	change := totalPrice.Sub(saleModel.TotalPrice)
+
+	return approval.Submit(db, approval.Submission{
+		OrgId:       orgId,
+		Society:     society,
+		Action:      custom.APPROVAL_PRICE_OVERRIDE,
+		EntityId:    saleId,
+		Amount:      &change,
+		Payload:     h,
+		RequestedBy: requestedBy,
+	})
+}
+
+func (h *hOverrideSalePrice) execute(db *gorm.DB, orgId, society, saleId string) (*models.Sale, error) {
+	err := h.validate(db, orgId, society, saleId)
+	if err != nil {
+		return nil, err
+	}
+
+	saleModel := models.Sale{}
+	err = db.Preload("Flat").First(&saleModel, "id = ?", saleId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	priceBreakdowns, totalPrice := calcPriceBreakdown(saleModel.Flat.SaleableArea, decimal.NewFromFloat(h.BasicCost), h.OtherCharges)
+	err = db.Model(&saleModel).Updates(map[string]any{
+		"price_breakdown": priceBreakdowns,
+		"total_price":     totalPrice,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	saleModel.PriceBreakdown = priceBreakdowns
+	saleModel.TotalPrice = totalPrice
+	return &saleModel, nil
+}
+
+// executeApprovedPriceOverride applies the price of an approved request
+func executeApprovedPriceOverride(db *gorm.DB, request *models.ApprovalRequest) error {
+	price := hOverrideSalePrice{}
+	if err := approval.DecodePayload(request, &price); err != nil {
+		return err
+	}
+	_, err := price.execute(db, request.OrgId.String(), request.SocietyId, request.EntityId)
+	return err
+}
+
+func (s *saleService) overrideSalePrice(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	societyRera := chi.URLParam(r, "society")
+	saleId := chi.URLParam(r, "saleId")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+
+	reqBody := payload.ValidateAndDecodeRequest[hOverrideSalePrice](w, r)
+	if reqBody == nil {
+		return
+	}
+
+	pending, err := reqBody.submit(s.db, orgId, societyRera, saleId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	if pending != nil {
+		approval.RespondPending(w, pending)
+		return
+	}
+
+	sale, err := reqBody.execute(s.db, orgId, societyRera, saleId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated sale price."
+	response.Data = sale
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -4,42 +4,56 @@ import (
 	"circledigital.in/real-state-erp/models"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
-	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 	"net/http"
 )
 
 type hDeleteSociety struct{}
 
-func (h *hDeleteSociety) execute(db *gorm.DB, society, orgId string) error {
-	societyModel := models.Society{
-		ReraNumber: society,
-		OrgId:      uuid.MustParse(orgId),
+func (h *hDeleteSociety) validate(db *gorm.DB, society, orgId string) error {
+	var activeTowers int64
+	err := db.Model(&models.Tower{}).Where("org_id = ? and society_id = ?", orgId, society).Count(&activeTowers).Error
+	if err != nil {
+		return err
 	}
+	if activeTowers > 0 {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "You need to first delete society resources to delete society.",
+		}
+	}
+	return nil
+}
 
-	err := db.Delete(&societyModel).Error
+// execute soft deletes the society, it can be restored from recycle bin until purged
+func (h *hDeleteSociety) execute(db *gorm.DB, society, orgId, deletedBy string) error {
+	err := h.validate(db, society, orgId)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
-			return &custom.RequestError{
-				Status:  http.StatusBadRequest,
-				Message: "You need to first delete society resources to delete society.",
-			}
-		}
 		return err
 	}
-	return nil
+
+	societyModel := models.Society{
+		ReraNumber: society,
+		OrgId:      uuid.MustParse(orgId),
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&societyModel).Update("deleted_by", deletedBy).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&societyModel).Error
+	})
 }
 
 func (s *societyService) deleteSociety(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	societyRera := chi.URLParam(r, "society")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
 
 	society := hDeleteSociety{}
-	err := society.execute(s.db, societyRera, orgId)
+	err := society.execute(s.db, societyRera, orgId, userEmail)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
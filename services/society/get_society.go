@@ -14,9 +14,13 @@ import (
 
 type hGetAllSocieties struct{}
 
-func (h *hGetAllSocieties) execute(db *gorm.DB, orgId, cursor string) (*custom.PaginatedData, error) {
+// execute lists societies of the organization, users restricted to their societies only see those
+func (h *hGetAllSocieties) execute(db *gorm.DB, orgId, cursor string, access *custom.UserAccess) (*custom.PaginatedData, error) {
 	var societyData []models.Society
 	query := db.Where("org_id = ?", orgId).Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if access != nil && access.Societies != nil {
+		query = query.Where("rera_number IN ?", access.SocietyIds())
+	}
 	if strings.TrimSpace(cursor) != "" {
 		decodedCursor, err := common.DecodeCursor(cursor)
 		if err == nil {
@@ -46,7 +50,7 @@ func (h *hGetAllSocieties) execute(db *gorm.DB, orgId, cursor string) (*custom.P
 			SELECT t.society_id, COUNT(f.id) AS total_flats
 			FROM towers t
 			JOIN flats f ON f.tower_id = t.id
-			WHERE t.society_id IN ?
+			WHERE t.society_id IN ? AND t.deleted_at IS NULL AND f.deleted_at IS NULL
 			GROUP BY t.society_id
 		),
 		sold_flats_cte AS (
@@ -54,7 +58,7 @@ func (h *hGetAllSocieties) execute(db *gorm.DB, orgId, cursor string) (*custom.P
 			FROM sales s
 			JOIN flats f ON f.id = s.flat_id
 			JOIN towers t ON t.id = f.tower_id
-			WHERE t.society_id IN ?
+			WHERE t.society_id IN ? AND s.deleted_at IS NULL AND f.deleted_at IS NULL
 			GROUP BY t.society_id
 		)
 		SELECT
@@ -86,9 +90,10 @@ func (h *hGetAllSocieties) execute(db *gorm.DB, orgId, cursor string) (*custom.P
 func (s *societyService) getAllSocieties(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	cursor := r.URL.Query().Get("cursor")
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
 
 	society := hGetAllSocieties{}
-	res, err := society.execute(s.db, orgId, cursor)
+	res, err := society.execute(s.db, orgId, cursor, access)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
@@ -125,7 +130,7 @@ func (h *hGetSocietyById) execute(db *gorm.DB, orgId, society string) (*models.S
 			SELECT t.society_id, COUNT(f.id) AS total_flats
 			FROM towers t
 			JOIN flats f ON f.tower_id = t.id
-			WHERE t.society_id = ?
+			WHERE t.society_id = ? AND t.deleted_at IS NULL AND f.deleted_at IS NULL
 			GROUP BY t.society_id
 		),
 		sold_flats_cte AS (
@@ -133,7 +138,7 @@ func (h *hGetSocietyById) execute(db *gorm.DB, orgId, society string) (*models.S
 			FROM sales s
 			JOIN flats f ON f.id = s.flat_id
 			JOIN towers t ON t.id = f.tower_id
-			WHERE t.society_id = ?
+			WHERE t.society_id = ? AND s.deleted_at IS NULL AND f.deleted_at IS NULL
 			GROUP BY t.society_id
 		)
 		SELECT
@@ -1,6 +1,7 @@
 package society
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,24 +13,17 @@ func (s *societyService) GetBasePath() string {
 func (s *societyService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
 
-	// org admin authorization
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Post("/", s.createSociety)
-		router.Patch("/{society}", s.updateSocietyDetails)
-		router.Delete("/{society}", s.deleteSociety)
-	})
-
-	// org admin and user
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.With(permission(custom.PERMISSION_SOCIETY_MANAGE)).Post("/", s.createSociety)
+		router.With(permission(custom.PERMISSION_SOCIETY_MANAGE)).Patch("/{society}", s.updateSocietyDetails)
+		router.With(permission(custom.PERMISSION_SOCIETY_MANAGE)).Delete("/{society}", s.deleteSociety)
 
-		router.Get("/", s.getAllSocieties)
-		router.Get("/{society}", s.getSocietyById)
+		router.With(permission(custom.PERMISSION_SOCIETY_VIEW)).Get("/", s.getAllSocieties)
+		router.With(permission(custom.PERMISSION_SOCIETY_VIEW)).Get("/{society}", s.getSocietyById)
 	})
 
 	return mux
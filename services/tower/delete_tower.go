@@ -2,32 +2,89 @@ package tower
 
 import (
 	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"errors"
 	"net/http"
 )
 
+var towerNotFoundError = &custom.RequestError{
+	Status:  http.StatusNotFound,
+	Message: "Tower not found",
+}
+
 type hDeleteTower struct{}
 
-func (h *hDeleteTower) execute(db *gorm.DB, orgId, society, tower string) error {
-	return db.
-		Model(&models.Tower{
-			Id: uuid.MustParse(tower),
-		}).
-		Where("org_id = ? and society_id = ?", orgId, society).
-		Delete(&models.Tower{}).Error
+// validate checks the tower belongs to the society before looking at its flats
+func (h *hDeleteTower) validate(db *gorm.DB, orgId, society, tower string) error {
+	towerId, err := uuid.Parse(tower)
+	if err != nil {
+		return towerNotFoundError
+	}
+	towerSocietyInfoService := CreateTowerSocietyInfoService(db, towerId)
+	err = common.IsSameSociety(towerSocietyInfoService, orgId, society)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return towerNotFoundError
+	}
+	if err != nil {
+		return err
+	}
+
+	var activeFlats int64
+	err = db.Model(&models.Flat{}).Where("tower_id = ?", tower).Count(&activeFlats).Error
+	if err != nil {
+		return err
+	}
+	if activeFlats > 0 {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Tower has flats, delete the flats first.",
+		}
+	}
+	return nil
+}
+
+// execute soft deletes the tower, it can be restored from recycle bin until purged
+func (h *hDeleteTower) execute(db *gorm.DB, orgId, society, tower, deletedBy string) error {
+	err := h.validate(db, orgId, society, tower)
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		query := tx.
+			Model(&models.Tower{
+				Id: uuid.MustParse(tower),
+			}).
+			Where("org_id = ? and society_id = ?", orgId, society)
+
+		err := query.Session(&gorm.Session{}).Update("deleted_by", deletedBy).Error
+		if err != nil {
+			return err
+		}
+		result := query.Delete(&models.Tower{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return towerNotFoundError
+		}
+		return nil
+	})
 }
 
 func (s *towerService) deleteTower(w http.ResponseWriter, r *http.Request) {
 	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
 	towerId := chi.URLParam(r, "tower")
 	societyRera := chi.URLParam(r, "society")
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
 
 	tower := hDeleteTower{}
-	err := tower.execute(s.db, orgId, societyRera, towerId)
+	err := tower.execute(s.db, orgId, societyRera, towerId, userEmail)
 	if err != nil {
 		payload.HandleError(w, err)
 		return
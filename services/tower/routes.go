@@ -1,6 +1,7 @@
 package tower
 
 import (
+	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/middleware"
 	"github.com/go-chi/chi/v5"
 )
@@ -12,25 +13,18 @@ func (s *towerService) GetBasePath() string {
 func (s *towerService) GetRoutes() *chi.Mux {
 	mux := chi.NewMux()
 	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+	permission := authorizationMiddleware.Permission
 
-	// org admin authorization
 	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAuthorization)
 		router.Use(authorizationMiddleware.OrganizationAuthorization)
 
-		router.Post("/", s.createTower)
-		router.Post("/bulk", s.bulkCreateTower)
-		router.Patch("/{tower}", s.updateTower)
-		router.Delete("/{tower}", s.deleteTower)
-	})
-
-	// org admin and user
-	mux.Group(func(router chi.Router) {
-		router.Use(authorizationMiddleware.OrganizationAdminAndUserAuthorization)
-		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.With(permission(custom.PERMISSION_TOWER_MANAGE)).Post("/", s.createTower)
+		router.With(permission(custom.PERMISSION_TOWER_MANAGE)).Post("/bulk", s.bulkCreateTower)
+		router.With(permission(custom.PERMISSION_TOWER_MANAGE)).Patch("/{tower}", s.updateTower)
+		router.With(permission(custom.PERMISSION_TOWER_MANAGE)).Delete("/{tower}", s.deleteTower)
 
-		router.Get("/", s.getAllTowers)
-		router.Get("/{towerId}", s.getTowerById)
+		router.With(permission(custom.PERMISSION_SOCIETY_VIEW)).Get("/", s.getAllTowers)
+		router.With(permission(custom.PERMISSION_SOCIETY_VIEW)).Get("/{towerId}", s.getTowerById)
 	})
 
 	return mux
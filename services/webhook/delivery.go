@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// hGetWebhookDeliveries lists the delivery log of an endpoint, filtered by status
+type hGetWebhookDeliveries struct {
+	Status string
+	access *custom.UserAccess
+}
+
+func (h *hGetWebhookDeliveries) execute(db *gorm.DB, orgId, webhookId, cursor string) (*custom.PaginatedData, error) {
+	endpoint, err := findWebhookEndpoint(db, orgId, webhookId, h.access)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Preload("OutboxEvent").Where("endpoint_id = ?", endpoint.Id)
+	if h.Status != "" {
+		if !custom.WebhookDeliveryStatus(h.Status).IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid delivery status.",
+			}
+		}
+		query = query.Where("status = ?", h.Status)
+	}
+
+	query = query.Order("created_at DESC").Limit(custom.LIMIT + 1)
+	if strings.TrimSpace(cursor) != "" {
+		decodedCursor, err := common.DecodeCursor(cursor)
+		if err == nil {
+			query = query.Where("created_at < ?", decodedCursor)
+		}
+	}
+
+	var deliveries []models.WebhookDelivery
+	err = query.Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return common.CreatePaginatedResponse(&deliveries), nil
+}
+
+func (s *webhookService) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	webhookId := chi.URLParam(r, "webhookId")
+	cursor := r.URL.Query().Get("cursor")
+
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	deliveries := hGetWebhookDeliveries{
+		Status: strings.TrimSpace(r.URL.Query().Get("status")),
+		access: access,
+	}
+	res, err := deliveries.execute(s.db, orgId, webhookId, cursor)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = res
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// replayDelivery queues a new delivery of the same event to the same endpoint, the original is kept in the log
+func (s *webhookService) replayDelivery(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	deliveryId := chi.URLParam(r, "deliveryId")
+
+	var original models.WebhookDelivery
+	err := s.db.Where("id = ? and org_id = ?", deliveryId, orgId).First(&original).Error
+	if _, parseErr := uuid.Parse(deliveryId); parseErr != nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Webhook delivery not found.",
+		})
+		return
+	}
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	// deliveries of endpoints the user can't access are not found
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+	_, err = findWebhookEndpoint(s.db, orgId, original.EndpointId.String(), access)
+	if errors.Is(err, webhookNotFoundError) {
+		payload.HandleError(w, &custom.RequestError{
+			Status:  http.StatusNotFound,
+			Message: "Webhook delivery not found.",
+		})
+		return
+	}
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	now := time.Now()
+	replay := models.WebhookDelivery{
+		OrgId:         original.OrgId,
+		EndpointId:    original.EndpointId,
+		EventId:       original.EventId,
+		Status:        custom.DELIVERY_PENDING,
+		NextAttemptAt: &now,
+		ReplayOf:      &original.Id,
+	}
+	err = s.db.Create(&replay).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully queued webhook delivery."
+	response.Data = replay
+
+	payload.EncodeJSON(w, http.StatusAccepted, response)
+}
@@ -0,0 +1,372 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	appWebhook "circledigital.in/real-state-erp/utils/webhook"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var webhookNotFoundError = &custom.RequestError{
+	Status:  http.StatusNotFound,
+	Message: "Webhook not found.",
+}
+
+// validateUrl checks the endpoint is an absolute http or https url
+func validateUrl(endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Webhook url must be an http or https url.",
+		}
+	}
+	return nil
+}
+
+// validateEvents returns the events as a list, duplicates are removed
+func validateEvents(events []string) (models.StringList, error) {
+	list := make(models.StringList, 0, len(events))
+	seen := make(map[custom.WebhookEvent]bool)
+	for _, e := range events {
+		event := custom.WebhookEvent(strings.TrimSpace(e))
+		if !event.IsValid() {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid webhook event: " + e,
+			}
+		}
+		if !seen[event] {
+			seen[event] = true
+			list = append(list, string(event))
+		}
+	}
+	return list, nil
+}
+
+// validateSocieties returns the societies as a list, societies are limited to the access of the user
+// and empty societies are allowed only to users who can access every society
+func validateSocieties(db *gorm.DB, orgId string, access *custom.UserAccess, societies []string) (models.StringList, error) {
+	if len(societies) == 0 {
+		if access != nil && access.Societies != nil {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Societies are required for webhook created by user restricted to societies.",
+			}
+		}
+		return models.StringList{}, nil
+	}
+
+	for _, society := range societies {
+		if !access.CanAccessSociety(society) {
+			return nil, &custom.RequestError{
+				Status:  http.StatusForbidden,
+				Message: "Webhook can not receive events of society the creator is not member of: " + society,
+			}
+		}
+	}
+	var count int64
+	err := db.Model(&models.Society{}).Where("org_id = ? and rera_number IN ?", orgId, societies).Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+	if int(count) != len(societies) {
+		return nil, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid society in webhook.",
+		}
+	}
+	return models.StringList(societies), nil
+}
+
+// findWebhookEndpoint returns the endpoint of the organization, endpoints the user can't access are not found
+func findWebhookEndpoint(db *gorm.DB, orgId, webhookId string, access *custom.UserAccess) (*models.WebhookEndpoint, error) {
+	if _, err := uuid.Parse(webhookId); err != nil {
+		return nil, webhookNotFoundError
+	}
+
+	var endpoint models.WebhookEndpoint
+	err := db.Where("id = ? and org_id = ?", webhookId, orgId).First(&endpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, webhookNotFoundError
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.AccessibleBy(access) {
+		return nil, webhookNotFoundError
+	}
+	return &endpoint, nil
+}
+
+// endpointWithSecret is the endpoint with its signing secret, returned only on creation and rotation
+type endpointWithSecret struct {
+	*models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// hCreateWebhookEndpoint creates endpoint receiving the events of the societies, every society when empty
+// societies are limited to the access of the creator like the societies of an api key
+type hCreateWebhookEndpoint struct {
+	Url         string `validate:"required"`
+	Description string
+	Events      []string `validate:"required,min=1"`
+	Societies   []string
+	access      *custom.UserAccess
+}
+
+func (h *hCreateWebhookEndpoint) execute(db *gorm.DB, orgId, createdBy string) (*endpointWithSecret, error) {
+	err := validateUrl(h.Url)
+	if err != nil {
+		return nil, err
+	}
+	events, err := validateEvents(h.Events)
+	if err != nil {
+		return nil, err
+	}
+	societies, err := validateSocieties(db, orgId, h.access, h.Societies)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := appWebhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := models.WebhookEndpoint{
+		OrgId:       uuid.MustParse(orgId),
+		Url:         strings.TrimSpace(h.Url),
+		Description: h.Description,
+		Events:      events,
+		Societies:   societies,
+		Secret:      secret,
+		Enabled:     true,
+		CreatedBy:   createdBy,
+	}
+	err = db.Create(&endpoint).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &endpointWithSecret{
+		WebhookEndpoint: &endpoint,
+		Secret:          secret,
+	}, nil
+}
+
+func (s *webhookService) createWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	userEmail, _ := r.Context().Value(custom.UserEmailKey).(string)
+	reqBody := payload.ValidateAndDecodeRequest[hCreateWebhookEndpoint](w, r)
+	if reqBody == nil {
+		return
+	}
+	reqBody.access, _ = r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	endpoint, err := reqBody.execute(s.db, orgId, userEmail)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully created webhook. Store the secret, it is not shown again."
+	response.Data = endpoint
+
+	payload.EncodeJSON(w, http.StatusCreated, response)
+}
+
+// hUpdateWebhookEndpoint updates the provided fields of an endpoint, events and societies replace the existing ones
+type hUpdateWebhookEndpoint struct {
+	Url         string
+	Description string
+	Events      []string
+	Societies   []string
+	Enabled     *bool
+	access      *custom.UserAccess
+}
+
+func (h *hUpdateWebhookEndpoint) execute(db *gorm.DB, orgId, webhookId string) (*models.WebhookEndpoint, error) {
+	endpoint, err := findWebhookEndpoint(db, orgId, webhookId, h.access)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(h.Url) != "" {
+		if err := validateUrl(h.Url); err != nil {
+			return nil, err
+		}
+		endpoint.Url = strings.TrimSpace(h.Url)
+	}
+	if strings.TrimSpace(h.Description) != "" {
+		endpoint.Description = h.Description
+	}
+	if h.Events != nil {
+		endpoint.Events, err = validateEvents(h.Events)
+		if err != nil {
+			return nil, err
+		}
+		if len(endpoint.Events) == 0 {
+			return nil, &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Webhook must subscribe to at least one event.",
+			}
+		}
+	}
+	if h.Societies != nil {
+		endpoint.Societies, err = validateSocieties(db, orgId, h.access, h.Societies)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if h.Enabled != nil {
+		endpoint.Enabled = *h.Enabled
+	}
+	return endpoint, db.Save(endpoint).Error
+}
+
+func (s *webhookService) updateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	webhookId := chi.URLParam(r, "webhookId")
+	reqBody := payload.ValidateAndDecodeRequest[hUpdateWebhookEndpoint](w, r)
+	if reqBody == nil {
+		return
+	}
+	reqBody.access, _ = r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	endpoint, err := reqBody.execute(s.db, orgId, webhookId)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully updated webhook."
+	response.Data = endpoint
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// rotateWebhookSecret replaces the signing secret, deliveries are signed with the new secret right away
+func (s *webhookService) rotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	webhookId := chi.URLParam(r, "webhookId")
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	endpoint, err := findWebhookEndpoint(s.db, orgId, webhookId, access)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	secret, err := appWebhook.NewSecret()
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	err = s.db.Model(endpoint).Update("secret", secret).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully rotated webhook secret. Store the secret, it is not shown again."
+	response.Data = endpointWithSecret{
+		WebhookEndpoint: endpoint,
+		Secret:          secret,
+	}
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+// deleteWebhookEndpoint deletes the endpoint with its delivery log
+func (s *webhookService) deleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	webhookId := chi.URLParam(r, "webhookId")
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	endpoint, err := findWebhookEndpoint(s.db, orgId, webhookId, access)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	err = s.db.Delete(endpoint).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Successfully deleted webhook."
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *webhookService) getWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	// users restricted to societies list the endpoints allowed only on their societies
+	query := s.db.Where("org_id = ?", orgId)
+	if access != nil && access.Societies != nil {
+		societies, err := json.Marshal(access.SocietyIds())
+		if err != nil {
+			payload.HandleError(w, err)
+			return
+		}
+		query = query.Where("jsonb_array_length(societies) > 0 and societies <@ ?::jsonb", string(societies))
+	}
+
+	var endpoints []models.WebhookEndpoint
+	err := query.Order("created_at DESC").Find(&endpoints).Error
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = endpoints
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *webhookService) getWebhookEndpointById(w http.ResponseWriter, r *http.Request) {
+	orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+	webhookId := chi.URLParam(r, "webhookId")
+	access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+
+	endpoint, err := findWebhookEndpoint(s.db, orgId, webhookId, access)
+	if err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = endpoint
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
+
+func (s *webhookService) getWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	var response custom.JSONResponse
+	response.Error = false
+	response.Data = custom.AllWebhookEvents
+
+	payload.EncodeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+func (s *webhookService) GetBasePath() string {
+	return "/webhook"
+}
+
+func (s *webhookService) GetRoutes() *chi.Mux {
+	mux := chi.NewMux()
+	authorizationMiddleware := &middleware.AuthorizationMiddleware{}
+
+	mux.Group(func(router chi.Router) {
+		router.Use(authorizationMiddleware.OrganizationAuthorization)
+		router.Use(authorizationMiddleware.Permission(custom.PERMISSION_WEBHOOK_MANAGE))
+
+		router.Get("/event", s.getWebhookEvents)
+		router.Post("/delivery/{deliveryId}/replay", s.replayDelivery)
+
+		router.Get("/", s.getWebhookEndpoints)
+		router.Post("/", s.createWebhookEndpoint)
+		router.Get("/{webhookId}", s.getWebhookEndpointById)
+		router.Patch("/{webhookId}", s.updateWebhookEndpoint)
+		router.Delete("/{webhookId}", s.deleteWebhookEndpoint)
+		router.Post("/{webhookId}/secret", s.rotateWebhookSecret)
+		router.Get("/{webhookId}/delivery", s.getWebhookDeliveries)
+	})
+
+	return mux
+}
@@ -0,0 +1,18 @@
+package webhook
+
+import (
+	"circledigital.in/real-state-erp/utils/common"
+	"gorm.io/gorm"
+)
+
+type webhookService struct {
+	db *gorm.DB
+}
+
+// CreateWebhookService creates service for webhook endpoints and their deliveries
+// events are written by other services with utils/webhook and delivered by its dispatcher
+func CreateWebhookService(app common.IApp) common.IService {
+	return &webhookService{
+		db: app.GetDBClient(),
+	}
+}
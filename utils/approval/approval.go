@@ -0,0 +1,123 @@
+package approval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Executor runs the operation of an approved request, db is the transaction recording the approval
+type Executor func(db *gorm.DB, request *models.ApprovalRequest) error
+
+var executors = make(map[custom.ApprovalAction]Executor)
+
+// RegisterExecutor registers the executor of action, services register their executors when created
+func RegisterExecutor(action custom.ApprovalAction, executor Executor) {
+	executors[action] = executor
+}
+
+// Execute runs the executor registered for the action of request
+func Execute(db *gorm.DB, request *models.ApprovalRequest) error {
+	executor, ok := executors[request.Action]
+	if !ok {
+		return fmt.Errorf("no executor registered for approval action %s", request.Action)
+	}
+	return executor(db, request)
+}
+
+// Submission is a sensitive operation checked against the approval policy of the organization
+// amount is compared with the policy threshold, nil amount always requires approval when the policy is enabled
+type Submission struct {
+	OrgId       string
+	Society     string
+	Action      custom.ApprovalAction
+	EntityId    string
+	Amount      *decimal.Decimal
+	Payload     any
+	RequestedBy string
+}
+
+// Submit creates a pending approval request if the policy of the organization requires approval
+// nil request means the operation is not gated and must be executed by the caller
+func Submit(db *gorm.DB, submission Submission) (*models.ApprovalRequest, error) {
+	var policy models.ApprovalPolicy
+	result := db.
+		Where("org_id = ? and action = ?", submission.OrgId, submission.Action).
+		Limit(1).
+		Find(&policy)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || !policy.Requires(submission.Amount) {
+		return nil, nil
+	}
+
+	var requestPayload models.RawJSON
+	if submission.Payload != nil {
+		data, err := json.Marshal(submission.Payload)
+		if err != nil {
+			return nil, err
+		}
+		requestPayload = data
+	}
+
+	// same operation submitted again while pending is rejected instead of queued twice
+	var pending int64
+	query := db.Model(&models.ApprovalRequest{}).
+		Where("org_id = ? and action = ? and entity_id = ? and status = ?", submission.OrgId, submission.Action, submission.EntityId, custom.APPROVAL_PENDING)
+	if requestPayload == nil {
+		query = query.Where("payload IS NULL")
+	} else {
+		query = query.Where("payload = ?::jsonb", string(requestPayload))
+	}
+	if err := query.Count(&pending).Error; err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, &custom.RequestError{
+			Status:  http.StatusConflict,
+			Message: "Same request is already pending approval.",
+		}
+	}
+
+	request := models.ApprovalRequest{
+		OrgId:       uuid.MustParse(submission.OrgId),
+		SocietyId:   submission.Society,
+		Action:      submission.Action,
+		EntityId:    submission.EntityId,
+		Amount:      submission.Amount,
+		Payload:     requestPayload,
+		Status:      custom.APPROVAL_PENDING,
+		RequestedBy: submission.RequestedBy,
+	}
+	if err := db.Create(&request).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// DecodePayload decodes the payload of request into dest
+func DecodePayload(request *models.ApprovalRequest, dest any) error {
+	if len(request.Payload) == 0 {
+		return errors.New("approval request has no payload")
+	}
+	return json.Unmarshal(request.Payload, dest)
+}
+
+// RespondPending writes the response of an operation submitted for approval
+func RespondPending(w http.ResponseWriter, request *models.ApprovalRequest) {
+	var response custom.JSONResponse
+	response.Error = false
+	response.Message = "Request submitted for approval."
+	response.Data = request
+
+	payload.EncodeJSON(w, http.StatusAccepted, response)
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksProvider validates tokens signed with the keys published at a JWKS url
+type jwksProvider struct {
+	keys    keyfunc.Keyfunc
+	claims  ClaimMapping
+	options []jwt.ParserOption
+}
+
+func (p *jwksProvider) Authenticate(token string) (*Identity, error) {
+	parsed, err := jwt.Parse(token, p.keys.Keyfunc, p.options...)
+	if err != nil || !parsed.Valid {
+		return nil, invalidTokenError
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, invalidTokenError
+	}
+	return p.claims.identity(claims)
+}
+
+// NewCognitoProvider creates provider for tokens of the cognito user pool
+// role is the first group of the user, id tokens carry email and access tokens only the username which is the email
+func NewCognitoProvider(region, userPoolId string) (Provider, error) {
+	issuer := fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", region, userPoolId)
+	keys, err := keyfunc.NewDefault([]string{issuer + "/.well-known/jwks.json"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &jwksProvider{
+		keys: keys,
+		claims: ClaimMapping{
+			Role:          "cognito:groups",
+			OrgId:         custom.OrgIdCustomAttribute,
+			Email:         "email",
+			EmailFallback: "cognito:username",
+		},
+		options: []jwt.ParserOption{jwt.WithIssuer(issuer)},
+	}, nil
+}
+
+// OIDCConfig configures a generic OpenID Connect issuer
+// jwks url is discovered from the issuer when not set, audience is checked only when set
+type OIDCConfig struct {
+	Issuer   string
+	JWKSURL  string
+	Audience string
+	Claims   ClaimMapping
+}
+
+// NewOIDCProvider creates provider for tokens of an OpenID Connect issuer
+func NewOIDCProvider(config OIDCConfig) (Provider, error) {
+	if strings.TrimSpace(config.Issuer) == "" {
+		return nil, fmt.Errorf("oidc issuer is required")
+	}
+
+	jwksURL := config.JWKSURL
+	if jwksURL == "" {
+		var err error
+		jwksURL, err = discoverJWKSURL(config.Issuer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys, err := keyfunc.NewDefault([]string{jwksURL})
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{jwt.WithIssuer(config.Issuer)}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+	return &jwksProvider{
+		keys:    keys,
+		claims:  config.Claims,
+		options: options,
+	}, nil
+}
+
+// discoverJWKSURL reads jwks url from the openid configuration of the issuer
+func discoverJWKSURL(issuer string) (string, error) {
+	client := http.Client{Timeout: 10 * time.Second}
+	res, err := client.Get(strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("openid configuration of %s returned status %d", issuer, res.StatusCode)
+	}
+
+	var configuration struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&configuration); err != nil {
+		return "", err
+	}
+	if configuration.JWKSURI == "" {
+		return "", fmt.Errorf("openid configuration of %s has no jwks_uri", issuer)
+	}
+	return configuration.JWKSURI, nil
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSigningKey = "0123456789abcdef0123456789abcdef"
+
+func TestLocalProviderRoundTrip(t *testing.T) {
+	provider, err := NewLocalProvider(testSigningKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := provider.Mint(Identity{Role: custom.ORGADMIN, OrgId: "org-1", Email: "a@b.com"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identity, err := provider.Authenticate(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Role != custom.ORGADMIN || identity.OrgId != "org-1" || identity.Email != "a@b.com" || identity.Sub != "a@b.com" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+}
+
+func TestLocalProviderRejects(t *testing.T) {
+	provider, _ := NewLocalProvider(testSigningKey, "")
+	other, _ := NewLocalProvider("fedcba9876543210fedcba9876543210", "")
+	otherIssuer, _ := NewLocalProvider(testSigningKey, "other")
+
+	identity := Identity{Role: custom.ORGUSER, Email: "a@b.com"}
+	wrongKey, _ := other.Mint(identity, time.Hour)
+	wrongIssuer, _ := otherIssuer.Mint(identity, time.Hour)
+	expired, _ := provider.Mint(identity, -time.Minute)
+	noRole, _ := provider.Mint(Identity{Email: "a@b.com"}, time.Hour)
+
+	for name, token := range map[string]string{
+		"wrong key":    wrongKey,
+		"wrong issuer": wrongIssuer,
+		"expired":      expired,
+		"missing role": noRole,
+		"malformed":    "not-a-token",
+	} {
+		if _, err := provider.Authenticate(token); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := NewLocalProvider("short", ""); err == nil {
+		t.Error("expected error for short signing key")
+	}
+}
+
+func TestClaimString(t *testing.T) {
+	claims := jwt.MapClaims{
+		"groups": []any{"org-admin", "org-user"},
+		"app":    map[string]any{"org": "org-1"},
+		"email":  "a@b.com",
+	}
+
+	tests := map[string]string{
+		"groups":    "org-admin",
+		"app.org":   "org-1",
+		"email":     "a@b.com",
+		"missing":   "",
+		"email.sub": "",
+		"":          "",
+	}
+	for path, want := range tests {
+		if got := claimString(claims, path); got != want {
+			t.Errorf("claimString(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
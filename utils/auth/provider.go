@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// package auth handles validation of bearer tokens issued by the configured identity provider
+
+// Identity is the user of an authenticated request
+type Identity struct {
+	Role  custom.UserRole
+	OrgId string
+	Email string
+	Sub   string
+}
+
+// Provider validates a bearer token and returns the identity it carries
+type Provider interface {
+	Authenticate(token string) (*Identity, error)
+}
+
+var invalidTokenError = &custom.RequestError{
+	Status:  http.StatusUnauthorized,
+	Message: "Invalid token.",
+}
+
+// ClaimMapping names the token claims carrying role, organization and email
+// nested claims are separated by dot, first value is used for array claims
+type ClaimMapping struct {
+	Role          string
+	OrgId         string
+	Email         string
+	EmailFallback string
+}
+
+// identity maps the token claims to identity, role claim is required
+func (m ClaimMapping) identity(claims jwt.MapClaims) (*Identity, error) {
+	role := claimString(claims, m.Role)
+	if role == "" {
+		return nil, invalidTokenError
+	}
+
+	email := claimString(claims, m.Email)
+	if email == "" && m.EmailFallback != "" {
+		email = claimString(claims, m.EmailFallback)
+	}
+
+	sub, _ := claims["sub"].(string)
+	return &Identity{
+		Role:  custom.UserRole(role),
+		OrgId: claimString(claims, m.OrgId),
+		Email: email,
+		Sub:   sub,
+	}, nil
+}
+
+// claimString returns the string value of the claim at path, first string for array claims
+func claimString(claims jwt.MapClaims, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	var value any = map[string]any(claims)
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = object[key]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			str, _ := v[0].(string)
+			return str
+		}
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"", "/abs", "a/../b", "a//b", "a\\b", "..", "a/."} {
+		if validateKey(key) == nil {
+			t.Errorf("want error for key %q", key)
+		}
+	}
+	if err := validateKey("jobs/org/report.xlsx"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put(ctx, "jobs/1/report.xlsx", strings.NewReader("data"), 4, ""); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := store.Get(ctx, "jobs/1/report.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "data" {
+		t.Errorf("got %q", data)
+	}
+
+	if err := store.Delete(ctx, "jobs/1/report.xlsx"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "jobs/1/report.xlsx"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want ErrNotFound, got %v", err)
+	}
+	if err := store.Delete(ctx, "jobs/1/report.xlsx"); err != nil {
+		t.Errorf("want deleting missing object to succeed, got %v", err)
+	}
+}
+
+func TestS3StorePathStyle(t *testing.T) {
+	objects := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = string(body)
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			io.WriteString(w, body)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	credentials := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "key", SecretAccessKey: "secret"}, nil
+	})
+	store, err := NewS3Store(S3Config{Bucket: "exports", Region: "ap-south-1", Endpoint: server.URL, PathStyle: true}, credentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if err := store.Put(ctx, "jobs/1/master report.xlsx", strings.NewReader("data"), 4, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objects["/exports/jobs/1/master report.xlsx"]; !ok {
+		t.Fatalf("object not stored at bucket path: %v", objects)
+	}
+
+	reader, err := store.Get(ctx, "jobs/1/master report.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "data" {
+		t.Errorf("got %q", data)
+	}
+
+	if err := store.Delete(ctx, "jobs/1/master report.xlsx"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "jobs/1/master report.xlsx"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("want ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// S3Config is the bucket objects are kept in
+// Endpoint is set for s3 compatible services, aws is used when blank
+type S3Config struct {
+	Bucket    string
+	Region    string
+	Endpoint  string
+	PathStyle bool
+}
+
+type s3Store struct {
+	config      S3Config
+	base        *url.URL
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	client      *http.Client
+}
+
+// NewS3Store keeps objects in an s3 or s3 compatible bucket, requests are signed with the credentials
+func NewS3Store(config S3Config, credentials aws.CredentialsProvider) (Store, error) {
+	if config.Bucket == "" || config.Region == "" {
+		return nil, fmt.Errorf("s3 bucket and region are required")
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+	base, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", endpoint)
+	}
+	if !config.PathStyle {
+		base.Host = config.Bucket + "." + base.Host
+	}
+
+	return &s3Store{
+		config:      config,
+		base:        base,
+		credentials: credentials,
+		signer: v4.NewSigner(func(o *v4.SignerOptions) {
+			o.DisableURIPathEscaping = true
+		}),
+		client: &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+func (s *s3Store) objectUrl(key string) (*url.URL, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(key, "/")
+	if s.config.PathStyle {
+		segments = append([]string{s.config.Bucket}, segments...)
+	}
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+
+	objectUrl := *s.base
+	objectUrl.Path = s.base.Path + "/" + strings.Join(segments, "/")
+	objectUrl.RawPath = s.base.Path + "/" + strings.Join(escaped, "/")
+	return &objectUrl, nil
+}
+
+// do signs and sends the request, body is buffered to compute the payload hash
+func (s *s3Store) do(ctx context.Context, method, key string, body []byte, contentType string) (*http.Response, error) {
+	objectUrl, err := s.objectUrl(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, objectUrl.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	hash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	credentials, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = s.signer.SignHTTP(ctx, credentials, req, payloadHash, "s3", s.config.Region, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(req)
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, body io.Reader, _ int64, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.do(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return checkS3Response(res)
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, ErrNotFound
+	}
+	if err := checkS3Response(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	res, err := s.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkS3Response(res)
+}
+
+func checkS3Response(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("s3 responded with status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+}
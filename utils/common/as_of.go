@@ -0,0 +1,27 @@
+package common
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+// ParseAsOfDate parses the asOf query value (YYYY-MM-DD) used to reproduce a position on a past date
+// the returned time is the end of the given day, empty value returns current time
+func ParseAsOfDate(value string) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Now(), nil
+	}
+
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid asOf date (expected YYYY-MM-DD)",
+		}
+	}
+
+	return date.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
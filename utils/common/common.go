@@ -1,6 +1,8 @@
 package common
 
 import (
+	"circledigital.in/real-state-erp/utils/blob"
+	"circledigital.in/real-state-erp/utils/custom"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
 	"github.com/go-chi/chi/v5"
 	"gorm.io/gorm"
@@ -18,10 +20,23 @@ type IAWSConfig interface {
 	GetCognitoClient() *cognitoidentityprovider.Client
 }
 
+// IdentityProvider manages the login of users, provider delivering invites itself returns empty tokens
+type IdentityProvider interface {
+	UserExists(email string) (bool, error)
+	// CreateUser creates login for the user and returns the invite token
+	CreateUser(email, orgId string, role custom.UserRole) (string, error)
+	UpdateRole(email string, role custom.UserRole) error
+	DeleteUser(email string) error
+	// ResetPassword starts password reset of the user and delivers the reset token to the user
+	ResetPassword(email string) error
+}
+
 // IApp is an application interface with all the configurations
 type IApp interface {
 	GetRouter() *chi.Mux
 
 	GetDBClient() *gorm.DB
 	GetAWSConfig() IAWSConfig
+	GetIdentityProvider() IdentityProvider
+	GetBlobStore() blob.Store
 }
\ No newline at end of file
@@ -66,6 +66,9 @@ const (
 	ORGADMIN    UserRole = "org-admin"
 	ORGUSER     UserRole = "org-user"
 	ORGVIEWER   UserRole = "org-viewer"
+
+	// SERVICEACCOUNT is the role of requests authenticated with an api key, never assigned to users
+	SERVICEACCOUNT UserRole = "service-account"
 )
 
 func (r UserRole) IsValid() bool {
@@ -238,3 +241,295 @@ var ValidPaymentPlanScopeCondtion = map[PaymentPlanItemScope][]PaymentPlanCondit
 		ONFlatSTAGE,
 	},
 }
+
+type ConstructionStage string
+
+const (
+	STAGE_EXCAVATION ConstructionStage = "excavation"
+	STAGE_FOUNDATION ConstructionStage = "foundation"
+	STAGE_SLAB       ConstructionStage = "slab"
+	STAGE_BRICKWORK  ConstructionStage = "brickwork"
+	STAGE_PLASTERING ConstructionStage = "plastering"
+	STAGE_FLOORING   ConstructionStage = "flooring"
+	STAGE_FINISHING  ConstructionStage = "finishing"
+	STAGE_POSSESSION ConstructionStage = "possession"
+	STAGE_OTHER      ConstructionStage = "other"
+)
+
+func (s ConstructionStage) IsValid() bool {
+	switch s {
+	case STAGE_EXCAVATION, STAGE_FOUNDATION, STAGE_SLAB, STAGE_BRICKWORK, STAGE_PLASTERING,
+		STAGE_FLOORING, STAGE_FINISHING, STAGE_POSSESSION, STAGE_OTHER:
+		return true
+	default:
+		return false
+	}
+}
+
+// RequiresFloor reports whether milestones of the stage are recorded per floor
+func (s ConstructionStage) RequiresFloor() bool {
+	return s == STAGE_SLAB
+}
+
+type CommissionType string
+
+const (
+	COMMISSION_PERCENT CommissionType = "percent"
+	COMMISSION_FLAT    CommissionType = "flat"
+	COMMISSION_SLAB    CommissionType = "slab"
+)
+
+func (c CommissionType) IsValid() bool {
+	switch c {
+	case COMMISSION_PERCENT, COMMISSION_FLAT, COMMISSION_SLAB:
+		return true
+	default:
+		return false
+	}
+}
+
+type CommissionStatus string
+
+const (
+	COMMISSION_ACCRUED  CommissionStatus = "accrued"
+	COMMISSION_ELIGIBLE CommissionStatus = "eligible"
+	COMMISSION_PAID     CommissionStatus = "paid"
+	// COMMISSION_CANCELLED is the unpaid commission of a deleted sale, accrued again if the sale is restored
+	COMMISSION_CANCELLED CommissionStatus = "cancelled"
+)
+
+func (c CommissionStatus) IsValid() bool {
+	switch c {
+	case COMMISSION_ACCRUED, COMMISSION_ELIGIBLE, COMMISSION_PAID, COMMISSION_CANCELLED:
+		return true
+	default:
+		return false
+	}
+}
+
+type SaleChannel string
+
+const (
+	CHANNEL_DIRECT            SaleChannel = "direct"
+	CHANNEL_BROKER            SaleChannel = "broker"
+	CHANNEL_EMPLOYEE_REFERRAL SaleChannel = "employee-referral"
+	CHANNEL_CUSTOMER_REFERRAL SaleChannel = "customer-referral"
+)
+
+func (c SaleChannel) IsValid() bool {
+	switch c {
+	case CHANNEL_DIRECT, CHANNEL_BROKER, CHANNEL_EMPLOYEE_REFERRAL, CHANNEL_CUSTOMER_REFERRAL:
+		return true
+	default:
+		return false
+	}
+}
+
+type ApprovalAction string
+
+const (
+	APPROVAL_SALE_DELETE        ApprovalAction = "sale-delete"
+	APPROVAL_RECEIPT_CLEAR      ApprovalAction = "receipt-clear"
+	APPROVAL_RECEIPT_ADJUSTMENT ApprovalAction = "receipt-adjustment"
+	APPROVAL_PRICE_OVERRIDE     ApprovalAction = "price-override"
+	APPROVAL_PAYMENT_PLAN_EDIT  ApprovalAction = "payment-plan-edit"
+)
+
+func (a ApprovalAction) IsValid() bool {
+	switch a {
+	case APPROVAL_SALE_DELETE, APPROVAL_RECEIPT_CLEAR, APPROVAL_RECEIPT_ADJUSTMENT, APPROVAL_PRICE_OVERRIDE, APPROVAL_PAYMENT_PLAN_EDIT:
+		return true
+	default:
+		return false
+	}
+}
+
+type ApprovalStatus string
+
+const (
+	APPROVAL_PENDING  ApprovalStatus = "pending"
+	APPROVAL_APPROVED ApprovalStatus = "approved"
+	APPROVAL_REJECTED ApprovalStatus = "rejected"
+	// APPROVAL_FAILED is an approved request whose operation could not be executed
+	APPROVAL_FAILED ApprovalStatus = "failed"
+)
+
+func (s ApprovalStatus) IsValid() bool {
+	switch s {
+	case APPROVAL_PENDING, APPROVAL_APPROVED, APPROVAL_REJECTED, APPROVAL_FAILED:
+		return true
+	default:
+		return false
+	}
+}
+
+type WebhookEvent string
+
+const (
+	EVENT_SALE_CREATED                WebhookEvent = "sale.created"
+	EVENT_SALE_DELETED                WebhookEvent = "sale.deleted"
+	EVENT_RECEIPT_CREATED             WebhookEvent = "receipt.created"
+	EVENT_RECEIPT_CLEARED             WebhookEvent = "receipt.cleared"
+	EVENT_RECEIPT_FAILED              WebhookEvent = "receipt.failed"
+	EVENT_PAYMENT_PLAN_ITEM_ACTIVATED WebhookEvent = "payment_plan_item.activated"
+	EVENT_FLAT_CREATED                WebhookEvent = "flat.created"
+)
+
+// AllWebhookEvents lists every event webhooks can subscribe to
+var AllWebhookEvents = []WebhookEvent{
+	EVENT_SALE_CREATED, EVENT_SALE_DELETED,
+	EVENT_RECEIPT_CREATED, EVENT_RECEIPT_CLEARED, EVENT_RECEIPT_FAILED,
+	EVENT_PAYMENT_PLAN_ITEM_ACTIVATED,
+	EVENT_FLAT_CREATED,
+}
+
+func (e WebhookEvent) IsValid() bool {
+	for _, event := range AllWebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DELIVERY_PENDING   WebhookDeliveryStatus = "pending"
+	DELIVERY_SUCCEEDED WebhookDeliveryStatus = "succeeded"
+	// DELIVERY_FAILED is a delivery whose every attempt failed
+	DELIVERY_FAILED WebhookDeliveryStatus = "failed"
+)
+
+func (s WebhookDeliveryStatus) IsValid() bool {
+	switch s {
+	case DELIVERY_PENDING, DELIVERY_SUCCEEDED, DELIVERY_FAILED:
+		return true
+	default:
+		return false
+	}
+}
+
+type NotificationKind string
+
+const (
+	NOTIFY_RECEIPT_ACKNOWLEDGEMENT NotificationKind = "receipt-acknowledgement"
+	NOTIFY_CHEQUE_BOUNCE           NotificationKind = "cheque-bounce"
+	NOTIFY_DEMAND_DUE              NotificationKind = "demand-due"
+	NOTIFY_PAYMENT_REMINDER        NotificationKind = "payment-reminder"
+	NOTIFY_BOOKING_WELCOME         NotificationKind = "booking-welcome"
+)
+
+// AllNotificationKinds lists every notification organizations can template
+var AllNotificationKinds = []NotificationKind{
+	NOTIFY_RECEIPT_ACKNOWLEDGEMENT, NOTIFY_CHEQUE_BOUNCE, NOTIFY_DEMAND_DUE, NOTIFY_PAYMENT_REMINDER, NOTIFY_BOOKING_WELCOME,
+}
+
+func (k NotificationKind) IsValid() bool {
+	for _, kind := range AllNotificationKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type NotificationChannel string
+
+const (
+	CHANNEL_EMAIL NotificationChannel = "email"
+	CHANNEL_SMS   NotificationChannel = "sms"
+)
+
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case CHANNEL_EMAIL, CHANNEL_SMS:
+		return true
+	default:
+		return false
+	}
+}
+
+type NotificationStatus string
+
+const (
+	NOTIFICATION_PENDING NotificationStatus = "pending"
+	NOTIFICATION_SENT    NotificationStatus = "sent"
+	NOTIFICATION_FAILED  NotificationStatus = "failed"
+	// NOTIFICATION_SKIPPED is a message not sent because the recipient opted out
+	NOTIFICATION_SKIPPED NotificationStatus = "skipped"
+)
+
+func (s NotificationStatus) IsValid() bool {
+	switch s {
+	case NOTIFICATION_PENDING, NOTIFICATION_SENT, NOTIFICATION_FAILED, NOTIFICATION_SKIPPED:
+		return true
+	default:
+		return false
+	}
+}
+
+type CollectionTaskStatus string
+
+const (
+	TASK_OPEN   CollectionTaskStatus = "open"
+	TASK_CLOSED CollectionTaskStatus = "closed"
+)
+
+func (s CollectionTaskStatus) IsValid() bool {
+	switch s {
+	case TASK_OPEN, TASK_CLOSED:
+		return true
+	default:
+		return false
+	}
+}
+
+type JobType string
+
+const (
+	JOB_MASTER_REPORT JobType = "master-report"
+)
+
+type JobStatus string
+
+const (
+	JOB_QUEUED    JobStatus = "queued"
+	JOB_RUNNING   JobStatus = "running"
+	JOB_SUCCEEDED JobStatus = "succeeded"
+	JOB_FAILED    JobStatus = "failed"
+	JOB_CANCELLED JobStatus = "cancelled"
+)
+
+func (s JobStatus) IsValid() bool {
+	switch s {
+	case JOB_QUEUED, JOB_RUNNING, JOB_SUCCEEDED, JOB_FAILED, JOB_CANCELLED:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFinal reports whether the job will not run again
+func (s JobStatus) IsFinal() bool {
+	return s == JOB_SUCCEEDED || s == JOB_FAILED || s == JOB_CANCELLED
+}
+
+// BankAccountType is the purpose of a bank account of a society under RERA
+// collections are realised in a collection account, the designated share is deposited in the designated account and the rest in the free account
+type BankAccountType string
+
+const (
+	BANK_ACCOUNT_COLLECTION BankAccountType = "collection"
+	BANK_ACCOUNT_DESIGNATED BankAccountType = "designated"
+	BANK_ACCOUNT_FREE       BankAccountType = "free"
+)
+
+func (t BankAccountType) IsValid() bool {
+	switch t {
+	case BANK_ACCOUNT_COLLECTION, BANK_ACCOUNT_DESIGNATED, BANK_ACCOUNT_FREE:
+		return true
+	default:
+		return false
+	}
+}
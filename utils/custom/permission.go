@@ -0,0 +1,144 @@
+package custom
+
+// Permission is a named action a role can perform
+type Permission string
+
+const (
+	PERMISSION_ORGANIZATION_VIEW   Permission = "organization.view"
+	PERMISSION_ORGANIZATION_MANAGE Permission = "organization.manage"
+	PERMISSION_USER_MANAGE         Permission = "user.manage"
+	PERMISSION_API_KEY_MANAGE      Permission = "api-key.manage"
+
+	PERMISSION_SOCIETY_VIEW   Permission = "society.view"
+	PERMISSION_SOCIETY_MANAGE Permission = "society.manage"
+	PERMISSION_TOWER_MANAGE   Permission = "tower.manage"
+	PERMISSION_FLAT_MANAGE    Permission = "flat.manage"
+
+	PERMISSION_SALE_VIEW           Permission = "sale.view"
+	PERMISSION_SALE_CREATE         Permission = "sale.create"
+	PERMISSION_SALE_UPDATE         Permission = "sale.update"
+	PERMISSION_SALE_ALLOT          Permission = "sale.allot"
+	PERMISSION_SALE_DELETE         Permission = "sale.delete"
+	PERMISSION_SALE_PRICE_OVERRIDE Permission = "sale.price-override"
+
+	PERMISSION_RECEIPT_VIEW   Permission = "receipt.view"
+	PERMISSION_RECEIPT_CREATE Permission = "receipt.create"
+	PERMISSION_RECEIPT_CLEAR  Permission = "receipt.clear"
+	PERMISSION_RECEIPT_FAIL   Permission = "receipt.fail"
+
+	PERMISSION_PAYMENT_PLAN_VIEW   Permission = "payment-plan.view"
+	PERMISSION_PAYMENT_PLAN_MANAGE Permission = "payment-plan.manage"
+
+	PERMISSION_BROKER_VIEW         Permission = "broker.view"
+	PERMISSION_BROKER_MANAGE       Permission = "broker.manage"
+	PERMISSION_COMMISSION_ACCRUE   Permission = "commission.accrue"
+	PERMISSION_COMMISSION_MANAGE   Permission = "commission.manage"
+	PERMISSION_BANK_VIEW           Permission = "bank.view"
+	PERMISSION_BANK_MANAGE         Permission = "bank.manage"
+	PERMISSION_CONSTRUCTION_VIEW   Permission = "construction.view"
+	PERMISSION_CONSTRUCTION_MANAGE Permission = "construction.manage"
+
+	PERMISSION_REPORT_VIEW            Permission = "report.view"
+	PERMISSION_REPORT_MASTER          Permission = "report.master"
+	PERMISSION_REPORT_RECEIPT         Permission = "report.receipt"
+	PERMISSION_REPORT_PAYMENT_PLAN    Permission = "report.payment-plan"
+	PERMISSION_REPORT_AGEING          Permission = "report.ageing"
+	PERMISSION_REPORT_CASH_FLOW       Permission = "report.cash-flow"
+	PERMISSION_REPORT_RERA            Permission = "report.rera"
+	PERMISSION_REPORT_TEMPLATE_MANAGE Permission = "report-template.manage"
+	PERMISSION_ANALYTICS_VIEW         Permission = "analytics.view"
+
+	PERMISSION_AUDIT_VIEW         Permission = "audit.view"
+	PERMISSION_APPROVAL_VIEW      Permission = "approval.view"
+	PERMISSION_APPROVAL_DECIDE    Permission = "approval.decide"
+	PERMISSION_APPROVAL_POLICY    Permission = "approval.policy"
+	PERMISSION_RECYCLE_BIN_MANAGE Permission = "recycle-bin.manage"
+	PERMISSION_WEBHOOK_MANAGE     Permission = "webhook.manage"
+
+	PERMISSION_NOTIFICATION_VIEW   Permission = "notification.view"
+	PERMISSION_NOTIFICATION_MANAGE Permission = "notification.manage"
+	PERMISSION_REMINDER_VIEW       Permission = "reminder.view"
+	PERMISSION_REMINDER_MANAGE     Permission = "reminder.manage"
+)
+
+// AllPermissions lists every permission in the order shown to users
+var AllPermissions = []Permission{
+	PERMISSION_ORGANIZATION_VIEW, PERMISSION_ORGANIZATION_MANAGE, PERMISSION_USER_MANAGE, PERMISSION_API_KEY_MANAGE,
+	PERMISSION_SOCIETY_VIEW, PERMISSION_SOCIETY_MANAGE, PERMISSION_TOWER_MANAGE, PERMISSION_FLAT_MANAGE,
+	PERMISSION_SALE_VIEW, PERMISSION_SALE_CREATE, PERMISSION_SALE_UPDATE, PERMISSION_SALE_ALLOT, PERMISSION_SALE_DELETE, PERMISSION_SALE_PRICE_OVERRIDE,
+	PERMISSION_RECEIPT_VIEW, PERMISSION_RECEIPT_CREATE, PERMISSION_RECEIPT_CLEAR, PERMISSION_RECEIPT_FAIL,
+	PERMISSION_PAYMENT_PLAN_VIEW, PERMISSION_PAYMENT_PLAN_MANAGE,
+	PERMISSION_BROKER_VIEW, PERMISSION_BROKER_MANAGE, PERMISSION_COMMISSION_ACCRUE, PERMISSION_COMMISSION_MANAGE,
+	PERMISSION_BANK_VIEW, PERMISSION_BANK_MANAGE,
+	PERMISSION_CONSTRUCTION_VIEW, PERMISSION_CONSTRUCTION_MANAGE,
+	PERMISSION_REPORT_VIEW, PERMISSION_REPORT_MASTER, PERMISSION_REPORT_RECEIPT, PERMISSION_REPORT_PAYMENT_PLAN, PERMISSION_REPORT_AGEING, PERMISSION_REPORT_CASH_FLOW, PERMISSION_REPORT_RERA, PERMISSION_REPORT_TEMPLATE_MANAGE, PERMISSION_ANALYTICS_VIEW,
+	PERMISSION_AUDIT_VIEW, PERMISSION_APPROVAL_VIEW, PERMISSION_APPROVAL_DECIDE, PERMISSION_APPROVAL_POLICY,
+	PERMISSION_RECYCLE_BIN_MANAGE, PERMISSION_WEBHOOK_MANAGE,
+	PERMISSION_NOTIFICATION_VIEW, PERMISSION_NOTIFICATION_MANAGE, PERMISSION_REMINDER_VIEW, PERMISSION_REMINDER_MANAGE,
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRolePermissions are the permissions of users without a custom role, same as the access of the fixed roles
+// org-admin always has every permission
+var DefaultRolePermissions = map[UserRole][]Permission{
+	ORGADMIN: AllPermissions,
+	ORGUSER: {
+		PERMISSION_ORGANIZATION_VIEW,
+		PERMISSION_SOCIETY_VIEW,
+		PERMISSION_SALE_VIEW, PERMISSION_SALE_CREATE, PERMISSION_SALE_ALLOT,
+		PERMISSION_RECEIPT_VIEW, PERMISSION_RECEIPT_CREATE, PERMISSION_RECEIPT_CLEAR, PERMISSION_RECEIPT_FAIL,
+		PERMISSION_PAYMENT_PLAN_VIEW, PERMISSION_PAYMENT_PLAN_MANAGE,
+		PERMISSION_BROKER_VIEW, PERMISSION_COMMISSION_ACCRUE,
+		PERMISSION_BANK_VIEW,
+		PERMISSION_CONSTRUCTION_VIEW, PERMISSION_CONSTRUCTION_MANAGE,
+		PERMISSION_REPORT_VIEW, PERMISSION_REPORT_MASTER, PERMISSION_REPORT_RECEIPT, PERMISSION_REPORT_PAYMENT_PLAN, PERMISSION_REPORT_AGEING,
+		PERMISSION_ANALYTICS_VIEW,
+		PERMISSION_APPROVAL_VIEW,
+		PERMISSION_NOTIFICATION_VIEW, PERMISSION_REMINDER_VIEW,
+	},
+	ORGVIEWER: {
+		PERMISSION_CONSTRUCTION_VIEW,
+		PERMISSION_REPORT_VIEW,
+		PERMISSION_ANALYTICS_VIEW,
+	},
+}
+
+// UserAccess is the resolved access of the requesting user
+type UserAccess struct {
+	Permissions map[Permission]bool
+	// Societies is nil when the user can access every society of the organization
+	Societies map[string]bool
+}
+
+// Can reports whether the user has the permission
+func (a *UserAccess) Can(permission Permission) bool {
+	return a != nil && a.Permissions[permission]
+}
+
+// CanAccessSociety reports whether the user is member of the society
+func (a *UserAccess) CanAccessSociety(society string) bool {
+	if a == nil {
+		return false
+	}
+	return a.Societies == nil || a.Societies[society]
+}
+
+// SocietyIds returns the societies the user is member of, nil when the user can access every society
+func (a *UserAccess) SocietyIds() []string {
+	if a == nil || a.Societies == nil {
+		return nil
+	}
+	societies := make([]string, 0, len(a.Societies))
+	for society := range a.Societies {
+		societies = append(societies, society)
+	}
+	return societies
+}
@@ -6,4 +6,8 @@ package custom
 type RequestContextKey string
 
 const OrganizationIDKey RequestContextKey = "org-id"
-const UserRoleKey RequestContextKey = "user-role"
\ No newline at end of file
+const UserRoleKey RequestContextKey = "user-role"
+const UserEmailKey RequestContextKey = "user-email"
+const UserSubKey RequestContextKey = "user-sub"
+const UserAccessKey RequestContextKey = "user-access"
+const ApiKeyIdKey RequestContextKey = "api-key-id"
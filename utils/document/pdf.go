@@ -0,0 +1,124 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// package document handles generation of printable documents like letters and statements
+
+const (
+	lineHeight = 6.0
+	fontFamily = "Helvetica"
+)
+
+// PDF is a thin wrapper over fpdf with the layout used by all generated documents
+type PDF struct {
+	pdf       *fpdf.Fpdf
+	translate func(string) string
+	width     float64
+}
+
+// NewPDF creates an A4 portrait document with the given title printed at the top of the first page
+func NewPDF(title string) *PDF {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont(fontFamily, "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+
+	doc := &PDF{
+		pdf:       pdf,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+		width:     pageWidth - left - right,
+	}
+
+	pdf.SetFont(fontFamily, "B", 16)
+	pdf.CellFormat(0, 10, doc.translate(title), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+	return doc
+}
+
+// Heading adds a bold section heading
+func (d *PDF) Heading(text string) {
+	d.pdf.Ln(2)
+	d.pdf.SetFont(fontFamily, "B", 12)
+	d.pdf.CellFormat(0, 8, d.translate(text), "B", 1, "L", false, 0, "")
+	d.pdf.Ln(1)
+}
+
+// Paragraph adds wrapped body text
+func (d *PDF) Paragraph(text string) {
+	d.pdf.SetFont(fontFamily, "", 10)
+	d.pdf.MultiCell(0, lineHeight, d.translate(text), "", "L", false)
+	d.pdf.Ln(2)
+}
+
+// KeyValues adds a two column list of label and value
+func (d *PDF) KeyValues(rows [][2]string) {
+	labelWidth := d.width * 0.35
+	for _, row := range rows {
+		d.pdf.SetFont(fontFamily, "B", 10)
+		d.pdf.CellFormat(labelWidth, lineHeight, d.translate(row[0]), "", 0, "L", false, 0, "")
+		d.pdf.SetFont(fontFamily, "", 10)
+		d.pdf.MultiCell(0, lineHeight, d.translate(row[1]), "", "L", false)
+	}
+	d.pdf.Ln(2)
+}
+
+// Table adds a bordered table, columns share the page width equally
+func (d *PDF) Table(headers []string, rows [][]string) {
+	if len(headers) == 0 {
+		return
+	}
+	colWidth := d.width / float64(len(headers))
+
+	d.pdf.SetFont(fontFamily, "B", 9)
+	d.pdf.SetFillColor(230, 230, 230)
+	for _, h := range headers {
+		d.pdf.CellFormat(colWidth, lineHeight+1, d.translate(h), "1", 0, "C", true, 0, "")
+	}
+	d.pdf.Ln(-1)
+
+	d.pdf.SetFont(fontFamily, "", 9)
+	for _, row := range rows {
+		for i := range headers {
+			val := ""
+			if i < len(row) {
+				val = row[i]
+			}
+			d.pdf.CellFormat(colWidth, lineHeight, d.translate(val), "1", 0, "L", false, 0, "")
+		}
+		d.pdf.Ln(-1)
+	}
+	d.pdf.Ln(3)
+}
+
+// Signature adds a right aligned signature block
+func (d *PDF) Signature(lines ...string) {
+	d.pdf.Ln(12)
+	d.pdf.SetFont(fontFamily, "", 10)
+	for _, line := range lines {
+		d.pdf.CellFormat(0, lineHeight, d.translate(line), "", 1, "R", false, 0, "")
+	}
+}
+
+// Bytes renders the document
+func (d *PDF) Bytes() (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	if err := d.pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
@@ -0,0 +1,30 @@
+package identity
+
+import "strings"
+
+// ApiKeyPrefix starts every api key so keys can be told apart from jwt in the authorization header
+const ApiKeyPrefix = "rek_"
+
+// apiKeyDisplayLength is the length of the key prefix stored in clear to identify the key
+const apiKeyDisplayLength = 12
+
+// NewApiKey creates a random api key and returns the key, its display prefix and its hash
+func NewApiKey() (string, string, string, error) {
+	token, _, err := newToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key := ApiKeyPrefix + token
+	return key, key[:apiKeyDisplayLength], HashApiKey(key), nil
+}
+
+// HashApiKey returns the stored hash of the api key
+func HashApiKey(key string) string {
+	return hashToken(key)
+}
+
+// IsApiKey reports whether the credential is an api key
+func IsApiKey(credential string) bool {
+	return strings.HasPrefix(credential, ApiKeyPrefix)
+}
@@ -0,0 +1,120 @@
+package identity
+
+import (
+	"context"
+	"errors"
+
+	"circledigital.in/real-state-erp/utils/common"
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// cognitoProvider manages users of the cognito user pool, role is the cognito group of the user
+// cognito delivers the invites and reset codes so no tokens are returned
+type cognitoProvider struct {
+	client   *cognitoidentityprovider.Client
+	userPool string
+}
+
+// NewCognitoProvider creates identity provider for the cognito user pool
+func NewCognitoProvider(client *cognitoidentityprovider.Client, userPool string) common.IdentityProvider {
+	return &cognitoProvider{
+		client:   client,
+		userPool: userPool,
+	}
+}
+
+func (p *cognitoProvider) UserExists(email string) (bool, error) {
+	_, err := p.client.AdminGetUser(context.TODO(), &cognitoidentityprovider.AdminGetUserInput{
+		UserPoolId: aws.String(p.userPool),
+		Username:   aws.String(email),
+	})
+	var notFound *types.UserNotFoundException
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (p *cognitoProvider) CreateUser(email, orgId string, role custom.UserRole) (string, error) {
+	_, err := p.client.AdminCreateUser(context.TODO(), &cognitoidentityprovider.AdminCreateUserInput{
+		UserPoolId: aws.String(p.userPool),
+		Username:   aws.String(email),
+		UserAttributes: []types.AttributeType{
+			{
+				Name:  aws.String(custom.OrgIdCustomAttribute),
+				Value: aws.String(orgId),
+			},
+			{
+				Name:  aws.String("name"), // Standard attribute for full name
+				Value: aws.String(email),
+			},
+			{
+				Name:  aws.String("picture"),
+				Value: aws.String(""),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = p.addToGroup(email, role)
+	if err != nil {
+		// clean up user without group
+		_ = p.DeleteUser(email)
+		return "", err
+	}
+	return "", nil
+}
+
+// UpdateRole removes user from the existing groups and adds to the group of the role
+func (p *cognitoProvider) UpdateRole(email string, role custom.UserRole) error {
+	listOut, err := p.client.AdminListGroupsForUser(context.TODO(), &cognitoidentityprovider.AdminListGroupsForUserInput{
+		UserPoolId: aws.String(p.userPool),
+		Username:   aws.String(email),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, group := range listOut.Groups {
+		_, err := p.client.AdminRemoveUserFromGroup(context.TODO(), &cognitoidentityprovider.AdminRemoveUserFromGroupInput{
+			UserPoolId: aws.String(p.userPool),
+			Username:   aws.String(email),
+			GroupName:  group.GroupName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return p.addToGroup(email, role)
+}
+
+func (p *cognitoProvider) DeleteUser(email string) error {
+	_, err := p.client.AdminDeleteUser(context.TODO(), &cognitoidentityprovider.AdminDeleteUserInput{
+		UserPoolId: aws.String(p.userPool),
+		Username:   aws.String(email),
+	})
+	return err
+}
+
+// ResetPassword makes cognito send a reset code to the user
+func (p *cognitoProvider) ResetPassword(email string) error {
+	_, err := p.client.AdminResetUserPassword(context.TODO(), &cognitoidentityprovider.AdminResetUserPasswordInput{
+		UserPoolId: aws.String(p.userPool),
+		Username:   aws.String(email),
+	})
+	return err
+}
+
+func (p *cognitoProvider) addToGroup(email string, role custom.UserRole) error {
+	_, err := p.client.AdminAddUserToGroup(context.TODO(), &cognitoidentityprovider.AdminAddUserToGroupInput{
+		UserPoolId: aws.String(p.userPool),
+		GroupName:  aws.String(string(role)),
+		Username:   aws.String(email),
+	})
+	return err
+}
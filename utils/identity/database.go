@@ -0,0 +1,198 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/auth"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
+	"gorm.io/gorm"
+)
+
+const (
+	inviteTTL  = 7 * 24 * time.Hour
+	resetTTL   = time.Hour
+	sessionTTL = 12 * time.Hour
+)
+
+var invalidCredentialsError = &custom.RequestError{
+	Status:  http.StatusUnauthorized,
+	Message: "Invalid email or password.",
+}
+
+var invalidTokenError = &custom.RequestError{
+	Status:  http.StatusBadRequest,
+	Message: "Invalid or expired token.",
+}
+
+// DatabaseProvider manages users in the database, tokens of logged in users are issued by the local auth provider
+// invite tokens are returned to the caller for delivery, reset tokens are mailed to the user so that
+// the admin starting the reset can't set the password of the user
+type DatabaseProvider struct {
+	db       *gorm.DB
+	issuer   *auth.LocalProvider
+	mailer   notification.Transport
+	resetUrl string
+}
+
+// NewDatabaseProvider creates identity provider storing credentials in the database,
+// reset tokens are sent through mailer with a link to resetUrl when it is set
+func NewDatabaseProvider(db *gorm.DB, issuer *auth.LocalProvider, mailer notification.Transport, resetUrl string) *DatabaseProvider {
+	return &DatabaseProvider{
+		db:       db,
+		issuer:   issuer,
+		mailer:   mailer,
+		resetUrl: resetUrl,
+	}
+}
+
+func (p *DatabaseProvider) UserExists(email string) (bool, error) {
+	var count int64
+	err := p.db.Model(&models.UserCredential{}).Where("email = ?", email).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateUser creates credential without password, password is set by accepting the invite
+func (p *DatabaseProvider) CreateUser(email, orgId string, role custom.UserRole) (string, error) {
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := time.Now().Add(inviteTTL)
+	err = p.db.Create(&models.UserCredential{
+		Email:          email,
+		OrgId:          orgId,
+		Role:           role,
+		TokenHash:      &tokenHash,
+		TokenPurpose:   models.CREDENTIAL_INVITE,
+		TokenExpiresAt: &expiresAt,
+	}).Error
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (p *DatabaseProvider) UpdateRole(email string, role custom.UserRole) error {
+	result := p.db.Model(&models.UserCredential{}).Where("email = ?", email).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return userNotFoundError
+	}
+	return nil
+}
+
+func (p *DatabaseProvider) DeleteUser(email string) error {
+	return p.db.Where("email = ?", email).Delete(&models.UserCredential{}).Error
+}
+
+// ResetPassword replaces any pending token with a reset token and mails it to the user,
+// the token is replaced only if the mail is sent and existing password stays valid until reset
+func (p *DatabaseProvider) ResetPassword(email string) error {
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserCredential{}).Where("email = ?", email).Updates(map[string]any{
+			"token_hash":       tokenHash,
+			"token_purpose":    models.CREDENTIAL_RESET,
+			"token_expires_at": time.Now().Add(resetTTL),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return userNotFoundError
+		}
+		return p.mailer.Send(context.Background(), resetMessage(email, token, p.resetUrl))
+	})
+}
+
+// resetMessage is the email delivering the reset token to the user
+func resetMessage(email, token, resetUrl string) notification.Message {
+	body := fmt.Sprintf("A password reset was requested for your account.\n\nReset token: %s\n", token)
+	if resetUrl != "" {
+		body += fmt.Sprintf("\nSet a new password at %s?token=%s\n", resetUrl, url.QueryEscape(token))
+	}
+	body += fmt.Sprintf("\nThe token expires in %s. Your current password stays valid until it is reset.\n", resetTTL)
+	return notification.Message{
+		Channel: custom.CHANNEL_EMAIL,
+		To:      email,
+		Subject: "Reset your password",
+		Body:    body,
+	}
+}
+
+// AcceptInvite sets the first password of the invited user
+func (p *DatabaseProvider) AcceptInvite(token, password string) error {
+	return p.redeemToken(models.CREDENTIAL_INVITE, token, password)
+}
+
+// CompleteReset sets the new password of the user
+func (p *DatabaseProvider) CompleteReset(token, password string) error {
+	return p.redeemToken(models.CREDENTIAL_RESET, token, password)
+}
+
+// redeemToken sets the password of the credential holding the token and clears the token
+func (p *DatabaseProvider) redeemToken(purpose models.CredentialToken, token, password string) error {
+	err := validatePassword(password)
+	if err != nil {
+		return err
+	}
+
+	var credential models.UserCredential
+	err = p.db.Where("token_hash = ?", hashToken(strings.TrimSpace(token))).First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return invalidTokenError
+	}
+	if err != nil {
+		return err
+	}
+	if !credential.TokenValid(purpose, time.Now()) {
+		return invalidTokenError
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return p.db.Model(&credential).Updates(map[string]any{
+		"password_hash":    passwordHash,
+		"token_hash":       nil,
+		"token_purpose":    "",
+		"token_expires_at": nil,
+	}).Error
+}
+
+// Login verifies the password and returns a token of the local auth provider
+func (p *DatabaseProvider) Login(email, password string) (string, error) {
+	var credential models.UserCredential
+	err := p.db.Where("email = ?", strings.TrimSpace(email)).First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", invalidCredentialsError
+	}
+	if err != nil {
+		return "", err
+	}
+	if !checkPassword(credential.PasswordHash, password) {
+		return "", invalidCredentialsError
+	}
+
+	return p.issuer.Mint(auth.Identity{
+		Role:  credential.Role,
+		OrgId: credential.OrgId,
+		Email: credential.Email,
+	}, sessionTTL)
+}
@@ -0,0 +1,67 @@
+package identity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"unicode/utf8"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// package identity implements the identity providers managing login of users
+
+// password length limits, bcrypt uses only the first 72 bytes
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72
+)
+
+var userNotFoundError = &custom.RequestError{
+	Status:  http.StatusBadRequest,
+	Message: "user not found",
+}
+
+// validatePassword checks the password length
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return &custom.RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "Password must be 8 to 72 characters.",
+		}
+	}
+	return nil
+}
+
+// hashPassword hashes the password with bcrypt
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// checkPassword compares the password with its hash
+func checkPassword(hash, password string) bool {
+	if hash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// newToken creates a random one time token and its hash, only the hash is stored
+func newToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+// hashToken returns hex encoded sha256 of the token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
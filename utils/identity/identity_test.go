@@ -0,0 +1,83 @@
+package identity
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordHash(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !checkPassword(hash, "correct horse") {
+		t.Error("want password to match its hash")
+	}
+	if checkPassword(hash, "wrong horse") {
+		t.Error("want other password rejected")
+	}
+	if checkPassword("", "") {
+		t.Error("want credential without password rejected")
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	if validatePassword("short") == nil {
+		t.Error("want short password rejected")
+	}
+	if validatePassword(strings.Repeat("a", 73)) == nil {
+		t.Error("want password longer than 72 bytes rejected")
+	}
+	if validatePassword("long enough") != nil {
+		t.Error("want valid password accepted")
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash, err := newToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashToken(token) != hash {
+		t.Error("want stored hash to match the token")
+	}
+
+	other, _, _ := newToken()
+	if other == token {
+		t.Error("want tokens to be random")
+	}
+}
+
+func TestNewApiKey(t *testing.T) {
+	key, prefix, hash, err := NewApiKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsApiKey(key) || !strings.HasPrefix(key, prefix) {
+		t.Errorf("unexpected key %q with prefix %q", key, prefix)
+	}
+	if HashApiKey(key) != hash {
+		t.Error("want stored hash to match the key")
+	}
+	if IsApiKey("eyJhbGciOiJSUzI1NiJ9.e30.sig") {
+		t.Error("want jwt not taken as api key")
+	}
+}
+
+func TestResetMessage(t *testing.T) {
+	message := resetMessage("user@example.com", "a b", "https://erp.example.com/reset")
+	if message.To != "user@example.com" {
+		t.Fatalf("reset mailed to %s", message.To)
+	}
+	if !strings.Contains(message.Body, "Reset token: a b") {
+		t.Fatalf("reset token missing from %q", message.Body)
+	}
+	if !strings.Contains(message.Body, "https://erp.example.com/reset?token=a+b") {
+		t.Fatalf("reset link missing from %q", message.Body)
+	}
+
+	message = resetMessage("user@example.com", "token", "")
+	if strings.Contains(message.Body, "?token=") {
+		t.Fatalf("reset link without reset url in %q", message.Body)
+	}
+}
@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"gorm.io/gorm"
+)
+
+// package jobs is a postgres backed queue of background work run by worker goroutines
+
+// Result is the file produced by a job, kept in the blob store
+type Result struct {
+	Name        string
+	ContentType string
+	Body        io.Reader
+	Size        int64
+}
+
+// Handler runs a job, it must return when ctx is cancelled and report progress as it goes
+// a job without a file to download returns a nil result
+type Handler func(ctx context.Context, job *models.Job, progress *Progress) (*Result, error)
+
+var (
+	handlersMu sync.RWMutex
+	handlers   = make(map[custom.JobType]Handler)
+)
+
+// Register registers the handler of the job type, services register their handlers when created
+func Register(jobType custom.JobType, handler Handler) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	handlers[jobType] = handler
+}
+
+func handlerOf(jobType custom.JobType) (Handler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	handler, ok := handlers[jobType]
+	return handler, ok
+}
+
+// ErrPermanent marks an error that is not retried
+var ErrPermanent = errors.New("permanent job error")
+
+// Permanent wraps err so the job fails without being retried
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
+
+// Enqueue writes the job to the queue, params are stored as json and decoded by the handler
+func Enqueue(db *gorm.DB, job *models.Job, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	job.Params = raw
+	job.Status = custom.JOB_QUEUED
+	job.RunAfter = time.Now()
+	if job.MaxAttempts == 0 {
+		job.MaxAttempts = 3
+	}
+	return db.Create(job).Error
+}
+
+// Cancel cancels a queued job right away and asks the worker of a running job to stop
+// returns false when the job has already finished
+func Cancel(db *gorm.DB, job *models.Job) (bool, error) {
+	now := time.Now()
+	result := db.Model(&models.Job{}).
+		Where("id = ? and status = ?", job.Id, custom.JOB_QUEUED).
+		Updates(map[string]any{"status": custom.JOB_CANCELLED, "finished_at": now, "cancel_requested": true})
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.RowsAffected > 0, result.Error
+	}
+
+	result = db.Model(&models.Job{}).
+		Where("id = ? and status = ?", job.Id, custom.JOB_RUNNING).
+		Update("cancel_requested", true)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Progress records the progress of a running job, writes are throttled
+type Progress struct {
+	db       *gorm.DB
+	job      *models.Job
+	mu       sync.Mutex
+	lastSave time.Time
+}
+
+const progressInterval = time.Second
+
+// Report records the percent done with a short message, 100 is recorded by the runner when the job succeeds
+func (p *Progress) Report(percent int, message string) {
+	if p == nil || p.db == nil {
+		return
+	}
+	percent = max(0, min(percent, 99))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if time.Since(p.lastSave) < progressInterval && message == p.job.Message {
+		return
+	}
+	p.lastSave = time.Now()
+	p.job.Progress = percent
+	p.job.Message = message
+
+	p.db.Model(&models.Job{}).Where("id = ?", p.job.Id).
+		Updates(map[string]any{"progress": percent, "message": message})
+}
@@ -0,0 +1,274 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/blob"
+	"circledigital.in/real-state-erp/utils/custom"
+	"gorm.io/gorm"
+)
+
+const (
+	pollInterval = 2 * time.Second
+	// jobLease keeps a claimed job from being claimed by another worker, extended by the heartbeat while it runs
+	jobLease          = time.Minute
+	heartbeatInterval = 10 * time.Second
+	retryBaseDelay    = 30 * time.Second
+	janitorInterval   = time.Minute
+)
+
+// retryDelay is the delay before the next attempt after the failed attempt
+func retryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	return retryBaseDelay * time.Duration(attempt)
+}
+
+// Runner claims queued jobs with skip locked and runs them on worker goroutines
+// jobs of a worker that stopped are claimed again when their lease expires
+type Runner struct {
+	db        *gorm.DB
+	store     blob.Store
+	workers   int
+	resultTTL time.Duration
+}
+
+func NewRunner(db *gorm.DB, store blob.Store, workers int, resultTTL time.Duration) *Runner {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Runner{db: db, store: store, workers: workers, resultTTL: resultTTL}
+}
+
+// Run runs the workers and the janitor until the context is cancelled
+func (r *Runner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.recoverStale(); err != nil {
+			log.Printf("Error recovering stale jobs: %v\n", err)
+		}
+		if err := r.expireResults(ctx); err != nil {
+			log.Printf("Error expiring job results: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// work runs claimed jobs one at a time, polling while the queue is empty
+func (r *Runner) work(ctx context.Context) {
+	for {
+		job, err := r.claim()
+		if err != nil {
+			log.Printf("Error claiming job: %v\n", err)
+		}
+		if job != nil {
+			r.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// claim marks the oldest due job running and returns it, nil when there is none
+func (r *Runner) claim() (*models.Job, error) {
+	now := time.Now()
+	var job models.Job
+	result := r.db.Raw(`
+		UPDATE jobs SET status = ?, attempts = attempts + 1, locked_until = ?, started_at = COALESCE(started_at, ?), updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs WHERE status = ? AND run_after <= ?
+			ORDER BY run_after FOR UPDATE SKIP LOCKED LIMIT 1
+		)
+		RETURNING *`,
+		custom.JOB_RUNNING, now.Add(jobLease), now, now, custom.JOB_QUEUED, now,
+	).Scan(&job)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+// run runs the handler with a heartbeat that extends the lease and stops the handler when cancel is requested
+func (r *Runner) run(parent context.Context, job *models.Job) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	cancelled := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		r.heartbeat(ctx, job, cancel, cancelled)
+	}()
+
+	res, err := r.execute(ctx, job)
+	if err == nil && res != nil {
+		err = r.storeResult(ctx, job, res)
+	}
+	cancel()
+	<-heartbeatDone
+
+	select {
+	case <-cancelled:
+		r.finish(job, map[string]any{"status": custom.JOB_CANCELLED, "error": "cancelled"})
+		return
+	default:
+	}
+
+	if err != nil {
+		r.fail(job, err)
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]any{"status": custom.JOB_SUCCEEDED, "progress": 100, "message": "completed", "error": ""}
+	if job.ResultKey != "" {
+		updates["result_key"] = job.ResultKey
+		updates["result_name"] = job.ResultName
+		updates["result_content_type"] = job.ResultContentType
+		updates["result_size"] = job.ResultSize
+		if r.resultTTL > 0 {
+			updates["expires_at"] = now.Add(r.resultTTL)
+		}
+	}
+	r.finish(job, updates)
+}
+
+// execute runs the handler of the job, a panic fails the attempt instead of the process
+func (r *Runner) execute(ctx context.Context, job *models.Job) (res *Result, err error) {
+	handler, ok := handlerOf(job.Type)
+	if !ok {
+		return nil, Permanent(fmt.Errorf("no handler registered for job type %s", job.Type))
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("Job %s panicked: %v\n%s", job.Id, p, debug.Stack())
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return handler(ctx, job, &Progress{db: r.db, job: job})
+}
+
+func (r *Runner) storeResult(ctx context.Context, job *models.Job, res *Result) error {
+	key := fmt.Sprintf("jobs/%s/%s/%s", job.OrgId, job.Id, res.Name)
+	if err := r.store.Put(ctx, key, res.Body, res.Size, res.ContentType); err != nil {
+		return fmt.Errorf("storing result: %w", err)
+	}
+
+	job.ResultKey = key
+	job.ResultName = res.Name
+	job.ResultContentType = res.ContentType
+	job.ResultSize = res.Size
+	return nil
+}
+
+func (r *Runner) heartbeat(ctx context.Context, job *models.Job, cancel context.CancelFunc, cancelled chan<- struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var current models.Job
+		err := r.db.Select("cancel_requested").Where("id = ?", job.Id).First(&current).Error
+		if err == nil && current.CancelRequested {
+			close(cancelled)
+			cancel()
+			return
+		}
+		r.db.Model(&models.Job{}).Where("id = ?", job.Id).Update("locked_until", time.Now().Add(jobLease))
+	}
+}
+
+// fail queues the job again with backoff, or marks it failed after its last attempt or a permanent error
+func (r *Runner) fail(job *models.Job, err error) {
+	if job.Attempts >= job.MaxAttempts || errors.Is(err, ErrPermanent) {
+		r.finish(job, map[string]any{"status": custom.JOB_FAILED, "error": err.Error()})
+		return
+	}
+
+	update := r.db.Model(&models.Job{}).Where("id = ?", job.Id).Updates(map[string]any{
+		"status":       custom.JOB_QUEUED,
+		"error":        err.Error(),
+		"run_after":    time.Now().Add(retryDelay(job.Attempts)),
+		"locked_until": nil,
+	})
+	if update.Error != nil {
+		log.Printf("Error recording failure of job %s: %v\n", job.Id, update.Error)
+	}
+}
+
+func (r *Runner) finish(job *models.Job, updates map[string]any) {
+	updates["finished_at"] = time.Now()
+	updates["locked_until"] = nil
+	if err := r.db.Model(&models.Job{}).Where("id = ?", job.Id).Updates(updates).Error; err != nil {
+		log.Printf("Error finishing job %s: %v\n", job.Id, err)
+	}
+}
+
+// recoverStale queues again the running jobs whose lease expired, their worker stopped without finishing them
+func (r *Runner) recoverStale() error {
+	now := time.Now()
+	err := r.db.Model(&models.Job{}).
+		Where("status = ? and locked_until < ? and attempts >= max_attempts", custom.JOB_RUNNING, now).
+		Updates(map[string]any{"status": custom.JOB_FAILED, "error": "worker stopped", "finished_at": now, "locked_until": nil}).Error
+	if err != nil {
+		return err
+	}
+
+	return r.db.Model(&models.Job{}).
+		Where("status = ? and locked_until < ?", custom.JOB_RUNNING, now).
+		Updates(map[string]any{"status": custom.JOB_QUEUED, "run_after": now, "locked_until": nil}).Error
+}
+
+// expireResults deletes the results that expired from the blob store
+func (r *Runner) expireResults(ctx context.Context) error {
+	var expired []models.Job
+	err := r.db.Where("status = ? and result_key <> '' and expires_at < ?", custom.JOB_SUCCEEDED, time.Now()).
+		Limit(100).Find(&expired).Error
+	if err != nil {
+		return err
+	}
+
+	for _, job := range expired {
+		if err := r.store.Delete(ctx, job.ResultKey); err != nil {
+			log.Printf("Error deleting result of job %s: %v\n", job.Id, err)
+			continue
+		}
+		r.db.Model(&models.Job{}).Where("id = ?", job.Id).Update("result_key", "")
+	}
+	return nil
+}
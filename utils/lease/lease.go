@@ -0,0 +1,67 @@
+package lease
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// package lease claims the rows of the outgoing queues (webhook deliveries, notifications) for one worker at a time
+
+// Queue is a table of rows sent one attempt at a time by workers of several instances,
+// a row is due while its status is pending and its next_attempt_at has passed,
+// claiming a row moves its next_attempt_at to the end of the lease so no other worker claims it meanwhile
+type Queue struct {
+	Model     any // model of the table
+	Pending   any // status of the rows still to be sent
+	BatchSize int
+	// Lease keeps a claimed row from being claimed again while it is being sent, it must outlast one attempt
+	Lease          time.Duration
+	RetryBaseDelay time.Duration
+}
+
+// Claim leases the due rows with skip locked and returns their ids and the end of their lease
+func (q Queue) Claim(db *gorm.DB) ([]uuid.UUID, time.Time, error) {
+	var ids []uuid.UUID
+	var leasedUntil time.Time
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(q.Model).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? and next_attempt_at <= ?", q.Pending, now).
+			Order("next_attempt_at").
+			Limit(q.BatchSize).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		leasedUntil = q.until(now)
+		return tx.Model(q.Model).Where("id IN ?", ids).Update("next_attempt_at", leasedUntil).Error
+	})
+	return ids, leasedUntil, err
+}
+
+// Renew extends the lease of a claimed row from now, to be called just before the row is sent as a batch
+// may outlast the lease of its claim, it is not renewed when the lease was lost to another worker after
+// it expired or the row is no longer pending
+func (q Queue) Renew(db *gorm.DB, id uuid.UUID, leasedUntil time.Time) (bool, error) {
+	result := db.Model(q.Model).
+		Where("id = ? and status = ? and next_attempt_at = ?", id, q.Pending, leasedUntil).
+		Update("next_attempt_at", q.until(time.Now()))
+	return result.RowsAffected > 0, result.Error
+}
+
+// RetryDelay is the delay before the next attempt after the failed attempt, doubled on every attempt
+func (q Queue) RetryDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	return q.RetryBaseDelay << (attempt - 1)
+}
+
+// until is the end of a lease taken at now, in microseconds as stored by the database so the lease can be matched
+func (q Queue) until(now time.Time) time.Time {
+	return now.Add(q.Lease).Truncate(time.Microsecond)
+}
@@ -0,0 +1,32 @@
+package lease
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	queue := Queue{RetryBaseDelay: time.Minute}
+	tests := map[int]time.Duration{
+		0: time.Minute,
+		1: time.Minute,
+		2: 2 * time.Minute,
+		4: 8 * time.Minute,
+		7: 64 * time.Minute,
+	}
+	for attempt, want := range tests {
+		if got := queue.RetryDelay(attempt); got != want {
+			t.Errorf("RetryDelay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestUntil(t *testing.T) {
+	queue := Queue{Lease: 2 * time.Minute}
+	now := time.Date(2025, 1, 1, 10, 0, 0, 123456789, time.UTC)
+	got := queue.until(now)
+	want := time.Date(2025, 1, 1, 10, 2, 0, 123456000, time.UTC)
+	if !got.Equal(want) {
+		t.Fatalf("until(%s) = %s, want %s", now, got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/payload"
+	"gorm.io/gorm"
+)
+
+// AccessMiddleware resolves permissions and society membership of organization users
+type AccessMiddleware struct {
+	DB *gorm.DB
+}
+
+// LoadUserAccess adds the access of the user to request context
+// requests without organization and requests made with api keys, whose access comes from the key, are skipped
+func (am *AccessMiddleware) LoadUserAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgId, _ := r.Context().Value(custom.OrganizationIDKey).(string)
+		if _, ok := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess); orgId == "" || ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		role, _ := r.Context().Value(custom.UserRoleKey).(custom.UserRole)
+		email, _ := r.Context().Value(custom.UserEmailKey).(string)
+
+		var user *models.User
+		userModel := models.User{}
+		err := am.DB.
+			Preload("AccessRole").
+			Preload("Societies").
+			Where("email = ? and org_id = ?", email, orgId).
+			First(&userModel).Error
+		if err == nil {
+			user = &userModel
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			payload.HandleError(w, err)
+			return
+		}
+
+		access := models.ResolveUserAccess(role, user)
+		reqContext := context.WithValue(r.Context(), custom.UserAccessKey, access)
+		*r = *r.WithContext(reqContext)
+
+		next.ServeHTTP(w, r)
+	})
+}
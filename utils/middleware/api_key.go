@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/identity"
+	"gorm.io/gorm"
+)
+
+// apiKeyUsageInterval limits how often last used time of a key is written
+const apiKeyUsageInterval = time.Minute
+
+var invalidApiKeyError = &custom.RequestError{
+	Status:  http.StatusUnauthorized,
+	Message: "Invalid api key.",
+}
+
+// parseApiKey validates the api key, the request acts as the key with the email "api-key:<prefix>"
+func (am *AuthenticationMiddleware) parseApiKey(key string) (*tokenPayload, error) {
+	if !identity.IsApiKey(key) {
+		return nil, invalidApiKeyError
+	}
+
+	var apiKey models.ApiKey
+	err := am.DB.Where("key_hash = ?", identity.HashApiKey(key)).First(&apiKey).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, invalidApiKeyError
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !apiKey.Usable(now) {
+		return nil, invalidApiKeyError
+	}
+
+	// usage time is best effort, failure must not fail the request
+	err = am.DB.Model(&models.ApiKey{}).
+		Where("id = ? and (last_used_at is null or last_used_at < ?)", apiKey.Id, now.Add(-apiKeyUsageInterval)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		log.Printf("Error updating last used time of api key %s: %v\n", apiKey.Id, err)
+	}
+
+	return &tokenPayload{
+		UserRole: custom.SERVICEACCOUNT,
+		OrgId:    apiKey.OrgId.String(),
+		Email:    "api-key:" + apiKey.Prefix,
+		Sub:      apiKey.Id.String(),
+		ApiKey:   &apiKey,
+	}, nil
+}
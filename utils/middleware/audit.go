@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxAuditRequestSize is the largest json request body recorded in the audit log
+const maxAuditRequestSize = 64 << 10
+
+// auditEntityTable maps route params to the entity they identify
+var auditEntityTable = map[string]struct {
+	entity string
+	table  string
+}{
+	"tower":             {"tower", "towers"},
+	"towerId":           {"tower", "towers"},
+	"flat":              {"flat", "flats"},
+	"flatId":            {"flat", "flats"},
+	"saleId":            {"sale", "sales"},
+	"receiptId":         {"receipt", "receipts"},
+	"brokerId":          {"broker", "brokers"},
+	"bankId":            {"bank", "banks"},
+	"customerId":        {"customer", "customers"},
+	"milestoneId":       {"construction-milestone", "construction_milestones"},
+	"paymentPlanItemId": {"payment-plan-item", "payment_plan_ratio_items"},
+	"userEmail":         {"user", "users"},
+	"orgId":             {"organization", "organizations"},
+	"approvalId":        {"approval-request", "approval_requests"},
+	"roleId":            {"role", "roles"},
+	"apiKeyId":          {"api-key", "api_keys"},
+	"webhookId":         {"webhook", "webhook_endpoints"},
+	"deliveryId":        {"webhook-delivery", "webhook_deliveries"},
+	"notificationId":    {"notification", "notification_logs"},
+	"taskId":            {"collection-task", "collection_tasks"},
+	"jobId":             {"job", "jobs"},
+	"templateId":        {"report-template", "report_templates"},
+}
+
+// auditEntityChildren are rows of other tables recorded under a key of the entity state,
+// clearing a receipt inserts its clearance and does not change the receipt row
+var auditEntityChildren = map[string]struct {
+	key    string
+	table  string
+	column string
+}{
+	"receipts": {"cleared", "receipt_clears", "receipt_id"},
+}
+
+// auditSecretColumns are never recorded in entity state
+var auditSecretColumns = []string{"key_hash", "password_hash", "token_hash", "secret"}
+
+// auditEntity is the record affected by a request
+type auditEntity struct {
+	entity string
+	table  string
+	id     string
+}
+
+// AuditMiddleware records every mutating api call in the audit log
+type AuditMiddleware struct {
+	DB     *gorm.DB
+	Router *chi.Mux
+}
+
+// RecordRequest records POST, PUT, PATCH and DELETE requests with the state of the affected entity before and after the call
+// report endpoints use POST only to receive filters and are not recorded
+// every request made with an api key is recorded as key usage, without entity state for reads and reports
+func (am *AuditMiddleware) RecordRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKeyId, _ := r.Context().Value(custom.ApiKeyIdKey).(string)
+
+		mutating := false
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			mutating = true
+		}
+		if !mutating && apiKeyId == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		path := r.URL.Path
+		if len(path) > 1 {
+			path = strings.TrimSuffix(path, "/")
+		}
+
+		rctx := chi.NewRouteContext()
+		route := am.Router.Find(rctx, r.Method, path)
+		report := strings.HasSuffix(route, "/report")
+		if route == "" || (report && apiKeyId == "") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		snapshot := mutating && !report
+
+		orgId, _ := r.Context().Value(custom.OrganizationIDKey).(string)
+		entity := resolveAuditEntity(route, rctx.URLParams, orgId)
+		society := rctx.URLParam("society")
+
+		var request models.RawJSON
+		var before map[string]any
+		if snapshot {
+			request = readAuditRequest(r)
+			before = am.snapshot(entity, orgId)
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		var after map[string]any
+		if snapshot {
+			after = am.snapshot(entity, orgId)
+		}
+
+		role, _ := r.Context().Value(custom.UserRoleKey).(custom.UserRole)
+		email, _ := r.Context().Value(custom.UserEmailKey).(string)
+		sub, _ := r.Context().Value(custom.UserSubKey).(string)
+
+		auditLog := models.AuditLog{
+			SocietyId:  society,
+			ActorEmail: email,
+			ActorSub:   sub,
+			ActorRole:  string(role),
+			Method:     r.Method,
+			Route:      route,
+			Path:       path,
+			EntityType: entity.entity,
+			EntityId:   entity.id,
+			StatusCode: ww.Status(),
+			Request:    request,
+			Before:     marshalAuditState(before),
+			After:      marshalAuditState(after),
+		}
+		if id, err := uuid.Parse(orgId); err == nil {
+			auditLog.OrgId = &id
+		}
+		if id, err := uuid.Parse(apiKeyId); err == nil {
+			auditLog.ApiKeyId = &id
+		}
+		if before != nil || after != nil {
+			auditLog.Diff = marshalAuditState(models.DiffAuditState(before, after))
+		}
+
+		// audit failure must not fail the request, response is already written
+		if err := am.DB.Create(&auditLog).Error; err != nil {
+			log.Printf("Error recording audit log for %s %s: %v\n", r.Method, path, err)
+		}
+	})
+}
+
+// resolveAuditEntity returns the entity of the first id param in the route
+// routes without an id param are attributed to the society or organization they act on
+func resolveAuditEntity(route string, params chi.RouteParams, orgId string) auditEntity {
+	for i, key := range params.Keys {
+		mapping, ok := auditEntityTable[key]
+		if !ok {
+			continue
+		}
+
+		entity := auditEntity{entity: mapping.entity, table: mapping.table, id: params.Values[i]}
+		if key == "customerId" && strings.Contains(route, "/company-customer/") {
+			entity.entity, entity.table = "company-customer", "company_customers"
+		}
+		return entity
+	}
+
+	for i, key := range params.Keys {
+		if key == "society" {
+			return auditEntity{entity: "society", table: "societies", id: params.Values[i]}
+		}
+	}
+
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	if segments[0] == "organization" && orgId != "" {
+		return auditEntity{entity: "organization", table: "organizations", id: orgId}
+	}
+	return auditEntity{entity: segments[0]}
+}
+
+// snapshot returns the current row of the entity with its child rows, nil if entity has no id or no longer exists
+func (am *AuditMiddleware) snapshot(entity auditEntity, orgId string) map[string]any {
+	if entity.table == "" || entity.id == "" {
+		return nil
+	}
+
+	query := am.DB.Table(entity.table)
+	switch entity.table {
+	case "societies":
+		query = query.Where("rera_number = ? and org_id = ?", entity.id, orgId)
+	case "users":
+		query = query.Where("email = ?", entity.id)
+	default:
+		if _, err := uuid.Parse(entity.id); err != nil {
+			return nil
+		}
+		query = query.Where("id = ?", entity.id)
+	}
+
+	row := make(map[string]any)
+	result := query.Limit(1).Find(&row)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+
+	if child, ok := auditEntityChildren[entity.table]; ok {
+		childRow := make(map[string]any)
+		result := am.DB.Table(child.table).Where(child.column+" = ?", entity.id).Limit(1).Find(&childRow)
+		if result.Error == nil && result.RowsAffected > 0 {
+			row[child.key] = cleanAuditRow(childRow)
+		}
+	}
+	return cleanAuditRow(row)
+}
+
+// cleanAuditRow removes secret and binary columns of the row
+func cleanAuditRow(row map[string]any) map[string]any {
+	for _, column := range auditSecretColumns {
+		delete(row, column)
+	}
+	for key, value := range row {
+		// jsonb columns are read as raw bytes, binary columns are not recorded
+		if raw, ok := value.([]byte); ok {
+			if json.Valid(raw) {
+				row[key] = json.RawMessage(raw)
+			} else {
+				delete(row, key)
+			}
+		}
+	}
+	return row
+}
+
+// auditRequestBody replays the part of the body read for the audit log before the rest of the body
+type auditRequestBody struct {
+	io.Reader
+	io.Closer
+}
+
+// readAuditRequest returns the json request body and restores it for the handler
+// at most maxAuditRequestSize is buffered, a larger body is not recorded and streams to the handler
+func readAuditRequest(r *http.Request) models.RawJSON {
+	if r.Body == nil || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	prefix, err := io.ReadAll(io.LimitReader(r.Body, maxAuditRequestSize+1))
+	r.Body = auditRequestBody{Reader: io.MultiReader(bytes.NewReader(prefix), r.Body), Closer: r.Body}
+	if err != nil || len(prefix) > maxAuditRequestSize || !json.Valid(prefix) {
+		return nil
+	}
+	return prefix
+}
+
+func marshalAuditState(state any) models.RawJSON {
+	switch v := state.(type) {
+	case map[string]any:
+		if v == nil {
+			return nil
+		}
+	case map[string]models.AuditChange:
+		if len(v) == 0 {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	return data
+}
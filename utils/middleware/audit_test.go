@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadAuditRequest(t *testing.T) {
+	small := []byte(`{"name":"A"}`)
+	large := append([]byte(`{"name":"`), bytes.Repeat([]byte("a"), maxAuditRequestSize)...)
+	large = append(large, []byte(`"}`)...)
+
+	tests := []struct {
+		body     []byte
+		recorded bool
+	}{
+		{small, true},
+		{large, false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/json")
+
+		recorded := readAuditRequest(r)
+		if (recorded != nil) != test.recorded {
+			t.Errorf("body of %d bytes: want recorded %v, got %v", len(test.body), test.recorded, recorded != nil)
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(body, test.body) {
+			t.Errorf("body of %d bytes: handler read %d bytes", len(test.body), len(body))
+		}
+	}
+}
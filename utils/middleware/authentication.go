@@ -1,24 +1,31 @@
 package middleware
 
 import (
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/auth"
 	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/identity"
 	"circledigital.in/real-state-erp/utils/payload"
 	"context"
-	"github.com/MicahParks/keyfunc/v3"
-	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 	"net/http"
 	"strings"
 )
 
-// tokenPayload represents user related info from JWT
+// tokenPayload represents user related info from JWT or api key
 type tokenPayload struct {
 	UserRole custom.UserRole
 	OrgId    string
+	Email    string
+	Sub      string
+	ApiKey   *models.ApiKey
 }
 
-// AuthenticationMiddleware authenticates the incoming http request for JWT authentication
+// AuthenticationMiddleware authenticates the incoming http request with the configured auth provider or an api key
+// api keys are sent in X-API-Key header or as bearer token
 type AuthenticationMiddleware struct {
-	JWKS keyfunc.Keyfunc
+	Provider auth.Provider
+	DB       *gorm.DB
 }
 
 // AuthenticateRequest authenticates and adds values to request context
@@ -33,10 +40,16 @@ func (am *AuthenticationMiddleware) AuthenticateRequest(next http.Handler) http.
 		// add context values to request
 		reqContext := r.Context()
 		reqContext = context.WithValue(reqContext, custom.UserRoleKey, tokenPayloadObj.UserRole)
+		reqContext = context.WithValue(reqContext, custom.UserEmailKey, tokenPayloadObj.Email)
+		reqContext = context.WithValue(reqContext, custom.UserSubKey, tokenPayloadObj.Sub)
 
 		if tokenPayloadObj.OrgId != "" {
 			reqContext = context.WithValue(reqContext, custom.OrganizationIDKey, tokenPayloadObj.OrgId)
 		}
+		if tokenPayloadObj.ApiKey != nil {
+			reqContext = context.WithValue(reqContext, custom.ApiKeyIdKey, tokenPayloadObj.ApiKey.Id.String())
+			reqContext = context.WithValue(reqContext, custom.UserAccessKey, tokenPayloadObj.ApiKey.Access())
+		}
 		reqWithValues := r.WithContext(reqContext)
 		*r = *reqWithValues
 
@@ -46,6 +59,10 @@ func (am *AuthenticationMiddleware) AuthenticateRequest(next http.Handler) http.
 
 // parseAuthHeader parses the JWT and returns token payload relevant information
 func (am *AuthenticationMiddleware) parseAuthHeader(r *http.Request) (*tokenPayload, error) {
+	if apiKey := strings.TrimSpace(r.Header.Get("X-API-Key")); apiKey != "" {
+		return am.parseApiKey(apiKey)
+	}
+
 	// get auth header
 	authHeader := r.Header.Get("Authorization")
 	if strings.TrimSpace(authHeader) == "" {
@@ -70,43 +87,20 @@ func (am *AuthenticationMiddleware) parseAuthHeader(r *http.Request) (*tokenPayl
 		}
 	}
 
-	invalidTokenError := &custom.RequestError{
-		Status:  http.StatusUnauthorized,
-		Message: "Invalid token.",
-	}
-
-	// validate JWT
-	tokenString := authArray[1]
-	token, err := jwt.Parse(tokenString, am.JWKS.Keyfunc)
-	if err != nil || !token.Valid {
-		return nil, invalidTokenError
-	}
-
-	// get claims from token
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, invalidTokenError
+	if identity.IsApiKey(authArray[1]) {
+		return am.parseApiKey(authArray[1])
 	}
 
-	groups, ok := claims["cognito:groups"].([]interface{})
-	if !ok || len(groups) == 0 {
-		return nil, invalidTokenError
-	}
-
-	role, ok := groups[0].(string)
-	if !ok {
-		return nil, invalidTokenError
-	}
-
-	orgID := ""
-	if val, ok := claims[custom.OrgIdCustomAttribute]; ok {
-		if str, ok := val.(string); ok && str != "" {
-			orgID = str
-		}
+	// validate token with the auth provider
+	user, err := am.Provider.Authenticate(authArray[1])
+	if err != nil {
+		return nil, err
 	}
 
 	return &tokenPayload{
-		UserRole: custom.UserRole(role),
-		OrgId:    orgID,
+		UserRole: user.Role,
+		OrgId:    user.OrgId,
+		Email:    user.Email,
+		Sub:      user.Sub,
 	}, nil
-}
\ No newline at end of file
+}
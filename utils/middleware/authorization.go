@@ -3,6 +3,7 @@ package middleware
 import (
 	"circledigital.in/real-state-erp/utils/custom"
 	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/go-chi/chi/v5"
 	"net/http"
 	"strings"
 )
@@ -69,14 +70,38 @@ func (am *AuthorizationMiddleware) OrganizationAdminAndUserAndViewerAuthorizatio
 }
 
 // OrganizationAuthorization checks organization id for user
+// routes of a society also require the user to be member of the society
 func (am *AuthorizationMiddleware) OrganizationAuthorization(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		orgId := r.Context().Value(custom.OrganizationIDKey).(string)
+		orgId, _ := r.Context().Value(custom.OrganizationIDKey).(string)
 		if strings.TrimSpace(orgId) == "" {
 			payload.HandleError(w, unauthorizedError)
 			return
 		}
 
+		if society := chi.URLParam(r, "society"); society != "" {
+			access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+			if !access.CanAccessSociety(society) {
+				payload.HandleError(w, unauthorizedError)
+				return
+			}
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Permission protects routes requiring the permission, use with OrganizationAuthorization
+func (am *AuthorizationMiddleware) Permission(permission custom.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			access, _ := r.Context().Value(custom.UserAccessKey).(*custom.UserAccess)
+			if !access.Can(permission) {
+				payload.HandleError(w, unauthorizedError)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package notification
+
+import (
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Channels are the channels every notification is sent on
+var Channels = []custom.NotificationChannel{custom.CHANNEL_EMAIL, custom.CHANNEL_SMS}
+
+// Event is something the buyers of a sale are notified about
+type Event struct {
+	Kind   custom.NotificationKind
+	SaleId uuid.UUID
+	// Reference identifies what the event is about, like the receipt or payment plan item
+	Reference string
+	Receipt   *models.Receipt
+	Demand    *DemandData
+}
+
+// Enqueue renders the notification of the event for every owner of the sale and writes them to the log as pending
+// it runs in the transaction of the change the event describes so buyers are notified only if it commits
+// disabled templates and missing addresses send nothing, opted out addresses are logged as skipped
+func Enqueue(tx *gorm.DB, orgId string, event Event) error {
+	if event.Receipt != nil && event.Receipt.Mode == custom.ADJUSTMENT {
+		return nil
+	}
+
+	var sale models.Sale
+	err := tx.Preload("Customers").
+		Preload("Flat.Tower").
+		Preload("Society.Organization").
+		Preload("Receipts", "failed = ?", false).
+		Preload("Receipts.Cleared").
+		Where("id = ? and org_id = ?", event.SaleId, orgId).
+		First(&sale).Error
+	if err != nil {
+		return err
+	}
+	if len(sale.Customers) == 0 {
+		return nil
+	}
+
+	var overrides []models.NotificationTemplate
+	err = tx.Where("org_id = ? and kind = ?", orgId, event.Kind).Find(&overrides).Error
+	if err != nil {
+		return err
+	}
+	templates := make(map[custom.NotificationChannel]*models.NotificationTemplate)
+	for i := range overrides {
+		templates[overrides[i].Channel] = &overrides[i]
+	}
+
+	data := saleData(sale)
+	data.Receipt = receiptData(event.Receipt)
+	data.Demand = event.Demand
+
+	now := time.Now()
+	for _, channel := range Channels {
+		tmpl := DefaultTemplates[event.Kind][channel]
+		if override, ok := templates[channel]; ok {
+			if !override.Enabled {
+				continue
+			}
+			tmpl = Template{Subject: override.Subject, Body: override.Body}
+		}
+
+		for _, customer := range sale.Customers {
+			address := customer.Email
+			if channel == custom.CHANNEL_SMS {
+				address = customer.PhoneNumber
+			}
+			address = NormalizeAddress(channel, address)
+			if address == "" {
+				continue
+			}
+
+			recipient := Recipient{OrgId: sale.OrgId, Channel: channel, Address: address}
+			data.CustomerName = customerName(customer)
+			data.UnsubscribeUrl = unsubscribeUrl(recipient)
+
+			notification := models.NotificationLog{
+				OrgId:      sale.OrgId,
+				SocietyId:  sale.SocietyId,
+				SaleId:     &sale.Id,
+				CustomerId: &customer.Id,
+				Kind:       event.Kind,
+				Channel:    channel,
+				Reference:  event.Reference,
+				Recipient:  address,
+				Status:     custom.NOTIFICATION_PENDING,
+			}
+
+			// a bad template must not fail the change being notified, the render error is logged instead
+			rendered, err := Render(tmpl, data)
+			if err != nil {
+				notification.Status = custom.NOTIFICATION_FAILED
+				notification.Error = err.Error()
+			} else {
+				notification.Subject = rendered.Subject
+				notification.Body = rendered.Body
+			}
+
+			optedOut, err := isOptedOut(tx, recipient)
+			if err != nil {
+				return err
+			}
+			switch {
+			case notification.Status == custom.NOTIFICATION_FAILED:
+			case optedOut:
+				notification.Status = custom.NOTIFICATION_SKIPPED
+				notification.Error = "recipient opted out"
+			default:
+				notification.NextAttemptAt = &now
+			}
+
+			if err := tx.Create(&notification).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// EnqueueDemandDue notifies the buyers of the flats who are on the plan of the activated item and have a balance
+// flats is a subquery or list of flat ids
+func EnqueueDemandDue(tx *gorm.DB, orgId, paymentId string, activation models.PaymentActivation, flats any) error {
+	var item models.PaymentPlanRatioItem
+	if err := tx.First(&item, "id = ?", paymentId).Error; err != nil {
+		return err
+	}
+
+	var sales []models.Sale
+	err := tx.Preload("Receipts", "failed = ?", false).
+		Preload("Receipts.Cleared").
+		Where("org_id = ? and payment_plan_ratio_id = ? and flat_id IN (?)", orgId, item.PaymentPlanRatioId, flats).
+		Find(&sales).Error
+	if err != nil {
+		return err
+	}
+
+	for _, sale := range sales {
+		if !sale.Pending().IsPositive() {
+			continue
+		}
+		amount := item.GetAmountDetails(sale.GetTotalPayableAmount(), decimal.Zero)
+		if amount == nil {
+			continue
+		}
+
+		err := Enqueue(tx, orgId, Event{
+			Kind:      custom.NOTIFY_DEMAND_DUE,
+			SaleId:    sale.Id,
+			Reference: paymentId,
+			Demand: &DemandData{
+				Description: item.Description,
+				Ratio:       item.Ratio,
+				Amount:      amount.Total.StringFixed(2),
+				DueDate:     activation.DueDate.Format("02-01-2006"),
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isOptedOut(tx *gorm.DB, recipient Recipient) (bool, error) {
+	var count int64
+	err := tx.Model(&models.NotificationOptOut{}).
+		Where("org_id = ? and channel = ? and address = ?", recipient.OrgId, recipient.Channel, recipient.Address).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// saleData returns the template data of the sale, customer and notification specific fields are left blank
+func saleData(sale models.Sale) Data {
+	data := Data{
+		SaleNumber: sale.SaleNumber,
+		TotalPrice: sale.TotalPrice.StringFixed(2),
+		Paid:       sale.PaidAmount().StringFixed(2),
+		Pending:    sale.Pending().StringFixed(2),
+	}
+	if sale.Society != nil {
+		data.Society = sale.Society.Name
+		data.SocietyAddress = sale.Society.Address
+		if sale.Society.Organization != nil {
+			data.Organization = sale.Society.Organization.Name
+		}
+	}
+	if sale.Flat != nil {
+		data.Flat = sale.Flat.Name
+		if sale.Flat.Tower != nil {
+			data.Tower = sale.Flat.Tower.Name
+		}
+	}
+	return data
+}
+
+func receiptData(receipt *models.Receipt) *ReceiptData {
+	if receipt == nil {
+		return nil
+	}
+
+	data := &ReceiptData{
+		Number:            receipt.ReceiptNumber,
+		Amount:            receipt.TotalAmount.StringFixed(2),
+		Mode:              string(receipt.Mode),
+		BankName:          receipt.BankName,
+		TransactionNumber: receipt.TransactionNumber,
+		Cleared:           receipt.Cleared != nil,
+	}
+	if receipt.DateIssued.Valid {
+		data.Date = receipt.DateIssued.Time.Format("02-01-2006")
+	}
+	return data
+}
+
+func customerName(customer models.Customer) string {
+	return strings.Join(strings.Fields(
+		string(customer.Salutation)+" "+customer.FirstName+" "+customer.MiddleName+" "+customer.LastName,
+	), " ")
+}
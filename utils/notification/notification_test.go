@@ -0,0 +1,87 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+func TestDefaultTemplatesRender(t *testing.T) {
+	for _, kind := range custom.AllNotificationKinds {
+		for _, channel := range Channels {
+			tmpl, ok := DefaultTemplates[kind][channel]
+			if !ok {
+				t.Errorf("missing default template for %s %s", kind, channel)
+				continue
+			}
+			if err := Validate(channel, tmpl); err != nil {
+				t.Errorf("default template %s %s: %v", kind, channel, err)
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := SampleData
+	data.UnsubscribeUrl = ""
+
+	got, err := Render(Template{
+		Subject: "Receipt\n{{.Receipt.Number}}",
+		Body:    "Dear {{.CustomerName}}{{if .UnsubscribeUrl}} {{.UnsubscribeUrl}}{{end}}\n",
+	}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Subject != "Receipt R-0001" {
+		t.Errorf("subject = %q", got.Subject)
+	}
+	if got.Body != "Dear Mr. Sample Buyer" {
+		t.Errorf("body = %q", got.Body)
+	}
+
+	if _, err := Render(Template{Body: "{{.Unknown}}"}, data); err == nil {
+		t.Error("want error for unknown field")
+	}
+	if err := Validate(custom.CHANNEL_EMAIL, Template{Body: "hello"}); err == nil {
+		t.Error("want error for email without subject")
+	}
+}
+
+func TestUnsubscribeToken(t *testing.T) {
+	recipient := Recipient{
+		OrgId:   uuid.New(),
+		Channel: custom.CHANNEL_EMAIL,
+		Address: NormalizeAddress(custom.CHANNEL_EMAIL, " Buyer@Example.com "),
+	}
+	if recipient.Address != "buyer@example.com" {
+		t.Fatalf("address = %q", recipient.Address)
+	}
+
+	token := UnsubscribeToken("secret", recipient)
+	got, err := ParseUnsubscribeToken("secret", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != recipient {
+		t.Errorf("got %+v, want %+v", got, recipient)
+	}
+
+	if _, err := ParseUnsubscribeToken("other", token); err == nil {
+		t.Error("want error for other secret")
+	}
+	if _, err := ParseUnsubscribeToken("", token); err == nil {
+		t.Error("want error without secret")
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+	if _, err := ParseUnsubscribeToken("secret", payload+"x."+signature); err == nil {
+		t.Error("want error for tampered payload")
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	if got := NormalizeAddress(custom.CHANNEL_SMS, " +91 98765 43210 "); got != "+919876543210" {
+		t.Errorf("phone = %q", got)
+	}
+}
@@ -0,0 +1,128 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/lease"
+	"gorm.io/gorm"
+)
+
+const (
+	pollInterval = 10 * time.Second
+	// MaxAttempts is the number of attempts of a notification before it is marked failed
+	MaxAttempts = 5
+	sendTimeout = 30 * time.Second
+)
+
+// notifications are the due notifications, a lease outlasts many sends of sendTimeout
+var notifications = lease.Queue{
+	Model:          &models.NotificationLog{},
+	Pending:        custom.NOTIFICATION_PENDING,
+	BatchSize:      50,
+	Lease:          2 * time.Minute,
+	RetryBaseDelay: time.Minute,
+}
+
+// Sender sends the pending notifications through the transport of their channel
+// rows are claimed with skip locked so several instances can run the sender
+type Sender struct {
+	db         *gorm.DB
+	transports map[custom.NotificationChannel]Transport
+}
+
+func NewSender(db *gorm.DB, transports map[custom.NotificationChannel]Transport) *Sender {
+	return &Sender{db: db, transports: transports}
+}
+
+// Run sends notifications until the context is cancelled
+func (s *Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.sendDue(ctx); err != nil {
+			log.Printf("Error sending notifications: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendDue claims the due notifications and sends them one after another,
+// the lease of every notification is renewed just before it is sent as the batch may outlast the lease of the claim
+func (s *Sender) sendDue(ctx context.Context) error {
+	ids, leasedUntil, err := notifications.Claim(s.db)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	var claimed []models.NotificationLog
+	err = s.db.Where("id IN ?", ids).Find(&claimed).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range claimed {
+		renewed, err := notifications.Renew(s.db, claimed[i].Id, leasedUntil)
+		if err != nil {
+			return err
+		}
+		if !renewed {
+			continue
+		}
+		if err := s.send(ctx, &claimed[i]); err != nil {
+			log.Printf("Error recording notification %s: %v\n", claimed[i].Id, err)
+		}
+	}
+	return nil
+}
+
+// send makes one attempt and records its result, failures are retried with backoff until MaxAttempts
+func (s *Sender) send(ctx context.Context, notification *models.NotificationLog) error {
+	now := time.Now()
+	updates := map[string]any{
+		"attempts": notification.Attempts + 1,
+		"error":    "",
+	}
+
+	transport, ok := s.transports[notification.Channel]
+	var err error
+	if !ok {
+		err = fmt.Errorf("no transport configured for %s", notification.Channel)
+	} else {
+		updates["transport"] = transport.Name()
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err = transport.Send(sendCtx, Message{
+			Channel: notification.Channel,
+			To:      notification.Recipient,
+			Subject: notification.Subject,
+			Body:    notification.Body,
+		})
+		cancel()
+	}
+
+	switch {
+	case err == nil:
+		updates["status"] = custom.NOTIFICATION_SENT
+		updates["sent_at"] = now
+		updates["next_attempt_at"] = nil
+	case !ok || notification.Attempts+1 >= MaxAttempts:
+		updates["status"] = custom.NOTIFICATION_FAILED
+		updates["next_attempt_at"] = nil
+		updates["error"] = err.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(notifications.RetryDelay(notification.Attempts + 1))
+		updates["error"] = err.Error()
+	}
+
+	return s.db.Model(&models.NotificationLog{}).Where("id = ?", notification.Id).Updates(updates).Error
+}
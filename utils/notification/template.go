@@ -0,0 +1,235 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+// package notification renders and sends email and sms notifications to the buyers of a sale
+
+// Template is the subject and body of a notification, subject is used only by email
+type Template struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// ReceiptData is the receipt a notification is about
+type ReceiptData struct {
+	Number            string
+	Amount            string
+	Mode              string
+	Date              string
+	BankName          string
+	TransactionNumber string
+	Cleared           bool
+}
+
+// DemandData is the payment plan item a notification is about
+// DaysOverdue is negative before the due date
+type DemandData struct {
+	Description string
+	Ratio       string
+	Amount      string
+	DueDate     string
+	DaysOverdue int
+}
+
+// Data is available to templates, Receipt and Demand are set only for the notifications about them
+type Data struct {
+	Organization   string
+	Society        string
+	SocietyAddress string
+	Tower          string
+	Flat           string
+	SaleNumber     string
+	CustomerName   string
+	TotalPrice     string
+	Paid           string
+	Pending        string
+	Receipt        *ReceiptData
+	Demand         *DemandData
+	UnsubscribeUrl string
+}
+
+// SampleData is used to check templates before they are saved
+var SampleData = Data{
+	Organization:   "Sample Developers",
+	Society:        "Sample Heights",
+	SocietyAddress: "Sector 1, Sample City",
+	Tower:          "A",
+	Flat:           "A-101",
+	SaleNumber:     "S-0001",
+	CustomerName:   "Mr. Sample Buyer",
+	TotalPrice:     "5000000.00",
+	Paid:           "1000000.00",
+	Pending:        "4000000.00",
+	Receipt: &ReceiptData{
+		Number:            "R-0001",
+		Amount:            "500000.00",
+		Mode:              "cheque",
+		Date:              "01-01-2024",
+		BankName:          "Sample Bank",
+		TransactionNumber: "000123",
+		Cleared:           true,
+	},
+	Demand: &DemandData{
+		Description: "On completion of plinth",
+		Ratio:       "10",
+		Amount:      "500000.00",
+		DueDate:     "31-01-2024",
+		DaysOverdue: 0,
+	},
+	UnsubscribeUrl: "https://example.com/unsubscribe?token=sample",
+}
+
+// DefaultTemplates are used by organizations that have not customized a notification
+var DefaultTemplates = map[custom.NotificationKind]map[custom.NotificationChannel]Template{
+	custom.NOTIFY_RECEIPT_ACKNOWLEDGEMENT: {
+		custom.CHANNEL_EMAIL: {
+			Subject: "Payment {{if .Receipt.Cleared}}realised{{else}}received{{end}} - {{.Flat}}, {{.Society}}",
+			Body: `Dear {{.CustomerName}},
+
+We acknowledge {{if .Receipt.Cleared}}the realisation of{{else}}the receipt of{{end}} Rs. {{.Receipt.Amount}} by {{.Receipt.Mode}} dated {{.Receipt.Date}} (receipt {{.Receipt.Number}}) towards flat {{.Flat}}, {{.Society}}.
+
+Total paid: Rs. {{.Paid}}
+Balance: Rs. {{.Pending}}
+
+Regards,
+{{.Organization}}
+{{if .UnsubscribeUrl}}
+To stop receiving these emails: {{.UnsubscribeUrl}}{{end}}`,
+		},
+		custom.CHANNEL_SMS: {
+			Body: `Dear {{.CustomerName}}, Rs. {{.Receipt.Amount}} {{if .Receipt.Cleared}}realised{{else}}received{{end}} against receipt {{.Receipt.Number}} for flat {{.Flat}}, {{.Society}}. - {{.Organization}}`,
+		},
+	},
+	custom.NOTIFY_CHEQUE_BOUNCE: {
+		custom.CHANNEL_EMAIL: {
+			Subject: "Payment not realised - {{.Flat}}, {{.Society}}",
+			Body: `Dear {{.CustomerName}},
+
+Your payment of Rs. {{.Receipt.Amount}} by {{.Receipt.Mode}}{{if .Receipt.TransactionNumber}} no. {{.Receipt.TransactionNumber}}{{end}} dated {{.Receipt.Date}} (receipt {{.Receipt.Number}}) towards flat {{.Flat}}, {{.Society}} could not be realised.
+
+Please arrange the payment at the earliest. Balance: Rs. {{.Pending}}
+
+Regards,
+{{.Organization}}
+{{if .UnsubscribeUrl}}
+To stop receiving these emails: {{.UnsubscribeUrl}}{{end}}`,
+		},
+		custom.CHANNEL_SMS: {
+			Body: `Dear {{.CustomerName}}, your payment of Rs. {{.Receipt.Amount}} (receipt {{.Receipt.Number}}) for flat {{.Flat}}, {{.Society}} could not be realised. Please pay at the earliest. - {{.Organization}}`,
+		},
+	},
+	custom.NOTIFY_DEMAND_DUE: {
+		custom.CHANNEL_EMAIL: {
+			Subject: "Payment demand - {{.Demand.Description}} - {{.Flat}}, {{.Society}}",
+			Body: `Dear {{.CustomerName}},
+
+The instalment "{{.Demand.Description}}" ({{.Demand.Ratio}}%) for flat {{.Flat}}, {{.Society}} is now payable.
+
+Amount: Rs. {{.Demand.Amount}}
+Due date: {{.Demand.DueDate}}
+Total balance: Rs. {{.Pending}}
+
+Regards,
+{{.Organization}}
+{{if .UnsubscribeUrl}}
+To stop receiving these emails: {{.UnsubscribeUrl}}{{end}}`,
+		},
+		custom.CHANNEL_SMS: {
+			Body: `Dear {{.CustomerName}}, instalment "{{.Demand.Description}}" of Rs. {{.Demand.Amount}} for flat {{.Flat}}, {{.Society}} is due on {{.Demand.DueDate}}. - {{.Organization}}`,
+		},
+	},
+	custom.NOTIFY_PAYMENT_REMINDER: {
+		custom.CHANNEL_EMAIL: {
+			Subject: "Payment reminder - {{.Demand.Description}} - {{.Flat}}, {{.Society}}",
+			Body: `Dear {{.CustomerName}},
+
+{{if lt .Demand.DaysOverdue 0}}This is a reminder that the instalment "{{.Demand.Description}}" of Rs. {{.Demand.Amount}} for flat {{.Flat}}, {{.Society}} is due on {{.Demand.DueDate}}.{{else if eq .Demand.DaysOverdue 0}}The instalment "{{.Demand.Description}}" of Rs. {{.Demand.Amount}} for flat {{.Flat}}, {{.Society}} is due today.{{else}}The instalment "{{.Demand.Description}}" of Rs. {{.Demand.Amount}} for flat {{.Flat}}, {{.Society}} was due on {{.Demand.DueDate}} and is overdue by {{.Demand.DaysOverdue}} days.{{end}}
+
+Total balance: Rs. {{.Pending}}
+
+Please ignore this message if the payment has already been made.
+
+Regards,
+{{.Organization}}
+{{if .UnsubscribeUrl}}
+To stop receiving these emails: {{.UnsubscribeUrl}}{{end}}`,
+		},
+		custom.CHANNEL_SMS: {
+			Body: `Dear {{.CustomerName}}, instalment "{{.Demand.Description}}" of Rs. {{.Demand.Amount}} for flat {{.Flat}}, {{.Society}} {{if gt .Demand.DaysOverdue 0}}is overdue since{{else}}is due on{{end}} {{.Demand.DueDate}}. Please ignore if paid. - {{.Organization}}`,
+		},
+	},
+	custom.NOTIFY_BOOKING_WELCOME: {
+		custom.CHANNEL_EMAIL: {
+			Subject: "Welcome to {{.Society}} - booking {{.SaleNumber}}",
+			Body: `Dear {{.CustomerName}},
+
+Thank you for booking flat {{.Flat}}{{if .Tower}} in tower {{.Tower}}{{end}} at {{.Society}}, {{.SocietyAddress}}.
+
+Booking number: {{.SaleNumber}}
+Total price: Rs. {{.TotalPrice}}
+
+We will keep you updated on your payments by email and sms.
+
+Regards,
+{{.Organization}}
+{{if .UnsubscribeUrl}}
+To stop receiving these emails: {{.UnsubscribeUrl}}{{end}}`,
+		},
+		custom.CHANNEL_SMS: {
+			Body: `Dear {{.CustomerName}}, welcome to {{.Society}}! Your booking {{.SaleNumber}} for flat {{.Flat}} is confirmed. - {{.Organization}}`,
+		},
+	},
+}
+
+// Render renders the template with the data, the subject is rendered on a single line
+func Render(tmpl Template, data Data) (Template, error) {
+	subject, err := renderText("subject", tmpl.Subject, data)
+	if err != nil {
+		return Template{}, err
+	}
+	body, err := renderText("body", tmpl.Body, data)
+	if err != nil {
+		return Template{}, err
+	}
+
+	return Template{
+		Subject: strings.Join(strings.Fields(subject), " "),
+		Body:    strings.TrimSpace(body),
+	}, nil
+}
+
+// Validate parses the template and renders it with the sample data
+func Validate(channel custom.NotificationChannel, tmpl Template) error {
+	if strings.TrimSpace(tmpl.Body) == "" {
+		return fmt.Errorf("body is required")
+	}
+	if channel == custom.CHANNEL_EMAIL && strings.TrimSpace(tmpl.Subject) == "" {
+		return fmt.Errorf("subject is required for email")
+	}
+	_, err := Render(tmpl, SampleData)
+	return err
+}
+
+func renderText(name, text string, data Data) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
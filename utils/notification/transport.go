@@ -0,0 +1,179 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+)
+
+// Message is a rendered notification handed to a transport
+type Message struct {
+	Channel custom.NotificationChannel `json:"channel"`
+	To      string                     `json:"to"`
+	Subject string                     `json:"subject,omitempty"`
+	Body    string                     `json:"body"`
+}
+
+// Transport sends messages of a channel
+type Transport interface {
+	Name() string
+	Send(ctx context.Context, message Message) error
+}
+
+// SMTPConfig is the mail server email notifications are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpTransport struct {
+	config SMTPConfig
+	from   *mail.Address
+}
+
+// NewSMTPTransport sends email through the smtp server, starttls is used when the server supports it
+func NewSMTPTransport(config SMTPConfig) (Transport, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, fmt.Errorf("smtp host and from address are required")
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	return &smtpTransport{config: config, from: from}, nil
+}
+
+func (t *smtpTransport) Name() string {
+	return "smtp"
+}
+
+func (t *smtpTransport) Send(_ context.Context, message Message) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", t.from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	buf.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if t.config.Username != "" {
+		auth = smtp.PlainAuth("", t.config.Username, t.config.Password, t.config.Host)
+	}
+	addr := net.JoinHostPort(t.config.Host, t.config.Port)
+	return smtp.SendMail(addr, auth, t.from.Address, []string{to.Address}, buf.Bytes())
+}
+
+// SMSGatewayConfig is the http gateway sms notifications are sent through
+type SMSGatewayConfig struct {
+	Url      string
+	Token    string
+	SenderId string
+}
+
+type smsGatewayTransport struct {
+	config SMSGatewayConfig
+	client *http.Client
+}
+
+// NewSMSGatewayTransport posts every sms as json {to, from, message} to the gateway with the token as bearer
+func NewSMSGatewayTransport(config SMSGatewayConfig) (Transport, error) {
+	if config.Url == "" {
+		return nil, fmt.Errorf("sms gateway url is required")
+	}
+	return &smsGatewayTransport{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (t *smsGatewayTransport) Name() string {
+	return "sms-gateway"
+}
+
+func (t *smsGatewayTransport) Send(ctx context.Context, message Message) error {
+	body, err := json.Marshal(map[string]string{
+		"to":      message.To,
+		"from":    t.config.SenderId,
+		"message": message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.config.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.config.Token)
+	}
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		resBody, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("sms gateway responded with status %d: %s", res.StatusCode, strings.TrimSpace(string(resBody)))
+	}
+	return nil
+}
+
+type logTransport struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// NewLogTransport writes every message as a json line to the writer instead of sending it, meant for development
+func NewLogTransport(writer io.Writer) Transport {
+	return &logTransport{writer: writer}
+}
+
+func (t *logTransport) Name() string {
+	return "log"
+}
+
+func (t *logTransport) Send(_ context.Context, message Message) error {
+	line, err := json.Marshal(struct {
+		Message
+		Time time.Time `json:"time"`
+	}{message, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err = t.writer.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,103 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+)
+
+// Config is the configuration of the notifications rendered by the application
+type Config struct {
+	// UnsubscribeUrl is the public url of the unsubscribe endpoint, the token is added as query param
+	UnsubscribeUrl string
+	// Secret signs the unsubscribe tokens, links are left out of messages when not set
+	Secret string
+}
+
+var config Config
+
+// Configure sets the configuration used when notifications are rendered
+func Configure(c Config) {
+	config = c
+}
+
+// Recipient is an address of an organization that can opt out of notifications
+type Recipient struct {
+	OrgId   uuid.UUID
+	Channel custom.NotificationChannel
+	Address string
+}
+
+// NormalizeAddress returns the address in the form it is stored in opt outs
+func NormalizeAddress(channel custom.NotificationChannel, address string) string {
+	address = strings.TrimSpace(address)
+	if channel == custom.CHANNEL_EMAIL {
+		return strings.ToLower(address)
+	}
+	return strings.Join(strings.Fields(address), "")
+}
+
+// UnsubscribeToken returns the signed token of the recipient
+func UnsubscribeToken(secret string, recipient Recipient) string {
+	payload := base64.RawURLEncoding.EncodeToString(
+		[]byte(recipient.OrgId.String() + "|" + string(recipient.Channel) + "|" + recipient.Address),
+	)
+	return payload + "." + signUnsubscribe(secret, payload)
+}
+
+// ParseUnsubscribeToken verifies the token and returns its recipient
+func ParseUnsubscribeToken(secret, token string) (Recipient, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if secret == "" || !ok || !hmac.Equal([]byte(signature), []byte(signUnsubscribe(secret, payload))) {
+		return Recipient{}, fmt.Errorf("invalid unsubscribe token")
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Recipient{}, fmt.Errorf("invalid unsubscribe token")
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return Recipient{}, fmt.Errorf("invalid unsubscribe token")
+	}
+	orgId, err := uuid.Parse(parts[0])
+	channel := custom.NotificationChannel(parts[1])
+	if err != nil || !channel.IsValid() || parts[2] == "" {
+		return Recipient{}, fmt.Errorf("invalid unsubscribe token")
+	}
+
+	return Recipient{OrgId: orgId, Channel: channel, Address: parts[2]}, nil
+}
+
+// Secret returns the secret unsubscribe tokens are signed with
+func Secret() string {
+	return config.Secret
+}
+
+// unsubscribeUrl returns the unsubscribe link of the recipient, blank when links are not configured
+func unsubscribeUrl(recipient Recipient) string {
+	if config.UnsubscribeUrl == "" || config.Secret == "" {
+		return ""
+	}
+
+	link, err := url.Parse(config.UnsubscribeUrl)
+	if err != nil {
+		return ""
+	}
+	query := link.Query()
+	query.Set("token", UnsubscribeToken(config.Secret, recipient))
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+func signUnsubscribe(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("unsubscribe." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,250 @@
+package reminder
+
+import (
+	"fmt"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/notification"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// package reminder reminds buyers of unpaid payment plan items and escalates long overdue items
+
+// Reminder is a stage reached by an unpaid payment plan item of a sale on a date
+type Reminder struct {
+	SaleId        uuid.UUID       `json:"saleId"`
+	SaleNumber    string          `json:"saleNumber"`
+	Flat          string          `json:"flat"`
+	Owners        []string        `json:"owners"`
+	PaymentItemId uuid.UUID       `json:"paymentItemId"`
+	Description   string          `json:"description"`
+	DueDate       custom.DateOnly `json:"dueDate"`
+	Days          int             `json:"days"`
+	DaysOverdue   int             `json:"daysOverdue"`
+	Amount        decimal.Decimal `json:"amount"`
+	Notify        bool            `json:"notify"`
+	Escalate      bool            `json:"escalate"`
+}
+
+// reminderKey identifies a stage of an item, same as the unique index of payment reminders
+type reminderKey struct {
+	saleId  uuid.UUID
+	itemId  uuid.UUID
+	dueDate string
+	days    int
+}
+
+// Engine finds and records the reminders of societies
+type Engine struct {
+	db *gorm.DB
+}
+
+func NewEngine(db *gorm.DB) *Engine {
+	return &Engine{db: db}
+}
+
+// Policy returns the reminder policy of the society, default stages when the society has none
+func Policy(db *gorm.DB, orgId uuid.UUID, society string) (*models.PaymentReminderPolicy, error) {
+	policy := models.PaymentReminderPolicy{OrgId: orgId, SocietyId: society}
+	result := db.Where("org_id = ? and society_id = ?", orgId, society).Limit(1).Find(&policy)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		policy.Enabled = true
+		policy.Stages = models.DefaultReminderStages
+	}
+	return &policy, nil
+}
+
+// Plan returns the reminders of the society on date that have not run yet
+// items are active and unpaid as of date, payments are counted as received till now
+func (e *Engine) Plan(orgId uuid.UUID, society string, date time.Time) ([]Reminder, error) {
+	policy, err := Policy(e.db, orgId, society)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.Enabled || len(policy.Stages) == 0 {
+		return []Reminder{}, nil
+	}
+
+	var sales []models.Sale
+	err = e.db.
+		Preload("Customers").
+		Preload("PaymentPlanRatio").
+		Preload("PaymentPlanRatio.Ratios", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Receipts").
+		Preload("Receipts.Cleared").
+		Preload("Allotment").
+		Preload("Flat").
+		Preload("Flat.ActivePaymentPlanRatioItems").
+		Preload("Flat.Tower").
+		Preload("Flat.Tower.ActivePaymentPlanRatioItems").
+		Where("org_id = ? and society_id = ?", orgId, society).
+		Find(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded []models.PaymentReminder
+	err = e.db.Select("sale_id", "payment_item_id", "due_date", "days").
+		Where("org_id = ? and society_id = ?", orgId, society).
+		Find(&recorded).Error
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[reminderKey]bool, len(recorded))
+	for _, r := range recorded {
+		done[reminderKey{r.SaleId, r.PaymentItemId, r.DueDate.Format("2006-01-02"), r.Days}] = true
+	}
+
+	reminders := make([]Reminder, 0)
+	for i := range sales {
+		for _, reminder := range saleReminders(&sales[i], policy.Stages, date) {
+			key := reminderKey{reminder.SaleId, reminder.PaymentItemId, reminder.DueDate.Format("2006-01-02"), reminder.Days}
+			if !done[key] {
+				reminders = append(reminders, reminder)
+			}
+		}
+	}
+	return reminders, nil
+}
+
+// saleReminders returns the current stage of every active item of the sale with a remaining balance
+func saleReminders(sale *models.Sale, stages models.ReminderStages, date time.Time) []Reminder {
+	var activeFlatPaymentPlans []models.FlatPaymentStatus
+	var activeTowerPaymentPlans []models.TowerPaymentStatus
+	flat := ""
+	if sale.Flat != nil {
+		flat = sale.Flat.Name
+		activeFlatPaymentPlans = sale.Flat.ActivePaymentPlanRatioItems
+		if sale.Flat.Tower != nil {
+			activeTowerPaymentPlans = sale.Flat.Tower.ActivePaymentPlanRatioItems
+		}
+	}
+
+	activationCtx := models.NewPaymentActivationContext(date, sale, activeFlatPaymentPlans, activeTowerPaymentPlans)
+	breakDown := sale.GetPaymentPlanBreakDown(activationCtx)
+
+	var reminders []Reminder
+	for _, detail := range breakDown.Details {
+		if detail.Activation == nil || detail.Finance == nil || !detail.Finance.Remaining.IsPositive() {
+			continue
+		}
+
+		dueDate := detail.Activation.DueDate
+		stage := stages.Current(dueDate.Time, date)
+		if stage == nil {
+			continue
+		}
+
+		owners := make([]string, 0, len(sale.Customers))
+		for _, customer := range sale.Customers {
+			owners = append(owners, customer.FirstName+" "+customer.LastName)
+		}
+
+		reminders = append(reminders, Reminder{
+			SaleId:        sale.Id,
+			SaleNumber:    sale.SaleNumber,
+			Flat:          flat,
+			Owners:        owners,
+			PaymentItemId: detail.Item.Id,
+			Description:   detail.Item.Description,
+			DueDate:       dueDate,
+			Days:          stage.Days,
+			DaysOverdue:   models.DaysBetween(dueDate.Time, date),
+			Amount:        detail.Finance.Remaining,
+			Notify:        stage.Notify,
+			Escalate:      stage.Escalate,
+		})
+	}
+	return reminders
+}
+
+// RunSociety records the reminders of the society on date, notifies the buyers and opens collection tasks
+// every reminder is recorded in its own transaction, a reminder recorded by another run is skipped
+func (e *Engine) RunSociety(orgId uuid.UUID, society string, date time.Time) (int, error) {
+	reminders, err := e.Plan(orgId, society, date)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, reminder := range reminders {
+		recorded := false
+		err := e.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			recorded, err = record(tx, orgId, society, reminder)
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("sale %s item %s: %w", reminder.SaleNumber, reminder.PaymentItemId, err)
+		}
+		if recorded {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// record writes the reminder with its notification and task, false if the reminder was already recorded
+func record(tx *gorm.DB, orgId uuid.UUID, society string, reminder Reminder) (bool, error) {
+	model := models.PaymentReminder{
+		OrgId:         orgId,
+		SocietyId:     society,
+		SaleId:        reminder.SaleId,
+		PaymentItemId: reminder.PaymentItemId,
+		DueDate:       reminder.DueDate,
+		Days:          reminder.Days,
+		Amount:        reminder.Amount,
+		Notified:      reminder.Notify,
+		Escalated:     reminder.Escalate,
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	if reminder.Notify {
+		err := notification.Enqueue(tx, orgId.String(), notification.Event{
+			Kind:      custom.NOTIFY_PAYMENT_REMINDER,
+			SaleId:    reminder.SaleId,
+			Reference: fmt.Sprintf("%s:%d", reminder.PaymentItemId, reminder.Days),
+			Demand: &notification.DemandData{
+				Description: reminder.Description,
+				Amount:      reminder.Amount.StringFixed(2),
+				DueDate:     reminder.DueDate.Format("02-01-2006"),
+				DaysOverdue: reminder.DaysOverdue,
+			},
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if reminder.Escalate {
+		err := tx.Create(&models.CollectionTask{
+			OrgId:         orgId,
+			SocietyId:     society,
+			SaleId:        reminder.SaleId,
+			ReminderId:    model.Id,
+			PaymentItemId: reminder.PaymentItemId,
+			Description:   reminder.Description,
+			DueDate:       reminder.DueDate,
+			DaysOverdue:   reminder.DaysOverdue,
+			Amount:        reminder.Amount,
+			Status:        custom.TASK_OPEN,
+		}).Error
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
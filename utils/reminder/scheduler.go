@@ -0,0 +1,63 @@
+package reminder
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+)
+
+const checkInterval = 15 * time.Minute
+
+// Scheduler runs the reminders of every society once a day after the run hour
+// reminders are recorded once so restarts and several instances do not remind twice
+type Scheduler struct {
+	engine  *Engine
+	runHour int
+	lastRun string
+}
+
+func NewScheduler(engine *Engine, runHour int) *Scheduler {
+	return &Scheduler{engine: engine, runHour: runHour}
+}
+
+// Run checks for the daily run until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if today := now.Format("2006-01-02"); today != s.lastRun && now.Hour() >= s.runHour {
+			s.runAll(now)
+			s.lastRun = today
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runAll runs the reminders of every society, a failing society does not stop the others
+func (s *Scheduler) runAll(date time.Time) {
+	var societies []models.Society
+	err := s.engine.db.Select("rera_number", "org_id").Find(&societies).Error
+	if err != nil {
+		log.Printf("Error loading societies for payment reminders: %v\n", err)
+		return
+	}
+
+	for _, society := range societies {
+		count, err := s.engine.RunSociety(society.OrgId, society.ReraNumber, date)
+		if err != nil {
+			log.Printf("Error running payment reminders of society %s: %v\n", society.ReraNumber, err)
+		}
+		if count > 0 {
+			log.Printf("Queued %d payment reminders of society %s\n", count, society.ReraNumber)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package tabular
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/shopspring/decimal"
+)
+
+// package tabular writes reports as xlsx, csv, json or ndjson from a single row model so the formats never disagree
+
+// Format is an output format of a report
+type Format string
+
+const (
+	XLSX   Format = "xlsx"
+	CSV    Format = "csv"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+)
+
+var contentTypes = map[Format]string{
+	XLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	CSV:    "text/csv; charset=utf-8",
+	JSON:   "application/json",
+	NDJSON: "application/x-ndjson",
+}
+
+// acceptedTypes maps the media types of the Accept header to their format
+var acceptedTypes = map[string]Format{
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": XLSX,
+	"text/csv":             CSV,
+	"application/json":     JSON,
+	"application/x-ndjson": NDJSON,
+	"application/ndjson":   NDJSON,
+}
+
+// IsDefault reports whether the format selects the default response of an endpoint that responded with json
+// before formats, json is what most clients accept so it selects the default response like no format
+func (f Format) IsDefault() bool {
+	return f == "" || f == JSON
+}
+
+func (f Format) IsValid() bool {
+	_, ok := contentTypes[f]
+	return ok
+}
+
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// Negotiate returns the format requested with the format query param or the Accept header
+// blank when neither requests a format, the endpoint then responds as it did before formats
+func Negotiate(r *http.Request) (Format, error) {
+	if value := strings.TrimSpace(r.URL.Query().Get("format")); value != "" {
+		format := Format(strings.ToLower(value))
+		if !format.IsValid() {
+			return "", &custom.RequestError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid format, must be one of xlsx, csv, json or ndjson.",
+			}
+		}
+		return format, nil
+	}
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if format, ok := acceptedTypes[mediaType]; ok {
+			return format, nil
+		}
+	}
+	return "", nil
+}
+
+// Column is a leaf column of a table, path is its heading under the headings of its groups
+type Column struct {
+	Path     []string
+	Monetary bool
+}
+
+// Heading is the flattened path of the column such as "Sale Details > Total Price"
+func (c Column) Heading() string {
+	return strings.Join(c.Path, " > ")
+}
+
+// Table is a report as rows under columns
+// Each calls fn with every row in order, a row has a value per column
+// values are strings, decimals, ints, dates or nil for a blank cell, the row is reused after fn returns
+type Table struct {
+	Sheet   string
+	Columns []Column
+	Each    func(fn func(row []any) error) error
+}
+
+// Rows returns a table over rows held in memory
+func Rows(sheet string, columns []Column, rows [][]any) *Table {
+	return &Table{
+		Sheet:   sheet,
+		Columns: columns,
+		Each: func(fn func(row []any) error) error {
+			for _, row := range rows {
+				if err := fn(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+const dateLayout = "2006-01-02"
+
+// text formats a value for csv and xlsx text cells
+func text(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case decimal.Decimal:
+		return v.String()
+	case *decimal.Decimal:
+		if v == nil {
+			return ""
+		}
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(dateLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
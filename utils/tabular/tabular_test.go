@@ -0,0 +1,174 @@
+package tabular
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+func sampleTable() *Table {
+	columns := []Column{
+		{Path: []string{"Sale Number"}},
+		{Path: []string{"Sale Details", "Booked On"}},
+		{Path: []string{"Sale Details", "Total Price"}, Monetary: true},
+		{Path: []string{"Customer", "Name"}},
+	}
+	return Rows("Sales", columns, [][]any{
+		{"A00001", time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC), decimal.RequireFromString("9500000.50"), "Asha, \"Ravi\""},
+		{"A00002", nil, decimal.NewFromInt(0), nil},
+	})
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		target string
+		accept string
+		want   Format
+		err    bool
+	}{
+		{"/report", "", "", false},
+		{"/report", "application/json, text/plain, */*", JSON, false},
+		{"/report", "text/plain, */*", "", false},
+		{"/report?format=CSV", "application/json", CSV, false},
+		{"/report?format=json", "text/csv", JSON, false},
+		{"/report", "text/csv; charset=utf-8", CSV, false},
+		{"/report", "application/x-ndjson", NDJSON, false},
+		{"/report", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", XLSX, false},
+		{"/report?format=pdf", "", "", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.target, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		got, err := Negotiate(r)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%s accept %q: got %q, %v", tt.target, tt.accept, got, err)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, sampleTable()); err != nil {
+		t.Fatal(err)
+	}
+	want := "Sale Number,Sale Details > Booked On,Sale Details > Total Price,Customer > Name\n" +
+		"A00001,2024-03-05,9500000.5,\"Asha, \"\"Ravi\"\"\"\n" +
+		"A00002,,0,\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteJSONAndNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, sampleTable()); err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("want 2 rows, got %d", len(rows))
+	}
+	if rows[0]["Sale Details > Total Price"] != 9500000.5 || rows[1]["Customer > Name"] != nil {
+		t.Errorf("unexpected rows %v", rows)
+	}
+	// keys are in column order
+	if !strings.HasPrefix(buf.String(), `[{"Sale Number":"A00001","Sale Details > Booked On":"2024-03-05"`) {
+		t.Errorf("keys out of order: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteNDJSON(&buf, sampleTable()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %d", len(lines))
+	}
+	var row map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row["Sale Number"] != rows[0]["Sale Number"] || row["Sale Details > Total Price"] != rows[0]["Sale Details > Total Price"] {
+		t.Errorf("ndjson row %v differs from json row %v", row, rows[0])
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, sampleTable()); err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	rows, err := file.GetRows("Sales", excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 header rows for the group headings and a row per sale
+	if len(rows) != 4 {
+		t.Fatalf("want 4 rows, got %d", len(rows))
+	}
+	if rows[0][0] != "Sale Number" || rows[0][1] != "Sale Details" || rows[0][3] != "Customer" {
+		t.Errorf("unexpected group headings %v", rows[0])
+	}
+	if rows[1][1] != "Booked On" || rows[1][2] != "Total Price" || rows[1][3] != "Name" {
+		t.Errorf("unexpected column headings %v", rows[1])
+	}
+	if rows[2][2] != "9500000.5" {
+		t.Errorf("want total price as number, got %q", rows[2][2])
+	}
+
+	merged, err := file.GetMergeCells("Sales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranges := make(map[string]bool)
+	for _, m := range merged {
+		ranges[m.GetStartAxis()+":"+m.GetEndAxis()] = true
+	}
+	if !ranges["A1:A2"] || !ranges["B1:C1"] {
+		t.Errorf("unexpected merges %v", ranges)
+	}
+}
+
+func TestWriteWorkbook(t *testing.T) {
+	summary := Rows("Summary", []Column{{Path: []string{"Particulars"}}, {Path: []string{"Amount"}, Monetary: true}}, [][]any{
+		{"Collected", decimal.NewFromInt(100)},
+	})
+
+	var buf bytes.Buffer
+	if err := WriteWorkbook(&buf, summary, sampleTable()); err != nil {
+		t.Fatal(err)
+	}
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if sheets := file.GetSheetList(); len(sheets) != 2 || sheets[0] != "Summary" || sheets[1] != "Sales" {
+		t.Fatalf("want a sheet per table in order, got %v", sheets)
+	}
+	rows, err := file.GetRows("Summary", excelize.Options{RawCellValue: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][0] != "Collected" || rows[1][1] != "100" {
+		t.Errorf("unexpected summary rows %v", rows)
+	}
+}
@@ -0,0 +1,355 @@
+package tabular
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"circledigital.in/real-state-erp/utils/payload"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+// Write writes the table to w in the format
+func Write(w io.Writer, format Format, table *Table) error {
+	switch format {
+	case XLSX:
+		return WriteXLSX(w, table)
+	case CSV:
+		return WriteCSV(w, table)
+	case JSON:
+		return WriteJSON(w, table)
+	case NDJSON:
+		return WriteNDJSON(w, table)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// Respond writes the table as an attachment named name with the extension of the format
+// the table is written to memory first so a failure is still reported as an error response
+func Respond(w http.ResponseWriter, format Format, name string, table *Table) {
+	var buf bytes.Buffer
+	if err := Write(&buf, format, table); err != nil {
+		payload.HandleError(w, err)
+		return
+	}
+	RespondFile(w, format, name, &buf)
+}
+
+// RespondFile writes a report already written in the format as an attachment
+func RespondFile(w http.ResponseWriter, format Format, name string, report *bytes.Buffer) {
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", name, format.Extension()))
+	w.Header().Set("Content-Length", fmt.Sprint(report.Len()))
+
+	if _, err := w.Write(report.Bytes()); err != nil {
+		payload.HandleError(w, err)
+	}
+}
+
+// WriteCSV writes a header row of the flattened column paths and a record per row
+func WriteCSV(w io.Writer, table *Table) error {
+	writer := csv.NewWriter(w)
+
+	record := make([]string, len(table.Columns))
+	for i, column := range table.Columns {
+		record[i] = column.Heading()
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+
+	err := table.Each(func(row []any) error {
+		for i := range record {
+			record[i] = ""
+			if i < len(row) {
+				record[i] = text(row[i])
+			}
+		}
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the rows as an array of objects keyed by the flattened column paths
+func WriteJSON(w io.Writer, table *Table) error {
+	buffered := bufio.NewWriter(w)
+	if _, err := buffered.WriteString("["); err != nil {
+		return err
+	}
+
+	first := true
+	err := table.Each(func(row []any) error {
+		if !first {
+			if err := buffered.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		first = false
+		return writeObject(buffered, table.Columns, row)
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := buffered.WriteString("]\n"); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// WriteNDJSON writes an object per row on its own line, keyed by the flattened column paths
+func WriteNDJSON(w io.Writer, table *Table) error {
+	buffered := bufio.NewWriter(w)
+	err := table.Each(func(row []any) error {
+		if err := writeObject(buffered, table.Columns, row); err != nil {
+			return err
+		}
+		return buffered.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// writeObject writes the row as an object with keys in column order, decimals are written as numbers
+func writeObject(w *bufio.Writer, columns []Column, row []any) error {
+	if err := w.WriteByte('{'); err != nil {
+		return err
+	}
+	for i, column := range columns {
+		if i > 0 {
+			if err := w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+
+		var value any
+		if i < len(row) {
+			value = jsonValue(row[i])
+		}
+		if err := writeJSONValue(w, column.Heading()); err != nil {
+			return err
+		}
+		if err := w.WriteByte(':'); err != nil {
+			return err
+		}
+		if err := writeJSONValue(w, value); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('}')
+}
+
+// writeJSONValue writes the value without html escaping so headings keep their ">"
+func writeJSONValue(w *bufio.Writer, value any) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	// the encoder ends every value with a newline
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case decimal.Decimal:
+		return json.Number(v.String())
+	case *decimal.Decimal:
+		if v == nil {
+			return nil
+		}
+		return json.Number(v.String())
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.Format(dateLayout)
+	}
+	return value
+}
+
+// IndianNumberFormat is the excel number format of monetary cells without symbol: 1,00,00,000
+const IndianNumberFormat = `[>=10000000]##\,##\,##\,##0;[>=100000]##\,##\,##0;##,##0`
+
+// WriteXLSX writes the table to a single sheet, the column paths are laid out as merged header rows
+// monetary values are written as numbers in indian format
+func WriteXLSX(w io.Writer, table *Table) error {
+	return WriteWorkbook(w, table)
+}
+
+// WriteWorkbook writes every table to its own sheet in order, like WriteXLSX does for a single table
+func WriteWorkbook(w io.Writer, tables ...*Table) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	headerStyle, err := file.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true},
+		Alignment: &excelize.Alignment{Horizontal: "center", Vertical: "center"},
+	})
+	if err != nil {
+		return err
+	}
+	numberFormat := IndianNumberFormat
+	numberStyle, err := file.NewStyle(&excelize.Style{
+		CustomNumFmt: &numberFormat,
+		Alignment:    &excelize.Alignment{Horizontal: "right", Vertical: "center"},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if err := writeSheet(file, table, headerStyle, numberStyle); err != nil {
+			return err
+		}
+	}
+	if err := file.DeleteSheet("Sheet1"); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
+
+func writeSheet(file *excelize.File, table *Table, headerStyle, numberStyle int) error {
+	sheet := table.Sheet
+	if sheet == "" {
+		sheet = "Report"
+	}
+	if _, err := file.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	writer, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	headerRows, merges := layoutColumns(table.Columns, headerStyle)
+	for col, column := range table.Columns {
+		width := max(float64(min(len(column.Path[len(column.Path)-1]), 15))*1.2, 12)
+		if err := writer.SetColWidth(col+1, col+1, width); err != nil {
+			return err
+		}
+	}
+	for i, row := range headerRows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := writer.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	for _, merge := range merges {
+		if err := writer.MergeCell(merge[0], merge[1]); err != nil {
+			return err
+		}
+	}
+
+	rowNum := len(headerRows) + 1
+	cells := make([]any, len(table.Columns))
+	err = table.Each(func(row []any) error {
+		for i, column := range table.Columns {
+			var value any
+			if i < len(row) {
+				value = row[i]
+			}
+			cells[i] = xlsxValue(value, column.Monetary, numberStyle)
+		}
+
+		cell, _ := excelize.CoordinatesToCellName(1, rowNum)
+		rowNum++
+		return writer.SetRow(cell, cells)
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+func xlsxValue(value any, monetary bool, numberStyle int) any {
+	switch v := value.(type) {
+	case decimal.Decimal:
+		if monetary {
+			return excelize.Cell{StyleID: numberStyle, Value: v.InexactFloat64()}
+		}
+		return v.InexactFloat64()
+	case *decimal.Decimal:
+		if v == nil {
+			return nil
+		}
+		return xlsxValue(*v, monetary, numberStyle)
+	case int, int64:
+		return v
+	}
+	return text(value)
+}
+
+// layoutColumns lays the column paths out as header rows with the cell ranges to merge
+// consecutive columns sharing a heading at a level are merged across, a path shorter than the deepest is merged down
+func layoutColumns(columns []Column, style int) ([][]any, [][2]string) {
+	depth := 1
+	for _, column := range columns {
+		depth = max(depth, len(column.Path))
+	}
+
+	rows := make([][]any, depth)
+	for i := range rows {
+		rows[i] = make([]any, len(columns))
+	}
+
+	var merges [][2]string
+	for level := 0; level < depth; level++ {
+		for start := 0; start < len(columns); {
+			path := columns[start].Path
+			if level >= len(path) {
+				start++
+				continue
+			}
+
+			end := start
+			leaf := level == len(path)-1
+			if !leaf {
+				for end+1 < len(columns) && samePrefix(columns[end+1].Path, path, level+1) && len(columns[end+1].Path) > level+1 {
+					end++
+				}
+			}
+
+			rows[level][start] = excelize.Cell{StyleID: style, Value: path[level]}
+			bottom := level
+			if leaf {
+				bottom = depth - 1
+			}
+			if end > start || bottom > level {
+				topLeft, _ := excelize.CoordinatesToCellName(start+1, level+1)
+				bottomRight, _ := excelize.CoordinatesToCellName(end+1, bottom+1)
+				merges = append(merges, [2]string{topLeft, bottomRight})
+			}
+			start = end + 1
+		}
+	}
+	return rows, merges
+}
+
+func samePrefix(a, b []string, n int) bool {
+	if len(a) < n || len(b) < n {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,239 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"circledigital.in/real-state-erp/utils/lease"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	// MaxAttempts is the number of attempts of a delivery before it is marked failed
+	MaxAttempts     = 8
+	requestTimeout  = 10 * time.Second
+	maxResponseBody = 1024
+)
+
+// deliveries are the due webhook deliveries, a lease outlasts many requests of requestTimeout
+var deliveries = lease.Queue{
+	Model:          &models.WebhookDelivery{},
+	Pending:        custom.DELIVERY_PENDING,
+	BatchSize:      50,
+	Lease:          2 * time.Minute,
+	RetryBaseDelay: time.Minute,
+}
+
+// envelope is the body of a delivery
+type envelope struct {
+	Id        uuid.UUID           `json:"id"`
+	Event     custom.WebhookEvent `json:"event"`
+	OrgId     uuid.UUID           `json:"orgId"`
+	SocietyId string              `json:"societyId,omitempty"`
+	EntityId  string              `json:"entityId"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Data      models.RawJSON      `json:"data"`
+}
+
+// Dispatcher creates deliveries of outbox events and sends due deliveries
+// rows are claimed with skip locked so several instances can run the dispatcher
+type Dispatcher struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+func NewDispatcher(db *gorm.DB) *Dispatcher {
+	return &Dispatcher{
+		db:     db,
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Run dispatches events until the context is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.dispatchEvents(); err != nil {
+			log.Printf("Error dispatching webhook events: %v\n", err)
+		}
+		if err := d.deliverDue(); err != nil {
+			log.Printf("Error delivering webhooks: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchEvents creates a delivery for every enabled endpoint subscribed to the undispatched events and allowed on their society
+func (d *Dispatcher) dispatchEvents() error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("dispatched_at is null").
+			Order("created_at").
+			Limit(deliveries.BatchSize).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		now := time.Now()
+		ids := make([]uuid.UUID, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.Id)
+
+			// endpoints allowed on some societies receive only the events of those societies
+			query := tx.Where("org_id = ? and enabled = true and events @> ?::jsonb", event.OrgId, fmt.Sprintf("[%q]", event.Event))
+			if event.SocietyId == "" {
+				query = query.Where("jsonb_array_length(societies) = 0")
+			} else {
+				query = query.Where("(jsonb_array_length(societies) = 0 or societies @> ?::jsonb)", fmt.Sprintf("[%q]", event.SocietyId))
+			}
+
+			var endpoints []models.WebhookEndpoint
+			err := query.Find(&endpoints).Error
+			if err != nil {
+				return err
+			}
+
+			for _, endpoint := range endpoints {
+				err := tx.Create(&models.WebhookDelivery{
+					OrgId:         event.OrgId,
+					EndpointId:    endpoint.Id,
+					EventId:       event.Id,
+					Status:        custom.DELIVERY_PENDING,
+					NextAttemptAt: &now,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("dispatched_at", now).Error
+	})
+}
+
+// deliverDue claims the due deliveries and sends them one after another,
+// the lease of every delivery is renewed just before it is sent as the batch may outlast the lease of the claim
+func (d *Dispatcher) deliverDue() error {
+	ids, leasedUntil, err := deliveries.Claim(d.db)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	var claimed []models.WebhookDelivery
+	err = d.db.Preload("Endpoint").Preload("OutboxEvent").Where("id IN ?", ids).Find(&claimed).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range claimed {
+		renewed, err := deliveries.Renew(d.db, claimed[i].Id, leasedUntil)
+		if err != nil {
+			return err
+		}
+		if !renewed {
+			continue
+		}
+		if err := d.deliver(&claimed[i]); err != nil {
+			log.Printf("Error recording webhook delivery %s: %v\n", claimed[i].Id, err)
+		}
+	}
+	return nil
+}
+
+// deliver sends one attempt of the delivery and records its result
+// non 2xx responses and network errors are retried with backoff until MaxAttempts
+func (d *Dispatcher) deliver(delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	updates := map[string]any{
+		"attempts":        delivery.Attempts + 1,
+		"last_attempt_at": now,
+		"response_status": 0,
+		"response_body":   "",
+		"error":           "",
+	}
+
+	status, body, err := d.send(delivery, now)
+	updates["response_status"] = status
+	updates["response_body"] = body
+	switch {
+	case err == nil:
+		updates["status"] = custom.DELIVERY_SUCCEEDED
+		updates["next_attempt_at"] = nil
+	case delivery.Endpoint == nil || !delivery.Endpoint.Enabled || delivery.Attempts+1 >= MaxAttempts:
+		updates["status"] = custom.DELIVERY_FAILED
+		updates["next_attempt_at"] = nil
+		updates["error"] = err.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(deliveries.RetryDelay(delivery.Attempts + 1))
+		updates["error"] = err.Error()
+	}
+
+	return d.db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.Id).Updates(updates).Error
+}
+
+// send posts the signed event to the endpoint and returns the response status and truncated body
+func (d *Dispatcher) send(delivery *models.WebhookDelivery, now time.Time) (int, string, error) {
+	if delivery.Endpoint == nil || delivery.OutboxEvent == nil {
+		return 0, "", fmt.Errorf("endpoint or event no longer exists")
+	}
+	if !delivery.Endpoint.Enabled {
+		return 0, "", fmt.Errorf("endpoint is disabled")
+	}
+
+	event := delivery.OutboxEvent
+	body, err := json.Marshal(envelope{
+		Id:        event.Id,
+		Event:     event.Event,
+		OrgId:     event.OrgId,
+		SocietyId: event.SocietyId,
+		EntityId:  event.EntityId,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Data,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.Endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "real-estate-erp-webhook")
+	req.Header.Set("X-Webhook-Event", string(event.Event))
+	req.Header.Set("X-Webhook-Id", event.Id.String())
+	req.Header.Set("X-Webhook-Delivery", delivery.Id.String())
+	req.Header.Set(SignatureHeader, Sign(delivery.Endpoint.Secret, now, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer res.Body.Close()
+
+	resBody, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBody))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, string(resBody), fmt.Errorf("endpoint responded with status %d", res.StatusCode)
+	}
+	return res.StatusCode, string(resBody), nil
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"encoding/json"
+
+	"circledigital.in/real-state-erp/models"
+	"circledigital.in/real-state-erp/utils/custom"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// package webhook delivers domain events to the webhook endpoints of organizations
+
+// Publish writes the event to the outbox with the transaction of the change it describes
+// the event is delivered only if the transaction commits, and is not lost when delivery fails
+func Publish(tx *gorm.DB, orgId, society string, event custom.WebhookEvent, entityId string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.OutboxEvent{
+		OrgId:     uuid.MustParse(orgId),
+		SocietyId: society,
+		Event:     event,
+		EntityId:  entityId,
+		Data:      raw,
+	}).Error
+}
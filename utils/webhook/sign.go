@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SignatureHeader carries the signature of a delivery as "t=<unix time>,v1=<signature>"
+// signature is hex encoded HMAC-SHA256 of "<unix time>.<body>" with the endpoint secret
+const SignatureHeader = "X-Webhook-Signature"
+
+// secretPrefix starts every endpoint secret
+const secretPrefix = "whsec_"
+
+// NewSecret creates a random signing secret for an endpoint
+func NewSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(buf), nil
+}
+
+// Sign returns the signature header value of the body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, signature(secret, unix, body))
+}
+
+func signature(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
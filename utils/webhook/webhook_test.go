@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"sale.created"}`)
+
+	got := Sign("whsec_test", timestamp, body)
+	if !strings.HasPrefix(got, "t=1700000000,v1=") {
+		t.Fatalf("unexpected signature header %q", got)
+	}
+	if got != Sign("whsec_test", timestamp, body) {
+		t.Error("want signature to be deterministic")
+	}
+	if got == Sign("whsec_other", timestamp, body) {
+		t.Error("want signature to depend on secret")
+	}
+	if got == Sign("whsec_test", timestamp, []byte(`{}`)) {
+		t.Error("want signature to depend on body")
+	}
+}